@@ -0,0 +1,128 @@
+package apitoken
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"strings"
+
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+)
+
+// Prefix is prepended to every personal access token, so that they can be told apart from session JWTs in the
+// Authorization header, and picked up by secret scanners
+const Prefix = "tpat_"
+
+const secretLength = 40
+
+type Access string
+
+const (
+	AccessRead  Access = "read"
+	AccessWrite Access = "write"
+)
+
+// Resources are the first path segment of guild routes, e.g. /api/:id/tickets. A token can be granted read or write
+// access to each of them.
+var Resources = []string{
+	"guild",
+	"channels",
+	"roles",
+	"emojis",
+	"members",
+	"user",
+	"premium",
+	"settings",
+	"blacklist",
+	"panels",
+	"multipanels",
+	"forms",
+	"transcripts",
+	"tickets",
+	"tags",
+	"team",
+	"staff-override",
+	"integrations",
+	"audit-log",
+	"config",
+	"webhooks",
+	"analytics",
+	"ticket-labels",
+	"sla-policies",
+	"embed-templates",
+}
+
+// readOnlyRoutes are routes that use a method other than GET, but do not modify anything
+var readOnlyRoutes = map[string]struct{}{
+	"POST /api/:id/transcripts": {},
+}
+
+// Generate returns a new token, and the hash that should be stored in its place
+func Generate() (string, []byte, error) {
+	secret, err := utils.RandString(secretLength)
+	if err != nil {
+		return "", nil, err
+	}
+
+	token := Prefix + secret
+	return token, Hash(token), nil
+}
+
+func Hash(token string) []byte {
+	hash := sha256.Sum256([]byte(token))
+	return hash[:]
+}
+
+// DisplayPrefix returns the start of the token, which is stored so users can identify their tokens
+func DisplayPrefix(token string) string {
+	return utils.StringMax(token, len(Prefix)+4)
+}
+
+// IsValidScope reports whether scope is of the form <resource>:<read|write>
+func IsValidScope(scope string) bool {
+	resource, access, ok := strings.Cut(scope, ":")
+	if !ok || (Access(access) != AccessRead && Access(access) != AccessWrite) {
+		return false
+	}
+
+	return utils.Contains(Resources, resource)
+}
+
+// RequiredScope returns the scope needed to call a guild route, given the request method and the route pattern, e.g.
+// /api/:id/tickets/:ticketId. GET requests only need read access.
+func RequiredScope(method, route string) (string, bool) {
+	trimmed := strings.TrimPrefix(route, "/api/:id/")
+	if trimmed == route {
+		return "", false
+	}
+
+	resource, _, _ := strings.Cut(trimmed, "/")
+	if !utils.Contains(Resources, resource) {
+		return "", false
+	}
+
+	access := AccessWrite
+	if method == http.MethodGet || method == http.MethodHead {
+		access = AccessRead
+	} else if _, ok := readOnlyRoutes[method+" "+route]; ok {
+		access = AccessRead
+	}
+
+	return resource + ":" + string(access), true
+}
+
+// HasScope reports whether the granted scopes include required. Write access implies read access.
+func HasScope(granted []string, required string) bool {
+	resource, access, _ := strings.Cut(required, ":")
+
+	for _, scope := range granted {
+		if scope == required {
+			return true
+		}
+
+		if Access(access) == AccessRead && scope == resource+":"+string(AccessWrite) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,38 @@
+package apitoken
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequiredScope(t *testing.T) {
+	scope, ok := RequiredScope("GET", "/api/:id/tickets/:ticketId")
+	assert.True(t, ok)
+	assert.Equal(t, "tickets:read", scope)
+
+	scope, ok = RequiredScope("DELETE", "/api/:id/tickets/:ticketId")
+	assert.True(t, ok)
+	assert.Equal(t, "tickets:write", scope)
+
+	scope, ok = RequiredScope("POST", "/api/:id/transcripts")
+	assert.True(t, ok)
+	assert.Equal(t, "transcripts:read", scope)
+
+	_, ok = RequiredScope("GET", "/api/admin/bot-staff")
+	assert.False(t, ok)
+}
+
+func TestHasScope(t *testing.T) {
+	assert.True(t, HasScope([]string{"tickets:read"}, "tickets:read"))
+	assert.True(t, HasScope([]string{"tickets:write"}, "tickets:read"))
+	assert.False(t, HasScope([]string{"tickets:read"}, "tickets:write"))
+	assert.False(t, HasScope([]string{"transcripts:write"}, "tickets:read"))
+}
+
+func TestIsValidScope(t *testing.T) {
+	assert.True(t, IsValidScope("transcripts:read"))
+	assert.False(t, IsValidScope("transcripts"))
+	assert.False(t, IsValidScope("transcripts:delete"))
+	assert.False(t, IsValidScope("unknown:read"))
+}
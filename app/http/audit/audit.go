@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	beforeKey = "audit_before"
+	afterKey  = "audit_after"
+	skipKey   = "audit_skip"
+)
+
+// SetBefore records the state of the resource before the request modified it. Handlers should call this once they have
+// loaded the existing resource, so that the audit log can show what changed.
+func SetBefore(ctx *gin.Context, v any) {
+	ctx.Set(beforeKey, v)
+}
+
+// SetAfter records the state of the resource after the request. If it is not called, the JSON request body is used.
+func SetAfter(ctx *gin.Context, v any) {
+	ctx.Set(afterKey, v)
+}
+
+// Skip prevents the request from being recorded, for requests that did not modify anything
+func Skip(ctx *gin.Context) {
+	ctx.Set(skipKey, true)
+}
+
+func IsSkipped(ctx *gin.Context) bool {
+	return ctx.GetBool(skipKey)
+}
+
+func GetBefore(ctx *gin.Context) (json.RawMessage, error) {
+	return getEncoded(ctx, beforeKey)
+}
+
+func GetAfter(ctx *gin.Context) (json.RawMessage, error) {
+	return getEncoded(ctx, afterKey)
+}
+
+func getEncoded(ctx *gin.Context, key string) (json.RawMessage, error) {
+	v, ok := ctx.Get(key)
+	if !ok || v == nil {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return Redact(encoded)
+}
@@ -0,0 +1,145 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type Change struct {
+	Path   string `json:"path"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// redactedKeys are object keys whose values are never stored in the audit log
+var redactedKeys = map[string]struct{}{
+	"secrets":  {},
+	"secret":   {},
+	"token":    {},
+	"password": {},
+}
+
+const redactedValue = "[redacted]"
+
+// Diff compares two JSON documents, returning the changed leaf values keyed by their JSON pointer. Arrays are compared
+// as a whole, as element positions are rarely meaningful.
+func Diff(before, after json.RawMessage) ([]Change, error) {
+	beforeValue, err := decode(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterValue, err := decode(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	diffValues("", beforeValue, afterValue, &changes)
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes, nil
+}
+
+func diffValues(path string, before, after any, changes *[]Change) {
+	beforeMap, beforeIsMap := before.(map[string]any)
+	afterMap, afterIsMap := after.(map[string]any)
+
+	// Treat a created or deleted object as an empty one, so that each field is listed individually
+	if before == nil && afterIsMap {
+		beforeMap, beforeIsMap = map[string]any{}, true
+	} else if after == nil && beforeIsMap {
+		afterMap, afterIsMap = map[string]any{}, true
+	}
+
+	if beforeIsMap && afterIsMap {
+		for key, beforeValue := range beforeMap {
+			diffValues(path+"/"+escapePointer(key), beforeValue, afterMap[key], changes)
+		}
+
+		for key, afterValue := range afterMap {
+			if _, ok := beforeMap[key]; !ok {
+				diffValues(path+"/"+escapePointer(key), nil, afterValue, changes)
+			}
+		}
+
+		return
+	}
+
+	// Clients are inconsistent about sending null or [] for an empty list
+	if isEmptyArray(before) && isEmptyArray(after) {
+		return
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, Change{
+			Path:   path,
+			Before: before,
+			After:  after,
+		})
+	}
+}
+
+func isEmptyArray(value any) bool {
+	if value == nil {
+		return true
+	}
+
+	array, ok := value.([]any)
+	return ok && len(array) == 0
+}
+
+// Redact replaces the values of sensitive keys anywhere in the document
+func Redact(document json.RawMessage) (json.RawMessage, error) {
+	value, err := decode(document)
+	if err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		return document, nil
+	}
+
+	return json.Marshal(redactValue(value))
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if _, ok := redactedKeys[strings.ToLower(key)]; ok && child != nil {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactValue(child)
+			}
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = redactValue(child)
+		}
+	}
+
+	return value
+}
+
+func decode(document json.RawMessage) (any, error) {
+	if len(document) == 0 {
+		return nil, nil
+	}
+
+	var value any
+	if err := json.Unmarshal(document, &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffChangedFields(t *testing.T) {
+	before := json.RawMessage(`{"title":"Support","colour":1,"embed":{"title":"a"},"teams":[1,2]}`)
+	after := json.RawMessage(`{"title":"Support","colour":2,"embed":{"title":"b"},"teams":[1]}`)
+
+	changes, err := Diff(before, after)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Path: "/colour", Before: float64(1), After: float64(2)},
+		{Path: "/embed/title", Before: "a", After: "b"},
+		{Path: "/teams", Before: []any{float64(1), float64(2)}, After: []any{float64(1)}},
+	}, changes)
+}
+
+func TestDiffCreated(t *testing.T) {
+	changes, err := Diff(nil, json.RawMessage(`{"name":"Team","a/b":true}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Path: "/a~1b", Before: nil, After: true},
+		{Path: "/name", Before: nil, After: "Team"},
+	}, changes)
+}
+
+func TestDiffNoChanges(t *testing.T) {
+	changes, err := Diff(json.RawMessage(`{"a":1}`), json.RawMessage(`{"a":1}`))
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestDiffNullEqualsEmptyArray(t *testing.T) {
+	changes, err := Diff(json.RawMessage(`{"teams":null}`), json.RawMessage(`{"teams":[]}`))
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestRedact(t *testing.T) {
+	redacted, err := Redact(json.RawMessage(`{"secrets":{"api_key":"hunter2"},"nested":[{"token":"abc"}],"name":"x"}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"secrets":"[redacted]","nested":[{"token":"[redacted]"}],"name":"x"}`, string(redacted))
+}
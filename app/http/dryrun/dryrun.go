@@ -0,0 +1,75 @@
+package dryrun
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+)
+
+type Response struct {
+	Success bool           `json:"success"`
+	DryRun  bool           `json:"dry_run"`
+	Changes []audit.Change `json:"changes"`
+}
+
+// Requested reports whether the request was made with ?dry_run=true. Handlers should run their validation as normal,
+// and then call Respond instead of writing to the database or making any changes on Discord.
+func Requested(ctx *gin.Context) bool {
+	dryRun, _ := strconv.ParseBool(ctx.Query("dry_run"))
+	return dryRun
+}
+
+// Respond writes the changes that the request would have made. before should be nil for resources that would be
+// created, and after should be nil for resources that would be deleted. Both are encoded as JSON before comparison, so
+// should share a representation for the diff to be meaningful.
+func Respond(ctx *gin.Context, before, after any) {
+	// Nothing was modified, so there is nothing to record in the audit log
+	audit.Skip(ctx)
+
+	changes, err := diff(before, after)
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	ctx.JSON(200, Response{
+		Success: true,
+		DryRun:  true,
+		Changes: changes,
+	})
+}
+
+func diff(before, after any) ([]audit.Change, error) {
+	encodedBefore, err := encode(before)
+	if err != nil {
+		return nil, err
+	}
+
+	encodedAfter, err := encode(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := audit.Diff(encodedBefore, encodedAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	if changes == nil {
+		changes = make([]audit.Change, 0)
+	}
+
+	return changes, nil
+}
+
+func encode(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(v)
+}
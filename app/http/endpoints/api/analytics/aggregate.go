@@ -0,0 +1,220 @@
+package api
+
+import (
+	"sort"
+	"strings"
+
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+)
+
+const (
+	maxCloseReasons   = 10
+	otherCloseReasons = "Other"
+	noCloseReason     = "No reason specified"
+)
+
+type (
+	summary struct {
+		TicketCount              int           `json:"ticket_count"`
+		ClosedCount              int           `json:"closed_count"`
+		AverageFirstResponseSecs *float64      `json:"average_first_response_seconds"`
+		AverageResolutionSecs    *float64      `json:"average_resolution_seconds"`
+		AverageRating            *float64      `json:"average_rating"`
+		RatingCount              int           `json:"rating_count"`
+		CloseReasons             []reasonCount `json:"close_reasons"`
+	}
+
+	reasonCount struct {
+		Reason string `json:"reason"`
+		Count  int    `json:"count"`
+	}
+
+	staffSummary struct {
+		UserId uint64 `json:"user_id,string"`
+		summary
+	}
+
+	panelSummary struct {
+		// PanelId is nil for tickets not opened from a panel
+		PanelId *int `json:"panel_id"`
+		summary
+	}
+
+	// accumulator collects the totals for a summary
+	accumulator struct {
+		tickets, closed                int
+		responseTotal, resolutionTotal float64
+		responseCount                  int
+		ratingTotal                    int
+		ratingCount                    int
+		closeReasons                   map[string]int
+		closeReasonNames               map[string]string
+	}
+)
+
+func newAccumulator() *accumulator {
+	return &accumulator{
+		closeReasons:     make(map[string]int),
+		closeReasonNames: make(map[string]string),
+	}
+}
+
+func (a *accumulator) add(ticket dbclient.TicketStatistics) {
+	a.tickets++
+
+	if ticket.FirstResponseSeconds != nil {
+		a.responseTotal += *ticket.FirstResponseSeconds
+		a.responseCount++
+	}
+
+	if ticket.Rating != nil {
+		a.ratingTotal += int(*ticket.Rating)
+		a.ratingCount++
+	}
+
+	if ticket.ResolutionSeconds != nil {
+		a.closed++
+		a.resolutionTotal += *ticket.ResolutionSeconds
+
+		// Group reasons case-insensitively, displaying the first spelling seen
+		reason := noCloseReason
+		if ticket.CloseReason != nil && strings.TrimSpace(*ticket.CloseReason) != "" {
+			reason = strings.TrimSpace(*ticket.CloseReason)
+		}
+
+		key := strings.ToLower(reason)
+		if _, ok := a.closeReasonNames[key]; !ok {
+			a.closeReasonNames[key] = reason
+		}
+
+		a.closeReasons[key]++
+	}
+}
+
+func (a *accumulator) summary() summary {
+	s := summary{
+		TicketCount:  a.tickets,
+		ClosedCount:  a.closed,
+		RatingCount:  a.ratingCount,
+		CloseReasons: make([]reasonCount, 0, len(a.closeReasons)),
+	}
+
+	if a.responseCount > 0 {
+		s.AverageFirstResponseSecs = average(a.responseTotal, a.responseCount)
+	}
+
+	if a.closed > 0 {
+		s.AverageResolutionSecs = average(a.resolutionTotal, a.closed)
+	}
+
+	if a.ratingCount > 0 {
+		s.AverageRating = average(float64(a.ratingTotal), a.ratingCount)
+	}
+
+	for key, count := range a.closeReasons {
+		s.CloseReasons = append(s.CloseReasons, reasonCount{
+			Reason: a.closeReasonNames[key],
+			Count:  count,
+		})
+	}
+
+	sort.Slice(s.CloseReasons, func(i, j int) bool {
+		if s.CloseReasons[i].Count == s.CloseReasons[j].Count {
+			return s.CloseReasons[i].Reason < s.CloseReasons[j].Reason
+		}
+
+		return s.CloseReasons[i].Count > s.CloseReasons[j].Count
+	})
+
+	// Collapse the long tail of free-text reasons into a single entry
+	if len(s.CloseReasons) > maxCloseReasons {
+		var other int
+		for _, reason := range s.CloseReasons[maxCloseReasons-1:] {
+			other += reason.Count
+		}
+
+		s.CloseReasons = append(s.CloseReasons[:maxCloseReasons-1], reasonCount{
+			Reason: otherCloseReasons,
+			Count:  other,
+		})
+	}
+
+	return s
+}
+
+func average(total float64, count int) *float64 {
+	avg := total / float64(count)
+	return &avg
+}
+
+// aggregate builds the overall, per-staff and per-panel summaries. Tickets are attributed to the staff member who
+// claimed them, so unclaimed tickets only count towards the overall and per-panel figures.
+func aggregate(tickets []dbclient.TicketStatistics) (summary, []staffSummary, []panelSummary) {
+	overall := newAccumulator()
+	staff := make(map[uint64]*accumulator)
+	panels := make(map[int]*accumulator)
+	noPanel := newAccumulator()
+
+	for _, ticket := range tickets {
+		overall.add(ticket)
+
+		if ticket.ClaimedBy != nil {
+			acc, ok := staff[*ticket.ClaimedBy]
+			if !ok {
+				acc = newAccumulator()
+				staff[*ticket.ClaimedBy] = acc
+			}
+
+			acc.add(ticket)
+		}
+
+		if ticket.PanelId != nil {
+			acc, ok := panels[*ticket.PanelId]
+			if !ok {
+				acc = newAccumulator()
+				panels[*ticket.PanelId] = acc
+			}
+
+			acc.add(ticket)
+		} else {
+			noPanel.add(ticket)
+		}
+	}
+
+	staffSummaries := make([]staffSummary, 0, len(staff))
+	for userId, acc := range staff {
+		staffSummaries = append(staffSummaries, staffSummary{
+			UserId:  userId,
+			summary: acc.summary(),
+		})
+	}
+
+	sort.Slice(staffSummaries, func(i, j int) bool {
+		if staffSummaries[i].TicketCount == staffSummaries[j].TicketCount {
+			return staffSummaries[i].UserId < staffSummaries[j].UserId
+		}
+
+		return staffSummaries[i].TicketCount > staffSummaries[j].TicketCount
+	})
+
+	panelSummaries := make([]panelSummary, 0, len(panels)+1)
+	for panelId, acc := range panels {
+		panelId := panelId
+		panelSummaries = append(panelSummaries, panelSummary{
+			PanelId: &panelId,
+			summary: acc.summary(),
+		})
+	}
+
+	sort.Slice(panelSummaries, func(i, j int) bool {
+		return *panelSummaries[i].PanelId < *panelSummaries[j].PanelId
+	})
+
+	if noPanel.tickets > 0 {
+		panelSummaries = append(panelSummaries, panelSummary{
+			summary: noPanel.summary(),
+		})
+	}
+
+	return overall.summary(), staffSummaries, panelSummaries
+}
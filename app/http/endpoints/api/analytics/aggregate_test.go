@@ -0,0 +1,63 @@
+package api
+
+import (
+	"testing"
+
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregate(t *testing.T) {
+	tickets := []dbclient.TicketStatistics{
+		{TicketId: 1, PanelId: utils.Ptr(1), ClaimedBy: utils.Ptr(uint64(10)), FirstResponseSeconds: utils.Ptr(60.0), ResolutionSeconds: utils.Ptr(600.0), Rating: utils.Ptr(int16(5)), CloseReason: utils.Ptr("Resolved")},
+		{TicketId: 2, PanelId: utils.Ptr(1), ClaimedBy: utils.Ptr(uint64(10)), FirstResponseSeconds: utils.Ptr(120.0), ResolutionSeconds: utils.Ptr(1200.0), Rating: utils.Ptr(int16(3)), CloseReason: utils.Ptr("resolved ")},
+		{TicketId: 3, PanelId: utils.Ptr(2), ClaimedBy: utils.Ptr(uint64(20)), ResolutionSeconds: utils.Ptr(300.0)},
+		{TicketId: 4},
+	}
+
+	overall, staff, panels := aggregate(tickets)
+
+	assert.Equal(t, 4, overall.TicketCount)
+	assert.Equal(t, 3, overall.ClosedCount)
+	assert.Equal(t, 90.0, *overall.AverageFirstResponseSecs)
+	assert.Equal(t, 700.0, *overall.AverageResolutionSecs)
+	assert.Equal(t, 4.0, *overall.AverageRating)
+	assert.Equal(t, 2, overall.RatingCount)
+	assert.Equal(t, []reasonCount{{Reason: "Resolved", Count: 2}, {Reason: noCloseReason, Count: 1}}, overall.CloseReasons)
+
+	if assert.Len(t, staff, 2) {
+		assert.Equal(t, uint64(10), staff[0].UserId)
+		assert.Equal(t, 2, staff[0].TicketCount)
+		assert.Equal(t, uint64(20), staff[1].UserId)
+		assert.Nil(t, staff[1].AverageFirstResponseSecs)
+		assert.Nil(t, staff[1].AverageRating)
+	}
+
+	if assert.Len(t, panels, 3) {
+		assert.Equal(t, 1, *panels[0].PanelId)
+		assert.Equal(t, 2, *panels[1].PanelId)
+		assert.Nil(t, panels[2].PanelId)
+		assert.Equal(t, 1, panels[2].TicketCount)
+		assert.Equal(t, 0, panels[2].ClosedCount)
+	}
+}
+
+func TestAggregateCollapsesCloseReasons(t *testing.T) {
+	var tickets []dbclient.TicketStatistics
+	for i := 0; i < maxCloseReasons+5; i++ {
+		tickets = append(tickets, dbclient.TicketStatistics{
+			TicketId:          i,
+			ResolutionSeconds: utils.Ptr(1.0),
+			CloseReason:       utils.Ptr(string(rune('a' + i))),
+		})
+	}
+
+	overall, _, _ := aggregate(tickets)
+
+	if assert.Len(t, overall.CloseReasons, maxCloseReasons) {
+		last := overall.CloseReasons[maxCloseReasons-1]
+		assert.Equal(t, otherCloseReasons, last.Reason)
+		assert.Equal(t, 6, last.Count)
+	}
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/redis"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/rpc/cache"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/rxdn/gdl/objects/user"
+)
+
+const (
+	defaultRange = 30 * 24 * time.Hour
+	maxRange     = 366 * 24 * time.Hour
+	// maxTickets bounds the work done for a single request. Larger ranges are aggregated over the most recent tickets
+	// only, and the response covers the shorter range.
+	maxTickets = 50000
+)
+
+type (
+	analyticsQuery struct {
+		From *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+		To   *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	}
+
+	analyticsResponse struct {
+		From          time.Time            `json:"from"`
+		To            time.Time            `json:"to"`
+		Truncated     bool                 `json:"truncated"`
+		Overall       summary              `json:"overall"`
+		Staff         []staffSummary       `json:"staff"`
+		Panels        []panelSummary       `json:"panels"`
+		ResolvedUsers map[uint64]user.User `json:"resolved_users"`
+		PanelTitles   map[int]string       `json:"panel_titles"`
+		GeneratedAt   time.Time            `json:"generated_at"`
+	}
+)
+
+func GetAnalyticsHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	var query analyticsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(400, utils.ErrorJson(err))
+		return
+	}
+
+	// By default, cover up to the end of the current day, so that repeated requests share a cache entry
+	to := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	if query.To != nil {
+		to = query.To.UTC()
+	}
+
+	from := to.Add(-defaultRange)
+	if query.From != nil {
+		from = query.From.UTC()
+	}
+
+	if !from.Before(to) {
+		ctx.JSON(400, utils.ErrorStr("from must be before to"))
+		return
+	}
+
+	if to.Sub(from) > maxRange {
+		ctx.JSON(400, utils.ErrorStr("The date range cannot be longer than %d days", int(maxRange.Hours()/24)))
+		return
+	}
+
+	cached, ok, err := redis.Client.GetCachedAnalytics(ctx, guildId, from, to)
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	if ok {
+		ctx.Data(200, "application/json; charset=utf-8", cached)
+		return
+	}
+
+	tickets, coveredFrom, truncated, err := getTicketStatistics(ctx, guildId, from, to)
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	overall, staff, panels := aggregate(tickets)
+
+	userIds := make([]uint64, len(staff))
+	for i, s := range staff {
+		userIds[i] = s.UserId
+	}
+
+	users, err := cache.Instance.GetUsers(ctx, userIds)
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	guildPanels, err := dbclient.Client.Panel.GetByGuild(ctx, guildId)
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	panelTitles := make(map[int]string, len(guildPanels))
+	for _, panel := range guildPanels {
+		panelTitles[panel.PanelId] = panel.Title
+	}
+
+	encoded, err := json.Marshal(analyticsResponse{
+		From:          coveredFrom,
+		To:            to,
+		Truncated:     truncated,
+		Overall:       overall,
+		Staff:         staff,
+		Panels:        panels,
+		ResolvedUsers: users,
+		PanelTitles:   panelTitles,
+		GeneratedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	// Failing to cache the result should not fail the request
+	_ = redis.Client.SetCachedAnalytics(ctx, guildId, from, to, encoded)
+
+	ctx.Data(200, "application/json; charset=utf-8", encoded)
+}
+
+// getTicketStatistics returns the statistics for up to maxTickets of the tickets opened within [from, to), the most
+// recent first. If there are more, the start of the range covered by the returned tickets is returned instead of from.
+func getTicketStatistics(ctx context.Context, guildId uint64, from, to time.Time) ([]dbclient.TicketStatistics, time.Time, bool, error) {
+	// Fetch one more than maxTickets, to find out whether the range has been truncated
+	statistics, err := dbclient.Dashboard.Analytics.GetTicketStatistics(ctx, guildId, from, to, maxTickets+1)
+	if err != nil {
+		return nil, time.Time{}, false, err
+	}
+
+	if len(statistics) <= maxTickets {
+		return statistics, from, false, nil
+	}
+
+	statistics = statistics[:maxTickets]
+	return statistics, statistics[len(statistics)-1].OpenTime, true, nil
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/apitoken"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+	"github.com/jadevelopmentgrp/Tickets-Utilities/permission"
+)
+
+const (
+	maxTokens         = 10
+	maxGuildsPerToken = 25
+	maxTokenLifetime  = 365 * 24 * time.Hour
+)
+
+type (
+	createBody struct {
+		Name      string                  `json:"name"`
+		Guilds    types.UInt64StringSlice `json:"guilds"`
+		Scopes    []string                `json:"scopes"`
+		ExpiresAt *time.Time              `json:"expires_at"`
+	}
+
+	createResponse struct {
+		tokenResponse
+		// Token is only returned once, when it is created
+		Token string `json:"token"`
+	}
+)
+
+func CreateTokenHandler(ctx *gin.Context) {
+	userId := ctx.Keys["userid"].(uint64)
+
+	var body createBody
+	if err := ctx.BindJSON(&body); err != nil {
+		ctx.JSON(400, utils.ErrorJson(err))
+		return
+	}
+
+	if len(body.Name) == 0 || len(body.Name) > 32 {
+		ctx.JSON(400, utils.ErrorStr("Token name must be between 1 and 32 characters"))
+		return
+	}
+
+	if len(body.Guilds) == 0 || len(body.Guilds) > maxGuildsPerToken {
+		ctx.JSON(400, utils.ErrorStr("Tokens must be restricted to between 1 and %d servers", maxGuildsPerToken))
+		return
+	}
+
+	if len(body.Scopes) == 0 {
+		ctx.JSON(400, utils.ErrorStr("Tokens must have at least one scope"))
+		return
+	}
+
+	for _, scope := range body.Scopes {
+		if !apitoken.IsValidScope(scope) {
+			ctx.JSON(400, utils.ErrorStr("Invalid scope: %s", scope))
+			return
+		}
+	}
+
+	if body.ExpiresAt != nil {
+		if !body.ExpiresAt.After(time.Now()) {
+			ctx.JSON(400, utils.ErrorStr("The expiry time must be in the future"))
+			return
+		}
+
+		if time.Until(*body.ExpiresAt) > maxTokenLifetime {
+			ctx.JSON(400, utils.ErrorStr("Tokens cannot be valid for more than a year"))
+			return
+		}
+	}
+
+	// Tokens can never grant more access than the user has, as permissions are still checked on each request, but
+	// reject servers the user cannot access now to catch mistakes early
+	for _, guildId := range body.Guilds {
+		permissionLevel, err := utils.GetPermissionLevel(ctx, guildId, userId)
+		if err != nil {
+			ctx.JSON(500, utils.ErrorJson(err))
+			return
+		}
+
+		if permissionLevel < permission.Support {
+			ctx.JSON(400, utils.ErrorStr("You do not have access to the server %d", guildId))
+			return
+		}
+	}
+
+	count, err := dbclient.Dashboard.ApiTokens.Count(ctx, userId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if count >= maxTokens {
+		ctx.JSON(400, utils.ErrorStr("You have reached the token limit (%d/%d)", maxTokens, maxTokens))
+		return
+	}
+
+	secret, hash, err := apitoken.Generate()
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	token, err := dbclient.Dashboard.ApiTokens.Create(ctx, dbclient.ApiToken{
+		UserId:    userId,
+		Name:      body.Name,
+		Prefix:    apitoken.DisplayPrefix(secret),
+		Guilds:    utils.Unique(body.Guilds),
+		Scopes:    utils.Unique(body.Scopes),
+		ExpiresAt: body.ExpiresAt,
+	}, hash)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	ctx.JSON(200, createResponse{
+		tokenResponse: tokenToResponse(token),
+		Token:         secret,
+	})
+}
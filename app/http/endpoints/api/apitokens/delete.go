@@ -0,0 +1,32 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+)
+
+func DeleteTokenHandler(ctx *gin.Context) {
+	userId := ctx.Keys["userid"].(uint64)
+
+	tokenId, err := strconv.ParseInt(ctx.Param("tokenid"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, utils.ErrorStr("Invalid token ID"))
+		return
+	}
+
+	deleted, err := dbclient.Dashboard.ApiTokens.Delete(ctx, userId, tokenId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if !deleted {
+		ctx.JSON(404, utils.ErrorStr("Token not found"))
+		return
+	}
+
+	ctx.JSON(200, utils.SuccessResponse)
+}
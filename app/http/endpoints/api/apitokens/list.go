@@ -0,0 +1,51 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+)
+
+type tokenResponse struct {
+	Id         int64                   `json:"id"`
+	Name       string                  `json:"name"`
+	Prefix     string                  `json:"prefix"`
+	Guilds     types.UInt64StringSlice `json:"guilds"`
+	Scopes     []string                `json:"scopes"`
+	CreatedAt  time.Time               `json:"created_at"`
+	ExpiresAt  *time.Time              `json:"expires_at"`
+	LastUsedAt *time.Time              `json:"last_used_at"`
+}
+
+func ListTokensHandler(ctx *gin.Context) {
+	userId := ctx.Keys["userid"].(uint64)
+
+	tokens, err := dbclient.Dashboard.ApiTokens.GetByUser(ctx, userId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	res := make([]tokenResponse, len(tokens))
+	for i, token := range tokens {
+		res[i] = tokenToResponse(token)
+	}
+
+	ctx.JSON(200, res)
+}
+
+func tokenToResponse(token dbclient.ApiToken) tokenResponse {
+	return tokenResponse{
+		Id:         token.Id,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Guilds:     token.Guilds,
+		Scopes:     token.Scopes,
+		CreatedAt:  token.CreatedAt,
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+	}
+}
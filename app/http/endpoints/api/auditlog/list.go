@@ -0,0 +1,108 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/rpc/cache"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/rxdn/gdl/objects/user"
+	"net/http"
+)
+
+const (
+	defaultPageLimit = 25
+	maxPageLimit     = 100
+)
+
+type (
+	listQuery struct {
+		UserId *uint64    `form:"user_id"`
+		Action string     `form:"action"`
+		Since  *time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+		Until  *time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+		Cursor int64      `form:"cursor"`
+		Limit  int        `form:"limit"`
+	}
+
+	listResponse struct {
+		Entries       []entry              `json:"entries"`
+		ResolvedUsers map[uint64]user.User `json:"resolved_users"`
+		NextCursor    *string              `json:"next_cursor,omitempty"`
+	}
+
+	entry struct {
+		dbclient.AuditLogEntry
+		Changes []audit.Change `json:"changes"`
+	}
+)
+
+func ListAuditLogHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	var query listQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(400, utils.ErrorJson(err))
+		return
+	}
+
+	if query.Limit == 0 {
+		query.Limit = defaultPageLimit
+	} else if query.Limit < 0 || query.Limit > maxPageLimit {
+		ctx.JSON(400, utils.ErrorStr("Limit must be between 1 and %d", maxPageLimit))
+		return
+	}
+
+	entries, err := dbclient.Dashboard.AuditLog.GetByGuild(ctx, guildId, dbclient.AuditLogQueryOptions{
+		UserId:   query.UserId,
+		Action:   query.Action,
+		Since:    query.Since,
+		Until:    query.Until,
+		BeforeId: query.Cursor,
+		Limit:    query.Limit,
+	})
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	userIds := make([]uint64, 0, len(entries))
+	for _, entry := range entries {
+		userIds = append(userIds, entry.UserId)
+	}
+
+	users, err := cache.Instance.GetUsers(ctx, userIds)
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	data := make([]entry, len(entries))
+	for i, e := range entries {
+		changes, err := audit.Diff(e.Before, e.After)
+		if err != nil {
+			_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+			return
+		}
+
+		data[i] = entry{
+			AuditLogEntry: e,
+			Changes:       changes,
+		}
+	}
+
+	var nextCursor *string
+	if len(entries) == query.Limit {
+		nextCursor = utils.Ptr(strconv.FormatInt(entries[len(entries)-1].Id, 10))
+	}
+
+	ctx.JSON(200, listResponse{
+		Entries:       data,
+		ResolvedUsers: users,
+		NextCursor:    nextCursor,
+	})
+}
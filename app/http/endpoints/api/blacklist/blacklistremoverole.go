@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	"strconv"
@@ -16,6 +17,16 @@ func RemoveRoleBlacklistHandler(ctx *gin.Context) {
 		return
 	}
 
+	blacklistedRoles, err := database.Client.RoleBlacklist.GetBlacklistedRoles(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if utils.Contains(blacklistedRoles, roleId) {
+		audit.SetBefore(ctx, blacklistAddBody{EntityType: entityTypeRole, Snowflake: roleId})
+	}
+
 	if err := database.Client.RoleBlacklist.Remove(ctx, guildId, roleId); err != nil {
 		ctx.JSON(500, utils.ErrorJson(err))
 		return
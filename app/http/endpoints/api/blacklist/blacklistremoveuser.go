@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	"strconv"
@@ -16,6 +17,16 @@ func RemoveUserBlacklistHandler(ctx *gin.Context) {
 		return
 	}
 
+	blacklisted, err := database.Client.Blacklist.IsBlacklisted(ctx, guildId, userId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if blacklisted {
+		audit.SetBefore(ctx, blacklistAddBody{EntityType: entityTypeUser, Snowflake: userId})
+	}
+
 	if err := database.Client.Blacklist.Remove(ctx, guildId, userId); err != nil {
 		ctx.JSON(500, utils.ErrorJson(err))
 		return
@@ -0,0 +1,354 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v4"
+	api_forms "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/forms"
+	api_panels "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/panel"
+	api_tags "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/tags"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+	"github.com/rxdn/gdl/objects/channel"
+)
+
+// apply writes the validated document to the database in a single transaction, so that a failed import leaves the
+// guild unchanged. Panel messages and tag commands can only be sent to Discord once the transaction has been
+// committed; failures to do so are reported as warnings, as they can be retried from the dashboard.
+func (d *document) apply(
+	ctx context.Context,
+	guildId uint64,
+	botContext *botcontext.BotContext,
+	channels []channel.Channel,
+) (importResponse, error) {
+	res := importResponse{
+		Success:  true,
+		Panels:   make(map[int]int, len(d.Panels)),
+		Forms:    make(map[int]int, len(d.Forms)),
+		Teams:    make(map[int]int, len(d.Teams)),
+		Warnings: make([]string, 0),
+	}
+
+	var panels map[int]database.Panel
+	multiPanels := make([]database.MultiPanel, len(d.MultiPanels))
+	subPanels := make([][]database.Panel, len(d.MultiPanels))
+	if err := dbclient.Client.WithTx(ctx, func(tx pgx.Tx) error {
+		var err error
+		panels, err = d.applyPanels(ctx, tx, guildId, &res)
+		if err != nil {
+			return err
+		}
+
+		for _, userId := range d.Blacklist.Users {
+			if err := dbclient.TxClient.AddBlacklistedUser(ctx, tx, guildId, userId); err != nil {
+				return err
+			}
+		}
+
+		for _, roleId := range d.Blacklist.Roles {
+			if err := dbclient.TxClient.AddBlacklistedRole(ctx, tx, guildId, roleId); err != nil {
+				return err
+			}
+		}
+
+		for _, tag := range d.Tags {
+			if err := api_tags.StoreTagTx(ctx, tx, guildId, tag); err != nil {
+				return err
+			}
+		}
+
+		if d.Settings.ContextMenuPanel != nil {
+			d.Settings.ContextMenuPanel = utils.Ptr(res.Panels[*d.Settings.ContextMenuPanel])
+		}
+
+		if _, err := d.Settings.SaveTx(ctx, tx, guildId, channels); err != nil {
+			return err
+		}
+
+		for i, mp := range d.MultiPanels {
+			multiPanels[i], subPanels[i], err = mp.insert(ctx, tx, guildId, panels)
+			if err != nil {
+				return err
+			}
+
+			res.MultiPanels = append(res.MultiPanels, multiPanels[i].Id)
+		}
+
+		return nil
+	}); err != nil {
+		return importResponse{}, err
+	}
+
+	for _, tag := range d.Tags {
+		if err := api_tags.RegisterTagCommand(ctx, guildId, botContext, tag); err != nil {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("Failed to create the command for tag \"%s\": %s", tag.Id, err.Error()))
+		}
+	}
+
+	if err := d.sendPanels(ctx, botContext, panels, &res); err != nil {
+		return importResponse{}, err
+	}
+
+	for i, multiPanel := range multiPanels {
+		messageId, err := api_panels.SendMultiPanelMessage(botContext, multiPanel, subPanels[i])
+		if err != nil {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("Failed to send multi-panel %d: %s", multiPanel.Id, err.Error()))
+			continue
+		}
+
+		if err := dbclient.Client.MultiPanels.UpdateMessageId(ctx, multiPanel.Id, messageId); err != nil {
+			return importResponse{}, err
+		}
+	}
+
+	return res, nil
+}
+
+// applyPanels creates the forms, teams and panels in the document in the caller's transaction, recording the new IDs
+// in res. Teams are matched by name, so that existing teams are reused rather than duplicated.
+func (d *document) applyPanels(ctx context.Context, tx pgx.Tx, guildId uint64, res *importResponse) (map[int]database.Panel, error) {
+	for _, form := range d.Forms {
+		customId, err := utils.RandString(30)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := dbclient.TxClient.CreateForm(ctx, tx, guildId, form.Title, customId)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Forms[form.Id] = id
+
+		if form.Schema != nil {
+			if err := insertSchema(ctx, tx, guildId, id, *form.Schema); err != nil {
+				return nil, err
+			}
+		} else if err := insertInputs(ctx, tx, id, form); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, team := range d.Teams {
+		teamId, ok, err := dbclient.TxClient.GetSupportTeamByName(ctx, tx, guildId, team.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			teamId, err = dbclient.TxClient.CreateSupportTeam(ctx, tx, guildId, team.Name)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		res.Teams[team.Id] = teamId
+
+		for _, userId := range team.Users {
+			if err := dbclient.TxClient.AddSupportTeamMember(ctx, tx, teamId, userId); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, roleId := range team.Roles {
+			if err := dbclient.TxClient.AddSupportTeamRole(ctx, tx, teamId, roleId); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	panels := make(map[int]database.Panel, len(d.Panels))
+	for _, p := range d.Panels {
+		stored, err := p.insert(ctx, tx, guildId, res.Forms, res.Teams)
+		if err != nil {
+			return nil, err
+		}
+
+		panels[p.Id] = stored
+		res.Panels[p.Id] = stored.PanelId
+	}
+
+	return panels, nil
+}
+
+// sendPanels posts the messages for the panels created by applyPanels. Failures are recorded as warnings, as the
+// panels can be resent from the dashboard.
+func (d *document) sendPanels(
+	ctx context.Context,
+	botContext *botcontext.BotContext,
+	panels map[int]database.Panel,
+	res *importResponse,
+) error {
+	for _, p := range d.Panels {
+		stored := panels[p.Id]
+
+		messageId, err := api_panels.SendPanelMessage(botContext, stored)
+		if err != nil {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("Failed to send panel \"%s\": %s", p.Title, err.Error()))
+			continue
+		}
+
+		if err := dbclient.Client.Panel.UpdateMessageId(ctx, stored.PanelId, messageId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func insertInputs(ctx context.Context, tx pgx.Tx, formId int, form form) error {
+	for _, input := range form.Inputs {
+		customId, err := utils.RandString(30)
+		if err != nil {
+			return err
+		}
+
+		if _, err := dbclient.Client.FormInput.CreateTx(ctx,
+			tx,
+			formId,
+			customId,
+			input.Position,
+			uint8(input.Style),
+			input.Label,
+			input.Placeholder,
+			input.Required,
+			&input.MinLength,
+			&input.MaxLength,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// insertSchema stores the schema of a form, along with the inputs mirrored from it. The custom IDs in the document
+// belong to the exporting guild's inputs, so new ones are allocated.
+func insertSchema(ctx context.Context, tx pgx.Tx, guildId uint64, formId int, schema api_forms.Schema) error {
+	for i := range schema.Pages {
+		for j := range schema.Pages[i].Inputs {
+			schema.Pages[i].Inputs[j].CustomId = ""
+		}
+	}
+
+	return api_forms.StoreSchemaTx(ctx, tx, guildId, formId, &schema, nil)
+}
+
+// insert stores the panel without a message: the message is sent once the transaction has been committed
+func (p *panel) insert(ctx context.Context, tx pgx.Tx, guildId uint64, formIds, teamIds map[int]int) (database.Panel, error) {
+	customId, err := utils.RandString(30)
+	if err != nil {
+		return database.Panel{}, err
+	}
+
+	var welcomeMessageEmbed *int
+	if p.WelcomeMessage != nil {
+		embed, fields := p.WelcomeMessage.IntoDatabaseStruct()
+		embed.GuildId = guildId
+
+		id, err := dbclient.Client.Embeds.CreateWithFieldsTx(ctx, tx, embed, fields)
+		if err != nil {
+			return database.Panel{}, err
+		}
+
+		welcomeMessageEmbed = &id
+	}
+
+	var emojiId *uint64
+	var emojiName *string
+	if emoji := p.Emoji.IntoGdl(); emoji != nil {
+		emojiName = &emoji.Name
+
+		if emoji.Id.Value != 0 {
+			emojiId = &emoji.Id.Value
+		}
+	}
+
+	panel := database.Panel{
+		ChannelId:           p.ChannelId,
+		GuildId:             guildId,
+		Title:               p.Title,
+		Content:             p.Content,
+		Colour:              int32(p.Colour),
+		TargetCategory:      p.CategoryId,
+		EmojiId:             emojiId,
+		EmojiName:           emojiName,
+		WelcomeMessageEmbed: welcomeMessageEmbed,
+		WithDefaultTeam:     p.WithDefaultTeam,
+		CustomId:            customId,
+		ImageUrl:            p.ImageUrl,
+		ThumbnailUrl:        p.ThumbnailUrl,
+		ButtonStyle:         int(p.ButtonStyle),
+		ButtonLabel:         p.ButtonLabel,
+		FormId:              remapId(formIds, p.FormId),
+		NamingScheme:        p.NamingScheme,
+		Disabled:            p.Disabled,
+		ExitSurveyFormId:    remapId(formIds, p.ExitSurveyFormId),
+		PendingCategory:     p.PendingCategory,
+	}
+
+	options := api_panels.PanelCreateOptions{
+		TeamIds:            utils.Map(p.Teams, func(id int) int { return teamIds[id] }),
+		AccessControlRules: p.AccessControlList,
+	}
+
+	for _, mention := range p.Mentions {
+		if mention == "user" {
+			options.ShouldMentionUser = true
+		} else if roleId, err := strconv.ParseUint(mention, 10, 64); err == nil {
+			options.RoleMentions = append(options.RoleMentions, roleId)
+		}
+	}
+
+	panel.PanelId, err = api_panels.StorePanelWithTx(ctx, tx, panel, options)
+	if err != nil {
+		return database.Panel{}, err
+	}
+
+	return panel, nil
+}
+
+// insert stores the multi-panel and its targets without a message, returning the sub-panels so that the message can be
+// sent once the transaction has been committed
+func (mp *multiPanel) insert(ctx context.Context, tx pgx.Tx, guildId uint64, panels map[int]database.Panel) (database.MultiPanel, []database.Panel, error) {
+	dbEmbed, dbEmbedFields := mp.Embed.IntoDatabaseStruct()
+	multiPanel := database.MultiPanel{
+		ChannelId:             mp.ChannelId,
+		GuildId:               guildId,
+		SelectMenu:            mp.SelectMenu,
+		SelectMenuPlaceholder: mp.SelectMenuPlaceholder,
+		Embed: &database.CustomEmbedWithFields{
+			CustomEmbed: dbEmbed,
+			Fields:      dbEmbedFields,
+		},
+	}
+
+	var err error
+	multiPanel.Id, err = dbclient.TxClient.CreateMultiPanel(ctx, tx, multiPanel)
+	if err != nil {
+		return database.MultiPanel{}, nil, err
+	}
+
+	subPanels := make([]database.Panel, len(mp.Panels))
+	for i, panelId := range mp.Panels {
+		subPanels[i] = panels[panelId]
+
+		if err := dbclient.TxClient.AddMultiPanelTarget(ctx, tx, multiPanel.Id, subPanels[i].PanelId); err != nil {
+			return database.MultiPanel{}, nil, err
+		}
+	}
+
+	return multiPanel, subPanels, nil
+}
+
+func remapId(ids map[int]int, id *int) *int {
+	if id == nil {
+		return nil
+	}
+
+	return utils.Ptr(ids[*id])
+}
@@ -0,0 +1,315 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/dryrun"
+	api_panels "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/panel"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+	"github.com/jadevelopmentgrp/Tickets-Utilities/permission"
+)
+
+type (
+	cloneBody struct {
+		ChannelId *uint64 `json:"channel_id,string"`
+	}
+
+	copyBody struct {
+		GuildId   uint64    `json:"guild_id,string"`
+		ChannelId *uint64   `json:"channel_id,string"`
+		Mapping   idMapping `json:"mapping"`
+	}
+
+	// copyAuditState is recorded in the target guild's audit log when a panel is copied in from another guild
+	copyAuditState struct {
+		SourceGuildId uint64      `json:"source_guild_id,string"`
+		SourcePanelId int         `json:"source_panel_id"`
+		PanelId       int         `json:"panel_id"`
+		Forms         map[int]int `json:"forms"`
+		Teams         map[int]int `json:"teams"`
+	}
+
+	cloneResponse struct {
+		Success  bool        `json:"success"`
+		GuildId  uint64      `json:"guild_id,string"`
+		PanelId  int         `json:"panel_id"`
+		Forms    map[int]int `json:"forms"`
+		Teams    map[int]int `json:"teams"`
+		Warnings []string    `json:"warnings"`
+	}
+)
+
+// ClonePanelHandler duplicates a panel within the guild, optionally posting the copy to a different channel. The
+// panel's forms are copied, so that they can be edited independently, while support teams are shared.
+func ClonePanelHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	var body cloneBody
+	if err := ctx.BindJSON(&body); err != nil {
+		ctx.JSON(400, utils.ErrorJson(err))
+		return
+	}
+
+	doc, ok := loadPanelDocument(ctx, guildId)
+	if !ok {
+		return
+	}
+
+	// The teams already exist in this guild, and will be matched by name
+	for i := range doc.Teams {
+		doc.Teams[i].Users = nil
+		doc.Teams[i].Roles = nil
+	}
+
+	p := &doc.Panels[0]
+	if body.ChannelId != nil {
+		p.ChannelId = *body.ChannelId
+	}
+
+	doc.copyPanel(ctx, guildId, p.WelcomeMessageTemplateId)
+}
+
+// CopyPanelHandler copies a panel to another guild in which the user is an admin. Channel, role and category IDs are
+// rewritten using the mapping, and the panel is validated against the target guild before anything is created.
+func CopyPanelHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+	userId := ctx.Keys["userid"].(uint64)
+
+	var body copyBody
+	if err := ctx.BindJSON(&body); err != nil {
+		ctx.JSON(400, utils.ErrorJson(err))
+		return
+	}
+
+	if body.GuildId == 0 {
+		validation.RespondField(ctx, "/guild_id", validation.CodeRequired, "A target server is required")
+		return
+	}
+
+	if body.GuildId == guildId {
+		validation.RespondField(ctx, "/guild_id", validation.CodeInvalid, "Use the clone endpoint to duplicate a panel within the same server")
+		return
+	}
+
+	// API tokens are scoped to a set of guilds, which must include the target
+	if token, ok := ctx.Keys["apitoken"].(dbclient.ApiToken); ok && !utils.Contains(token.Guilds, body.GuildId) {
+		ctx.JSON(403, utils.ErrorStr("This API token does not have access to the target server"))
+		return
+	}
+
+	permissionLevel, err := utils.GetPermissionLevel(ctx, body.GuildId, userId)
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	if permissionLevel < permission.Admin {
+		ctx.JSON(403, utils.ErrorStr("You must be an admin in the target server"))
+		return
+	}
+
+	doc, ok := loadPanelDocument(ctx, guildId)
+	if !ok {
+		return
+	}
+
+	doc.remap(body.Mapping, body.GuildId)
+
+	if body.ChannelId != nil {
+		doc.Panels[0].ChannelId = *body.ChannelId
+	}
+
+	doc.copyPanel(ctx, body.GuildId, nil)
+}
+
+// loadPanelDocument builds a document containing the panel named in the request, along with the forms and teams that
+// it references. The response is written if the panel cannot be loaded.
+func loadPanelDocument(ctx *gin.Context, guildId uint64) (document, bool) {
+	panelId, err := strconv.Atoi(ctx.Param("panelid"))
+	if err != nil {
+		ctx.JSON(400, utils.ErrorStr("Invalid panel ID"))
+		return document{}, false
+	}
+
+	doc, ok, err := panelDocument(ctx, guildId, panelId)
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return document{}, false
+	}
+
+	if !ok {
+		ctx.JSON(404, utils.ErrorStr("Panel not found"))
+		return document{}, false
+	}
+
+	return doc, true
+}
+
+func panelDocument(ctx context.Context, guildId uint64, panelId int) (document, bool, error) {
+	stored, err := dbclient.Client.Panel.GetById(ctx, panelId)
+	if err != nil {
+		return document{}, false, err
+	}
+
+	if stored.PanelId == 0 || stored.GuildId != guildId {
+		return document{}, false, nil
+	}
+
+	body, err := api_panels.GetPanelBody(ctx, stored)
+	if err != nil {
+		return document{}, false, err
+	}
+
+	forms, err := exportForms(ctx, guildId)
+	if err != nil {
+		return document{}, false, err
+	}
+
+	teams, err := exportTeams(ctx, guildId)
+	if err != nil {
+		return document{}, false, err
+	}
+
+	doc := document{
+		Version: documentVersion,
+		GuildId: guildId,
+		Panels:  []panel{{Id: stored.PanelId, PanelBody: body}},
+	}
+
+	for _, f := range forms {
+		if (body.FormId != nil && *body.FormId == f.Id) || (body.ExitSurveyFormId != nil && *body.ExitSurveyFormId == f.Id) {
+			doc.Forms = append(doc.Forms, f)
+		}
+	}
+
+	for _, t := range teams {
+		if utils.Contains(body.Teams, t.Id) {
+			doc.Teams = append(doc.Teams, t)
+		}
+	}
+
+	return doc, true, nil
+}
+
+// copyPanel validates the single-panel document against the target guild, and then creates and sends the panel. The
+// welcome message template reference is only kept when cloning within a guild, as templates are per-guild.
+func (d *document) copyPanel(ctx *gin.Context, guildId uint64, welcomeMessageTemplateId *int) {
+	botContext, err := botcontext.ContextForGuild(guildId)
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	channels, err := botContext.GetGuildChannels(ctx, guildId)
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	roles, err := botContext.GetGuildRoles(ctx, guildId)
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	// The document only holds the one panel, so report pointers relative to it
+	if _, err := d.validatePanels(guildId, botContext, channels, roles); err != nil {
+		if !validation.RespondError(ctx, validation.TrimPrefix(err, "/panels/0")) {
+			_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		}
+
+		return
+	}
+
+	if dryrun.Requested(ctx) {
+		dryrun.Respond(ctx, nil, d.Panels[0].PanelBody)
+		return
+	}
+
+	res := importResponse{
+		Success:  true,
+		Panels:   make(map[int]int, 1),
+		Forms:    make(map[int]int, len(d.Forms)),
+		Teams:    make(map[int]int, len(d.Teams)),
+		Warnings: make([]string, 0),
+	}
+
+	var panels map[int]database.Panel
+	if err := dbclient.Client.WithTx(ctx, func(tx pgx.Tx) error {
+		var err error
+		panels, err = d.applyPanels(ctx, tx, guildId, &res)
+		if err != nil {
+			return err
+		}
+
+		panelId := strconv.Itoa(res.Panels[d.Panels[0].Id])
+		if err := dbclient.Dashboard.EmbedTemplateReferences.SetTx(ctx, tx, guildId, dbclient.EmbedTemplateTargetPanel, panelId, welcomeMessageTemplateId); err != nil {
+			return err
+		}
+
+		return auditCopy(ctx, tx, guildId, d.Panels[0].Id, res)
+	}); err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	panelId := res.Panels[d.Panels[0].Id]
+
+	if err := d.sendPanels(ctx, botContext, panels, &res); err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	response := cloneResponse{
+		Success:  true,
+		GuildId:  guildId,
+		PanelId:  panelId,
+		Forms:    res.Forms,
+		Teams:    res.Teams,
+		Warnings: res.Warnings,
+	}
+
+	audit.SetAfter(ctx, response)
+	ctx.JSON(200, response)
+}
+
+// auditCopy records a copy to another guild in the target guild's audit log. The request is made through the source
+// guild's routes, so the audit log middleware only records it in the source guild.
+func auditCopy(ctx *gin.Context, tx pgx.Tx, guildId uint64, sourcePanelId int, res importResponse) error {
+	sourceGuildId := ctx.Keys["guildid"].(uint64)
+	if sourceGuildId == guildId {
+		return nil
+	}
+
+	after, err := json.Marshal(copyAuditState{
+		SourceGuildId: sourceGuildId,
+		SourcePanelId: sourcePanelId,
+		PanelId:       res.Panels[sourcePanelId],
+		Forms:         res.Forms,
+		Teams:         res.Teams,
+	})
+	if err != nil {
+		return err
+	}
+
+	return dbclient.Dashboard.AuditLog.CreateTx(ctx, tx, dbclient.AuditLogEntry{
+		GuildId: guildId,
+		UserId:  ctx.Keys["userid"].(uint64),
+		Action:  fmt.Sprintf("%s %s", ctx.Request.Method, strings.TrimPrefix(ctx.FullPath(), "/api/:id")),
+		Path:    fmt.Sprintf("/panels/%d", res.Panels[sourcePanelId]),
+		After:   after,
+	})
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	api_forms "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/forms"
+	api_panels "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/panel"
+	api_settings "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/settings"
+	api_tags "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/tags"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+)
+
+// documentVersion must be incremented whenever the document format changes in a way that older documents cannot be
+// imported as-is
+const documentVersion = 1
+
+// IDs of panels, forms and teams within the document are those of the exporting guild, and are only used to link
+// resources together: new IDs are allocated when the document is imported.
+type (
+	document struct {
+		Version     int                   `json:"version"`
+		ExportedAt  time.Time             `json:"exported_at"`
+		GuildId     uint64                `json:"guild_id,string"`
+		Settings    api_settings.Settings `json:"settings"`
+		Panels      []panel               `json:"panels" validate:"dive"`
+		MultiPanels []multiPanel          `json:"multi_panels" validate:"dive"`
+		Forms       []form                `json:"forms" validate:"dive"`
+		Tags        []api_tags.Tag        `json:"tags"`
+		Teams       []team                `json:"teams" validate:"dive"`
+		Blacklist   blacklist             `json:"blacklist"`
+	}
+
+	panel struct {
+		Id int `json:"id"`
+		api_panels.PanelBody
+	}
+
+	multiPanel struct {
+		ChannelId             uint64             `json:"channel_id,string"`
+		SelectMenu            bool               `json:"select_menu"`
+		SelectMenuPlaceholder *string            `json:"select_menu_placeholder,omitempty" validate:"omitempty,max=150"`
+		Panels                []int              `json:"panels"`
+		Embed                 *types.CustomEmbed `json:"embed" validate:"omitempty,dive"`
+	}
+
+	// Inputs are ignored when the form has a schema, as they are mirrored from it
+	form struct {
+		Id     int                         `json:"id"`
+		Title  string                      `json:"title" validate:"max=45"`
+		Inputs []api_forms.InputCreateBody `json:"inputs" validate:"max=5,dive"`
+		Schema *api_forms.Schema           `json:"schema,omitempty"`
+	}
+
+	team struct {
+		Id    int                     `json:"id"`
+		Name  string                  `json:"name" validate:"min=1,max=32"`
+		Users types.UInt64StringSlice `json:"users"`
+		Roles types.UInt64StringSlice `json:"roles"`
+	}
+
+	blacklist struct {
+		Users types.UInt64StringSlice `json:"users"`
+		Roles types.UInt64StringSlice `json:"roles"`
+	}
+)
+
+// idMapping maps channel, role and category IDs from the exporting guild to the importing guild. IDs that are not
+// present in the mapping are left unchanged.
+type idMapping struct {
+	Channels   snowflakeMap `json:"channels"`
+	Roles      snowflakeMap `json:"roles"`
+	Categories snowflakeMap `json:"categories"`
+}
+
+// snowflakeMap is encoded as a JSON object with string keys and values, as snowflakes do not fit in a JS number
+type snowflakeMap map[uint64]uint64
+
+func (m *snowflakeMap) UnmarshalJSON(b []byte) error {
+	var raw map[string]string
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	parsed := make(snowflakeMap, len(raw))
+	for k, v := range raw {
+		from, err := strconv.ParseUint(k, 10, 64)
+		if err != nil {
+			return err
+		}
+
+		to, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return err
+		}
+
+		parsed[from] = to
+	}
+
+	*m = parsed
+	return nil
+}
+
+func (m snowflakeMap) get(id uint64) uint64 {
+	if mapped, ok := m[id]; ok {
+		return mapped
+	}
+
+	return id
+}
+
+func (m snowflakeMap) getPtr(id *uint64) *uint64 {
+	if id == nil {
+		return nil
+	}
+
+	mapped := m.get(*id)
+	return &mapped
+}
@@ -0,0 +1,321 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	api_forms "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/forms"
+	api_panels "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/panel"
+	api_settings "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/settings"
+	api_tags "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/tags"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+	"github.com/rxdn/gdl/objects/interaction/component"
+	"golang.org/x/sync/errgroup"
+)
+
+// Same as the limit enforced by AddBlacklistHandler
+const maxBlacklistedUsers = 250
+
+func ExportConfigHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	doc, err := buildDocument(ctx, guildId)
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	fileName := fmt.Sprintf("config-%d-%s.json", guildId, doc.ExportedAt.Format("2006-01-02"))
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	ctx.JSON(200, doc)
+}
+
+func buildDocument(ctx context.Context, guildId uint64) (document, error) {
+	doc := document{
+		Version:    documentVersion,
+		ExportedAt: time.Now(),
+		GuildId:    guildId,
+	}
+
+	group, _ := errgroup.WithContext(ctx)
+
+	group.Go(func() (err error) {
+		doc.Settings, err = api_settings.GetSettings(ctx, guildId)
+		return
+	})
+
+	group.Go(func() (err error) {
+		doc.Panels, err = exportPanels(ctx, guildId)
+		return
+	})
+
+	group.Go(func() (err error) {
+		doc.MultiPanels, err = exportMultiPanels(ctx, guildId)
+		return
+	})
+
+	group.Go(func() (err error) {
+		doc.Forms, err = exportForms(ctx, guildId)
+		return
+	})
+
+	group.Go(func() (err error) {
+		doc.Tags, err = api_tags.GetTags(ctx, guildId)
+		return
+	})
+
+	group.Go(func() (err error) {
+		doc.Teams, err = exportTeams(ctx, guildId)
+		return
+	})
+
+	group.Go(func() (err error) {
+		doc.Blacklist, err = exportBlacklist(ctx, guildId)
+		return
+	})
+
+	if err := group.Wait(); err != nil {
+		return document{}, err
+	}
+
+	return doc, nil
+}
+
+func exportPanels(ctx context.Context, guildId uint64) ([]panel, error) {
+	panels, err := dbclient.Client.Panel.GetByGuildWithWelcomeMessage(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	accessControlLists, err := dbclient.Client.PanelAccessControlRules.GetAllForGuild(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	allFields, err := dbclient.Client.EmbedFields.GetAllFieldsForPanels(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	exported := make([]panel, len(panels))
+
+	group, _ := errgroup.WithContext(ctx)
+	for i, p := range panels {
+		i := i
+		p := p
+
+		group.Go(func() error {
+			var mentions []string
+
+			shouldMention, err := dbclient.Client.PanelUserMention.ShouldMentionUser(ctx, p.PanelId)
+			if err != nil {
+				return err
+			}
+
+			if shouldMention {
+				mentions = append(mentions, "user")
+			}
+
+			roles, err := dbclient.Client.PanelRoleMentions.GetRoles(ctx, p.PanelId)
+			if err != nil {
+				return err
+			}
+
+			for _, roleId := range roles {
+				mentions = append(mentions, strconv.FormatUint(roleId, 10))
+			}
+
+			teamIds, err := dbclient.Client.PanelTeams.GetTeamIds(ctx, p.PanelId)
+			if err != nil {
+				return err
+			}
+
+			var welcomeMessage *types.CustomEmbed
+			if p.WelcomeMessage != nil {
+				welcomeMessage = types.NewCustomEmbed(p.WelcomeMessage, allFields[p.WelcomeMessage.Id])
+			}
+
+			exported[i] = panel{
+				Id: p.PanelId,
+				PanelBody: api_panels.PanelBody{
+					ChannelId:         p.ChannelId,
+					Title:             p.Title,
+					Content:           p.Content,
+					Colour:            uint32(p.Colour),
+					CategoryId:        p.TargetCategory,
+					Emoji:             types.NewEmoji(p.EmojiName, p.EmojiId),
+					WelcomeMessage:    welcomeMessage,
+					Mentions:          mentions,
+					WithDefaultTeam:   p.WithDefaultTeam,
+					Teams:             teamIds,
+					ImageUrl:          p.ImageUrl,
+					ThumbnailUrl:      p.ThumbnailUrl,
+					ButtonStyle:       component.ButtonStyle(p.ButtonStyle),
+					ButtonLabel:       p.ButtonLabel,
+					FormId:            p.FormId,
+					NamingScheme:      p.NamingScheme,
+					Disabled:          p.Disabled,
+					ExitSurveyFormId:  p.ExitSurveyFormId,
+					AccessControlList: accessControlLists[p.PanelId],
+					PendingCategory:   p.PendingCategory,
+				},
+			}
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	return exported, nil
+}
+
+func exportMultiPanels(ctx context.Context, guildId uint64) ([]multiPanel, error) {
+	multiPanels, err := dbclient.Client.MultiPanels.GetByGuild(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	exported := make([]multiPanel, len(multiPanels))
+
+	group, _ := errgroup.WithContext(ctx)
+	for i, mp := range multiPanels {
+		i := i
+		mp := mp
+
+		group.Go(func() error {
+			panels, err := dbclient.Client.MultiPanelTargets.GetPanels(ctx, mp.Id)
+			if err != nil {
+				return err
+			}
+
+			var embed *types.CustomEmbed
+			if mp.Embed != nil {
+				embed = types.NewCustomEmbed(mp.Embed.CustomEmbed, mp.Embed.Fields)
+			}
+
+			exported[i] = multiPanel{
+				ChannelId:             mp.ChannelId,
+				SelectMenu:            mp.SelectMenu,
+				SelectMenuPlaceholder: mp.SelectMenuPlaceholder,
+				Panels:                utils.Map(panels, func(p database.Panel) int { return p.PanelId }),
+				Embed:                 embed,
+			}
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	return exported, nil
+}
+
+func exportForms(ctx context.Context, guildId uint64) ([]form, error) {
+	forms, err := dbclient.Client.Forms.GetForms(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, err := dbclient.Client.FormInput.GetInputsForGuild(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas, err := dbclient.Dashboard.FormSchemas.GetByGuild(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	exported := make([]form, len(forms))
+	for i, f := range forms {
+		formInputs := make([]api_forms.InputCreateBody, len(inputs[f.Id]))
+		for j, input := range inputs[f.Id] {
+			formInputs[j] = api_forms.InputCreateBody{
+				Label:       input.Label,
+				Placeholder: input.Placeholder,
+				Position:    input.Position,
+				Style:       component.TextStyleTypes(input.Style),
+				Required:    input.Required,
+				MinLength:   utils.ValueOrZero(input.MinLength),
+				MaxLength:   utils.ValueOrZero(input.MaxLength),
+			}
+		}
+
+		exported[i] = form{
+			Id:     f.Id,
+			Title:  f.Title,
+			Inputs: formInputs,
+		}
+
+		if stored, ok := schemas[f.Id]; ok {
+			var schema api_forms.Schema
+			if err := json.Unmarshal(stored.Data, &schema); err != nil {
+				return nil, err
+			}
+
+			exported[i].Schema = &schema
+		}
+	}
+
+	return exported, nil
+}
+
+func exportTeams(ctx context.Context, guildId uint64) ([]team, error) {
+	teams, err := dbclient.Client.SupportTeam.Get(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	exported := make([]team, len(teams))
+
+	group, _ := errgroup.WithContext(ctx)
+	for i, t := range teams {
+		i := i
+		t := t
+
+		exported[i] = team{
+			Id:   t.Id,
+			Name: t.Name,
+		}
+
+		group.Go(func() (err error) {
+			exported[i].Users, err = dbclient.Client.SupportTeamMembers.Get(ctx, t.Id)
+			return
+		})
+
+		group.Go(func() (err error) {
+			exported[i].Roles, err = dbclient.Client.SupportTeamRoles.Get(ctx, t.Id)
+			return
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	return exported, nil
+}
+
+func exportBlacklist(ctx context.Context, guildId uint64) (exported blacklist, err error) {
+	exported.Users, err = dbclient.Client.Blacklist.GetBlacklistedUsers(ctx, guildId, maxBlacklistedUsers, 0)
+	if err != nil {
+		return
+	}
+
+	exported.Roles, err = dbclient.Client.RoleBlacklist.GetBlacklistedRoles(ctx, guildId)
+	return
+}
@@ -0,0 +1,413 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	api_forms "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/forms"
+	api_panels "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/panel"
+	api_tags "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/tags"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Utilities/permission"
+	"github.com/rxdn/gdl/objects/channel"
+	"github.com/rxdn/gdl/objects/guild"
+)
+
+const maxTags = api_tags.MaxTags
+
+type (
+	importBody struct {
+		Document document  `json:"document"`
+		Mapping  idMapping `json:"mapping"`
+	}
+
+	importResponse struct {
+		Success     bool        `json:"success"`
+		Panels      map[int]int `json:"panels"`
+		MultiPanels []int       `json:"multi_panels"`
+		Forms       map[int]int `json:"forms"`
+		Teams       map[int]int `json:"teams"`
+		Warnings    []string    `json:"warnings"`
+	}
+)
+
+var validate = validator.New()
+
+func ImportConfigHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	var body importBody
+	if err := ctx.BindJSON(&body); err != nil {
+		ctx.JSON(400, utils.ErrorJson(err))
+		return
+	}
+
+	doc := body.Document
+	if doc.Version != documentVersion {
+		validation.RespondField(ctx, "/document/version", validation.CodeInvalid, "Unsupported config version %d", doc.Version)
+		return
+	}
+
+	if err := validation.Struct(validate, doc); err != nil {
+		if !validation.RespondError(ctx, validation.WithPrefix(err, "/document")) {
+			_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewError(err, "An error occurred while validating the config"))
+		}
+
+		return
+	}
+
+	doc.remap(body.Mapping, guildId)
+
+	botContext, err := botcontext.ContextForGuild(guildId)
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	channels, err := botContext.GetGuildChannels(ctx, guildId)
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	roles, err := botContext.GetGuildRoles(ctx, guildId)
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	if err := doc.validate(ctx, guildId, botContext, channels, roles); err != nil {
+		if !validation.RespondError(ctx, err) {
+			_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		}
+
+		return
+	}
+
+	before, err := buildDocument(ctx, guildId)
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	audit.SetBefore(ctx, before)
+
+	res, err := doc.apply(ctx, guildId, botContext, channels)
+	if err != nil {
+		_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	audit.SetAfter(ctx, res)
+	ctx.JSON(200, res)
+}
+
+// remap rewrites all channel, role and category IDs in the document to those of the importing guild
+func (d *document) remap(mapping idMapping, guildId uint64) {
+	// The @everyone role shares its ID with the guild
+	roles := make(snowflakeMap, len(mapping.Roles)+1)
+	for from, to := range mapping.Roles {
+		roles[from] = to
+	}
+
+	if _, ok := roles[d.GuildId]; !ok {
+		roles[d.GuildId] = guildId
+	}
+
+	for i := range d.Panels {
+		p := &d.Panels[i]
+
+		p.ChannelId = mapping.Channels.get(p.ChannelId)
+		p.CategoryId = mapping.Categories.get(p.CategoryId)
+		p.PendingCategory = mapping.Categories.getPtr(p.PendingCategory)
+
+		// Embed templates belong to the exporting guild. The document already contains a copy of the embed.
+		p.WelcomeMessageTemplateId = nil
+
+		for j, mention := range p.Mentions {
+			if roleId, err := strconv.ParseUint(mention, 10, 64); err == nil {
+				p.Mentions[j] = strconv.FormatUint(roles.get(roleId), 10)
+			}
+		}
+
+		for j := range p.AccessControlList {
+			p.AccessControlList[j].RoleId = roles.get(p.AccessControlList[j].RoleId)
+		}
+	}
+
+	for i := range d.MultiPanels {
+		d.MultiPanels[i].ChannelId = mapping.Channels.get(d.MultiPanels[i].ChannelId)
+	}
+
+	for i := range d.Tags {
+		d.Tags[i].EmbedTemplateId = nil
+	}
+
+	for i := range d.Teams {
+		d.Teams[i].Roles = utils.Map(d.Teams[i].Roles, roles.get)
+	}
+
+	d.Blacklist.Roles = utils.Map(d.Blacklist.Roles, roles.get)
+
+	s := &d.Settings
+	s.Category = mapping.Categories.get(s.Category)
+	s.ArchiveChannel = mapping.Channels.getPtr(s.ArchiveChannel)
+	s.TicketNotificationChannel = mapping.Channels.getPtr(s.TicketNotificationChannel)
+	s.OverflowCategoryId = mapping.Categories.getPtr(s.OverflowCategoryId)
+}
+
+// validate runs the same checks as the individual handlers, so that nothing is written unless the whole document is
+// valid. References between panels, forms and teams are checked against the document rather than the database, as
+// the referenced resources do not exist until the import is applied.
+func (d *document) validate(
+	ctx context.Context,
+	guildId uint64,
+	botContext *botcontext.BotContext,
+	channels []channel.Channel,
+	roles []guild.Role,
+) error {
+	panelIds, err := d.validatePanels(guildId, botContext, channels, roles)
+	errs := []error{validation.WithPrefix(err, "/document")}
+
+	for i, mp := range d.MultiPanels {
+		errs = append(errs, validation.WithPrefix(mp.validate(channels, panelIds), validation.IndexPointer("/document/multi_panels", i)))
+	}
+
+	errs = append(errs, d.validateTags(ctx, guildId), d.validateBlacklist(ctx, guildId))
+
+	// The context menu panel is remapped once the panels have been created
+	settings := d.Settings
+	if settings.ContextMenuPanel != nil {
+		if _, ok := panelIds[*settings.ContextMenuPanel]; !ok {
+			errs = append(errs, validation.NewFieldError("/document/settings/context_menu_panel", validation.CodeNotFound, "Context menu panel not found"))
+		}
+
+		settings.ContextMenuPanel = nil
+	}
+
+	errs = append(errs, validation.WithPrefix(settings.Validate(ctx, guildId), "/document/settings"))
+	if err := validation.Join(errs...); err != nil {
+		return err
+	}
+
+	// Validate may apply defaults
+	settings.ContextMenuPanel = d.Settings.ContextMenuPanel
+	d.Settings = settings
+
+	return nil
+}
+
+// validatePanels checks the forms, teams and panels in the document, returning the IDs of the panels. All failures
+// are collected, with pointers relative to the document. The IDs of invalid panels are still returned, so that
+// references to them are not also reported.
+func (d *document) validatePanels(
+	guildId uint64,
+	botContext *botcontext.BotContext,
+	channels []channel.Channel,
+	roles []guild.Role,
+) (map[int]struct{}, error) {
+	var errs []error
+
+	formIds := make(map[int]struct{}, len(d.Forms))
+	for i, form := range d.Forms {
+		pointer := validation.IndexPointer("/forms", i)
+
+		if _, ok := formIds[form.Id]; ok {
+			errs = append(errs, validation.NewFieldErrorf(pointer+"/id", validation.CodeDuplicate, "Duplicate form ID %d", form.Id))
+		}
+
+		formIds[form.Id] = struct{}{}
+
+		if form.Schema != nil {
+			errs = append(errs, validation.WithPrefix(api_forms.ValidateSchema(*form.Schema), pointer+"/schema"))
+		} else if !arePositionsCorrect(form) {
+			errs = append(errs, validation.NewFieldError(pointer+"/inputs", validation.CodeInvalid, "Positions must be unique and in ascending order"))
+		}
+	}
+
+	teamIds := make(map[int]struct{}, len(d.Teams))
+	for i, team := range d.Teams {
+		if _, ok := teamIds[team.Id]; ok {
+			errs = append(errs, validation.NewFieldErrorf(validation.IndexPointer("/teams", i)+"/id", validation.CodeDuplicate, "Duplicate team ID %d", team.Id))
+		}
+
+		teamIds[team.Id] = struct{}{}
+	}
+
+	panelIds := make(map[int]struct{}, len(d.Panels))
+	for i := range d.Panels {
+		p := &d.Panels[i]
+		pointer := validation.IndexPointer("/panels", i)
+
+		if _, ok := panelIds[p.Id]; ok {
+			errs = append(errs, validation.NewFieldErrorf(pointer+"/id", validation.CodeDuplicate, "Duplicate panel ID %d", p.Id))
+		}
+
+		panelIds[p.Id] = struct{}{}
+
+		errs = append(errs, validation.WithPrefix(p.validate(guildId, botContext, channels, roles, formIds, teamIds), pointer))
+	}
+
+	return panelIds, validation.Join(errs...)
+}
+
+func (p *panel) validate(
+	guildId uint64,
+	botContext *botcontext.BotContext,
+	channels []channel.Channel,
+	roles []guild.Role,
+	formIds, teamIds map[int]struct{},
+) error {
+	p.MessageId = 0
+	api_panels.ApplyPanelDefaults(&p.PanelBody)
+
+	var errs []error
+	if p.FormId != nil {
+		if _, ok := formIds[*p.FormId]; !ok {
+			errs = append(errs, validation.NewFieldError("/form_id", validation.CodeNotFound, "Form not found"))
+		}
+	}
+
+	if p.ExitSurveyFormId != nil {
+		if _, ok := formIds[*p.ExitSurveyFormId]; !ok {
+			errs = append(errs, validation.NewFieldError("/exit_survey_form_id", validation.CodeNotFound, "Exit survey form not found"))
+		}
+	}
+
+	for i, teamId := range p.Teams {
+		if _, ok := teamIds[teamId]; !ok {
+			errs = append(errs, validation.NewFieldError(validation.IndexPointer("/teams", i), validation.CodeNotFound, "Invalid support team"))
+		}
+	}
+
+	// Form and team references have been checked above
+	data := p.PanelBody
+	data.FormId = nil
+	data.ExitSurveyFormId = nil
+	data.Teams = nil
+
+	errs = append(errs, api_panels.ValidatePanelBody(api_panels.PanelValidationContext{
+		Data:       data,
+		GuildId:    guildId,
+		IsPremium:  true,
+		BotContext: botContext,
+		Channels:   channels,
+		Roles:      roles,
+	}))
+
+	return validation.Join(errs...)
+}
+
+func (mp *multiPanel) validate(channels []channel.Channel, panelIds map[int]struct{}) error {
+	errs := []error{validation.WithPrefix(api_panels.ValidateEmbed(mp.Embed), "/embed")}
+
+	if len(mp.Panels) < 2 {
+		errs = append(errs, validation.NewFieldError("/panels", validation.CodeTooShort, "A multi-panel must contain at least 2 sub-panels"))
+	}
+
+	if len(mp.Panels) > 15 {
+		errs = append(errs, validation.NewFieldError("/panels", validation.CodeTooLong, "Multi-panels cannot contain more than 15 sub-panels"))
+	}
+
+	for i, panelId := range mp.Panels {
+		if _, ok := panelIds[panelId]; !ok {
+			errs = append(errs, validation.NewFieldErrorf(validation.IndexPointer("/panels", i), validation.CodeNotFound, "Panel %d not found", panelId))
+		}
+	}
+
+	if !isTextChannel(channels, mp.ChannelId) {
+		errs = append(errs, validation.NewFieldError("/channel_id", validation.CodeNotFound, "Channel does not exist"))
+	}
+
+	return validation.Join(errs...)
+}
+
+func isTextChannel(channels []channel.Channel, channelId uint64) bool {
+	for _, ch := range channels {
+		if ch.Id == channelId && (ch.Type == channel.ChannelTypeGuildText || ch.Type == channel.ChannelTypeGuildNews) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d *document) validateTags(ctx context.Context, guildId uint64) error {
+	existing, err := dbclient.Client.Tag.GetByGuild(ctx, guildId)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	count := len(existing)
+	for i := range d.Tags {
+		tag := &d.Tags[i]
+
+		tag.Normalise()
+
+		errs = append(errs, validation.WithPrefix(tag.Validate(), validation.IndexPointer("/document/tags", i)))
+
+		// Existing tags with the same ID are overwritten
+		if _, ok := existing[tag.Id]; !ok {
+			count++
+		}
+	}
+
+	if count > maxTags {
+		errs = append(errs, validation.NewFieldErrorf("/document/tags", validation.CodeLimitExceeded, "Tag limit (%d) reached", maxTags))
+	}
+
+	return validation.Join(errs...)
+}
+
+func (d *document) validateBlacklist(ctx context.Context, guildId uint64) error {
+	count, err := dbclient.Client.Blacklist.GetBlacklistedCount(ctx, guildId)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if count+len(d.Blacklist.Users) > maxBlacklistedUsers {
+		errs = append(errs, validation.NewFieldErrorf("/document/blacklist/users", validation.CodeLimitExceeded, "Blacklist limit (%d) reached: consider using a role instead", maxBlacklistedUsers))
+	}
+
+	for i, userId := range d.Blacklist.Users {
+		permLevel, err := utils.GetPermissionLevel(ctx, guildId, userId)
+		if err != nil {
+			return err
+		}
+
+		if permLevel > permission.Everyone {
+			errs = append(errs, validation.NewFieldErrorf(validation.IndexPointer("/document/blacklist/users", i), validation.CodeInvalid, "User %d is a staff member", userId))
+		}
+	}
+
+	return validation.Join(errs...)
+}
+
+func arePositionsCorrect(form form) bool {
+	positions := make([]int, len(form.Inputs))
+	for i, input := range form.Inputs {
+		positions[i] = input.Position
+	}
+
+	sort.Ints(positions)
+
+	for i, position := range positions {
+		if i+1 != position {
+			return false
+		}
+	}
+
+	return true
+}
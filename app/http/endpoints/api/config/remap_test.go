@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	api_panels "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/panel"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnowflakeMapUnmarshal(t *testing.T) {
+	var mapping idMapping
+	err := json.Unmarshal([]byte(`{"channels":{"1111111111111111111":"2222222222222222222"}}`), &mapping)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2222222222222222222), mapping.Channels.get(1111111111111111111))
+	assert.Equal(t, uint64(5), mapping.Channels.get(5))
+	assert.Nil(t, mapping.Roles.getPtr(nil))
+}
+
+func TestRemap(t *testing.T) {
+	doc := document{
+		GuildId: 100,
+		Panels: []panel{{
+			PanelBody: api_panels.PanelBody{
+				ChannelId:       1,
+				CategoryId:      2,
+				PendingCategory: utils.Ptr(uint64(3)),
+				Mentions:        []string{"user", "4"},
+				AccessControlList: []database.PanelAccessControlRule{
+					{RoleId: 100, Action: database.AccessControlActionAllow},
+					{RoleId: 4, Action: database.AccessControlActionDeny},
+				},
+			},
+		}},
+		Teams: []team{{Roles: []uint64{4}}},
+	}
+
+	doc.remap(idMapping{
+		Channels:   snowflakeMap{1: 11},
+		Roles:      snowflakeMap{4: 44},
+		Categories: snowflakeMap{2: 22},
+	}, 200)
+
+	p := doc.Panels[0]
+	assert.Equal(t, uint64(11), p.ChannelId)
+	assert.Equal(t, uint64(22), p.CategoryId)
+	assert.Equal(t, uint64(3), *p.PendingCategory, "unmapped IDs should be left unchanged")
+	assert.Equal(t, []string{"user", "44"}, p.Mentions)
+	assert.Equal(t, uint64(200), p.AccessControlList[0].RoleId, "@everyone should be mapped to the new guild")
+	assert.Equal(t, uint64(44), p.AccessControlList[1].RoleId)
+	assert.Equal(t, []uint64{44}, []uint64(doc.Teams[0].Roles))
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+)
+
+func CreateTemplateHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	body, ok := bindTemplateBody(ctx)
+	if !ok {
+		return
+	}
+
+	count, err := dbclient.Dashboard.EmbedTemplates.Count(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if count >= maxTemplates {
+		ctx.JSON(400, utils.ErrorStr("You cannot have more than %d embed templates", maxTemplates))
+		return
+	}
+
+	data, err := body.encode()
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	template, ok, err := dbclient.Dashboard.EmbedTemplates.Create(ctx, dbclient.EmbedTemplate{
+		GuildId: guildId,
+		Name:    body.Name,
+		Data:    data,
+	})
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if !ok {
+		ctx.JSON(409, utils.ErrorStr("An embed template with this name already exists"))
+		return
+	}
+
+	res, err := types.NewEmbedTemplate(template)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	audit.SetAfter(ctx, res)
+
+	ctx.JSON(200, res)
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+)
+
+// DeleteTemplateHandler deletes the template. Panels, multi-panels and tags that used it keep their current embed.
+func DeleteTemplateHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	template, ok := getTemplate(ctx, guildId)
+	if !ok {
+		return
+	}
+
+	before, err := types.NewEmbedTemplate(template)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	audit.SetBefore(ctx, before)
+
+	if err := dbclient.Dashboard.EmbedTemplates.Delete(ctx, guildId, template.Id); err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	ctx.JSON(200, utils.SuccessResponse)
+}
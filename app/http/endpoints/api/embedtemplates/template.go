@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	api_panels "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/panel"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+)
+
+const maxTemplates = 50
+
+type templateBody struct {
+	Name  string             `json:"name" validate:"required,min=1,max=32"`
+	Embed *types.CustomEmbed `json:"embed" validate:"required"`
+	// Resend re-sends the messages of multi-panels using the template after it is edited. Otherwise, they are only
+	// updated when next sent.
+	Resend bool `json:"resend"`
+}
+
+var validate = validator.New()
+
+func (b *templateBody) validate() error {
+	b.Name = strings.TrimSpace(b.Name)
+
+	errs := []error{validation.Struct(validate, b)}
+	if b.Embed != nil {
+		errs = append(errs, validation.WithPrefix(api_panels.ValidateEmbed(b.Embed), "/embed"))
+	}
+
+	return validation.Join(errs...)
+}
+
+// bindTemplateBody reads and validates the request body, writing an error response and returning false if it is
+// invalid
+func bindTemplateBody(ctx *gin.Context) (templateBody, bool) {
+	var body templateBody
+	if err := ctx.BindJSON(&body); err != nil {
+		ctx.JSON(400, utils.ErrorJson(err))
+		return templateBody{}, false
+	}
+
+	if err := body.validate(); err != nil {
+		if !validation.RespondError(ctx, err) {
+			ctx.JSON(500, utils.ErrorStr("An error occurred while validating the embed template"))
+		}
+
+		return templateBody{}, false
+	}
+
+	return body, true
+}
+
+func (b *templateBody) encode() (json.RawMessage, error) {
+	return json.Marshal(b.Embed)
+}
+
+// getTemplate loads the template from the path, writing an error response and returning false if it cannot be used
+func getTemplate(ctx *gin.Context, guildId uint64) (dbclient.EmbedTemplate, bool) {
+	templateId, err := strconv.Atoi(ctx.Param("templateid"))
+	if err != nil {
+		ctx.JSON(400, utils.ErrorStr("Invalid embed template ID"))
+		return dbclient.EmbedTemplate{}, false
+	}
+
+	template, ok, err := dbclient.Dashboard.EmbedTemplates.Get(ctx, guildId, templateId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return dbclient.EmbedTemplate{}, false
+	}
+
+	if !ok {
+		ctx.JSON(404, utils.ErrorStr("Embed template not found"))
+		return dbclient.EmbedTemplate{}, false
+	}
+
+	return template, true
+}
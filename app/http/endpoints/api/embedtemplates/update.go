@@ -0,0 +1,200 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	api_panels "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/panel"
+	api_tags "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/tags"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+)
+
+// maxResends is the number of multi-panel messages that will be resent for a single edit, to prevent DoS. Multi-panels
+// beyond the limit are still updated, and show the new embed once they are next sent.
+const maxResends = 5
+
+type updateTemplateResponse struct {
+	Template types.EmbedTemplate `json:"template"`
+	// Updated is the number of panels, multi-panels and tags that the edit was applied to
+	Updated  int      `json:"updated"`
+	Warnings []string `json:"warnings"`
+}
+
+// UpdateTemplateHandler edits the template and applies the new embed to everything that uses it
+func UpdateTemplateHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	body, ok := bindTemplateBody(ctx)
+	if !ok {
+		return
+	}
+
+	template, ok := getTemplate(ctx, guildId)
+	if !ok {
+		return
+	}
+
+	references, err := dbclient.Dashboard.EmbedTemplateReferences.GetByTemplate(ctx, guildId, template.Id)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if err := validateForTargets(ctx, guildId, references, body.Embed); err != nil {
+		if !validation.RespondError(ctx, validation.WithPrefix(err, "/embed")) {
+			ctx.JSON(500, utils.ErrorJson(err))
+		}
+
+		return
+	}
+
+	before, err := types.NewEmbedTemplate(template)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	audit.SetBefore(ctx, before)
+
+	template.Name = body.Name
+	template.Data, err = body.encode()
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	template, ok, err = dbclient.Dashboard.EmbedTemplates.Update(ctx, template)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if !ok {
+		ctx.JSON(409, utils.ErrorStr("An embed template with this name already exists"))
+		return
+	}
+
+	res := updateTemplateResponse{
+		Warnings: make([]string, 0),
+	}
+
+	res.Template, err = types.NewEmbedTemplate(template)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	audit.SetAfter(ctx, res.Template)
+
+	if len(references) > 0 {
+		botContext, err := botcontext.ContextForGuild(guildId)
+		if err != nil {
+			ctx.JSON(500, utils.ErrorJson(err))
+			return
+		}
+
+		res.Updated, res.Warnings = applyTemplate(ctx, botContext, guildId, references, res.Template.Embed, body.Resend)
+	}
+
+	ctx.JSON(200, res)
+}
+
+// validateForTargets checks the embed against the placeholder rules of each kind of target that uses the template, so
+// that the template cannot be edited into a state that one of its targets would reject. Of the targets, only tags
+// expand placeholders.
+func validateForTargets(ctx context.Context, guildId uint64, references []dbclient.EmbedTemplateReference, embed *types.CustomEmbed) error {
+	for _, reference := range references {
+		if reference.Kind == dbclient.EmbedTemplateTargetTag {
+			return api_tags.ValidateEmbedTemplate(ctx, guildId, embed)
+		}
+	}
+
+	return nil
+}
+
+// applyTemplate copies the embed to each of the references, returning the number of targets updated. The targets are
+// stored by the bot's tables, which cannot be updated in a single transaction, so a target that fails to update does
+// not stop the remaining targets from being updated, and is returned as a warning instead.
+func applyTemplate(
+	ctx context.Context,
+	botContext *botcontext.BotContext,
+	guildId uint64,
+	references []dbclient.EmbedTemplateReference,
+	embed *types.CustomEmbed,
+	resend bool,
+) (int, []string) {
+	var updated, resent int
+	warnings := make([]string, 0)
+	for _, reference := range references {
+		var ok bool
+		var err error
+
+		switch reference.Kind {
+		case dbclient.EmbedTemplateTargetPanel:
+			var panelId int
+			if panelId, err = strconv.Atoi(reference.TargetId); err != nil {
+				break
+			}
+
+			ok, err = api_panels.ApplyWelcomeMessageTemplate(ctx, guildId, panelId, embed)
+		case dbclient.EmbedTemplateTargetMultiPanel:
+			var multiPanelId int
+			if multiPanelId, err = strconv.Atoi(reference.TargetId); err != nil {
+				break
+			}
+
+			shouldResend := resend && resent < maxResends
+			if shouldResend {
+				resent++
+			} else if resend {
+				warnings = append(warnings, fmt.Sprintf("Multi-panel %d was updated but not resent, as only %d multi-panels can be resent at once", multiPanelId, maxResends))
+			}
+
+			ok, err = api_panels.ApplyMultiPanelTemplate(ctx, botContext, guildId, multiPanelId, embed, shouldResend)
+			if err != nil && shouldResend {
+				warnings = append(warnings, fmt.Sprintf("Failed to resend multi-panel %d: %s", multiPanelId, err.Error()))
+				ok, err = true, nil
+			}
+		case dbclient.EmbedTemplateTargetTag:
+			ok, err = api_tags.ApplyEmbedTemplate(ctx, guildId, reference.TargetId, embed)
+		default:
+			continue
+		}
+
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("Failed to update %s: %s", targetName(reference), err.Error()))
+			continue
+		}
+
+		// The target was deleted without its reference being removed
+		if !ok {
+			if err := dbclient.Dashboard.EmbedTemplateReferences.Delete(ctx, guildId, reference.Kind, reference.TargetId); err != nil {
+				warnings = append(warnings, fmt.Sprintf("Failed to unlink deleted %s: %s", targetName(reference), err.Error()))
+			}
+
+			continue
+		}
+
+		updated++
+	}
+
+	return updated, warnings
+}
+
+func targetName(reference dbclient.EmbedTemplateReference) string {
+	switch reference.Kind {
+	case dbclient.EmbedTemplateTargetPanel:
+		return "panel " + reference.TargetId
+	case dbclient.EmbedTemplateTargetMultiPanel:
+		return "multi-panel " + reference.TargetId
+	default:
+		return fmt.Sprintf("%s %s", reference.Kind, reference.TargetId)
+	}
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/dryrun"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	database "github.com/jadevelopmentgrp/Tickets-Database"
@@ -24,7 +26,12 @@ func CreateForm(c *gin.Context) {
 	}
 
 	if len(data.Title) > 45 {
-		c.JSON(400, utils.ErrorStr("Title is too long"))
+		validation.RespondField(c, "/title", validation.CodeTooLong, "Title is too long")
+		return
+	}
+
+	if dryrun.Requested(c) {
+		dryrun.Respond(c, nil, data)
 		return
 	}
 
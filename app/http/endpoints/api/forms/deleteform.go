@@ -3,6 +3,8 @@ package forms
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/dryrun"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	"net/http"
@@ -34,10 +36,22 @@ func DeleteForm(c *gin.Context) {
 		return
 	}
 
+	if dryrun.Requested(c) {
+		dryrun.Respond(c, form, nil)
+		return
+	}
+
+	audit.SetBefore(c, form)
+
 	if err := dbclient.Client.Forms.Delete(c, formId); err != nil {
 		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
 		return
 	}
 
+	if err := dbclient.Dashboard.FormSchemas.Delete(c, formId); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
 	c.JSON(200, utils.SuccessResponse)
 }
@@ -11,7 +11,8 @@ import (
 
 type embeddedForm struct {
 	database.Form
-	Inputs []database.FormInput `json:"inputs"`
+	Inputs        []database.FormInput `json:"inputs"`
+	SchemaVersion int                  `json:"schema_version"`
 }
 
 func GetForms(c *gin.Context) {
@@ -29,6 +30,12 @@ func GetForms(c *gin.Context) {
 		return
 	}
 
+	schemas, err := dbclient.Dashboard.FormSchemas.GetByGuild(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
 	data := make([]embeddedForm, len(forms))
 	for i, form := range forms {
 		formInputs, ok := inputs[form.Id]
@@ -36,9 +43,15 @@ func GetForms(c *gin.Context) {
 			formInputs = make([]database.FormInput, 0)
 		}
 
+		schemaVersion := SchemaVersionLegacy
+		if schema, ok := schemas[form.Id]; ok {
+			schemaVersion = schema.Version
+		}
+
 		data[i] = embeddedForm{
-			Form:   form,
-			Inputs: formInputs,
+			Form:          form,
+			Inputs:        formInputs,
+			SchemaVersion: schemaVersion,
 		}
 	}
 
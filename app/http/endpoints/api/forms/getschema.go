@@ -0,0 +1,51 @@
+package forms
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+)
+
+func GetSchema(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	formId, err := strconv.Atoi(c.Param("form_id"))
+	if err != nil {
+		c.JSON(400, utils.ErrorStr("Invalid form ID"))
+		return
+	}
+
+	form, ok, err := dbclient.Client.Forms.Get(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	if !ok {
+		c.JSON(404, utils.ErrorStr("Form not found"))
+		return
+	}
+
+	if form.GuildId != guildId {
+		c.JSON(403, utils.ErrorStr("Form does not belong to this guild"))
+		return
+	}
+
+	inputs, err := dbclient.Client.FormInput.GetInputs(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	schema, err := LoadSchema(c, formId, inputs)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	c.JSON(200, schema)
+}
@@ -0,0 +1,130 @@
+package forms
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+	"github.com/rxdn/gdl/objects/interaction/component"
+)
+
+const (
+	// SchemaVersionLegacy forms are defined entirely by their form_input rows: a single page of up to 5 text inputs
+	SchemaVersionLegacy = 1
+	// SchemaVersion forms are stored in the form schemas table, and are mirrored to form_input rows, which is what the
+	// bot reads. The schema is limited to what the bot can show from those rows: a single page of text inputs.
+	SchemaVersion = 2
+)
+
+type InputType string
+
+const (
+	InputTypeText InputType = "text"
+)
+
+type (
+	Schema struct {
+		Version int    `json:"version"`
+		Pages   []Page `json:"pages" validate:"required,len=1,dive"`
+	}
+
+	// Page is shown to the user as a single modal
+	Page struct {
+		Inputs []Input `json:"inputs" validate:"required,min=1,max=5,dive"`
+	}
+
+	Input struct {
+		// Key identifies the input within the form
+		Key string `json:"key" validate:"required,min=1,max=32"`
+		// CustomId is assigned by the server, and is kept when the schema is updated
+		CustomId    string    `json:"custom_id"`
+		Type        InputType `json:"type" validate:"required,oneof=text"`
+		Label       string    `json:"label" validate:"required,min=1,max=45"`
+		Placeholder *string   `json:"placeholder,omitempty" validate:"omitempty,min=1,max=100"`
+		Required    bool      `json:"required"`
+
+		Style     component.TextStyleTypes `json:"style,omitempty"`
+		MinLength uint16                   `json:"min_length,omitempty" validate:"max=1024"`
+		MaxLength uint16                   `json:"max_length,omitempty" validate:"max=1024"`
+	}
+)
+
+var keyPattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// ValidateSchema checks a submitted schema, collecting every invalid field. Checks that the struct tags cannot express
+// are made here.
+func ValidateSchema(schema Schema) error {
+	errs := []error{validation.Struct(validate, schema)}
+
+	if schema.Version != SchemaVersion {
+		errs = append(errs, validation.NewFieldErrorf("/version", validation.CodeInvalid, "Unsupported form schema version %d", schema.Version))
+	}
+
+	keys := make(map[string]struct{})
+	for i, page := range schema.Pages {
+		pagePointer := validation.IndexPointer("/pages", i)
+
+		for j, input := range page.Inputs {
+			pointer := validation.IndexPointer(pagePointer+"/inputs", j)
+
+			if _, ok := keys[input.Key]; ok {
+				errs = append(errs, validation.NewFieldErrorf(pointer+"/key", validation.CodeDuplicate, "Key \"%s\" is used by another input", input.Key))
+			} else if input.Key != "" && !keyPattern.MatchString(input.Key) {
+				errs = append(errs, validation.NewFieldError(pointer+"/key", validation.CodeInvalid, "Keys may only contain lowercase letters, numbers, dashes and underscores"))
+			}
+
+			keys[input.Key] = struct{}{}
+			errs = append(errs, validateInput(pointer, input))
+		}
+	}
+
+	return validation.Join(errs...)
+}
+
+func validateInput(pointer string, input Input) error {
+	var errs []error
+
+	if input.Style != component.TextStyleShort && input.Style != component.TextStyleParagraph {
+		errs = append(errs, validation.NewFieldError(pointer+"/style", validation.CodeInvalid, "Invalid text input style"))
+	}
+
+	if input.MaxLength != 0 && input.MinLength > input.MaxLength {
+		errs = append(errs, validation.NewFieldError(pointer+"/min_length", validation.CodeInvalid, "Minimum length cannot be greater than the maximum length"))
+	}
+
+	return validation.Join(errs...)
+}
+
+// legacySchema describes a form that has no stored schema, so that it can be edited with the schema endpoint. The
+// custom IDs of the inputs are kept, so that upgrading the form does not recreate its inputs.
+func legacySchema(inputs []database.FormInput) Schema {
+	sort.Slice(inputs, func(i, j int) bool {
+		return inputs[i].Position < inputs[j].Position
+	})
+
+	page := Page{
+		Inputs: make([]Input, len(inputs)),
+	}
+
+	for i, input := range inputs {
+		page.Inputs[i] = Input{
+			Key:         "input_" + strconv.Itoa(input.Id),
+			CustomId:    input.CustomId,
+			Type:        InputTypeText,
+			Label:       input.Label,
+			Placeholder: input.Placeholder,
+			Required:    input.Required,
+			Style:       component.TextStyleTypes(input.Style),
+			MinLength:   utils.ValueOrZero(input.MinLength),
+			MaxLength:   utils.ValueOrZero(input.MaxLength),
+		}
+	}
+
+	return Schema{
+		Version: SchemaVersionLegacy,
+		Pages:   []Page{page},
+	}
+}
@@ -0,0 +1,64 @@
+package forms
+
+import (
+	"testing"
+
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/rxdn/gdl/objects/interaction/component"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSchema() Schema {
+	return Schema{
+		Version: SchemaVersion,
+		Pages: []Page{
+			{
+				Inputs: []Input{
+					{Key: "email", Type: InputTypeText, Label: "Email", Style: component.TextStyleShort, MaxLength: 100},
+					{Key: "details", Type: InputTypeText, Label: "Details", Style: component.TextStyleParagraph, Required: true},
+				},
+			},
+		},
+	}
+}
+
+func pointers(err error) []string {
+	failures, _ := validation.Failures(err)
+	return utils.Map(failures, func(failure *validation.InvalidInputError) string { return failure.Pointer })
+}
+
+func TestValidateSchema(t *testing.T) {
+	assert.NoError(t, ValidateSchema(testSchema()))
+}
+
+func TestValidateSchemaVersion(t *testing.T) {
+	schema := testSchema()
+	schema.Version = SchemaVersionLegacy
+
+	assert.Equal(t, []string{"/version"}, pointers(ValidateSchema(schema)))
+}
+
+func TestValidateSchemaPages(t *testing.T) {
+	schema := testSchema()
+	schema.Pages = append(schema.Pages, Page{
+		Inputs: []Input{{Key: "invoice", Type: InputTypeText, Label: "Invoice number", Style: component.TextStyleShort}},
+	})
+
+	assert.Equal(t, []string{"/pages"}, pointers(ValidateSchema(schema)))
+}
+
+func TestValidateSchemaInputs(t *testing.T) {
+	schema := testSchema()
+	schema.Pages[0].Inputs[0].Type = "string_select"
+	schema.Pages[0].Inputs[0].MinLength = 200
+	schema.Pages[0].Inputs[1].Key = "email"
+	schema.Pages[0].Inputs[1].Style = 0
+
+	assert.ElementsMatch(t, []string{
+		"/pages/0/inputs/0/type",
+		"/pages/0/inputs/0/min_length",
+		"/pages/0/inputs/1/key",
+		"/pages/0/inputs/1/style",
+	}, pointers(ValidateSchema(schema)))
+}
@@ -0,0 +1,144 @@
+package forms
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v4"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+)
+
+// LoadSchema returns the stored schema of the form, or describes its form_input rows if it has not been upgraded
+func LoadSchema(ctx context.Context, formId int, inputs []database.FormInput) (Schema, error) {
+	stored, ok, err := dbclient.Dashboard.FormSchemas.Get(ctx, formId)
+	if err != nil {
+		return Schema{}, err
+	}
+
+	if !ok {
+		return legacySchema(inputs), nil
+	}
+
+	var schema Schema
+	if err := json.Unmarshal(stored.Data, &schema); err != nil {
+		return Schema{}, err
+	}
+
+	return schema, nil
+}
+
+// StoreSchemaTx stores a validated schema, assigning custom IDs to new inputs. Inputs must either have no custom ID,
+// or keep the custom ID of one of the form's existing inputs. The inputs are mirrored to the form_input table, matched
+// by custom ID so that existing inputs are updated rather than recreated.
+func StoreSchemaTx(ctx context.Context, tx pgx.Tx, guildId uint64, formId int, schema *Schema, existingInputs []database.FormInput) error {
+	for i := range schema.Pages {
+		for j := range schema.Pages[i].Inputs {
+			input := &schema.Pages[i].Inputs[j]
+			if input.CustomId != "" {
+				continue
+			}
+
+			customId, err := utils.RandString(30)
+			if err != nil {
+				return err
+			}
+
+			input.CustomId = customId
+		}
+	}
+
+	existing := make(map[string]database.FormInput, len(existingInputs))
+	for _, input := range existingInputs {
+		existing[input.CustomId] = input
+	}
+
+	var mirrored []database.FormInput
+	for _, input := range schema.Pages[0].Inputs {
+		mirrored = append(mirrored, database.FormInput{
+			FormId:      formId,
+			Position:    len(mirrored) + 1,
+			CustomId:    input.CustomId,
+			Style:       uint8(input.Style),
+			Label:       input.Label,
+			Placeholder: input.Placeholder,
+			Required:    input.Required,
+			MinLength:   utils.Ptr(input.MinLength),
+			MaxLength:   utils.Ptr(input.MaxLength),
+		})
+	}
+
+	// Delete first, as the remaining inputs may be moved to the positions of the deleted inputs
+	for _, input := range existingInputs {
+		if !utils.ExistsMap(mirrored, input.CustomId, customIdMapper) {
+			if err := dbclient.Client.FormInput.DeleteTx(ctx, tx, input.Id, formId); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, input := range mirrored {
+		if current, ok := existing[input.CustomId]; ok {
+			input.Id = current.Id
+			if err := dbclient.Client.FormInput.UpdateTx(ctx, tx, input); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if _, err := dbclient.Client.FormInput.CreateTx(ctx,
+			tx,
+			formId,
+			input.CustomId,
+			input.Position,
+			input.Style,
+			input.Label,
+			input.Placeholder,
+			input.Required,
+			input.MinLength,
+			input.MaxLength,
+		); err != nil {
+			return err
+		}
+	}
+
+	schema.Version = SchemaVersion
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return err
+	}
+
+	return dbclient.Dashboard.FormSchemas.SetTx(ctx, tx, dbclient.FormSchema{
+		FormId:  formId,
+		GuildId: guildId,
+		Version: schema.Version,
+		Data:    data,
+	})
+}
+
+// keepKnownCustomIds clears the custom IDs of inputs that do not belong to the form, so that a submitted schema cannot
+// take over the custom ID of another form's input
+func keepKnownCustomIds(schema *Schema, current Schema) {
+	known := make(map[string]struct{})
+	for _, page := range current.Pages {
+		for _, input := range page.Inputs {
+			known[input.CustomId] = struct{}{}
+		}
+	}
+
+	for i := range schema.Pages {
+		for j := range schema.Pages[i].Inputs {
+			input := &schema.Pages[i].Inputs[j]
+			if _, ok := known[input.CustomId]; !ok {
+				input.CustomId = ""
+			}
+		}
+	}
+}
+
+func customIdMapper(input database.FormInput) string {
+	return input.CustomId
+}
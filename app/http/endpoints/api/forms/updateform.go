@@ -3,6 +3,9 @@ package forms
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/dryrun"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	"net/http"
@@ -19,7 +22,7 @@ func UpdateForm(c *gin.Context) {
 	}
 
 	if len(data.Title) > 45 {
-		c.JSON(400, utils.ErrorStr("Title is too long"))
+		validation.RespondField(c, "/title", validation.CodeTooLong, "Title is too long")
 		return
 	}
 
@@ -45,6 +48,13 @@ func UpdateForm(c *gin.Context) {
 		return
 	}
 
+	if dryrun.Requested(c) {
+		dryrun.Respond(c, createFormBody{Title: form.Title}, data)
+		return
+	}
+
+	audit.SetBefore(c, createFormBody{Title: form.Title})
+
 	if err := dbclient.Client.Forms.UpdateTitle(c, formId, data.Title); err != nil {
 		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
 		return
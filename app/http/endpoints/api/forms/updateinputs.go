@@ -2,7 +2,6 @@ package forms
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/http"
 	"sort"
@@ -11,6 +10,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/dryrun"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	database "github.com/jadevelopmentgrp/Tickets-Database"
@@ -19,12 +21,12 @@ import (
 
 type (
 	updateInputsBody struct {
-		Create []inputCreateBody `json:"create" validate:"omitempty,dive"`
+		Create []InputCreateBody `json:"create" validate:"omitempty,dive"`
 		Update []inputUpdateBody `json:"update" validate:"omitempty,dive"`
 		Delete []int             `json:"delete" validate:"omitempty"`
 	}
 
-	inputCreateBody struct {
+	InputCreateBody struct {
 		Label       string                   `json:"label" validate:"required,min=1,max=45"`
 		Placeholder *string                  `json:"placeholder,omitempty" validate:"omitempty,min=1,max=100"`
 		Position    int                      `json:"position" validate:"required,min=1,max=5"`
@@ -36,7 +38,7 @@ type (
 
 	inputUpdateBody struct {
 		Id              int `json:"id" validate:"required"`
-		inputCreateBody `validate:"required,dive"`
+		InputCreateBody `validate:"required,dive"`
 	}
 )
 
@@ -57,24 +59,6 @@ func UpdateInputs(c *gin.Context) {
 		return
 	}
 
-	if err := validate.Struct(data); err != nil {
-		var validationErrors validator.ValidationErrors
-		if !errors.As(err, &validationErrors) {
-			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "An error occurred while validating the integration"))
-			return
-		}
-
-		formatted := "Your input contained the following errors:\n" + utils.FormatValidationErrors(validationErrors)
-		c.JSON(400, utils.ErrorStr(formatted))
-		return
-	}
-
-	fieldCount := len(data.Create) + len(data.Update)
-	if fieldCount <= 0 || fieldCount > 5 {
-		c.JSON(400, utils.ErrorStr("Forms must have between 1 and 5 inputs"))
-		return
-	}
-
 	// Verify form exists and is from the right guild
 	form, ok, err := dbclient.Client.Forms.Get(c, formId)
 	if err != nil {
@@ -92,33 +76,68 @@ func UpdateInputs(c *gin.Context) {
 		return
 	}
 
+	// Inputs of upgraded forms are mirrored from the schema, and would be overwritten by the next schema update
+	if _, upgraded, err := dbclient.Dashboard.FormSchemas.Get(c, formId); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	} else if upgraded {
+		c.JSON(409, utils.ErrorStr("This form uses version %d of the form schema, and must be edited through its schema", SchemaVersion))
+		return
+	}
+
 	existingInputs, err := dbclient.Client.FormInput.GetInputs(c, formId)
 	if err != nil {
 		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
 		return
 	}
 
-	// Verify that the UPDATE inputs exist
-	for _, input := range data.Update {
-		if !utils.ExistsMap(existingInputs, input.Id, idMapper) {
-			c.JSON(400, utils.ErrorStr("Input (to be updated) not found"))
-			return
+	if err := validateInputs(data, existingInputs); err != nil {
+		if !validation.RespondError(c, err) {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
 		}
+
+		return
 	}
 
-	// Verify that the DELETE inputs exist
-	for _, id := range data.Delete {
-		if !utils.ExistsMap(existingInputs, id, idMapper) {
-			c.JSON(400, utils.ErrorStr("Input (to be deleted) not found"))
-			return
+	before, after := previewInputs(data, existingInputs)
+	if dryrun.Requested(c) {
+		dryrun.Respond(c, before, after)
+		return
+	}
+
+	audit.SetBefore(c, before)
+	audit.SetAfter(c, after)
+
+	if err := saveInputs(c, formId, data, existingInputs); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	c.Status(204)
+}
+
+// validateInputs checks the body against the existing inputs of the form, collecting every invalid field
+func validateInputs(data updateInputsBody, existingInputs []database.FormInput) error {
+	errs := []error{validation.Struct(validate, data)}
+
+	fieldCount := len(data.Create) + len(data.Update)
+	if fieldCount <= 0 || fieldCount > 5 {
+		errs = append(errs, validation.NewFieldError("", validation.CodeInvalid, "Forms must have between 1 and 5 inputs"))
+	}
+
+	// Verify that the UPDATE inputs exist
+	for i, input := range data.Update {
+		if !utils.ExistsMap(existingInputs, input.Id, idMapper) {
+			errs = append(errs, validation.NewFieldError(validation.IndexPointer("/update", i)+"/id", validation.CodeNotFound, "Input (to be updated) not found"))
 		}
 	}
 
-	// Ensure no overlap between DELETE and UPDATE
-	for _, id := range data.Delete {
-		if utils.ExistsMap(data.Update, id, idMapperBody) {
-			c.JSON(400, utils.ErrorStr("Delete and update overlap"))
-			return
+	// Verify that the DELETE inputs exist, and do not overlap with the UPDATE inputs
+	for i, id := range data.Delete {
+		if !utils.ExistsMap(existingInputs, id, idMapper) {
+			errs = append(errs, validation.NewFieldError(validation.IndexPointer("/delete", i), validation.CodeNotFound, "Input (to be deleted) not found"))
+		} else if utils.ExistsMap(data.Update, id, idMapperBody) {
+			errs = append(errs, validation.NewFieldError(validation.IndexPointer("/delete", i), validation.CodeDuplicate, "Delete and update overlap"))
 		}
 	}
 
@@ -131,30 +150,23 @@ func UpdateInputs(c *gin.Context) {
 	}
 
 	// Now verify that the contents match exactly
-	if len(remainingExisting) != len(data.Update) {
-		c.JSON(400, utils.ErrorStr("All inputs must be included in the update array"))
-		return
-	}
-
+	allIncluded := len(remainingExisting) == len(data.Update)
 	for _, input := range data.Update {
 		if !utils.Exists(remainingExisting, input.Id) {
-			c.JSON(400, utils.ErrorStr("All inputs must be included in the update array"))
-			return
+			allIncluded = false
 		}
 	}
 
-	// Verify that the positions are unique, and are in ascending order
-	if !arePositionsCorrect(data) {
-		c.JSON(400, utils.ErrorStr("Positions must be unique and in ascending order"))
-		return
+	if !allIncluded {
+		errs = append(errs, validation.NewFieldError("/update", validation.CodeInvalid, "All inputs must be included in the update array"))
 	}
 
-	if err := saveInputs(c, formId, data, existingInputs); err != nil {
-		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
-		return
+	// Verify that the positions are unique, and are in ascending order
+	if !arePositionsCorrect(data) {
+		errs = append(errs, validation.NewFieldError("", validation.CodeInvalid, "Positions must be unique and in ascending order"))
 	}
 
-	c.Status(204)
+	return validation.Join(errs...)
 }
 
 func idMapper(input database.FormInput) int {
@@ -188,6 +200,34 @@ func arePositionsCorrect(body updateInputsBody) bool {
 	return true
 }
 
+// previewInputs returns the inputs before and after the update, keyed by input ID. Inputs that would be created are
+// keyed by their index in the create array, prefixed with "new_".
+func previewInputs(data updateInputsBody, existingInputs []database.FormInput) (before, after map[string]InputCreateBody) {
+	before = make(map[string]InputCreateBody, len(existingInputs))
+	for _, input := range existingInputs {
+		before[strconv.Itoa(input.Id)] = InputCreateBody{
+			Label:       input.Label,
+			Placeholder: input.Placeholder,
+			Position:    input.Position,
+			Style:       component.TextStyleTypes(input.Style),
+			Required:    input.Required,
+			MinLength:   utils.ValueOrZero(input.MinLength),
+			MaxLength:   utils.ValueOrZero(input.MaxLength),
+		}
+	}
+
+	after = make(map[string]InputCreateBody, len(data.Update)+len(data.Create))
+	for _, input := range data.Update {
+		after[strconv.Itoa(input.Id)] = input.InputCreateBody
+	}
+
+	for i, input := range data.Create {
+		after[fmt.Sprintf("new_%d", i)] = input
+	}
+
+	return
+}
+
 func saveInputs(ctx context.Context, formId int, data updateInputsBody, existingInputs []database.FormInput) error {
 	// We can now update in the database
 	tx, err := dbclient.Client.BeginTx(ctx)
@@ -0,0 +1,99 @@
+package forms
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/dryrun"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+)
+
+// UpdateSchema replaces the schema of the form. Forms that still use the legacy schema are upgraded, keeping their
+// existing inputs.
+func UpdateSchema(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	formId, err := strconv.Atoi(c.Param("form_id"))
+	if err != nil {
+		c.JSON(400, utils.ErrorStr("Invalid form ID"))
+		return
+	}
+
+	var schema Schema
+	if err := c.BindJSON(&schema); err != nil {
+		c.JSON(400, utils.ErrorJson(err))
+		return
+	}
+
+	form, ok, err := dbclient.Client.Forms.Get(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	if !ok {
+		c.JSON(404, utils.ErrorStr("Form not found"))
+		return
+	}
+
+	if form.GuildId != guildId {
+		c.JSON(403, utils.ErrorStr("Form does not belong to this guild"))
+		return
+	}
+
+	if err := ValidateSchema(schema); err != nil {
+		if !validation.RespondError(c, err) {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		}
+
+		return
+	}
+
+	existingInputs, err := dbclient.Client.FormInput.GetInputs(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	current, err := LoadSchema(c, formId, existingInputs)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	keepKnownCustomIds(&schema, current)
+
+	if dryrun.Requested(c) {
+		dryrun.Respond(c, current, schema)
+		return
+	}
+
+	audit.SetBefore(c, current)
+
+	tx, err := dbclient.Client.BeginTx(c)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	defer tx.Rollback(context.Background())
+
+	if err := StoreSchemaTx(c, tx, guildId, formId, &schema, existingInputs); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	if err := tx.Commit(c); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	audit.SetAfter(c, schema)
+	c.JSON(200, schema)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	"net/http"
@@ -26,7 +27,7 @@ func ActivateIntegrationHandler(ctx *gin.Context) {
 	}
 
 	if activeCount >= 5 {
-		ctx.JSON(400, utils.ErrorStr("You can only have 5 integrations active at once"))
+		validation.RespondField(ctx, "", validation.CodeLimitExceeded, "You can only have 5 integrations active at once")
 		return
 	}
 
@@ -72,37 +73,13 @@ func ActivateIntegrationHandler(ctx *gin.Context) {
 		return
 	}
 
-	if len(secrets) != len(data.Secrets) {
-		ctx.JSON(400, utils.ErrorStr("Invalid secret values"))
+	secretMap, err := validateSecretValues(secrets, data.Secrets)
+	if err != nil {
+		validation.RespondError(ctx, err)
 		return
 	}
 
-	// Since we've checked the length, we can just iterate over the secrets, and they're guaranteed to be correct
-	secretMap := make(map[int]string)
-	secretValues := make(map[string]string)
-	for secretName, value := range data.Secrets {
-		if len(value) == 0 || len(value) > 255 {
-			ctx.JSON(400, utils.ErrorStr("Secret values must be between 1 and 255 characters"))
-			return
-		}
-
-		found := false
-
-	inner:
-		for _, secret := range secrets {
-			if secret.Name == secretName {
-				found = true
-				secretMap[secret.Id] = value
-				secretValues[secret.Name] = value
-				break inner
-			}
-		}
-
-		if !found {
-			ctx.JSON(400, utils.ErrorStr("Invalid secret values"))
-			return
-		}
-	}
+	secretValues := data.Secrets
 
 	// Validate secrets
 	if integration.Public && integration.Approved && integration.ValidationUrl != nil {
@@ -5,7 +5,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	database "github.com/jadevelopmentgrp/Tickets-Database"
@@ -59,31 +59,14 @@ func CreateIntegrationHandler(ctx *gin.Context) {
 		return
 	}
 
-	if err := validate.Struct(data); err != nil {
-		validationErrors, ok := err.(validator.ValidationErrors)
-		if !ok {
+	if err := validation.Join(validation.Struct(validate, data), validateValidationUrl(data.WebhookUrl, data.ValidationUrl)); err != nil {
+		if !validation.RespondError(ctx, err) {
 			ctx.JSON(500, utils.ErrorStr("An error occurred while validating the integration"))
-			return
 		}
 
-		formatted := "Your input contained the following errors:\n" + utils.FormatValidationErrors(validationErrors)
-		ctx.JSON(400, utils.ErrorStr(formatted))
 		return
 	}
 
-	if data.ValidationUrl != nil {
-		sameHost, err := isSameValidationUrlHost(data.WebhookUrl, *data.ValidationUrl)
-		if err != nil {
-			ctx.JSON(500, utils.ErrorJson(err))
-			return
-		}
-
-		if !sameHost {
-			ctx.JSON(400, utils.ErrorStr("Validation URL must be on the same host as the webhook URL"))
-			return
-		}
-	}
-
 	integration, err := dbclient.Client.CustomIntegrations.Create(ctx, userId, data.WebhookUrl, data.ValidationUrl, data.Method, data.Name, data.Description, data.ImageUrl, data.PrivacyPolicyUrl)
 	if err != nil {
 		ctx.JSON(500, utils.ErrorJson(err))
@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	"strconv"
@@ -40,34 +41,14 @@ func UpdateIntegrationSecretsHandler(ctx *gin.Context) {
 		return
 	}
 
-	if len(secrets) != len(data.Secrets) {
-		ctx.JSON(400, utils.ErrorStr("Invalid secret values"))
+	secretMap, err := validateSecretValues(secrets, data.Secrets)
+	if err != nil {
+		validation.RespondError(ctx, err)
 		return
 	}
 
-	// Since we've checked the length, we can just iterate over the secrets and they're guaranteed to be correct
-	secretMap := make(map[int]string)
-	for secretName, value := range data.Secrets {
-		if len(value) == 0 || len(value) > 255 {
-			ctx.JSON(400, utils.ErrorStr("Secret values must be between 1 and 255 characters"))
-			return
-		}
-
-		found := false
-
-	inner:
-		for _, secret := range secrets {
-			if secret.Name == secretName {
-				found = true
-				secretMap[secret.Id] = value
-				break inner
-			}
-		}
-
-		if !found {
-			ctx.JSON(400, utils.ErrorStr("Invalid secret values"))
-			return
-		}
+	if !setActiveIntegrationBefore(ctx, guildId, integrationId) {
+		return
 	}
 
 	if err := dbclient.Client.CustomIntegrationSecretValues.UpdateAll(ctx, guildId, integrationId, secretMap); err != nil {
@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	"strconv"
@@ -16,6 +17,10 @@ func RemoveIntegrationHandler(ctx *gin.Context) {
 		return
 	}
 
+	if !setActiveIntegrationBefore(ctx, guildId, integrationId) {
+		return
+	}
+
 	if err := dbclient.Client.CustomIntegrationGuilds.RemoveFromGuild(ctx, integrationId, guildId); err != nil {
 		ctx.JSON(500, utils.ErrorJson(err))
 		return
@@ -23,3 +28,35 @@ func RemoveIntegrationHandler(ctx *gin.Context) {
 
 	ctx.Status(204)
 }
+
+// activeIntegration identifies an integration active in the guild in the audit log. Secret values are never recorded.
+type activeIntegration struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// setActiveIntegrationBefore records the integration in the audit log if it is active in the guild. If false is
+// returned, a response has already been written.
+func setActiveIntegrationBefore(ctx *gin.Context, guildId uint64, integrationId int) bool {
+	active, err := dbclient.Client.CustomIntegrationGuilds.IsActive(ctx, integrationId, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return false
+	}
+
+	if !active {
+		return true
+	}
+
+	integration, ok, err := dbclient.Client.CustomIntegrations.Get(ctx, integrationId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return false
+	}
+
+	if ok {
+		audit.SetBefore(ctx, activeIntegration{Id: integration.Id, Name: integration.Name})
+	}
+
+	return true
+}
@@ -1,12 +1,10 @@
 package api
 
 import (
-	"fmt"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	database "github.com/jadevelopmentgrp/Tickets-Database"
@@ -56,20 +54,11 @@ func UpdateIntegrationHandler(ctx *gin.Context) {
 		return
 	}
 
-	if err := validate.Struct(data); err != nil {
-		validationErrors, ok := err.(validator.ValidationErrors)
-		if !ok {
+	if err := validation.Join(validation.Struct(validate, data), validateValidationUrl(data.WebhookUrl, data.ValidationUrl)); err != nil {
+		if !validation.RespondError(ctx, err) {
 			ctx.JSON(500, utils.ErrorStr("An error occurred while validating the integration"))
-			return
 		}
 
-		formatted := "Your input contained the following errors:"
-		for _, validationError := range validationErrors {
-			formatted += fmt.Sprintf("\n%s", validationError.Error())
-		}
-
-		formatted = strings.TrimSuffix(formatted, "\n")
-		ctx.JSON(400, utils.ErrorStr(formatted))
 		return
 	}
 
@@ -89,19 +78,6 @@ func UpdateIntegrationHandler(ctx *gin.Context) {
 		return
 	}
 
-	if data.ValidationUrl != nil {
-		sameHost, err := isSameValidationUrlHost(data.WebhookUrl, *data.ValidationUrl)
-		if err != nil {
-			ctx.JSON(500, utils.ErrorJson(err))
-			return
-		}
-
-		if !sameHost {
-			ctx.JSON(400, utils.ErrorStr("Validation URL must be on the same host as the webhook URL"))
-			return
-		}
-	}
-
 	// Update integration metadata
 	err = dbclient.Client.CustomIntegrations.Update(ctx, database.CustomIntegration{
 		Id:               integration.Id,
@@ -148,7 +124,7 @@ func (b *integrationUpdateBody) updatePlaceholders(ctx *gin.Context, integration
 		return false
 	}
 
-	for _, placeholder := range b.Placeholders {
+	for i, placeholder := range b.Placeholders {
 		if placeholder.Id != 0 {
 			isValid := false
 		inner:
@@ -158,14 +134,14 @@ func (b *integrationUpdateBody) updatePlaceholders(ctx *gin.Context, integration
 						isValid = true
 						break inner
 					} else {
-						ctx.JSON(400, utils.ErrorStr("Integration ID mismatch for placeholders"))
+						validation.RespondField(ctx, validation.IndexPointer("/placeholders", i)+"/id", validation.CodeNotFound, "Integration ID mismatch for placeholders")
 						return false
 					}
 				}
 			}
 
 			if !isValid {
-				ctx.JSON(400, utils.ErrorStr("Integration ID mismatch for placeholders"))
+				validation.RespondField(ctx, validation.IndexPointer("/placeholders", i)+"/id", validation.CodeNotFound, "Integration ID mismatch for placeholders")
 				return false
 			}
 		}
@@ -196,7 +172,7 @@ func (b *integrationUpdateBody) updateHeaders(ctx *gin.Context, integrationId in
 		return false
 	}
 
-	for _, header := range b.Headers {
+	for i, header := range b.Headers {
 		if header.Id != 0 {
 			isValid := false
 
@@ -207,14 +183,14 @@ func (b *integrationUpdateBody) updateHeaders(ctx *gin.Context, integrationId in
 						isValid = true
 						break inner
 					} else {
-						ctx.JSON(400, utils.ErrorStr("Integration ID mismatch for headers"))
+						validation.RespondField(ctx, validation.IndexPointer("/headers", i)+"/id", validation.CodeNotFound, "Integration ID mismatch for headers")
 						return false
 					}
 				}
 			}
 
 			if !isValid {
-				ctx.JSON(400, utils.ErrorStr("Integration ID mismatch for headers"))
+				validation.RespondField(ctx, validation.IndexPointer("/headers", i)+"/id", validation.CodeNotFound, "Integration ID mismatch for headers")
 				return false
 			}
 		}
@@ -245,7 +221,7 @@ func (b *integrationUpdateBody) updateSecrets(ctx *gin.Context, integrationId in
 		return false
 	}
 
-	for _, secret := range b.Secrets {
+	for i, secret := range b.Secrets {
 		if secret.Id != 0 {
 			isValid := false
 		inner:
@@ -255,14 +231,14 @@ func (b *integrationUpdateBody) updateSecrets(ctx *gin.Context, integrationId in
 						isValid = true
 						break inner
 					} else {
-						ctx.JSON(400, utils.ErrorStr("Integration ID mismatch for secrets"))
+						validation.RespondField(ctx, validation.IndexPointer("/secrets", i)+"/id", validation.CodeNotFound, "Integration ID mismatch for secrets")
 						return false
 					}
 				}
 			}
 
 			if !isValid {
-				ctx.JSON(400, utils.ErrorStr("Integration ID mismatch for secrets"))
+				validation.RespondField(ctx, validation.IndexPointer("/secrets", i)+"/id", validation.CodeNotFound, "Integration ID mismatch for secrets")
 				return false
 			}
 		}
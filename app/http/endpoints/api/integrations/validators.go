@@ -1,10 +1,14 @@
 package api
 
 import (
-	"github.com/go-playground/validator/v10"
-	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	"net/url"
 	"regexp"
+	"sort"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
 )
 
 var placeholderRegex = regexp.MustCompile(`%[\w|-]+%`)
@@ -34,3 +38,67 @@ func WebhookValidator(fl validator.FieldLevel) bool {
 
 	return true
 }
+
+// validateValidationUrl checks that the validation URL, if set, is on the same host as the webhook URL
+func validateValidationUrl(webhookUrl string, validationUrl *string) error {
+	if validationUrl == nil {
+		return nil
+	}
+
+	sameHost, err := isSameValidationUrlHost(webhookUrl, *validationUrl)
+	if err != nil {
+		return validation.NewFieldError("/validation_url", validation.CodeInvalid, "Invalid webhook or validation URL")
+	}
+
+	if !sameHost {
+		return validation.NewFieldError("/validation_url", validation.CodeInvalid, "Validation URL must be on the same host as the webhook URL")
+	}
+
+	return nil
+}
+
+// validateSecretValues checks that a value has been provided for each of the integration's secrets, returning the
+// values keyed by secret ID
+func validateSecretValues(secrets []database.CustomIntegrationSecret, values map[string]string) (map[int]string, error) {
+	var errs []error
+	if len(secrets) != len(values) {
+		errs = append(errs, validation.NewFieldError("/secrets", validation.CodeInvalid, "Invalid secret values"))
+	}
+
+	// Sort the names so that failures are reported in a stable order
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	secretMap := make(map[int]string)
+	for _, secretName := range names {
+		value := values[secretName]
+		pointer := "/secrets/" + secretName
+		if len(value) == 0 || len(value) > 255 {
+			errs = append(errs, validation.NewFieldError(pointer, validation.CodeInvalid, "Secret values must be between 1 and 255 characters"))
+			continue
+		}
+
+		found := false
+		for _, secret := range secrets {
+			if secret.Name == secretName {
+				found = true
+				secretMap[secret.Id] = value
+				break
+			}
+		}
+
+		if !found {
+			errs = append(errs, validation.NewFieldError(pointer, validation.CodeNotFound, "Invalid secret values"))
+		}
+	}
+
+	if err := validation.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return secretMap, nil
+}
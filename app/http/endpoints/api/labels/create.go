@@ -0,0 +1,49 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+)
+
+func CreateLabelHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	body, ok := bindLabelBody(ctx)
+	if !ok {
+		return
+	}
+
+	count, err := dbclient.Dashboard.TicketLabels.Count(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if count >= maxLabels {
+		ctx.JSON(400, utils.ErrorStr("You cannot have more than %d labels", maxLabels))
+		return
+	}
+
+	label, ok, err := dbclient.Dashboard.TicketLabels.Create(ctx, dbclient.TicketLabel{
+		GuildId: guildId,
+		Name:    body.Name,
+		Colour:  body.Colour.Uint32(),
+	})
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if !ok {
+		ctx.JSON(409, utils.ErrorStr("A label with this name already exists"))
+		return
+	}
+
+	res := types.NewTicketLabel(label)
+	audit.SetAfter(ctx, res)
+
+	ctx.JSON(200, res)
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+)
+
+// DeleteLabelHandler deletes the label, removing it from every ticket it is attached to
+func DeleteLabelHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	label, ok := getLabel(ctx, guildId)
+	if !ok {
+		return
+	}
+
+	audit.SetBefore(ctx, types.NewTicketLabel(label))
+
+	if err := dbclient.Dashboard.TicketLabels.Delete(ctx, guildId, label.Id); err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	ctx.JSON(200, utils.SuccessResponse)
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+)
+
+const maxLabels = 50
+
+type labelBody struct {
+	Name   string       `json:"name" validate:"required,min=1,max=32"`
+	Colour types.Colour `json:"colour" validate:"lte=16777215"`
+}
+
+var validate = validator.New()
+
+func (b *labelBody) validate() error {
+	b.Name = strings.TrimSpace(b.Name)
+
+	return validation.Struct(validate, b)
+}
+
+// bindLabelBody reads and validates the request body, writing an error response and returning false if it is invalid
+func bindLabelBody(ctx *gin.Context) (labelBody, bool) {
+	var body labelBody
+	if err := ctx.BindJSON(&body); err != nil {
+		ctx.JSON(400, utils.ErrorJson(err))
+		return labelBody{}, false
+	}
+
+	if err := body.validate(); err != nil {
+		if !validation.RespondError(ctx, err) {
+			ctx.JSON(500, utils.ErrorStr("An error occurred while validating the label"))
+		}
+
+		return labelBody{}, false
+	}
+
+	return body, true
+}
+
+// getLabel loads the label from the path, writing an error response and returning false if it cannot be used
+func getLabel(ctx *gin.Context, guildId uint64) (dbclient.TicketLabel, bool) {
+	labelId, err := strconv.Atoi(ctx.Param("labelid"))
+	if err != nil {
+		ctx.JSON(400, utils.ErrorStr("Invalid label ID"))
+		return dbclient.TicketLabel{}, false
+	}
+
+	label, ok, err := dbclient.Dashboard.TicketLabels.Get(ctx, guildId, labelId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return dbclient.TicketLabel{}, false
+	}
+
+	if !ok {
+		ctx.JSON(404, utils.ErrorStr("Label not found"))
+		return dbclient.TicketLabel{}, false
+	}
+
+	return label, true
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+)
+
+func UpdateLabelHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	body, ok := bindLabelBody(ctx)
+	if !ok {
+		return
+	}
+
+	label, ok := getLabel(ctx, guildId)
+	if !ok {
+		return
+	}
+
+	audit.SetBefore(ctx, types.NewTicketLabel(label))
+
+	label.Name = body.Name
+	label.Colour = body.Colour.Uint32()
+
+	updated, err := dbclient.Dashboard.TicketLabels.Update(ctx, label)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if !updated {
+		ctx.JSON(409, utils.ErrorStr("A label with this name already exists"))
+		return
+	}
+
+	res := types.NewTicketLabel(label)
+	audit.SetAfter(ctx, res)
+
+	ctx.JSON(200, res)
+}
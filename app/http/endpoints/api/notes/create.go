@@ -0,0 +1,49 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+)
+
+func CreateNoteHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+	userId := ctx.Keys["userid"].(uint64)
+
+	var body noteBody
+	if err := ctx.BindJSON(&body); err != nil {
+		ctx.JSON(400, utils.ErrorJson(err))
+		return
+	}
+
+	if message, ok := body.validate(); !ok {
+		ctx.JSON(400, utils.ErrorStr(message))
+		return
+	}
+
+	ticket, ok := getNoteTicket(ctx, guildId, userId)
+	if !ok {
+		return
+	}
+
+	note, ok, err := dbclient.Dashboard.TicketNotes.Create(ctx, dbclient.TicketNote{
+		GuildId:  guildId,
+		TicketId: ticket.Id,
+		AuthorId: userId,
+		Content:  body.Content,
+	}, maxNotesPerTicket)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if !ok {
+		ctx.JSON(400, utils.ErrorStr("Tickets cannot have more than %d notes", maxNotesPerTicket))
+		return
+	}
+
+	audit.SetAfter(ctx, note)
+
+	ctx.JSON(200, note)
+}
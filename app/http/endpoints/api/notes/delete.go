@@ -0,0 +1,47 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Utilities/permission"
+)
+
+// DeleteNoteHandler deletes a note. Notes can be deleted by their author, or by an admin.
+func DeleteNoteHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+	userId := ctx.Keys["userid"].(uint64)
+
+	ticket, ok := getNoteTicket(ctx, guildId, userId)
+	if !ok {
+		return
+	}
+
+	note, ok := getNote(ctx, ticket)
+	if !ok {
+		return
+	}
+
+	if note.AuthorId != userId {
+		permissionLevel, err := utils.GetPermissionLevel(ctx, guildId, userId)
+		if err != nil {
+			ctx.JSON(500, utils.ErrorJson(err))
+			return
+		}
+
+		if permissionLevel < permission.Admin {
+			ctx.JSON(403, utils.ErrorStr("You can only delete your own notes"))
+			return
+		}
+	}
+
+	audit.SetBefore(ctx, note)
+
+	if err := dbclient.Dashboard.TicketNotes.Delete(ctx, guildId, ticket.Id, note.Id); err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	ctx.JSON(200, utils.SuccessResponse)
+}
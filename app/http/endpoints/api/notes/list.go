@@ -0,0 +1,47 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/rpc/cache"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/rxdn/gdl/objects/user"
+)
+
+type listResponse struct {
+	Notes         []dbclient.TicketNote `json:"notes"`
+	ResolvedUsers map[uint64]user.User  `json:"resolved_users"`
+}
+
+// ListNotesHandler returns the notes on a ticket. It is used for both open tickets and transcripts.
+func ListNotesHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+	userId := ctx.Keys["userid"].(uint64)
+
+	ticket, ok := getNoteTicket(ctx, guildId, userId)
+	if !ok {
+		return
+	}
+
+	notes, err := dbclient.Dashboard.TicketNotes.GetByTicket(ctx, guildId, ticket.Id)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	authorIds := make([]uint64, len(notes))
+	for i, note := range notes {
+		authorIds[i] = note.AuthorId
+	}
+
+	users, err := cache.Instance.GetUsers(ctx, utils.Unique(authorIds))
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	ctx.JSON(200, listResponse{
+		Notes:         notes,
+		ResolvedUsers: users,
+	})
+}
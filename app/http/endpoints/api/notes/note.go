@@ -0,0 +1,89 @@
+package api
+
+import (
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+)
+
+const (
+	maxNotesPerTicket = 50
+	maxNoteLength     = 2000
+)
+
+type noteBody struct {
+	Content string `json:"content"`
+}
+
+func (b *noteBody) validate() (string, bool) {
+	if len(b.Content) == 0 {
+		return "Notes cannot be empty", false
+	}
+
+	// Count characters rather than bytes, to match the limit of the VARCHAR column
+	if utf8.RuneCountInString(b.Content) > maxNoteLength {
+		return "Notes cannot be longer than 2000 characters", false
+	}
+
+	return "", true
+}
+
+// getNoteTicket loads the ticket from the path, which may be open or closed, and checks that the user can view it.
+// The route requires the support permission level, so the opener cannot reach here unless they are also staff.
+func getNoteTicket(ctx *gin.Context, guildId, userId uint64) (database.Ticket, bool) {
+	ticketId, err := strconv.Atoi(ctx.Param("ticketId"))
+	if err != nil {
+		ctx.JSON(400, utils.ErrorStr("Invalid ticket ID"))
+		return database.Ticket{}, false
+	}
+
+	ticket, err := dbclient.Client.Tickets.Get(ctx, ticketId, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return database.Ticket{}, false
+	}
+
+	if ticket.UserId == 0 || ticket.GuildId != guildId {
+		ctx.JSON(404, utils.ErrorStr("Ticket not found"))
+		return database.Ticket{}, false
+	}
+
+	hasPermission, requestErr := utils.HasPermissionToViewTicket(ctx, guildId, userId, ticket)
+	if requestErr != nil {
+		ctx.JSON(requestErr.StatusCode, utils.ErrorJson(requestErr))
+		return database.Ticket{}, false
+	}
+
+	if !hasPermission {
+		ctx.JSON(403, utils.ErrorStr("You do not have permission to view this ticket"))
+		return database.Ticket{}, false
+	}
+
+	return ticket, true
+}
+
+// getNote loads the note from the path, writing an error response and returning false if it cannot be used
+func getNote(ctx *gin.Context, ticket database.Ticket) (dbclient.TicketNote, bool) {
+	noteId, err := strconv.ParseInt(ctx.Param("noteid"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, utils.ErrorStr("Invalid note ID"))
+		return dbclient.TicketNote{}, false
+	}
+
+	note, ok, err := dbclient.Dashboard.TicketNotes.Get(ctx, ticket.GuildId, ticket.Id, noteId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return dbclient.TicketNote{}, false
+	}
+
+	if !ok {
+		ctx.JSON(404, utils.ErrorStr("Note not found"))
+		return dbclient.TicketNote{}, false
+	}
+
+	return note, true
+}
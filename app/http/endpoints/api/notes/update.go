@@ -0,0 +1,52 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+)
+
+// UpdateNoteHandler edits a note. Only the author of a note can edit it.
+func UpdateNoteHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+	userId := ctx.Keys["userid"].(uint64)
+
+	var body noteBody
+	if err := ctx.BindJSON(&body); err != nil {
+		ctx.JSON(400, utils.ErrorJson(err))
+		return
+	}
+
+	if message, ok := body.validate(); !ok {
+		ctx.JSON(400, utils.ErrorStr(message))
+		return
+	}
+
+	ticket, ok := getNoteTicket(ctx, guildId, userId)
+	if !ok {
+		return
+	}
+
+	note, ok := getNote(ctx, ticket)
+	if !ok {
+		return
+	}
+
+	if note.AuthorId != userId {
+		ctx.JSON(403, utils.ErrorStr("You can only edit your own notes"))
+		return
+	}
+
+	audit.SetBefore(ctx, note)
+
+	updated, err := dbclient.Dashboard.TicketNotes.Update(ctx, guildId, ticket.Id, note.Id, body.Content)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	audit.SetAfter(ctx, updated)
+
+	ctx.JSON(200, updated)
+}
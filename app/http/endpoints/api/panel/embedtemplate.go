@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+)
+
+// ApplyWelcomeMessageTemplate replaces the welcome message of the panel with an edited embed template, returning false
+// if the panel no longer exists. The welcome message is only sent when a ticket is opened, so the panel message does
+// not need to be resent.
+func ApplyWelcomeMessageTemplate(ctx context.Context, guildId uint64, panelId int, embed *types.CustomEmbed) (bool, error) {
+	panel, err := dbclient.Client.Panel.GetById(ctx, panelId)
+	if err != nil {
+		return false, err
+	}
+
+	if panel.PanelId == 0 || panel.GuildId != guildId {
+		return false, nil
+	}
+
+	dbEmbed, fields := embed.IntoDatabaseStruct()
+	dbEmbed.GuildId = guildId
+
+	if panel.WelcomeMessageEmbed != nil {
+		dbEmbed.Id = *panel.WelcomeMessageEmbed
+		return true, dbclient.Client.Embeds.UpdateWithFields(ctx, dbEmbed, fields)
+	}
+
+	id, err := dbclient.Client.Embeds.CreateWithFields(ctx, dbEmbed, fields)
+	if err != nil {
+		return false, err
+	}
+
+	panel.WelcomeMessageEmbed = &id
+	return true, dbclient.Client.Panel.Update(ctx, panel)
+}
+
+// ApplyMultiPanelTemplate replaces the embed of the multi-panel with an edited embed template, returning false if the
+// multi-panel no longer exists. If resend is false, the change is only visible once the multi-panel is next sent.
+func ApplyMultiPanelTemplate(
+	ctx context.Context,
+	botContext *botcontext.BotContext,
+	guildId uint64,
+	multiPanelId int,
+	embed *types.CustomEmbed,
+	resend bool,
+) (bool, error) {
+	multiPanel, ok, err := dbclient.Client.MultiPanels.Get(ctx, multiPanelId)
+	if err != nil {
+		return false, err
+	}
+
+	if !ok || multiPanel.GuildId != guildId {
+		return false, nil
+	}
+
+	dbEmbed, fields := embed.IntoDatabaseStruct()
+	multiPanel.Embed = &database.CustomEmbedWithFields{
+		CustomEmbed: dbEmbed,
+		Fields:      fields,
+	}
+
+	if err := dbclient.Client.MultiPanels.Update(ctx, multiPanel.Id, multiPanel); err != nil {
+		return false, err
+	}
+
+	if resend {
+		if err := ResendMultiPanel(ctx, botContext, multiPanel); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/dryrun"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/embedtemplates"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/rpc/cache"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
@@ -25,6 +28,7 @@ type multiPanelCreateData struct {
 	SelectMenuPlaceholder *string            `json:"select_menu_placeholder,omitempty" validate:"omitempty,max=150"`
 	Panels                []int              `json:"panels"`
 	Embed                 *types.CustomEmbed `json:"embed" validate:"omitempty,dive"`
+	EmbedTemplateId       *int               `json:"embed_template_id"`
 }
 
 func (d *multiPanelCreateData) IntoMessageData() multiPanelMessageData {
@@ -45,22 +49,26 @@ func MultiPanelCreate(c *gin.Context) {
 		return
 	}
 
-	if err := validate.Struct(data); err != nil {
-		var validationErrors validator.ValidationErrors
-		if ok := errors.As(err, &validationErrors); !ok {
-			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "An error occurred while validating the panel"))
-			return
+	if err := data.resolveTemplate(c, guildId); err != nil {
+		if !validation.RespondError(c, err) {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
 		}
 
-		formatted := "Your input contained the following errors:\n" + utils.FormatValidationErrors(validationErrors)
-		c.JSON(400, utils.ErrorStr(formatted))
 		return
 	}
 
 	// validate body & get sub-panels
 	panels, err := data.doValidations(guildId)
 	if err != nil {
-		c.JSON(400, utils.ErrorJson(err))
+		if !validation.RespondError(c, err) {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		}
+
+		return
+	}
+
+	if dryrun.Requested(c) {
+		dryrun.Respond(c, nil, data)
 		return
 	}
 
@@ -117,62 +125,75 @@ func MultiPanelCreate(c *gin.Context) {
 		return
 	}
 
+	if data.EmbedTemplateId != nil {
+		if err := dbclient.Dashboard.EmbedTemplateReferences.Set(c, guildId, dbclient.EmbedTemplateTargetMultiPanel, strconv.Itoa(multiPanel.Id), data.EmbedTemplateId); err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+			return
+		}
+	}
+
 	c.JSON(200, gin.H{
 		"success": true,
 		"data":    multiPanel,
 	})
 }
 
-func (d *multiPanelCreateData) doValidations(guildId uint64) (panels []database.Panel, err error) {
-	if err := validateEmbed(d.Embed); err != nil {
-		return nil, err
-	}
+// resolveTemplate replaces the embed with the embed template that the multi-panel references, if any. The template
+// takes precedence over an embed submitted alongside it.
+func (d *multiPanelCreateData) resolveTemplate(ctx context.Context, guildId uint64) error {
+	return embedtemplates.Resolve(ctx, guildId, d.EmbedTemplateId, &d.Embed, "/embed_template_id")
+}
 
+// doValidations validates the body against the guild, collecting every invalid field, and returns the sub-panels
+func (d *multiPanelCreateData) doValidations(guildId uint64) (panels []database.Panel, err error) {
 	group, _ := errgroup.WithContext(context.Background())
 
-	group.Go(d.validateChannel(guildId))
-	group.Go(func() (e error) {
-		panels, e = d.validatePanels(guildId)
-		return
+	var channelErr, panelsErr error
+	group.Go(func() error {
+		channelErr = d.validateChannel(guildId)
+		return nil
+	})
+
+	group.Go(func() error {
+		panels, panelsErr = d.validatePanels(guildId)
+		return nil
 	})
 
-	err = group.Wait()
+	_ = group.Wait()
+
+	err = validation.Join(
+		validation.Struct(validate, d),
+		validation.WithPrefix(ValidateEmbed(d.Embed), "/embed"),
+		channelErr,
+		panelsErr,
+	)
+
 	return
 }
 
-func (d *multiPanelCreateData) validateChannel(guildId uint64) func() error {
-	return func() error {
-		// TODO: Use proper context
-		channels, err := cache.Instance.GetGuildChannels(context.Background(), guildId)
-		if err != nil {
-			return err
-		}
-
-		var valid bool
-		for _, ch := range channels {
-			if ch.Id == d.ChannelId && (ch.Type == channel.ChannelTypeGuildText || ch.Type == channel.ChannelTypeGuildNews) {
-				valid = true
-				break
-			}
-		}
+func (d *multiPanelCreateData) validateChannel(guildId uint64) error {
+	// TODO: Use proper context
+	channels, err := cache.Instance.GetGuildChannels(context.Background(), guildId)
+	if err != nil {
+		return err
+	}
 
-		if !valid {
-			return errors.New("channel does not exist")
+	for _, ch := range channels {
+		if ch.Id == d.ChannelId && (ch.Type == channel.ChannelTypeGuildText || ch.Type == channel.ChannelTypeGuildNews) {
+			return nil
 		}
-
-		return nil
 	}
+
+	return validation.NewFieldError("/channel_id", validation.CodeNotFound, "Channel does not exist")
 }
 
-func (d *multiPanelCreateData) validatePanels(guildId uint64) (panels []database.Panel, err error) {
+func (d *multiPanelCreateData) validatePanels(guildId uint64) ([]database.Panel, error) {
 	if len(d.Panels) < 2 {
-		err = errors.New("a multi-panel must contain at least 2 sub-panels")
-		return
+		return nil, validation.NewFieldError("/panels", validation.CodeTooShort, "A multi-panel must contain at least 2 sub-panels")
 	}
 
 	if len(d.Panels) > 15 {
-		err = errors.New("multi-panels cannot contain more than 15 sub-panels")
-		return
+		return nil, validation.NewFieldError("/panels", validation.CodeTooLong, "Multi-panels cannot contain more than 15 sub-panels")
 	}
 
 	existingPanels, err := dbclient.Client.Panel.GetByGuild(context.Background(), guildId)
@@ -180,7 +201,9 @@ func (d *multiPanelCreateData) validatePanels(guildId uint64) (panels []database
 		return nil, err
 	}
 
-	for _, panelId := range d.Panels {
+	var panels []database.Panel
+	var errs []error
+	for i, panelId := range d.Panels {
 		var valid bool
 		// find panel struct
 		for _, panel := range existingPanels {
@@ -191,9 +214,13 @@ func (d *multiPanelCreateData) validatePanels(guildId uint64) (panels []database
 		}
 
 		if !valid {
-			return nil, errors.New("invalid panel ID")
+			errs = append(errs, validation.NewFieldError(validation.IndexPointer("/panels", i), validation.CodeNotFound, "Invalid panel ID"))
 		}
 	}
 
-	return
+	if err := validation.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return panels, nil
 }
@@ -7,6 +7,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/dryrun"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
@@ -31,6 +33,11 @@ func MultiPanelDelete(c *gin.Context) {
 	}
 
 	panel, ok, err := dbclient.Client.MultiPanels.Get(c, multiPanelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
 	if !ok {
 		c.JSON(404, utils.ErrorStr("No panel with matching ID found"))
 		return
@@ -41,6 +48,13 @@ func MultiPanelDelete(c *gin.Context) {
 		return
 	}
 
+	if dryrun.Requested(c) {
+		dryrun.Respond(c, panel, nil)
+		return
+	}
+
+	audit.SetBefore(c, panel)
+
 	// TODO: Use proper context
 	if err := rest.DeleteMessage(c, botContext.Token, botContext.RateLimiter, panel.ChannelId, panel.MessageId); err != nil {
 		var unwrapped request.RestError
@@ -67,5 +81,15 @@ func MultiPanelDelete(c *gin.Context) {
 		return
 	}
 
+	if err := dbclient.Dashboard.EmbedTemplateReferences.Delete(c, guildId, dbclient.EmbedTemplateTargetMultiPanel, strconv.Itoa(multiPanelId)); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	if err := dbclient.Dashboard.PanelHealth.Delete(c, dbclient.PanelKindMultiPanel, multiPanelId); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
 	c.JSON(200, utils.SuccessResponse)
 }
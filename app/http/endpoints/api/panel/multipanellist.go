@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
@@ -13,7 +14,9 @@ import (
 func MultiPanelList(ctx *gin.Context) {
 	type multiPanelResponse struct {
 		database.MultiPanel
-		Panels []int `json:"panels"`
+		Panels          []int                `json:"panels"`
+		EmbedTemplateId *int                 `json:"embed_template_id"`
+		Health          *panelHealthResponse `json:"health"`
 	}
 
 	guildId := ctx.Keys["guildid"].(uint64)
@@ -24,6 +27,18 @@ func MultiPanelList(ctx *gin.Context) {
 		return
 	}
 
+	templateIds, err := dbclient.Dashboard.EmbedTemplateReferences.GetByKind(ctx, guildId, dbclient.EmbedTemplateTargetMultiPanel)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	health, err := dbclient.Dashboard.PanelHealth.GetByGuild(ctx, guildId, dbclient.PanelKindMultiPanel)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
 	data := make([]multiPanelResponse, len(multiPanels))
 	group, _ := errgroup.WithContext(context.Background())
 	for i, multiPanel := range multiPanels {
@@ -32,6 +47,11 @@ func MultiPanelList(ctx *gin.Context) {
 
 		data[i] = multiPanelResponse{
 			MultiPanel: multiPanel,
+			Health:     newPanelHealthResponse(health, multiPanel.Id),
+		}
+
+		if templateId, ok := templateIds[strconv.Itoa(multiPanel.Id)]; ok {
+			data[i].EmbedTemplateId = &templateId
 		}
 
 		// TODO: Use a join
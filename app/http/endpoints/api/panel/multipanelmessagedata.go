@@ -32,6 +32,12 @@ func multiPanelIntoMessageData(panel database.MultiPanel) multiPanelMessageData
 	}
 }
 
+// SendMultiPanelMessage sends the message for an already stored multi-panel, returning the new message ID
+func SendMultiPanelMessage(ctx *botcontext.BotContext, multiPanel database.MultiPanel, panels []database.Panel) (uint64, error) {
+	messageData := multiPanelIntoMessageData(multiPanel)
+	return messageData.send(ctx, panels)
+}
+
 func (d *multiPanelMessageData) send(ctx *botcontext.BotContext, panels []database.Panel) (uint64, error) {
 	d.Embed.SetFooter("Tickets by jaDevelopment", "https://avatars.githubusercontent.com/u/142818403")
 
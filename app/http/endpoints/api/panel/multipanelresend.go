@@ -6,9 +6,11 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
 	"github.com/rxdn/gdl/rest"
 	"github.com/rxdn/gdl/rest/request"
 )
@@ -49,42 +51,51 @@ func MultiPanelResend(ctx *gin.Context) {
 		return
 	}
 
-	// delete old message
+	audit.SetBefore(ctx, multiPanel)
+
 	// TODO: Use proper context
-	if err := rest.DeleteMessage(context.Background(), botContext.Token, botContext.RateLimiter, multiPanel.ChannelId, multiPanel.MessageId); err != nil {
+	if err := ResendMultiPanel(context.Background(), botContext, multiPanel); err != nil {
 		var unwrapped request.RestError
-		if errors.As(err, &unwrapped) && !unwrapped.IsClientError() {
+		if errors.As(err, &unwrapped) && unwrapped.StatusCode == 403 {
+			ctx.JSON(500, utils.ErrorJson(errors.New("I do not have permission to send messages in the provided channel")))
+		} else {
 			ctx.JSON(500, utils.ErrorJson(err))
-			return
+		}
+
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"success": true,
+	})
+}
+
+// ResendMultiPanel deletes the multi-panel message and sends it again from the stored multi-panel, updating the stored
+// message ID and marking the multi-panel as healthy
+func ResendMultiPanel(ctx context.Context, botContext *botcontext.BotContext, multiPanel database.MultiPanel) error {
+	// delete old message
+	if err := rest.DeleteMessage(ctx, botContext.Token, botContext.RateLimiter, multiPanel.ChannelId, multiPanel.MessageId); err != nil {
+		var unwrapped request.RestError
+		if errors.As(err, &unwrapped) && !unwrapped.IsClientError() {
+			return err
 		}
 	}
 
 	panels, err := dbclient.Client.MultiPanelTargets.GetPanels(ctx, multiPanel.Id)
 	if err != nil {
-		ctx.JSON(500, utils.ErrorJson(err))
-		return
+		return err
 	}
 
 	// send new message
 	messageData := multiPanelIntoMessageData(multiPanel)
 	messageId, err := messageData.send(botContext, panels)
 	if err != nil {
-		var unwrapped request.RestError
-		if errors.As(err, &unwrapped) && unwrapped.StatusCode == 403 {
-			ctx.JSON(500, utils.ErrorJson(errors.New("I do not have permission to send messages in the provided channel")))
-		} else {
-			ctx.JSON(500, utils.ErrorJson(err))
-		}
-
-		return
+		return err
 	}
 
-	if err = dbclient.Client.MultiPanels.UpdateMessageId(ctx, multiPanel.Id, messageId); err != nil {
-		ctx.JSON(500, utils.ErrorJson(err))
-		return
+	if err := dbclient.Client.MultiPanels.UpdateMessageId(ctx, multiPanel.Id, messageId); err != nil {
+		return err
 	}
 
-	ctx.JSON(200, gin.H{
-		"success": true,
-	})
+	return markHealthy(ctx, dbclient.PanelKindMultiPanel, multiPanel.Id, multiPanel.GuildId)
 }
@@ -7,11 +7,14 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/dryrun"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
 	database "github.com/jadevelopmentgrp/Tickets-Database"
 	"github.com/rxdn/gdl/rest"
 	"github.com/rxdn/gdl/rest/request"
@@ -54,25 +57,37 @@ func MultiPanelUpdate(c *gin.Context) {
 		return
 	}
 
-	if err := validate.Struct(data); err != nil {
-		var validationErrors validator.ValidationErrors
-		if ok := errors.As(err, &validationErrors); !ok {
-			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "An error occurred while validating the panel"))
-			return
+	if err := data.resolveTemplate(c, guildId); err != nil {
+		if !validation.RespondError(c, err) {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
 		}
 
-		formatted := "Your input contained the following errors:\n" + utils.FormatValidationErrors(validationErrors)
-		c.JSON(400, utils.ErrorStr(formatted))
 		return
 	}
 
 	// validate body & get sub-panels
 	panels, err := data.doValidations(guildId)
 	if err != nil {
-		c.JSON(400, utils.ErrorJson(err))
+		if !validation.RespondError(c, err) {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		}
+
+		return
+	}
+
+	before, err := multiPanelIntoCreateData(c, multiPanel)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	if dryrun.Requested(c) {
+		dryrun.Respond(c, before, data)
 		return
 	}
 
+	audit.SetBefore(c, before)
+
 	for _, panel := range panels {
 		if panel.CustomId == "" {
 			panel.CustomId, err = utils.RandString(30)
@@ -164,8 +179,46 @@ func MultiPanelUpdate(c *gin.Context) {
 		return
 	}
 
+	if err := markHealthy(c, dbclient.PanelKindMultiPanel, multiPanel.Id, guildId); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	// Editing the embed directly detaches the multi-panel from its template
+	if err := dbclient.Dashboard.EmbedTemplateReferences.Set(c, guildId, dbclient.EmbedTemplateTargetMultiPanel, strconv.Itoa(multiPanel.Id), data.EmbedTemplateId); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
 	c.JSON(200, gin.H{
 		"success": true,
 		"data":    multiPanel,
 	})
 }
+
+// multiPanelIntoCreateData converts a stored multi-panel into the shape that it is submitted in
+func multiPanelIntoCreateData(ctx context.Context, multiPanel database.MultiPanel) (multiPanelCreateData, error) {
+	panels, err := dbclient.Client.MultiPanelTargets.GetPanels(ctx, multiPanel.Id)
+	if err != nil {
+		return multiPanelCreateData{}, err
+	}
+
+	var embed *types.CustomEmbed
+	if multiPanel.Embed != nil {
+		embed = types.NewCustomEmbed(multiPanel.Embed.CustomEmbed, multiPanel.Embed.Fields)
+	}
+
+	embedTemplateId, err := dbclient.Dashboard.EmbedTemplateReferences.Get(ctx, multiPanel.GuildId, dbclient.EmbedTemplateTargetMultiPanel, strconv.Itoa(multiPanel.Id))
+	if err != nil {
+		return multiPanelCreateData{}, err
+	}
+
+	return multiPanelCreateData{
+		ChannelId:             multiPanel.ChannelId,
+		SelectMenu:            multiPanel.SelectMenu,
+		SelectMenuPlaceholder: multiPanel.SelectMenuPlaceholder,
+		Panels:                utils.Map(panels, func(panel database.Panel) int { return panel.PanelId }),
+		Embed:                 embed,
+		EmbedTemplateId:       embedTemplateId,
+	}, nil
+}
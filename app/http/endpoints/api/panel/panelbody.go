@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"strconv"
+
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+	"github.com/rxdn/gdl/objects/interaction/component"
+)
+
+// GetPanelBody loads a stored panel in the same shape that it is submitted in, so that it can be compared with, or
+// used as the base for, an update
+func GetPanelBody(ctx context.Context, panel database.Panel) (PanelBody, error) {
+	var mentions []string
+
+	shouldMention, err := dbclient.Client.PanelUserMention.ShouldMentionUser(ctx, panel.PanelId)
+	if err != nil {
+		return PanelBody{}, err
+	}
+
+	if shouldMention {
+		mentions = append(mentions, "user")
+	}
+
+	roles, err := dbclient.Client.PanelRoleMentions.GetRoles(ctx, panel.PanelId)
+	if err != nil {
+		return PanelBody{}, err
+	}
+
+	for _, roleId := range roles {
+		mentions = append(mentions, strconv.FormatUint(roleId, 10))
+	}
+
+	teamIds, err := dbclient.Client.PanelTeams.GetTeamIds(ctx, panel.PanelId)
+	if err != nil {
+		return PanelBody{}, err
+	}
+
+	accessControlList, err := dbclient.Client.PanelAccessControlRules.GetAll(ctx, panel.PanelId)
+	if err != nil {
+		return PanelBody{}, err
+	}
+
+	var welcomeMessage *types.CustomEmbed
+	if panel.WelcomeMessageEmbed != nil {
+		embed, ok, err := dbclient.Client.Embeds.Get(ctx, *panel.WelcomeMessageEmbed)
+		if err != nil {
+			return PanelBody{}, err
+		}
+
+		if ok {
+			fields, err := dbclient.Client.EmbedFields.GetFieldsForEmbed(ctx, embed.Id)
+			if err != nil {
+				return PanelBody{}, err
+			}
+
+			welcomeMessage = types.NewCustomEmbed(&embed, fields)
+		}
+	}
+
+	welcomeMessageTemplateId, err := dbclient.Dashboard.EmbedTemplateReferences.Get(ctx, panel.GuildId, dbclient.EmbedTemplateTargetPanel, strconv.Itoa(panel.PanelId))
+	if err != nil {
+		return PanelBody{}, err
+	}
+
+	return PanelBody{
+		ChannelId:                panel.ChannelId,
+		MessageId:                panel.MessageId,
+		Title:                    panel.Title,
+		Content:                  panel.Content,
+		Colour:                   uint32(panel.Colour),
+		CategoryId:               panel.TargetCategory,
+		Emoji:                    types.NewEmoji(panel.EmojiName, panel.EmojiId),
+		WelcomeMessage:           welcomeMessage,
+		WelcomeMessageTemplateId: welcomeMessageTemplateId,
+		Mentions:                 mentions,
+		WithDefaultTeam:          panel.WithDefaultTeam,
+		Teams:                    teamIds,
+		ImageUrl:                 panel.ImageUrl,
+		ThumbnailUrl:             panel.ThumbnailUrl,
+		ButtonStyle:              component.ButtonStyle(panel.ButtonStyle),
+		ButtonLabel:              panel.ButtonLabel,
+		FormId:                   panel.FormId,
+		NamingScheme:             panel.NamingScheme,
+		Disabled:                 panel.Disabled,
+		ExitSurveyFormId:         panel.ExitSurveyFormId,
+		AccessControlList:        accessControlList,
+		PendingCategory:          panel.PendingCategory,
+	}, nil
+}
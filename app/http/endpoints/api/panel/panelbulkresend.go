@@ -0,0 +1,237 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/dryrun"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/middleware"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+	"github.com/rxdn/gdl/rest/request"
+)
+
+const (
+	// maxBulkResends is the number of messages resent per request. Resends take from ResendRateLimit, so this bounds
+	// the duration of the request; the remaining panels are resent by calling the endpoint again.
+	maxBulkResends = 10
+
+	// bulkCheckInterval paces the message fetches made when checking every panel in the guild
+	bulkCheckInterval = 100 * time.Millisecond
+)
+
+type (
+	bulkResendTarget struct {
+		Kind    dbclient.PanelKind         `json:"kind"`
+		PanelId int                        `json:"panel_id"`
+		Status  dbclient.PanelHealthStatus `json:"status"`
+		Error   *string                    `json:"error,omitempty"`
+
+		panel      database.Panel
+		multiPanel database.MultiPanel
+	}
+
+	bulkResendResponse struct {
+		Resent []bulkResendTarget `json:"resent"`
+		// Failed contains panels that could not be resent, including those whose channel has been deleted
+		Failed    []bulkResendTarget `json:"failed"`
+		Remaining int                `json:"remaining"`
+	}
+)
+
+// BulkResendPanels checks the message of every panel and multi-panel in the guild, and resends those that are missing
+func BulkResendPanels(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	botContext, err := botcontext.ContextForGuild(guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	targets, err := getUnhealthyPanels(c, botContext, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	res := bulkResendResponse{
+		Resent: make([]bulkResendTarget, 0),
+		Failed: make([]bulkResendTarget, 0),
+	}
+
+	var toResend []bulkResendTarget
+	for _, target := range targets {
+		if target.Error != nil {
+			res.Failed = append(res.Failed, target)
+			continue
+		}
+
+		switch target.Status {
+		case dbclient.PanelHealthStatusChannelMissing:
+			target.Error = utils.Ptr("The panel channel has been deleted: choose a new channel for the panel")
+			res.Failed = append(res.Failed, target)
+		case dbclient.PanelHealthStatusForbidden:
+			target.Error = utils.Ptr("I do not have permission to view the panel channel")
+			res.Failed = append(res.Failed, target)
+		default:
+			if len(toResend) < maxBulkResends {
+				toResend = append(toResend, target)
+			} else {
+				res.Remaining++
+			}
+		}
+	}
+
+	if dryrun.Requested(c) {
+		res.Resent = append(res.Resent, toResend...)
+		dryrun.Respond(c, nil, res)
+		return
+	}
+
+	for _, target := range toResend {
+		if err := waitForResend(c.Request.Context(), guildId); err != nil {
+			if c.Request.Context().Err() == nil {
+				_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+			}
+
+			return
+		}
+
+		if err := target.resend(c, botContext); err != nil {
+			var unwrapped request.RestError
+			if !errors.As(err, &unwrapped) {
+				_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+				return
+			}
+
+			if unwrapped.StatusCode == http.StatusForbidden {
+				target.Error = utils.Ptr("I do not have permission to send messages in the panel channel")
+			} else {
+				target.Error = utils.Ptr("Error sending panel message: " + unwrapped.ApiError.Message)
+			}
+
+			res.Failed = append(res.Failed, target)
+			continue
+		}
+
+		res.Resent = append(res.Resent, target)
+	}
+
+	audit.SetAfter(c, res)
+
+	c.JSON(200, res)
+}
+
+// waitForResend takes a token from the guild's ResendRateLimit bucket, waiting until one is available, so that bulk
+// resends and individual resends share the same rate
+func waitForResend(ctx context.Context, guildId uint64) error {
+	for {
+		res, err := middleware.AllowGuild(ctx, guildId, ResendRateLimit)
+		if err != nil {
+			return err
+		}
+
+		if res.Allowed > 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(res.RetryAfter):
+		}
+	}
+}
+
+// getUnhealthyPanels checks the message of each panel and multi-panel in the guild, recording the result, and returns
+// those that are not healthy. If Discord returns an error while checking a panel, it is returned with Error set and
+// its previous result is kept.
+func getUnhealthyPanels(ctx context.Context, botContext *botcontext.BotContext, guildId uint64) ([]bulkResendTarget, error) {
+	panels, err := dbclient.Client.Panel.GetByGuild(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	multiPanels, err := dbclient.Client.MultiPanels.GetByGuild(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]bulkResendTarget, 0, len(panels)+len(multiPanels))
+	for _, panel := range panels {
+		messages = append(messages, bulkResendTarget{Kind: dbclient.PanelKindPanel, PanelId: panel.PanelId, panel: panel})
+	}
+
+	for _, multiPanel := range multiPanels {
+		messages = append(messages, bulkResendTarget{Kind: dbclient.PanelKindMultiPanel, PanelId: multiPanel.Id, multiPanel: multiPanel})
+	}
+
+	ticker := time.NewTicker(bulkCheckInterval)
+	defer ticker.Stop()
+
+	var unhealthy []bulkResendTarget
+	for i, target := range messages {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-ticker.C:
+			}
+		}
+
+		channelId, messageId := target.message()
+
+		status, err := checkPanelMessage(ctx, botContext, channelId, messageId)
+		if err != nil {
+			var unwrapped request.RestError
+			if !errors.As(err, &unwrapped) {
+				return nil, err
+			}
+
+			target.Error = utils.Ptr("Discord returned an error while checking the panel message: try again later")
+			unhealthy = append(unhealthy, target)
+			continue
+		}
+
+		if err := dbclient.Dashboard.PanelHealth.Set(ctx, dbclient.PanelHealth{
+			Kind:      target.Kind,
+			PanelId:   target.PanelId,
+			GuildId:   guildId,
+			Status:    status,
+			CheckedAt: time.Now(),
+		}); err != nil {
+			return nil, err
+		}
+
+		if status != dbclient.PanelHealthStatusOk {
+			target.Status = status
+			unhealthy = append(unhealthy, target)
+		}
+	}
+
+	return unhealthy, nil
+}
+
+func (t *bulkResendTarget) message() (channelId, messageId uint64) {
+	if t.Kind == dbclient.PanelKindMultiPanel {
+		return t.multiPanel.ChannelId, t.multiPanel.MessageId
+	}
+
+	return t.panel.ChannelId, t.panel.MessageId
+}
+
+func (t *bulkResendTarget) resend(ctx context.Context, botContext *botcontext.BotContext) error {
+	if t.Kind == dbclient.PanelKindMultiPanel {
+		return ResendMultiPanel(ctx, botContext, t.multiPanel)
+	}
+
+	return ResendPanelMessage(ctx, botContext, t.panel)
+}
@@ -10,9 +10,12 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v4"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/dryrun"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/embedtemplates"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
 	database "github.com/jadevelopmentgrp/Tickets-Database"
@@ -23,31 +26,32 @@ import (
 
 const freePanelLimit = 3
 
-type panelBody struct {
-	ChannelId         uint64                            `json:"channel_id,string"`
-	MessageId         uint64                            `json:"message_id,string"`
-	Title             string                            `json:"title"`
-	Content           string                            `json:"content"`
-	Colour            uint32                            `json:"colour"`
-	CategoryId        uint64                            `json:"category_id,string"`
-	Emoji             types.Emoji                       `json:"emote"`
-	WelcomeMessage    *types.CustomEmbed                `json:"welcome_message" validate:"omitempty,dive"`
-	Mentions          []string                          `json:"mentions"`
-	WithDefaultTeam   bool                              `json:"default_team"`
-	Teams             []int                             `json:"teams"`
-	ImageUrl          *string                           `json:"image_url,omitempty"`
-	ThumbnailUrl      *string                           `json:"thumbnail_url,omitempty"`
-	ButtonStyle       component.ButtonStyle             `json:"button_style,string"`
-	ButtonLabel       string                            `json:"button_label"`
-	FormId            *int                              `json:"form_id"`
-	NamingScheme      *string                           `json:"naming_scheme"`
-	Disabled          bool                              `json:"disabled"`
-	ExitSurveyFormId  *int                              `json:"exit_survey_form_id"`
-	AccessControlList []database.PanelAccessControlRule `json:"access_control_list"`
-	PendingCategory   *uint64                           `json:"pending_category,string"`
+type PanelBody struct {
+	ChannelId                uint64                            `json:"channel_id,string"`
+	MessageId                uint64                            `json:"message_id,string"`
+	Title                    string                            `json:"title"`
+	Content                  string                            `json:"content"`
+	Colour                   uint32                            `json:"colour"`
+	CategoryId               uint64                            `json:"category_id,string"`
+	Emoji                    types.Emoji                       `json:"emote"`
+	WelcomeMessage           *types.CustomEmbed                `json:"welcome_message" validate:"omitempty,dive"`
+	WelcomeMessageTemplateId *int                              `json:"welcome_message_template_id"`
+	Mentions                 []string                          `json:"mentions"`
+	WithDefaultTeam          bool                              `json:"default_team"`
+	Teams                    []int                             `json:"teams"`
+	ImageUrl                 *string                           `json:"image_url,omitempty"`
+	ThumbnailUrl             *string                           `json:"thumbnail_url,omitempty"`
+	ButtonStyle              component.ButtonStyle             `json:"button_style,string"`
+	ButtonLabel              string                            `json:"button_label"`
+	FormId                   *int                              `json:"form_id"`
+	NamingScheme             *string                           `json:"naming_scheme"`
+	Disabled                 bool                              `json:"disabled"`
+	ExitSurveyFormId         *int                              `json:"exit_survey_form_id"`
+	AccessControlList        []database.PanelAccessControlRule `json:"access_control_list"`
+	PendingCategory          *uint64                           `json:"pending_category,string"`
 }
 
-func (p *panelBody) IntoPanelMessageData(customId string) panelMessageData {
+func (p *PanelBody) IntoPanelMessageData(customId string) panelMessageData {
 	return panelMessageData{
 		ChannelId:      p.ChannelId,
 		Title:          p.Title,
@@ -75,10 +79,10 @@ func CreatePanel(c *gin.Context) {
 		return
 	}
 
-	var data panelBody
+	var data PanelBody
 
 	if err := c.BindJSON(&data); err != nil {
-		c.JSON(400, utils.ErrorStr("Invalid request body"))
+		validation.RespondField(c, "", validation.CodeInvalid, "Invalid request body")
 		return
 	}
 
@@ -102,37 +106,24 @@ func CreatePanel(c *gin.Context) {
 		return
 	}
 
-	// Do custom validation
-	validationContext := PanelValidationContext{
-		Data:       data,
-		GuildId:    guildId,
-		IsPremium:  true,
-		BotContext: botContext,
-		Channels:   channels,
-		Roles:      roles,
-	}
-
-	if err := ValidatePanelBody(validationContext); err != nil {
-		var validationError *validation.InvalidInputError
-		if errors.As(err, &validationError) {
-			c.JSON(400, utils.ErrorStr(validationError.Error()))
-		} else {
+	if err := data.resolveTemplate(ctx, guildId); err != nil {
+		if !validation.RespondError(c, err) {
 			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
 		}
 
 		return
 	}
 
-	// Do tag validation
-	if err := validate.Struct(data); err != nil {
-		var validationErrors validator.ValidationErrors
-		if ok := errors.As(err, &validationErrors); !ok {
-			c.JSON(500, utils.ErrorStr("An error occurred while validating the panel"))
-			return
+	if err := validatePanelUpdate(botContext, guildId, data, channels, roles); err != nil {
+		if !validation.RespondError(c, err) {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
 		}
 
-		formatted := "Your input contained the following errors:\n" + utils.FormatValidationErrors(validationErrors)
-		c.JSON(400, utils.ErrorStr(formatted))
+		return
+	}
+
+	if dryrun.Requested(c) {
+		dryrun.Respond(c, nil, data)
 		return
 	}
 
@@ -213,7 +204,7 @@ func CreatePanel(c *gin.Context) {
 		PendingCategory:     data.PendingCategory,
 	}
 
-	createOptions := panelCreateOptions{
+	createOptions := PanelCreateOptions{
 		TeamIds:            data.Teams,             // Already validated
 		AccessControlRules: data.AccessControlList, // Already validated
 	}
@@ -223,13 +214,13 @@ func CreatePanel(c *gin.Context) {
 	validRoles := utils.ToSet(utils.Map(roles, utils.RoleToId))
 
 	var roleMentions []uint64
-	for _, mention := range data.Mentions {
+	for i, mention := range data.Mentions {
 		if mention == "user" {
 			createOptions.ShouldMentionUser = true
 		} else {
 			roleId, err := strconv.ParseUint(mention, 10, 64)
 			if err != nil {
-				c.JSON(400, utils.ErrorStr("Invalid role ID"))
+				validation.RespondField(c, validation.IndexPointer("/mentions", i), validation.CodeInvalid, "Invalid role ID")
 				return
 			}
 
@@ -245,6 +236,17 @@ func CreatePanel(c *gin.Context) {
 		return
 	}
 
+	panel.PanelId = panelId
+
+	if data.WelcomeMessageTemplateId != nil {
+		if err := dbclient.Dashboard.EmbedTemplateReferences.Set(c, guildId, dbclient.EmbedTemplateTargetPanel, strconv.Itoa(panelId), data.WelcomeMessageTemplateId); err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+			return
+		}
+	}
+
+	audit.SetAfter(c, panel)
+
 	c.JSON(200, gin.H{
 		"success":  true,
 		"panel_id": panelId,
@@ -253,50 +255,62 @@ func CreatePanel(c *gin.Context) {
 
 // DB functions
 
-type panelCreateOptions struct {
+type PanelCreateOptions struct {
 	ShouldMentionUser  bool
 	RoleMentions       []uint64
 	TeamIds            []int
 	AccessControlRules []database.PanelAccessControlRule
 }
 
-func storePanel(ctx context.Context, panel database.Panel, options panelCreateOptions) (int, error) {
+func storePanel(ctx context.Context, panel database.Panel, options PanelCreateOptions) (int, error) {
 	var panelId int
-	err := dbclient.Client.Panel.BeginFunc(ctx, func(tx pgx.Tx) error {
-		var err error
-		panelId, err = dbclient.Client.Panel.CreateWithTx(ctx, tx, panel)
-		if err != nil {
-			return err
-		}
+	err := dbclient.Client.Panel.BeginFunc(ctx, func(tx pgx.Tx) (err error) {
+		panelId, err = StorePanelWithTx(ctx, tx, panel, options)
+		return
+	})
 
-		if err := dbclient.Client.PanelUserMention.SetWithTx(ctx, tx, panelId, options.ShouldMentionUser); err != nil {
-			return err
-		}
+	if err != nil {
+		return 0, err
+	}
 
-		if err := dbclient.Client.PanelRoleMentions.ReplaceWithTx(ctx, tx, panelId, options.RoleMentions); err != nil {
-			return err
-		}
+	return panelId, nil
+}
 
-		// Already validated, we are safe to insert
-		if err := dbclient.Client.PanelTeams.ReplaceWithTx(ctx, tx, panelId, options.TeamIds); err != nil {
-			return err
-		}
+// StorePanelWithTx inserts the panel and its associated mentions, teams and access control rules, returning the new
+// panel ID. The panel must have been validated before calling this function.
+func StorePanelWithTx(ctx context.Context, tx pgx.Tx, panel database.Panel, options PanelCreateOptions) (int, error) {
+	panelId, err := dbclient.Client.Panel.CreateWithTx(ctx, tx, panel)
+	if err != nil {
+		return 0, err
+	}
 
-		if err := dbclient.Client.PanelAccessControlRules.ReplaceWithTx(ctx, tx, panelId, options.AccessControlRules); err != nil {
-			return err
-		}
+	if err := dbclient.Client.PanelUserMention.SetWithTx(ctx, tx, panelId, options.ShouldMentionUser); err != nil {
+		return 0, err
+	}
 
-		return nil
-	})
+	if err := dbclient.Client.PanelRoleMentions.ReplaceWithTx(ctx, tx, panelId, options.RoleMentions); err != nil {
+		return 0, err
+	}
 
-	if err != nil {
+	// Already validated, we are safe to insert
+	if err := dbclient.Client.PanelTeams.ReplaceWithTx(ctx, tx, panelId, options.TeamIds); err != nil {
+		return 0, err
+	}
+
+	if err := dbclient.Client.PanelAccessControlRules.ReplaceWithTx(ctx, tx, panelId, options.AccessControlRules); err != nil {
 		return 0, err
 	}
 
 	return panelId, nil
 }
 
+// resolveTemplate replaces the welcome message with the embed template that the panel references, if any. The
+// template takes precedence over a welcome message submitted alongside it.
+func (p *PanelBody) resolveTemplate(ctx context.Context, guildId uint64) error {
+	return embedtemplates.Resolve(ctx, guildId, p.WelcomeMessageTemplateId, &p.WelcomeMessage, "/welcome_message_template_id")
+}
+
 // Data must be validated before calling this function
-func (p *panelBody) getEmoji() *emoji.Emoji {
+func (p *PanelBody) getEmoji() *emoji.Emoji {
 	return p.Emoji.IntoGdl()
 }
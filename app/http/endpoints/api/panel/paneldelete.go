@@ -7,6 +7,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/dryrun"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
@@ -46,6 +48,13 @@ func DeletePanel(c *gin.Context) {
 		return
 	}
 
+	audit.SetBefore(c, panel)
+
+	if dryrun.Requested(c) {
+		dryrun.Respond(c, panel, nil)
+		return
+	}
+
 	// Get any multi panels this panel is part of to use later
 	multiPanels, err := database.Client.MultiPanelTargets.GetMultiPanels(c, panelId)
 	if err != nil {
@@ -66,6 +75,21 @@ func DeletePanel(c *gin.Context) {
 		return
 	}
 
+	if err := database.Dashboard.SlaPolicies.Delete(c, guildId, panelId); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	if err := database.Dashboard.EmbedTemplateReferences.Delete(c, guildId, database.EmbedTemplateTargetPanel, strconv.Itoa(panelId)); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	if err := database.Dashboard.PanelHealth.Delete(c, database.PanelKindPanel, panelId); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
 	// TODO: Set timeout on context
 	if err := rest.DeleteMessage(c, botContext.Token, botContext.RateLimiter, panel.ChannelId, panel.MessageId); err != nil {
 		var unwrapped request.RestError
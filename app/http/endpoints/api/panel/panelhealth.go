@@ -0,0 +1,178 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/redis"
+	"github.com/rxdn/gdl/rest/request"
+	"go.uber.org/zap"
+)
+
+const (
+	healthCheckInterval  = time.Minute
+	healthCheckBatchSize = 50
+	healthCheckTimeout   = 10 * time.Second
+	healthCheckLockKey   = "tickets:panels:healthverifier"
+	healthCheckLockTtl   = 3 * healthCheckInterval
+
+	// healthCheckPace is the delay between message fetches, so that a batch is spread over the interval rather than
+	// sent to Discord at once
+	healthCheckPace = healthCheckInterval / (2 * healthCheckBatchSize)
+
+	// healthCheckMaxAge is how long the result of a check is trusted for before the panel is checked again
+	healthCheckMaxAge = time.Hour
+
+	// discordUnknownChannel is the error code returned by Discord when fetching a message from a deleted channel
+	discordUnknownChannel = 10003
+)
+
+type panelHealthResponse struct {
+	Status    dbclient.PanelHealthStatus `json:"status"`
+	CheckedAt time.Time                  `json:"checked_at"`
+}
+
+// RunPanelHealthVerifier checks that the message of each panel and multi-panel still exists until ctx is cancelled,
+// recording the result so that it can be shown in the panel list. One replica is elected to run the checks at a time,
+// so that each panel is only fetched from Discord once per check.
+func RunPanelHealthVerifier(ctx context.Context, client *redis.RedisClient, logger *zap.Logger) {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+
+	owner := uuid.NewString()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		elected, err := client.HoldLock(ctx, healthCheckLockKey, owner, healthCheckLockTtl)
+		if err != nil {
+			logger.Error("Failed to acquire the panel health verifier lock", zap.Error(err))
+			continue
+		}
+
+		if !elected {
+			continue
+		}
+
+		due, err := dbclient.Dashboard.PanelHealth.GetDue(ctx, time.Now().Add(-healthCheckMaxAge), healthCheckBatchSize)
+		if err != nil {
+			logger.Error("Failed to fetch panels due a health check", zap.Error(err))
+			continue
+		}
+
+		for i, message := range due {
+			if i > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(healthCheckPace):
+				}
+			}
+
+			if err := verifyPanelMessage(ctx, message); err != nil {
+				logger.Warn(
+					"Failed to check panel message",
+					zap.Error(err),
+					zap.Uint64("guild_id", message.GuildId),
+					zap.String("kind", string(message.Kind)),
+					zap.Int("panel_id", message.PanelId),
+				)
+			}
+		}
+	}
+}
+
+// verifyPanelMessage checks the message and records the result. If the check itself fails, for example because
+// Discord is unavailable, the previous result is kept, but the check time is still updated so that the panel does not
+// stay at the front of the queue and block the others.
+func verifyPanelMessage(ctx context.Context, message dbclient.PanelMessage) error {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	status, err := checkPanel(ctx, message)
+	if err != nil {
+		// Use a fresh context, as the check may have failed because ctx timed out
+		storeCtx, storeCancel := context.WithTimeout(context.WithoutCancel(ctx), healthCheckTimeout)
+		defer storeCancel()
+
+		if storeErr := dbclient.Dashboard.PanelHealth.SetCheckFailed(storeCtx, message.Kind, message.PanelId, message.GuildId, time.Now()); storeErr != nil {
+			return errors.Join(err, storeErr)
+		}
+
+		return err
+	}
+
+	return dbclient.Dashboard.PanelHealth.Set(ctx, dbclient.PanelHealth{
+		Kind:      message.Kind,
+		PanelId:   message.PanelId,
+		GuildId:   message.GuildId,
+		Status:    status,
+		CheckedAt: time.Now(),
+	})
+}
+
+func checkPanel(ctx context.Context, message dbclient.PanelMessage) (dbclient.PanelHealthStatus, error) {
+	botContext, err := botcontext.ContextForGuild(message.GuildId)
+	if err != nil {
+		return "", err
+	}
+
+	return checkPanelMessage(ctx, botContext, message.ChannelId, message.MessageId)
+}
+
+// checkPanelMessage fetches the message to determine the health of a panel. An error is only returned if the health
+// could not be determined.
+func checkPanelMessage(ctx context.Context, botContext *botcontext.BotContext, channelId, messageId uint64) (dbclient.PanelHealthStatus, error) {
+	if _, err := botContext.GetChannelMessage(ctx, channelId, messageId); err != nil {
+		var unwrapped request.RestError
+		if !errors.As(err, &unwrapped) {
+			return "", err
+		}
+
+		switch unwrapped.StatusCode {
+		case http.StatusNotFound:
+			if unwrapped.ApiError.Code == discordUnknownChannel {
+				return dbclient.PanelHealthStatusChannelMissing, nil
+			}
+
+			return dbclient.PanelHealthStatusMessageMissing, nil
+		case http.StatusForbidden:
+			return dbclient.PanelHealthStatusForbidden, nil
+		default:
+			return "", err
+		}
+	}
+
+	return dbclient.PanelHealthStatusOk, nil
+}
+
+// markHealthy records that a panel message was just sent successfully
+func markHealthy(ctx context.Context, kind dbclient.PanelKind, panelId int, guildId uint64) error {
+	return dbclient.Dashboard.PanelHealth.Set(ctx, dbclient.PanelHealth{
+		Kind:      kind,
+		PanelId:   panelId,
+		GuildId:   guildId,
+		Status:    dbclient.PanelHealthStatusOk,
+		CheckedAt: time.Now(),
+	})
+}
+
+func newPanelHealthResponse(health map[int]dbclient.PanelHealth, panelId int) *panelHealthResponse {
+	data, ok := health[panelId]
+	if !ok {
+		return nil
+	}
+
+	return &panelHealthResponse{
+		Status:    data.Status,
+		CheckedAt: data.CheckedAt,
+	}
+}
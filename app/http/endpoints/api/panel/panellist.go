@@ -17,12 +17,14 @@ func ListPanels(c *gin.Context) {
 	type panelResponse struct {
 		database.Panel
 		WelcomeMessage               *types.CustomEmbed                `json:"welcome_message"`
+		WelcomeMessageTemplateId     *int                              `json:"welcome_message_template_id"`
 		UseCustomEmoji               bool                              `json:"use_custom_emoji"`
 		Emoji                        types.Emoji                       `json:"emote"`
 		Mentions                     []string                          `json:"mentions"`
 		Teams                        []int                             `json:"teams"`
 		UseServerDefaultNamingScheme bool                              `json:"use_server_default_naming_scheme"`
 		AccessControlList            []database.PanelAccessControlRule `json:"access_control_list"`
+		Health                       *panelHealthResponse              `json:"health"`
 	}
 
 	guildId := c.Keys["guildid"].(uint64)
@@ -45,6 +47,18 @@ func ListPanels(c *gin.Context) {
 		return
 	}
 
+	templateIds, err := dbclient.Dashboard.EmbedTemplateReferences.GetByKind(c, guildId, dbclient.EmbedTemplateTargetPanel)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	health, err := dbclient.Dashboard.PanelHealth.GetByGuild(c, guildId, dbclient.PanelKindPanel)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
 	wrapped := make([]panelResponse, len(panels))
 
 	// we will need to lookup role mentions
@@ -99,15 +113,22 @@ func ListPanels(c *gin.Context) {
 				accessControlList = make([]database.PanelAccessControlRule, 0)
 			}
 
+			var welcomeMessageTemplateId *int
+			if templateId, ok := templateIds[strconv.Itoa(p.PanelId)]; ok {
+				welcomeMessageTemplateId = &templateId
+			}
+
 			wrapped[i] = panelResponse{
 				Panel:                        p.Panel,
 				WelcomeMessage:               welcomeMessage,
+				WelcomeMessageTemplateId:     welcomeMessageTemplateId,
 				UseCustomEmoji:               p.EmojiId != nil,
 				Emoji:                        types.NewEmoji(p.EmojiName, p.EmojiId),
 				Mentions:                     mentions,
 				Teams:                        teamIds,
 				UseServerDefaultNamingScheme: p.NamingScheme == nil,
 				AccessControlList:            accessControlList,
+				Health:                       newPanelHealthResponse(health, p.PanelId),
 			}
 
 			return nil
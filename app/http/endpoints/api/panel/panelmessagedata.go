@@ -55,6 +55,12 @@ func panelIntoMessageData(panel database.Panel) panelMessageData {
 	}
 }
 
+// SendPanelMessage sends the message for an already stored panel, returning the new message ID
+func SendPanelMessage(c *botcontext.BotContext, panel database.Panel) (uint64, error) {
+	messageData := panelIntoMessageData(panel)
+	return messageData.send(c)
+}
+
 func (p *panelMessageData) send(c *botcontext.BotContext) (uint64, error) {
 	e := embed.NewEmbed().
 		SetTitle(p.Title).
@@ -4,15 +4,27 @@ import (
 	"context"
 	"errors"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/middleware"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
 	"github.com/rxdn/gdl/rest"
 	"github.com/rxdn/gdl/rest/request"
 )
 
+// ResendRateLimit is shared by every way of resending a panel or multi-panel message: the individual resend routes, and
+// bulk resends
+var ResendRateLimit = middleware.RateLimitBucket{
+	Name:   "panel-resend",
+	Max:    5,
+	Period: 5 * time.Second,
+}
+
 func ResendPanel(ctx *gin.Context) {
 	guildId := ctx.Keys["guildid"].(uint64)
 
@@ -46,19 +58,10 @@ func ResendPanel(ctx *gin.Context) {
 		return
 	}
 
-	// delete old message
-	// TODO: Use proper context
-	if err := rest.DeleteMessage(context.Background(), botContext.Token, botContext.RateLimiter, panel.ChannelId, panel.GuildId); err != nil {
-		var unwrapped request.RestError
-		if errors.As(err, &unwrapped) && !unwrapped.IsClientError() {
-			ctx.JSON(500, utils.ErrorJson(err))
-			return
-		}
-	}
+	audit.SetBefore(ctx, panel)
 
-	messageData := panelIntoMessageData(panel)
-	msgId, err := messageData.send(botContext)
-	if err != nil {
+	// TODO: Use proper context
+	if err := ResendPanelMessage(context.Background(), botContext, panel); err != nil {
 		var unwrapped request.RestError
 		if errors.As(err, &unwrapped) && unwrapped.StatusCode == 403 {
 			ctx.JSON(500, utils.ErrorStr("I do not have permission to send messages in the provided channel"))
@@ -69,10 +72,29 @@ func ResendPanel(ctx *gin.Context) {
 		return
 	}
 
+	ctx.JSON(200, utils.SuccessResponse)
+}
+
+// ResendPanelMessage deletes the panel message and sends it again from the stored panel, updating the stored message
+// ID and marking the panel as healthy
+func ResendPanelMessage(ctx context.Context, botContext *botcontext.BotContext, panel database.Panel) error {
+	// delete old message
+	if err := rest.DeleteMessage(ctx, botContext.Token, botContext.RateLimiter, panel.ChannelId, panel.MessageId); err != nil {
+		var unwrapped request.RestError
+		if errors.As(err, &unwrapped) && !unwrapped.IsClientError() {
+			return err
+		}
+	}
+
+	messageData := panelIntoMessageData(panel)
+	msgId, err := messageData.send(botContext)
+	if err != nil {
+		return err
+	}
+
 	if err = dbclient.Client.Panel.UpdateMessageId(ctx, panel.PanelId, msgId); err != nil {
-		ctx.JSON(500, utils.ErrorJson(err))
-		return
+		return err
 	}
 
-	ctx.JSON(200, utils.SuccessResponse)
+	return markHealthy(ctx, dbclient.PanelKindPanel, panel.PanelId, panel.GuildId)
 }
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	schedulerInterval  = 30 * time.Second
+	schedulerBatchSize = 25 // schedules applied per tick
+	scheduleTimeout    = time.Minute
+
+	// scheduleLease must exceed scheduleTimeout, so that a schedule is only claimed again once the replica that
+	// claimed it can no longer be applying it. This holds because each schedule is claimed just before it is applied.
+	scheduleLease = 5 * time.Minute
+)
+
+// RunPanelScheduler applies scheduled panel changes once they are due, until ctx is cancelled. Due schedules are
+// claimed with row locks, so the scheduler can safely run on every replica, and are claimed again if a replica stops
+// while applying them.
+func RunPanelScheduler(ctx context.Context, logger *zap.Logger) {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Schedules are claimed one at a time, so that each is applied within its lease
+		for i := 0; i < schedulerBatchSize; i++ {
+			schedule, ok, err := dbclient.Dashboard.PanelSchedules.ClaimNext(ctx, scheduleLease)
+			if err != nil {
+				logger.Error("Failed to claim due panel schedule", zap.Error(err))
+				break
+			}
+
+			if !ok {
+				break
+			}
+
+			status := dbclient.PanelScheduleStatusComplete
+
+			var errorMessage *string
+			if err := executeSchedule(ctx, schedule); err != nil {
+				// Shutting down: leave the schedule running, so that it is claimed again once the lease expires
+				if ctx.Err() != nil {
+					return
+				}
+
+				status = dbclient.PanelScheduleStatusFailed
+				errorMessage = utils.Ptr(scheduleErrorMessage(err))
+
+				logger.Warn(
+					"Failed to apply scheduled panel change",
+					zap.Error(err),
+					zap.Int64("schedule_id", schedule.Id),
+					zap.Uint64("guild_id", schedule.GuildId),
+					zap.Int("panel_id", schedule.PanelId),
+				)
+			}
+
+			if err := dbclient.Dashboard.PanelSchedules.SetResult(ctx, schedule.Id, status, errorMessage); err != nil {
+				logger.Error("Failed to store panel schedule result", zap.Error(err), zap.Int64("schedule_id", schedule.Id))
+			}
+		}
+	}
+}
+
+// executeSchedule applies a scheduled change in the same way as UpdatePanel, re-validating the body against the
+// current state of the guild first
+func executeSchedule(ctx context.Context, schedule dbclient.PanelSchedule) error {
+	ctx, cancel := context.WithTimeout(ctx, scheduleTimeout)
+	defer cancel()
+
+	var data PanelBody
+	if err := json.Unmarshal(schedule.Data, &data); err != nil {
+		return err
+	}
+
+	existing, err := dbclient.Client.Panel.GetById(ctx, schedule.PanelId)
+	if err != nil {
+		return err
+	}
+
+	if existing.PanelId == 0 || existing.GuildId != schedule.GuildId {
+		return validation.NewInvalidInputError("The panel no longer exists")
+	}
+
+	botContext, err := botcontext.ContextForGuild(schedule.GuildId)
+	if err != nil {
+		return err
+	}
+
+	channels, err := botContext.GetGuildChannels(ctx, schedule.GuildId)
+	if err != nil {
+		return err
+	}
+
+	roles, err := botContext.GetGuildRoles(ctx, schedule.GuildId)
+	if err != nil {
+		return err
+	}
+
+	// The template may have been edited since the change was scheduled
+	if err := data.resolveTemplate(ctx, schedule.GuildId); err != nil {
+		return err
+	}
+
+	if err := validatePanelUpdate(botContext, schedule.GuildId, data, channels, roles); err != nil {
+		return err
+	}
+
+	panel, err := applyPanelUpdate(ctx, botContext, existing, data, roles)
+	if err != nil {
+		return err
+	}
+
+	before, err := json.Marshal(existing)
+	if err != nil {
+		return err
+	}
+
+	after, err := json.Marshal(panel)
+	if err != nil {
+		return err
+	}
+
+	// Record the change as if the user who created the schedule had made it
+	return dbclient.Dashboard.AuditLog.Create(ctx, dbclient.AuditLogEntry{
+		GuildId: schedule.GuildId,
+		UserId:  schedule.CreatedBy,
+		Action:  "PATCH /panels/:panelid",
+		Path:    fmt.Sprintf("/panels/%d", schedule.PanelId),
+		Before:  before,
+		After:   after,
+	})
+}
+
+// scheduleErrorMessage returns the message stored against a failed schedule. Internal errors are not exposed.
+func scheduleErrorMessage(err error) string {
+	if _, ok := validation.Failures(err); ok {
+		return err.Error()
+	}
+
+	return "An internal error occurred while applying the change"
+}
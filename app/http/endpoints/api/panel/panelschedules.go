@@ -0,0 +1,226 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+)
+
+const (
+	maxPendingSchedules = 10
+	maxScheduleDelay    = 365 * 24 * time.Hour
+	schedulesPageLimit  = 50
+)
+
+type (
+	scheduleCreateBody struct {
+		ExecuteAt time.Time `json:"execute_at"`
+		Data      PanelBody `json:"data"`
+	}
+
+	scheduleResponse struct {
+		Id         int64                        `json:"id"`
+		PanelId    int                          `json:"panel_id"`
+		CreatedBy  uint64                       `json:"created_by,string"`
+		ExecuteAt  time.Time                    `json:"execute_at"`
+		Data       json.RawMessage              `json:"data"`
+		Status     dbclient.PanelScheduleStatus `json:"status"`
+		Error      *string                      `json:"error,omitempty"`
+		CreatedAt  time.Time                    `json:"created_at"`
+		ExecutedAt *time.Time                   `json:"executed_at,omitempty"`
+	}
+)
+
+func ListPanelSchedules(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	panel, ok := getSchedulePanel(c, guildId)
+	if !ok {
+		return
+	}
+
+	schedules, err := dbclient.Dashboard.PanelSchedules.GetByPanel(c, guildId, panel.PanelId, schedulesPageLimit)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	res := make([]scheduleResponse, len(schedules))
+	for i, schedule := range schedules {
+		res[i] = scheduleToResponse(schedule)
+	}
+
+	c.JSON(200, res)
+}
+
+func CreatePanelSchedule(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	var body scheduleCreateBody
+	if err := c.BindJSON(&body); err != nil {
+		validation.RespondField(c, "", validation.CodeInvalid, "Invalid request body")
+		return
+	}
+
+	panel, ok := getSchedulePanel(c, guildId)
+	if !ok {
+		return
+	}
+
+	if !body.ExecuteAt.After(time.Now()) {
+		validation.RespondField(c, "/execute_at", validation.CodeTooSmall, "The execution time must be in the future")
+		return
+	}
+
+	if time.Until(body.ExecuteAt) > maxScheduleDelay {
+		validation.RespondField(c, "/execute_at", validation.CodeTooLarge, "Changes cannot be scheduled more than a year in advance")
+		return
+	}
+
+	pending, err := dbclient.Dashboard.PanelSchedules.CountPending(c, guildId, panel.PanelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	if pending >= maxPendingSchedules {
+		validation.RespondField(c, "", validation.CodeLimitExceeded, "A panel can have at most %d pending scheduled changes", maxPendingSchedules)
+		return
+	}
+
+	botContext, err := botcontext.ContextForGuild(guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	ApplyPanelDefaults(&body.Data)
+
+	channels, err := botContext.GetGuildChannels(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	roles, err := botContext.GetGuildRoles(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	// Validate now so the user gets immediate feedback. The body is validated again when the schedule is executed, as
+	// the guild may have changed in the meantime.
+	if err := validatePanelUpdate(botContext, guildId, body.Data, channels, roles); err != nil {
+		if !validation.RespondError(c, validation.WithPrefix(err, "/data")) {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		}
+
+		return
+	}
+
+	data, err := json.Marshal(body.Data)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	schedule, err := dbclient.Dashboard.PanelSchedules.Create(c, dbclient.PanelSchedule{
+		GuildId:   guildId,
+		PanelId:   panel.PanelId,
+		CreatedBy: userId,
+		ExecuteAt: body.ExecuteAt,
+		Data:      data,
+	})
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	res := scheduleToResponse(schedule)
+	audit.SetAfter(c, res)
+
+	c.JSON(200, res)
+}
+
+func CancelPanelSchedule(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	scheduleId, err := strconv.ParseInt(c.Param("scheduleid"), 10, 64)
+	if err != nil {
+		c.JSON(400, utils.ErrorStr("Invalid schedule ID"))
+		return
+	}
+
+	panel, ok := getSchedulePanel(c, guildId)
+	if !ok {
+		return
+	}
+
+	schedule, cancelled, err := dbclient.Dashboard.PanelSchedules.Cancel(c, guildId, panel.PanelId, scheduleId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	if !cancelled {
+		c.JSON(404, utils.ErrorStr("No pending schedule with this ID exists"))
+		return
+	}
+
+	audit.SetBefore(c, scheduleToResponse(schedule))
+
+	c.JSON(200, utils.SuccessResponse)
+}
+
+// getSchedulePanel loads the panel from the path, writing an error response and returning false if it cannot be used
+func getSchedulePanel(c *gin.Context, guildId uint64) (database.Panel, bool) {
+	panelId, err := strconv.Atoi(c.Param("panelid"))
+	if err != nil {
+		c.JSON(400, utils.ErrorStr("Missing panel ID"))
+		return database.Panel{}, false
+	}
+
+	panel, err := dbclient.Client.Panel.GetById(c, panelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return database.Panel{}, false
+	}
+
+	if panel.PanelId == 0 {
+		c.JSON(404, utils.ErrorStr("Panel not found"))
+		return database.Panel{}, false
+	}
+
+	// verify panel belongs to guild
+	if panel.GuildId != guildId {
+		c.JSON(403, utils.ErrorStr("Guild ID doesn't match"))
+		return database.Panel{}, false
+	}
+
+	return panel, true
+}
+
+func scheduleToResponse(schedule dbclient.PanelSchedule) scheduleResponse {
+	return scheduleResponse{
+		Id:         schedule.Id,
+		PanelId:    schedule.PanelId,
+		CreatedBy:  schedule.CreatedBy,
+		ExecuteAt:  schedule.ExecuteAt,
+		Data:       schedule.Data,
+		Status:     schedule.Status,
+		Error:      schedule.Error,
+		CreatedAt:  schedule.CreatedAt,
+		ExecutedAt: schedule.ExecutedAt,
+	}
+}
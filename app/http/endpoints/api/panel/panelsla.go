@@ -0,0 +1,179 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/rpc/cache"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/rxdn/gdl/objects/channel"
+)
+
+const (
+	minSlaSeconds        = 60
+	maxSlaSeconds        = 30 * 24 * 60 * 60
+	defaultAtRiskPercent = 75
+)
+
+type slaPolicyBody struct {
+	FirstResponseSeconds *int    `json:"first_response_seconds"`
+	ResolutionSeconds    *int    `json:"resolution_seconds"`
+	AtRiskPercent        int     `json:"at_risk_percent"`
+	NotifyChannelId      *uint64 `json:"notify_channel_id,string"`
+}
+
+type slaPolicyResponse struct {
+	PanelId int `json:"panel_id"`
+	slaPolicyBody
+}
+
+func ListSlaPolicies(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	policies, err := dbclient.Dashboard.SlaPolicies.GetByGuild(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	c.JSON(200, utils.Map(policies, slaPolicyToResponse))
+}
+
+// SetPanelSlaPolicy creates or replaces the SLA policy for tickets opened from the panel
+func SetPanelSlaPolicy(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	var body slaPolicyBody
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(400, utils.ErrorStr("Invalid request body"))
+		return
+	}
+
+	panel, ok := getSchedulePanel(c, guildId)
+	if !ok {
+		return
+	}
+
+	if body.AtRiskPercent == 0 {
+		body.AtRiskPercent = defaultAtRiskPercent
+	}
+
+	if err := body.validate(c, guildId); err != nil {
+		if !validation.RespondError(c, err) {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		}
+
+		return
+	}
+
+	existing, ok, err := dbclient.Dashboard.SlaPolicies.Get(c, guildId, panel.PanelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	if ok {
+		audit.SetBefore(c, slaPolicyToResponse(existing))
+	}
+
+	policy := dbclient.SlaPolicy{
+		GuildId:              guildId,
+		PanelId:              panel.PanelId,
+		FirstResponseSeconds: body.FirstResponseSeconds,
+		ResolutionSeconds:    body.ResolutionSeconds,
+		AtRiskPercent:        body.AtRiskPercent,
+		NotifyChannelId:      body.NotifyChannelId,
+	}
+
+	if err := dbclient.Dashboard.SlaPolicies.Set(c, policy); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	res := slaPolicyToResponse(policy)
+	audit.SetAfter(c, res)
+
+	c.JSON(200, res)
+}
+
+func DeletePanelSlaPolicy(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	panel, ok := getSchedulePanel(c, guildId)
+	if !ok {
+		return
+	}
+
+	existing, ok, err := dbclient.Dashboard.SlaPolicies.Get(c, guildId, panel.PanelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	if !ok {
+		c.JSON(404, utils.ErrorStr("This panel does not have an SLA policy"))
+		return
+	}
+
+	audit.SetBefore(c, slaPolicyToResponse(existing))
+
+	if err := dbclient.Dashboard.SlaPolicies.Delete(c, guildId, panel.PanelId); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	c.JSON(200, utils.SuccessResponse)
+}
+
+func (b *slaPolicyBody) validate(c *gin.Context, guildId uint64) error {
+	var errs []error
+	if b.FirstResponseSeconds == nil && b.ResolutionSeconds == nil {
+		errs = append(errs, validation.NewFieldError("", validation.CodeRequired, "An SLA policy must set a first response or resolution target"))
+	}
+
+	targets := []struct {
+		pointer string
+		value   *int
+	}{
+		{"/first_response_seconds", b.FirstResponseSeconds},
+		{"/resolution_seconds", b.ResolutionSeconds},
+	}
+
+	for _, target := range targets {
+		if target.value != nil && (*target.value < minSlaSeconds || *target.value > maxSlaSeconds) {
+			errs = append(errs, validation.NewFieldError(target.pointer, validation.CodeInvalid, "SLA targets must be between 1 minute and 30 days"))
+		}
+	}
+
+	if b.AtRiskPercent < 1 || b.AtRiskPercent > 99 {
+		errs = append(errs, validation.NewFieldError("/at_risk_percent", validation.CodeInvalid, "The at risk percentage must be between 1 and 99"))
+	}
+
+	if b.NotifyChannelId != nil {
+		if ch, err := cache.Instance.GetChannel(c, *b.NotifyChannelId); err != nil {
+			errs = append(errs, validation.NewFieldError("/notify_channel_id", validation.CodeNotFound, "Invalid notification channel"))
+		} else if ch.GuildId != guildId {
+			errs = append(errs, validation.NewFieldError("/notify_channel_id", validation.CodeNotFound, "Notification channel guild ID does not match"))
+		} else if ch.Type != channel.ChannelTypeGuildText {
+			errs = append(errs, validation.NewFieldError("/notify_channel_id", validation.CodeInvalid, "Notification channel is not a text channel"))
+		}
+	}
+
+	return validation.Join(errs...)
+}
+
+func slaPolicyToResponse(policy dbclient.SlaPolicy) slaPolicyResponse {
+	return slaPolicyResponse{
+		PanelId: policy.PanelId,
+		slaPolicyBody: slaPolicyBody{
+			FirstResponseSeconds: policy.FirstResponseSeconds,
+			ResolutionSeconds:    policy.ResolutionSeconds,
+			AtRiskPercent:        policy.AtRiskPercent,
+			NotifyChannelId:      policy.NotifyChannelId,
+		},
+	}
+}
@@ -7,14 +7,17 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v4"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/dryrun"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	database "github.com/jadevelopmentgrp/Tickets-Database"
+	"github.com/rxdn/gdl/objects/channel"
+	"github.com/rxdn/gdl/objects/guild"
 	"github.com/rxdn/gdl/objects/interaction/component"
 	"github.com/rxdn/gdl/rest"
 	"github.com/rxdn/gdl/rest/request"
@@ -29,9 +32,9 @@ func UpdatePanel(c *gin.Context) {
 		return
 	}
 
-	var data panelBody
+	var data PanelBody
 	if err := c.BindJSON(&data); err != nil {
-		c.JSON(400, utils.ErrorStr("Invalid request body"))
+		validation.RespondField(c, "", validation.CodeInvalid, "Invalid request body")
 		return
 	}
 
@@ -54,6 +57,8 @@ func UpdatePanel(c *gin.Context) {
 		return
 	}
 
+	audit.SetBefore(c, existing)
+
 	// Apply defaults
 	ApplyPanelDefaults(&data)
 
@@ -71,40 +76,69 @@ func UpdatePanel(c *gin.Context) {
 		return
 	}
 
-	// Do custom validation
-	validationContext := PanelValidationContext{
-		Data:       data,
-		GuildId:    guildId,
-		IsPremium:  true,
-		BotContext: botContext,
-		Channels:   channels,
-		Roles:      roles,
+	if err := data.resolveTemplate(c, guildId); err != nil {
+		if !validation.RespondError(c, err) {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		}
+
+		return
 	}
 
-	if err := ValidatePanelBody(validationContext); err != nil {
-		var validationError *validation.InvalidInputError
-		if errors.As(err, &validationError) {
-			c.JSON(400, utils.ErrorStr(validationError.Error()))
-		} else {
+	if err := validatePanelUpdate(botContext, guildId, data, channels, roles); err != nil {
+		if !validation.RespondError(c, err) {
 			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
 		}
 
 		return
 	}
 
-	// Do tag validation
-	if err := validate.Struct(data); err != nil {
-		var validationErrors validator.ValidationErrors
-		if !errors.As(err, &validationErrors) {
-			c.JSON(500, utils.ErrorStr("An error occurred while validating the panel"))
+	if dryrun.Requested(c) {
+		before, err := GetPanelBody(c, existing)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
 			return
 		}
 
-		formatted := "Your input contained the following errors:\n" + utils.FormatValidationErrors(validationErrors)
-		c.JSON(400, utils.ErrorStr(formatted))
+		// The message ID cannot be changed through an update
+		data.MessageId = existing.MessageId
+
+		dryrun.Respond(c, before, data)
 		return
 	}
 
+	panel, err := applyPanelUpdate(c, botContext, existing, data, roles)
+	if err != nil {
+		if !validation.RespondError(c, err) {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		}
+
+		return
+	}
+
+	audit.SetAfter(c, panel)
+
+	c.JSON(200, utils.SuccessResponse)
+}
+
+// validatePanelUpdate checks a panel body against the current state of the guild. Errors caused by the input are
+// returned as validation errors, with every invalid field collected.
+func validatePanelUpdate(botContext *botcontext.BotContext, guildId uint64, data PanelBody, channels []channel.Channel, roles []guild.Role) error {
+	validationContext := PanelValidationContext{
+		Data:       data,
+		GuildId:    guildId,
+		IsPremium:  true,
+		BotContext: botContext,
+		Channels:   channels,
+		Roles:      roles,
+	}
+
+	return validation.Join(ValidatePanelBody(validationContext), validation.Struct(validate, data))
+}
+
+// applyPanelUpdate stores a validated panel body over an existing panel, resending the panel message and any
+// multi-panels containing it if required. It is shared by UpdatePanel and the panel scheduler. Discord rejecting a
+// panel message for a reason that the user can resolve is returned as a validation error.
+func applyPanelUpdate(ctx context.Context, botContext *botcontext.BotContext, existing database.Panel, data PanelBody, roles []guild.Role) (database.Panel, error) {
 	var emojiId *uint64
 	var emojiName *string
 	{
@@ -135,27 +169,24 @@ func UpdatePanel(c *gin.Context) {
 
 	if shouldUpdateMessage {
 		// delete old message, ignoring error
-		// TODO: Use proper context
-		_ = rest.DeleteMessage(c, botContext.Token, botContext.RateLimiter, existing.ChannelId, existing.MessageId)
+		_ = rest.DeleteMessage(ctx, botContext.Token, botContext.RateLimiter, existing.ChannelId, existing.MessageId)
 
+		var err error
 		messageData := data.IntoPanelMessageData(existing.CustomId)
 		newMessageId, err = messageData.send(botContext)
 		if err != nil {
 			var unwrapped request.RestError
 			if errors.As(err, &unwrapped) {
 				if unwrapped.StatusCode == 403 {
-					c.JSON(403, utils.ErrorStr("I do not have permission to send messages in the specified channel"))
-					return
+					return database.Panel{}, validation.NewFieldError("/channel_id", validation.CodeInvalid, "I do not have permission to send messages in the specified channel")
 				} else if unwrapped.StatusCode == 404 {
 					// Swallow error
 					// TODO: Make channel_id column nullable, and set to null
 				} else {
-					_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
-					return
+					return database.Panel{}, err
 				}
 			} else {
-				_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
-				return
+				return database.Panel{}, err
 			}
 		}
 	}
@@ -164,21 +195,19 @@ func UpdatePanel(c *gin.Context) {
 	var welcomeMessageEmbed *int
 	if data.WelcomeMessage == nil {
 		if existing.WelcomeMessageEmbed != nil { // If welcome message wasn't null, but now is, delete the embed
-			if err := dbclient.Client.Embeds.Delete(c, *existing.WelcomeMessageEmbed); err != nil {
-				_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
-				return
+			if err := dbclient.Client.Embeds.Delete(ctx, *existing.WelcomeMessageEmbed); err != nil {
+				return database.Panel{}, err
 			}
 		} // else, welcomeMessageEmbed will be nil
 	} else {
 		// TODO: Upsert? Don't think we can, as no unique key in the table, panel_id is in panels table
 		if existing.WelcomeMessageEmbed == nil { // Create
 			embed, fields := data.WelcomeMessage.IntoDatabaseStruct()
-			embed.GuildId = guildId
+			embed.GuildId = existing.GuildId
 
-			id, err := dbclient.Client.Embeds.CreateWithFields(c, embed, fields)
+			id, err := dbclient.Client.Embeds.CreateWithFields(ctx, embed, fields)
 			if err != nil {
-				_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
-				return
+				return database.Panel{}, err
 			}
 
 			welcomeMessageEmbed = &id
@@ -187,21 +216,20 @@ func UpdatePanel(c *gin.Context) {
 
 			embed, fields := data.WelcomeMessage.IntoDatabaseStruct()
 			embed.Id = *existing.WelcomeMessageEmbed
-			embed.GuildId = guildId
+			embed.GuildId = existing.GuildId
 
-			if err := dbclient.Client.Embeds.UpdateWithFields(c, embed, fields); err != nil {
-				_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
-				return
+			if err := dbclient.Client.Embeds.UpdateWithFields(ctx, embed, fields); err != nil {
+				return database.Panel{}, err
 			}
 		}
 	}
 
 	// Store in DB
 	panel := database.Panel{
-		PanelId:             panelId,
+		PanelId:             existing.PanelId,
 		MessageId:           newMessageId,
 		ChannelId:           data.ChannelId,
-		GuildId:             guildId,
+		GuildId:             existing.GuildId,
 		Title:               data.Title,
 		Content:             data.Content,
 		Colour:              int32(data.Colour),
@@ -235,8 +263,7 @@ func UpdatePanel(c *gin.Context) {
 		} else {
 			roleId, err := strconv.ParseUint(mention, 10, 64)
 			if err != nil {
-				_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
-				return
+				return database.Panel{}, err
 			}
 
 			if validRoles.Contains(roleId) {
@@ -245,25 +272,25 @@ func UpdatePanel(c *gin.Context) {
 		}
 	}
 
-	err = dbclient.Client.Panel.BeginFunc(c, func(tx pgx.Tx) error {
-		if err := dbclient.Client.Panel.UpdateWithTx(c, tx, panel); err != nil {
+	err := dbclient.Client.Panel.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if err := dbclient.Client.Panel.UpdateWithTx(ctx, tx, panel); err != nil {
 			return err
 		}
 
-		if err := dbclient.Client.PanelUserMention.SetWithTx(c, tx, panel.PanelId, shouldMentionUser); err != nil {
+		if err := dbclient.Client.PanelUserMention.SetWithTx(ctx, tx, panel.PanelId, shouldMentionUser); err != nil {
 			return err
 		}
 
-		if err := dbclient.Client.PanelRoleMentions.ReplaceWithTx(c, tx, panel.PanelId, roleMentions); err != nil {
+		if err := dbclient.Client.PanelRoleMentions.ReplaceWithTx(ctx, tx, panel.PanelId, roleMentions); err != nil {
 			return err
 		}
 
 		// We are safe to insert, team IDs already validated
-		if err := dbclient.Client.PanelTeams.ReplaceWithTx(c, tx, panel.PanelId, data.Teams); err != nil {
+		if err := dbclient.Client.PanelTeams.ReplaceWithTx(ctx, tx, panel.PanelId, data.Teams); err != nil {
 			return err
 		}
 
-		if err := dbclient.Client.PanelAccessControlRules.ReplaceWithTx(c, tx, panel.PanelId, data.AccessControlList); err != nil {
+		if err := dbclient.Client.PanelAccessControlRules.ReplaceWithTx(ctx, tx, panel.PanelId, data.AccessControlList); err != nil {
 			return err
 		}
 
@@ -271,8 +298,18 @@ func UpdatePanel(c *gin.Context) {
 	})
 
 	if err != nil {
-		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
-		return
+		return database.Panel{}, err
+	}
+
+	if shouldUpdateMessage && newMessageId != 0 {
+		if err := markHealthy(ctx, dbclient.PanelKindPanel, existing.PanelId, existing.GuildId); err != nil {
+			return database.Panel{}, err
+		}
+	}
+
+	// Editing the welcome message directly detaches the panel from its template
+	if err := dbclient.Dashboard.EmbedTemplateReferences.Set(ctx, existing.GuildId, dbclient.EmbedTemplateTargetPanel, strconv.Itoa(existing.PanelId), data.WelcomeMessageTemplateId); err != nil {
+		return database.Panel{}, err
 	}
 
 	// This doesn't need to be done in a transaction
@@ -280,10 +317,9 @@ func UpdatePanel(c *gin.Context) {
 
 	// check if this will break a multi-panel;
 	// first, get any multipanels this panel belongs to
-	multiPanels, err := dbclient.Client.MultiPanelTargets.GetMultiPanels(c, existing.PanelId)
+	multiPanels, err := dbclient.Client.MultiPanelTargets.GetMultiPanels(ctx, existing.PanelId)
 	if err != nil {
-		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
-		return
+		return database.Panel{}, err
 	}
 
 	for i, multiPanel := range multiPanels {
@@ -292,10 +328,9 @@ func UpdatePanel(c *gin.Context) {
 			break
 		}
 
-		panels, err := dbclient.Client.MultiPanelTargets.GetPanels(c, multiPanel.Id)
+		panels, err := dbclient.Client.MultiPanelTargets.GetPanels(ctx, multiPanel.Id)
 		if err != nil {
-			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
-			return
+			return database.Panel{}, err
 		}
 
 		messageData := multiPanelIntoMessageData(multiPanel)
@@ -305,26 +340,22 @@ func UpdatePanel(c *gin.Context) {
 			var unwrapped request.RestError
 			if errors.As(err, &unwrapped) {
 				if unwrapped.StatusCode == http.StatusForbidden {
-					c.JSON(400, utils.ErrorStr("I do not have permission to send messages in the specified channel"))
+					return database.Panel{}, validation.NewInvalidInputError("I do not have permission to send messages in the specified channel")
 				} else {
-					c.JSON(400, utils.ErrorStr("Error sending panel message: "+unwrapped.ApiError.Message))
+					return database.Panel{}, validation.NewInvalidInputError("Error sending panel message: " + unwrapped.ApiError.Message)
 				}
-			} else {
-				_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
 			}
 
-			return
+			return database.Panel{}, err
 		}
 
-		if err := dbclient.Client.MultiPanels.UpdateMessageId(c, multiPanel.Id, messageId); err != nil {
-			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
-			return
+		if err := dbclient.Client.MultiPanels.UpdateMessageId(ctx, multiPanel.Id, messageId); err != nil {
+			return database.Panel{}, err
 		}
 
 		// Delete old panel
-		// TODO: Use proper context
-		_ = rest.DeleteMessage(c, botContext.Token, botContext.RateLimiter, multiPanel.ChannelId, multiPanel.MessageId)
+		_ = rest.DeleteMessage(ctx, botContext.Token, botContext.RateLimiter, multiPanel.ChannelId, multiPanel.MessageId)
 	}
 
-	c.JSON(200, utils.SuccessResponse)
+	return panel, nil
 }
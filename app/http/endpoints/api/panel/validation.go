@@ -3,7 +3,6 @@ package api
 import (
 	"context"
 	"errors"
-	"fmt"
 	"regexp"
 	"strings"
 	"time"
@@ -21,7 +20,7 @@ import (
 	"github.com/rxdn/gdl/objects/interaction/component"
 )
 
-func ApplyPanelDefaults(data *panelBody) {
+func ApplyPanelDefaults(data *PanelBody) {
 	for _, applicator := range DefaultApplicators(data) {
 		if applicator.ShouldApply() {
 			applicator.Apply()
@@ -29,7 +28,7 @@ func ApplyPanelDefaults(data *panelBody) {
 	}
 }
 
-func DefaultApplicators(data *panelBody) []defaults.DefaultApplicator {
+func DefaultApplicators(data *PanelBody) []defaults.DefaultApplicator {
 	return []defaults.DefaultApplicator{
 		defaults.NewDefaultApplicator(defaults.EmptyStringCheck, &data.Title, "Open a ticket!"),
 		defaults.NewDefaultApplicator(defaults.EmptyStringCheck, &data.Content, "By clicking the button, a ticket will be opened for you."),
@@ -42,7 +41,7 @@ func DefaultApplicators(data *panelBody) []defaults.DefaultApplicator {
 }
 
 type PanelValidationContext struct {
-	Data       panelBody
+	Data       PanelBody
 	GuildId    uint64
 	IsPremium  bool
 	BotContext *botcontext.BotContext
@@ -81,7 +80,7 @@ func panelValidators() []validation.Validator[PanelValidationContext] {
 func validateTitle(ctx PanelValidationContext) validation.ValidationFunc {
 	return func() error {
 		if len(ctx.Data.Title) > 80 {
-			return validation.NewInvalidInputError("Panel title must be less than 80 characters")
+			return validation.NewFieldError("/title", validation.CodeTooLong, "Panel title must be less than 80 characters")
 		}
 
 		return nil
@@ -91,7 +90,7 @@ func validateTitle(ctx PanelValidationContext) validation.ValidationFunc {
 func validateContent(ctx PanelValidationContext) validation.ValidationFunc {
 	return func() error {
 		if len(ctx.Data.Content) > 4096 {
-			return validation.NewInvalidInputError("Panel content must be less than 4096 characters")
+			return validation.NewFieldError("/content", validation.CodeTooLong, "Panel content must be less than 4096 characters")
 		}
 
 		return nil
@@ -106,7 +105,7 @@ func validateChannelId(ctx PanelValidationContext) validation.ValidationFunc {
 			}
 		}
 
-		return validation.NewInvalidInputError("Panel channel not found")
+		return validation.NewFieldError("/channel_id", validation.CodeNotFound, "Panel channel not found")
 	}
 }
 
@@ -118,7 +117,7 @@ func validateCategory(ctx PanelValidationContext) validation.ValidationFunc {
 			}
 		}
 
-		return validation.NewInvalidInputError("Invalid ticket category")
+		return validation.NewFieldError("/category_id", validation.CodeNotFound, "Invalid ticket category")
 	}
 }
 
@@ -128,7 +127,7 @@ func validateEmoji(c PanelValidationContext) validation.ValidationFunc {
 
 		if emoji.IsCustomEmoji {
 			if emoji.Id == nil {
-				return validation.NewInvalidInputError("Custom emoji was missing ID")
+				return validation.NewFieldError("/emote/id", validation.CodeRequired, "Custom emoji was missing ID")
 			}
 
 			ctx, cancel := context.WithTimeout(context.Background(), app.DefaultTimeout)
@@ -140,15 +139,15 @@ func validateEmoji(c PanelValidationContext) validation.ValidationFunc {
 			}
 
 			if resolvedEmoji.Id.Value == 0 {
-				return validation.NewInvalidInputError("Emoji not found")
+				return validation.NewFieldError("/emote/id", validation.CodeNotFound, "Emoji not found")
 			}
 
 			if resolvedEmoji.Name != emoji.Name {
-				return validation.NewInvalidInputError("Emoji name mismatch")
+				return validation.NewFieldError("/emote/name", validation.CodeInvalid, "Emoji name mismatch")
 			}
 		} else {
 			if len(emoji.Name) == 0 {
-				return validation.NewInvalidInputError("Emoji name was empty")
+				return validation.NewFieldError("/emote/name", validation.CodeRequired, "Emoji name was empty")
 			}
 
 			// Convert from :emoji: to unicode if we need to
@@ -157,7 +156,7 @@ func validateEmoji(c PanelValidationContext) validation.ValidationFunc {
 
 			unicode, ok := utils.GetEmoji(name)
 			if !ok {
-				return validation.NewInvalidInputError("Invalid emoji")
+				return validation.NewFieldError("/emote/name", validation.CodeInvalid, "Invalid emoji")
 			}
 
 			emoji.Name = unicode
@@ -169,10 +168,10 @@ func validateEmoji(c PanelValidationContext) validation.ValidationFunc {
 
 var urlRegex = regexp.MustCompile(`^https?://([-a-zA-Z0-9@:%._+~#=]{1,256})\.[a-zA-Z0-9()]{1,63}\b([-a-zA-Z0-9()@:%_+.~#?&//=]*)$`)
 
-func validateNullableUrl(url *string) validation.ValidationFunc {
+func validateNullableUrl(pointer string, url *string) validation.ValidationFunc {
 	return func() error {
 		if url != nil && (len(*url) > 255 || !urlRegex.MatchString(*url)) {
-			return validation.NewInvalidInputError("Invalid URL")
+			return validation.NewFieldError(pointer, validation.CodeInvalid, "Invalid URL")
 		}
 
 		return nil
@@ -180,17 +179,17 @@ func validateNullableUrl(url *string) validation.ValidationFunc {
 }
 
 func validateImageUrl(ctx PanelValidationContext) validation.ValidationFunc {
-	return validateNullableUrl(ctx.Data.ImageUrl)
+	return validateNullableUrl("/image_url", ctx.Data.ImageUrl)
 }
 
 func validateThumbnailUrl(ctx PanelValidationContext) validation.ValidationFunc {
-	return validateNullableUrl(ctx.Data.ThumbnailUrl)
+	return validateNullableUrl("/thumbnail_url", ctx.Data.ThumbnailUrl)
 }
 
 func validateButtonStyle(ctx PanelValidationContext) validation.ValidationFunc {
 	return func() error {
 		if ctx.Data.ButtonStyle < component.ButtonStylePrimary && ctx.Data.ButtonStyle > component.ButtonStyleDanger {
-			return validation.NewInvalidInputError("Invalid button style")
+			return validation.NewFieldError("/button_style", validation.CodeInvalid, "Invalid button style")
 		}
 
 		return nil
@@ -200,14 +199,14 @@ func validateButtonStyle(ctx PanelValidationContext) validation.ValidationFunc {
 func validateButtonLabel(ctx PanelValidationContext) validation.ValidationFunc {
 	return func() error {
 		if len(ctx.Data.ButtonLabel) > 80 {
-			return validation.NewInvalidInputError("Button label must be less than 80 characters")
+			return validation.NewFieldError("/button_label", validation.CodeTooLong, "Button label must be less than 80 characters")
 		}
 
 		return nil
 	}
 }
 
-func validatedNullableFormId(guildId uint64, formId *int) validation.ValidationFunc {
+func validatedNullableFormId(pointer string, guildId uint64, formId *int) validation.ValidationFunc {
 	return func() error {
 		if formId == nil {
 			return nil
@@ -219,11 +218,11 @@ func validatedNullableFormId(guildId uint64, formId *int) validation.ValidationF
 		}
 
 		if !ok {
-			return validation.NewInvalidInputError("Form not found")
+			return validation.NewFieldError(pointer, validation.CodeNotFound, "Form not found")
 		}
 
 		if form.GuildId != guildId {
-			return validation.NewInvalidInputError("Guild ID mismatch when validating form")
+			return validation.NewFieldError(pointer, validation.CodeNotFound, "Guild ID mismatch when validating form")
 		}
 
 		return nil
@@ -231,11 +230,11 @@ func validatedNullableFormId(guildId uint64, formId *int) validation.ValidationF
 }
 
 func validateFormId(ctx PanelValidationContext) validation.ValidationFunc {
-	return validatedNullableFormId(ctx.GuildId, ctx.Data.FormId)
+	return validatedNullableFormId("/form_id", ctx.GuildId, ctx.Data.FormId)
 }
 
 func validateExitSurveyFormId(ctx PanelValidationContext) validation.ValidationFunc {
-	return validatedNullableFormId(ctx.GuildId, ctx.Data.ExitSurveyFormId)
+	return validatedNullableFormId("/exit_survey_form_id", ctx.GuildId, ctx.Data.ExitSurveyFormId)
 }
 
 func validatePendingCategory(ctx PanelValidationContext) validation.ValidationFunc {
@@ -250,7 +249,7 @@ func validatePendingCategory(ctx PanelValidationContext) validation.ValidationFu
 			}
 		}
 
-		return validation.NewInvalidInputError("Invalid awaiting response category")
+		return validation.NewFieldError("/pending_category", validation.CodeNotFound, "Invalid awaiting response category")
 	}
 }
 
@@ -268,7 +267,7 @@ func validateTeams(ctx PanelValidationContext) validation.ValidationFunc {
 		}
 
 		if !ok {
-			return validation.NewInvalidInputError("Invalid support team")
+			return validation.NewFieldError("/teams", validation.CodeNotFound, "Invalid support team")
 		}
 
 		return nil
@@ -285,7 +284,7 @@ func validateNamingScheme(ctx PanelValidationContext) validation.ValidationFunc
 		}
 
 		if len(*ctx.Data.NamingScheme) > 100 {
-			return validation.NewInvalidInputError("Naming scheme must be less than 100 characters")
+			return validation.NewFieldError("/naming_scheme", validation.CodeTooLong, "Naming scheme must be less than 100 characters")
 		}
 
 		// Validate placeholders used
@@ -297,7 +296,7 @@ func validateNamingScheme(ctx PanelValidationContext) validation.ValidationFunc
 
 			placeholder := match[1]
 			if !utils.Contains(validPlaceholders, placeholder) {
-				return validation.NewInvalidInputError(fmt.Sprintf("Invalid naming scheme placeholder: %s", placeholder))
+				return validation.NewFieldErrorf("/naming_scheme", validation.CodeInvalid, "Invalid naming scheme placeholder: %s", placeholder)
 			}
 		}
 
@@ -307,7 +306,7 @@ func validateNamingScheme(ctx PanelValidationContext) validation.ValidationFunc
 
 func validateWelcomeMessage(ctx PanelValidationContext) validation.ValidationFunc {
 	return func() error {
-		return validateEmbed(ctx.Data.WelcomeMessage)
+		return validation.WithPrefix(ValidateEmbed(ctx.Data.WelcomeMessage), "/welcome_message")
 	}
 }
 
@@ -316,46 +315,46 @@ func validateAccessControlList(ctx PanelValidationContext) validation.Validation
 		acl := ctx.Data.AccessControlList
 
 		if len(acl) == 0 {
-			return validation.NewInvalidInputError("Access control list is empty")
+			return validation.NewFieldError("/access_control_list", validation.CodeRequired, "Access control list is empty")
 		}
 
 		if len(acl) > 10 {
-			return validation.NewInvalidInputError("Access control list cannot have more than 10 roles")
+			return validation.NewFieldError("/access_control_list", validation.CodeTooLong, "Access control list cannot have more than 10 roles")
 		}
 
 		roles := utils.ToSet(utils.Map(ctx.Roles, utils.RoleToId))
 
 		if roles.Size() != len(ctx.Roles) {
-			return validation.NewInvalidInputError("Duplicate roles in access control list")
+			return validation.NewFieldError("/access_control_list", validation.CodeDuplicate, "Duplicate roles in access control list")
 		}
 
 		everyoneRoleFound := false
-		for _, rule := range acl {
+		for i, rule := range acl {
 			if rule.RoleId == ctx.GuildId {
 				everyoneRoleFound = true
 			}
 
 			if rule.Action != database.AccessControlActionDeny && rule.Action != database.AccessControlActionAllow {
-				return validation.NewInvalidInputErrorf("Invalid access control action \"%s\"", rule.Action)
+				return validation.NewFieldErrorf(validation.IndexPointer("/access_control_list", i)+"/action", validation.CodeInvalid, "Invalid access control action \"%s\"", rule.Action)
 			}
 
 			if !roles.Contains(rule.RoleId) {
-				return validation.NewInvalidInputErrorf("Invalid role %d in access control list not found in the guild", rule.RoleId)
+				return validation.NewFieldErrorf(validation.IndexPointer("/access_control_list", i)+"/role_id", validation.CodeNotFound, "Invalid role %d in access control list not found in the guild", rule.RoleId)
 			}
 		}
 
 		if !everyoneRoleFound {
-			return validation.NewInvalidInputError("Access control list does not contain @everyone rule")
+			return validation.NewFieldError("/access_control_list", validation.CodeRequired, "Access control list does not contain @everyone rule")
 		}
 
 		return nil
 	}
 }
 
-func validateEmbed(e *types.CustomEmbed) error {
+func ValidateEmbed(e *types.CustomEmbed) error {
 	if e == nil || e.Title != nil || e.Description != nil || len(e.Fields) > 0 || e.ImageUrl != nil || e.ThumbnailUrl != nil {
 		return nil
 	}
 
-	return validation.NewInvalidInputError("Your embed message does not contain any content")
+	return validation.NewFieldError("", validation.CodeRequired, "Your embed message does not contain any content")
 }
@@ -45,6 +45,36 @@ type (
 func GetSettingsHandler(ctx *gin.Context) {
 	guildId := ctx.Keys["guildid"].(uint64)
 
+	settings, err := GetSettings(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	// short_code -> local_name
+	type MinimalLocale struct {
+		IsoShortCode string `json:"iso_short_code"`
+		LocalName    string `json:"local_name"`
+	}
+
+	locales := make([]MinimalLocale, len(i18n.Locales))
+	for i, locale := range i18n.Locales {
+		locales[i] = MinimalLocale{
+			IsoShortCode: locale.IsoShortCode,
+			LocalName:    locale.LocalName,
+		}
+	}
+
+	ctx.JSON(200, struct {
+		Settings
+		Locales []MinimalLocale `json:"locales"`
+	}{
+		Settings: settings,
+		Locales:  locales,
+	})
+}
+
+func GetSettings(ctx context.Context, guildId uint64) (Settings, error) {
 	var settings Settings
 
 	group, _ := errgroup.WithContext(context.Background())
@@ -155,31 +185,10 @@ func GetSettingsHandler(ctx *gin.Context) {
 	})
 
 	if err := group.Wait(); err != nil {
-		ctx.JSON(500, utils.ErrorJson(err))
-		return
+		return Settings{}, err
 	}
 
-	// short_code -> local_name
-	type MinimalLocale struct {
-		IsoShortCode string `json:"iso_short_code"`
-		LocalName    string `json:"local_name"`
-	}
-
-	locales := make([]MinimalLocale, len(i18n.Locales))
-	for i, locale := range i18n.Locales {
-		locales[i] = MinimalLocale{
-			IsoShortCode: locale.IsoShortCode,
-			LocalName:    locale.LocalName,
-		}
-	}
-
-	ctx.JSON(200, struct {
-		Settings
-		Locales []MinimalLocale `json:"locales"`
-	}{
-		Settings: settings,
-		Locales:  locales,
-	})
+	return settings, nil
 }
 
 func getColourMap(guildId uint64) (ColourMap, error) {
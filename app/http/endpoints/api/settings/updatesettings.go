@@ -2,11 +2,14 @@ package api
 
 import (
 	"context"
-	"errors"
-	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/dryrun"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/rpc/cache"
@@ -42,24 +45,56 @@ func UpdateSettingsHandler(ctx *gin.Context) {
 	}
 
 	if err := settings.Validate(ctx, guildId); err != nil {
-		ctx.JSON(400, utils.ErrorJson(err))
+		if !validation.RespondError(ctx, err) {
+			ctx.JSON(500, utils.ErrorJson(err))
+		}
+
+		return
+	}
+
+	existing, err := GetSettings(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
 		return
 	}
 
+	audit.SetBefore(ctx, existing)
+	audit.SetAfter(ctx, settings)
+
+	if dryrun.Requested(ctx) {
+		dryrun.Respond(ctx, existing, settings)
+		return
+	}
+
+	ctx.JSON(200, settings.Save(ctx, guildId, channels))
+}
+
+// SaveResult reports which of the individually checked settings were saved
+type SaveResult struct {
+	WelcomeMessage bool    `json:"welcome_message"`
+	TicketLimit    bool    `json:"ticket_limit"`
+	ArchiveChannel bool    `json:"archive_channel"`
+	Category       bool    `json:"category"`
+	NamingScheme   bool    `json:"naming_scheme"`
+	Error          *string `json:"error"`
+}
+
+// Save writes the settings to the database. Settings must be validated before calling this function.
+func (s *Settings) Save(ctx context.Context, guildId uint64, channels []channel.Channel) SaveResult {
 	group, _ := errgroup.WithContext(context.Background())
 
 	group.Go(func() error {
-		return settings.updateSettings(ctx, guildId)
+		return s.updateSettings(ctx, guildId)
 	})
 
 	group.Go(func() error {
-		return settings.updateClaimSettings(ctx, guildId)
+		return s.updateClaimSettings(ctx, guildId)
 	})
 
-	addToWaitGroup(group, guildId, settings.updateTicketPermissions)
-	addToWaitGroup(group, guildId, settings.updateLanguage)
-	addToWaitGroup(group, guildId, settings.updateAutoClose)
-	addToWaitGroup(group, guildId, settings.updateColours)
+	addToWaitGroup(group, guildId, s.updateTicketPermissions)
+	addToWaitGroup(group, guildId, s.updateLanguage)
+	addToWaitGroup(group, guildId, s.updateAutoClose)
+	addToWaitGroup(group, guildId, s.updateColours)
 
 	// TODO: Errors
 	var errStr *string = nil
@@ -67,23 +102,110 @@ func UpdateSettingsHandler(ctx *gin.Context) {
 		errStr = utils.Ptr(err.Error())
 	}
 
-	validWelcomeMessage := settings.updateWelcomeMessage(guildId)
-	validTicketLimit := settings.updateTicketLimit(guildId)
-	validArchiveChannel := settings.updateArchiveChannel(channels, guildId)
-	validCategory := settings.updateCategory(channels, guildId)
-	validNamingScheme := settings.updateNamingScheme(guildId)
-	settings.updateUsersCanClose(guildId)
-	settings.updateCloseConfirmation(guildId)
-	settings.updateFeedbackEnabled(guildId)
-
-	ctx.JSON(200, gin.H{
-		"welcome_message": validWelcomeMessage,
-		"ticket_limit":    validTicketLimit,
-		"archive_channel": validArchiveChannel,
-		"category":        validCategory,
-		"naming_scheme":   validNamingScheme,
-		"error":           errStr,
-	})
+	result := SaveResult{
+		WelcomeMessage: s.updateWelcomeMessage(guildId),
+		TicketLimit:    s.updateTicketLimit(guildId),
+		ArchiveChannel: s.updateArchiveChannel(channels, guildId),
+		Category:       s.updateCategory(channels, guildId),
+		NamingScheme:   s.updateNamingScheme(guildId),
+		Error:          errStr,
+	}
+
+	s.updateUsersCanClose(guildId)
+	s.updateCloseConfirmation(guildId)
+	s.updateFeedbackEnabled(guildId)
+
+	return result
+}
+
+// SaveTx writes the settings in the caller's transaction, for when they must be stored atomically with other changes,
+// such as during a config import. Unlike Save, any error writing a setting is returned. Settings must be validated
+// before calling this function.
+func (s *Settings) SaveTx(ctx context.Context, tx pgx.Tx, guildId uint64, channels []channel.Channel) (SaveResult, error) {
+	result := SaveResult{
+		WelcomeMessage: s.validWelcomeMessage(),
+		TicketLimit:    s.validTicketLimit(),
+		ArchiveChannel: s.ArchiveChannel == nil || s.validArchiveChannel(channels),
+		Category:       s.validCategory(channels),
+		NamingScheme:   utils.Exists(validScheme, s.NamingScheme),
+	}
+
+	converted := make(map[int16]int, len(s.Colours))
+	for colour, hex := range s.Colours {
+		converted[int16(colour)] = int(hex)
+	}
+
+	writes := []func() error{
+		func() error { return dbclient.TxClient.SetSettings(ctx, tx, guildId, s.Settings) },
+		func() error { return dbclient.TxClient.SetClaimSettings(ctx, tx, guildId, s.ClaimSettings) },
+		func() error { return dbclient.TxClient.SetTicketPermissions(ctx, tx, guildId, s.TicketPermissions) },
+		func() error { return dbclient.TxClient.SetActiveLanguage(ctx, tx, guildId, s.Language) },
+		func() error {
+			return dbclient.TxClient.SetAutoClose(ctx, tx, guildId, s.AutoCloseSettings.ConvertToDatabase())
+		},
+		func() error { return dbclient.TxClient.SetCustomColours(ctx, tx, guildId, converted) },
+		func() error { return dbclient.TxClient.SetUsersCanClose(ctx, tx, guildId, s.UsersCanClose) },
+		func() error { return dbclient.TxClient.SetCloseConfirmation(ctx, tx, guildId, s.CloseConfirmation) },
+		func() error { return dbclient.TxClient.SetFeedbackEnabled(ctx, tx, guildId, s.FeedbackEnabled) },
+	}
+
+	// As in Save, settings that fail their checks are left unchanged and reported in the result
+	if result.WelcomeMessage {
+		writes = append(writes, func() error { return dbclient.TxClient.SetWelcomeMessage(ctx, tx, guildId, s.WelcomeMessage) })
+	}
+
+	if result.TicketLimit {
+		writes = append(writes, func() error { return dbclient.TxClient.SetTicketLimit(ctx, tx, guildId, s.TicketLimit) })
+	}
+
+	if result.ArchiveChannel {
+		writes = append(writes, func() error { return dbclient.TxClient.SetArchiveChannel(ctx, tx, guildId, s.ArchiveChannel) })
+	}
+
+	if result.Category {
+		writes = append(writes, func() error { return dbclient.TxClient.SetChannelCategory(ctx, tx, guildId, s.Category) })
+	}
+
+	if result.NamingScheme {
+		writes = append(writes, func() error { return dbclient.TxClient.SetNamingScheme(ctx, tx, guildId, s.NamingScheme) })
+	}
+
+	// A transaction can only run one statement at a time, so the writes are not run concurrently as they are in Save
+	for _, write := range writes {
+		if err := write(); err != nil {
+			return SaveResult{}, err
+		}
+	}
+
+	return result, nil
+}
+
+func (s *Settings) validWelcomeMessage() bool {
+	return s.WelcomeMessage != "" && len(s.WelcomeMessage) <= 4096
+}
+
+func (s *Settings) validTicketLimit() bool {
+	return s.TicketLimit >= 1 && s.TicketLimit <= 10
+}
+
+func (s *Settings) validCategory(channels []channel.Channel) bool {
+	for _, ch := range channels {
+		if ch.Id == s.Category && ch.Type == channel.ChannelTypeGuildCategory {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Settings) validArchiveChannel(channels []channel.Channel) bool {
+	for _, ch := range channels {
+		if ch.Id == *s.ArchiveChannel && ch.Type == channel.ChannelTypeGuildText {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (s *Settings) updateSettings(ctx context.Context, guildId uint64) error {
@@ -99,14 +221,18 @@ var (
 	activeColours    = []customisation.Colour{customisation.Green, customisation.Red}
 )
 
+// Validate checks the settings, applying defaults where required. Every invalid field is collected into the returned
+// validation error.
 func (s *Settings) Validate(ctx context.Context, guildId uint64) error {
+	var errs []error
+
 	// Sync checks
 	if s.ClaimSettings.SupportCanType && !s.ClaimSettings.SupportCanView {
-		return errors.New("Must be able to view channel to type")
+		errs = append(errs, validation.NewFieldError("/claim_settings/support_can_type", validation.CodeInvalid, "Must be able to view channel to type"))
 	}
 
 	if s.Settings.UseThreads && s.TicketNotificationChannel == nil {
-		return errors.New("You must select a ticket notification channel")
+		errs = append(errs, validation.NewFieldError("/ticket_notification_channel", validation.CodeRequired, "You must select a ticket notification channel"))
 	}
 
 	if !s.Settings.UseThreads {
@@ -115,18 +241,18 @@ func (s *Settings) Validate(ctx context.Context, guildId uint64) error {
 
 	if s.Language != nil {
 		if _, ok := i18n.MappedByIsoShortCode[*s.Language]; !ok {
-			return errors.New("Invalid language")
+			errs = append(errs, validation.NewFieldError("/language", validation.CodeInvalid, "Invalid language"))
 		}
 	}
 
 	// Validate colours
 	if len(s.Colours) > len(activeColours) {
-		return errors.New("Invalid colour")
+		errs = append(errs, validation.NewFieldError("/colours", validation.CodeTooLong, "Invalid colour"))
 	}
 
 	for colour, _ := range s.Colours {
 		if !utils.Exists(activeColours, colour) {
-			return errors.New("Invalid colour")
+			errs = append(errs, validation.NewFieldError("/colours/"+strconv.Itoa(int(colour)), validation.CodeInvalid, "Invalid colour"))
 		}
 	}
 
@@ -153,93 +279,99 @@ func (s *Settings) Validate(ctx context.Context, guildId uint64) error {
 		s.AutoCloseSettings.SinceLastMessage = 0
 	}
 
-	if s.AutoCloseSettings.SinceLastMessage > int64((time.Hour*24*60).Seconds()) ||
-		s.AutoCloseSettings.SinceOpenWithNoResponse > int64((time.Hour*24*60).Seconds()) {
-		return errors.New("Autoclose time period cannot be longer than 60 days")
+	maxAutoCloseSeconds := int64((time.Hour * 24 * 60).Seconds())
+	if s.AutoCloseSettings.SinceLastMessage > maxAutoCloseSeconds {
+		errs = append(errs, validation.NewFieldError("/auto_close/since_last_message", validation.CodeTooLarge, "Autoclose time period cannot be longer than 60 days"))
 	}
 
-	// Async checks
-	group, _ := errgroup.WithContext(context.Background())
-
-	// Validate panel from same guild
-	group.Go(func() error {
-		if s.ContextMenuPanel != nil {
-			panelId := *s.ContextMenuPanel
-
-			panel, err := dbclient.Client.Panel.GetById(ctx, panelId)
-			if err != nil {
-				return err
-			}
-
-			if guildId != panel.GuildId {
-				return fmt.Errorf("guild ID doesn't match")
-			}
-		}
-
-		return nil
-	})
+	if s.AutoCloseSettings.SinceOpenWithNoResponse > maxAutoCloseSeconds {
+		errs = append(errs, validation.NewFieldError("/auto_close/since_open_with_no_response", validation.CodeTooLarge, "Autoclose time period cannot be longer than 60 days"))
+	}
 
-	group.Go(func() error {
-		valid := false
-		for _, duration := range validAutoArchive {
-			if duration == s.Settings.ThreadArchiveDuration {
-				valid = true
-				break
+	// Async checks
+	checks := []func() error{
+		// Validate panel from same guild
+		func() error {
+			if s.ContextMenuPanel != nil {
+				panelId := *s.ContextMenuPanel
+
+				panel, err := dbclient.Client.Panel.GetById(ctx, panelId)
+				if err != nil {
+					return err
+				}
+
+				if guildId != panel.GuildId {
+					return validation.NewFieldError("/context_menu_panel", validation.CodeNotFound, "Guild ID doesn't match")
+				}
 			}
-		}
-
-		if !valid {
-			return fmt.Errorf("Invalid thread auto archive duration")
-		}
-
-		return nil
-	})
-
-	group.Go(func() error {
-		if s.Settings.OverflowCategoryId != nil {
-			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-			defer cancel()
 
-			ch, err := cache.Instance.GetChannel(ctx, *s.Settings.OverflowCategoryId)
-			if err != nil {
-				return fmt.Errorf("Invalid overflow category")
+			return nil
+		},
+		func() error {
+			if !utils.Exists(validAutoArchive, s.Settings.ThreadArchiveDuration) {
+				return validation.NewFieldError("/thread_archive_duration", validation.CodeInvalid, "Invalid thread auto archive duration")
 			}
 
-			if ch.GuildId != guildId {
-				return fmt.Errorf("Overflow category guild ID does not match")
+			return nil
+		},
+		func() error {
+			if s.Settings.OverflowCategoryId != nil {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+				defer cancel()
+
+				ch, err := cache.Instance.GetChannel(ctx, *s.Settings.OverflowCategoryId)
+				if err != nil {
+					return validation.NewFieldError("/overflow_category_id", validation.CodeNotFound, "Invalid overflow category")
+				}
+
+				if ch.GuildId != guildId {
+					return validation.NewFieldError("/overflow_category_id", validation.CodeNotFound, "Overflow category guild ID does not match")
+				}
+
+				if ch.Type != channel.ChannelTypeGuildCategory {
+					return validation.NewFieldError("/overflow_category_id", validation.CodeInvalid, "Overflow category is not a category")
+				}
 			}
 
-			if ch.Type != channel.ChannelTypeGuildCategory {
-				return fmt.Errorf("Overflow category is not a category")
+			return nil
+		},
+		func() error {
+			if s.Settings.TicketNotificationChannel != nil {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+				defer cancel()
+
+				ch, err := cache.Instance.GetChannel(ctx, *s.Settings.TicketNotificationChannel)
+				if err != nil {
+					return validation.NewFieldError("/ticket_notification_channel", validation.CodeNotFound, "Invalid ticket notification channel")
+				}
+
+				if ch.GuildId != guildId {
+					return validation.NewFieldError("/ticket_notification_channel", validation.CodeNotFound, "Ticket notification channel guild ID does not match")
+				}
+
+				if ch.Type != channel.ChannelTypeGuildText {
+					return validation.NewFieldError("/ticket_notification_channel", validation.CodeInvalid, "Ticket notification channel is not a text channel")
+				}
 			}
-		}
-
-		return nil
-	})
 
-	group.Go(func() error {
-		if s.Settings.TicketNotificationChannel != nil {
-			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-			defer cancel()
-
-			ch, err := cache.Instance.GetChannel(ctx, *s.Settings.TicketNotificationChannel)
-			if err != nil {
-				return fmt.Errorf("Invalid ticket notification channel")
-			}
+			return nil
+		},
+	}
 
-			if ch.GuildId != guildId {
-				return fmt.Errorf("Ticket notification channel guild ID does not match")
-			}
+	group, _ := errgroup.WithContext(context.Background())
 
-			if ch.Type != channel.ChannelTypeGuildText {
-				return fmt.Errorf("Ticket notification channel is not a text channel")
-			}
-		}
+	results := make([]error, len(checks))
+	for i, check := range checks {
+		i, check := i, check
+		group.Go(func() error {
+			results[i] = check()
+			return nil
+		})
+	}
 
-		return nil
-	})
+	_ = group.Wait()
 
-	return group.Wait()
+	return validation.Join(append(errs, results...)...)
 }
 
 func addToWaitGroup(group *errgroup.Group, guildId uint64, f func(uint64) error) {
@@ -249,7 +381,7 @@ func addToWaitGroup(group *errgroup.Group, guildId uint64, f func(uint64) error)
 }
 
 func (s *Settings) updateWelcomeMessage(guildId uint64) bool {
-	if s.WelcomeMessage == "" || len(s.WelcomeMessage) > 4096 {
+	if !s.validWelcomeMessage() {
 		return false
 	}
 
@@ -258,7 +390,7 @@ func (s *Settings) updateWelcomeMessage(guildId uint64) bool {
 }
 
 func (s *Settings) updateTicketLimit(guildId uint64) bool {
-	if s.TicketLimit > 10 || s.TicketLimit < 1 {
+	if !s.validTicketLimit() {
 		return false
 	}
 
@@ -267,15 +399,7 @@ func (s *Settings) updateTicketLimit(guildId uint64) bool {
 }
 
 func (s *Settings) updateCategory(channels []channel.Channel, guildId uint64) bool {
-	var valid bool
-	for _, ch := range channels {
-		if ch.Id == s.Category && ch.Type == channel.ChannelTypeGuildCategory {
-			valid = true
-			break
-		}
-	}
-
-	if !valid {
+	if !s.validCategory(channels) {
 		return false
 	}
 
@@ -289,15 +413,7 @@ func (s *Settings) updateArchiveChannel(channels []channel.Channel, guildId uint
 		return true
 	}
 
-	var valid bool
-	for _, ch := range channels {
-		if ch.Id == *s.ArchiveChannel && ch.Type == channel.ChannelTypeGuildText {
-			valid = true
-			break
-		}
-	}
-
-	if !valid {
+	if !s.validArchiveChannel(channels) {
 		return false
 	}
 
@@ -308,15 +424,7 @@ func (s *Settings) updateArchiveChannel(channels []channel.Channel, guildId uint
 var validScheme = []database.NamingScheme{database.Id, database.Username}
 
 func (s *Settings) updateNamingScheme(guildId uint64) bool {
-	var valid bool
-	for _, scheme := range validScheme {
-		if scheme == s.NamingScheme {
-			valid = true
-			break
-		}
-	}
-
-	if !valid {
+	if !utils.Exists(validScheme, s.NamingScheme) {
 		return false
 	}
 
@@ -23,6 +23,10 @@ func CreateOverrideHandler(ctx *gin.Context) {
 		return
 	}
 
+	if !setOverrideBefore(ctx, guildId) {
+		return
+	}
+
 	expires := time.Now().Add(time.Hour * time.Duration(body.TimePeriod))
 	if err := database.Client.StaffOverride.Set(ctx, guildId, expires); err != nil {
 		ctx.JSON(500, utils.ErrorJson(err))
@@ -9,6 +9,10 @@ import (
 func DeleteOverrideHandler(ctx *gin.Context) {
 	guildId := ctx.Keys["guildid"].(uint64)
 
+	if !setOverrideBefore(ctx, guildId) {
+		return
+	}
+
 	if err := database.Client.StaffOverride.Delete(ctx, guildId); err != nil {
 		ctx.JSON(500, utils.ErrorJson(err))
 		return
@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 )
@@ -15,7 +16,24 @@ func GetOverrideHandler(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(200, gin.H{
-		"has_override": hasOverride,
+	ctx.JSON(200, overrideState{
+		HasOverride: hasOverride,
 	})
 }
+
+type overrideState struct {
+	HasOverride bool `json:"has_override"`
+}
+
+// setOverrideBefore records whether the guild had an active override in the audit log, returning false if a response
+// has already been written
+func setOverrideBefore(ctx *gin.Context, guildId uint64) bool {
+	hasOverride, err := database.Client.StaffOverride.HasActiveOverride(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return false
+	}
+
+	audit.SetBefore(ctx, overrideState{HasOverride: hasOverride})
+	return true
+}
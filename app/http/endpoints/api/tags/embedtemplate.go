@@ -0,0 +1,37 @@
+package api
+
+import (
+	"context"
+
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/placeholders"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+)
+
+// ValidateEmbedTemplate checks that an embed template used by tags only contains placeholders that tags can expand
+func ValidateEmbedTemplate(ctx context.Context, guildId uint64, embed *types.CustomEmbed) error {
+	formInputIds, err := getFormInputIds(ctx, guildId)
+	if err != nil {
+		return err
+	}
+
+	return placeholders.ValidateEmbed(embed, formInputIds)
+}
+
+// ApplyEmbedTemplate replaces the embed of the tag with an edited embed template, returning false if the tag no longer
+// exists. Tags are sent on demand, so there is no message to resend.
+func ApplyEmbedTemplate(ctx context.Context, guildId uint64, tagId string, embed *types.CustomEmbed) (bool, error) {
+	tag, ok, err := dbclient.Client.Tag.Get(ctx, guildId, tagId)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	dbEmbed, fields := embed.IntoDatabaseStruct()
+	tag.Embed = &database.CustomEmbedWithFields{
+		CustomEmbed: dbEmbed,
+		Fields:      fields,
+	}
+
+	return true, dbclient.Client.Tag.Set(ctx, tag)
+}
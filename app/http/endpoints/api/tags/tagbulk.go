@@ -0,0 +1,240 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/dryrun"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+)
+
+type (
+	importTagsBody struct {
+		Tags []Tag `json:"tags"`
+		// Overwrite replaces existing tags with the same ID. Otherwise, they are skipped.
+		Overwrite bool `json:"overwrite"`
+	}
+
+	importTagsResponse struct {
+		Imported []string `json:"imported"`
+		Skipped  []string `json:"skipped"`
+		Warnings []string `json:"warnings"`
+	}
+
+	bulkDeleteTagsBody struct {
+		TagIds []string `json:"tag_ids"`
+	}
+)
+
+// ExportTagsHandler returns all tags in the guild, in the format accepted by ImportTagsHandler
+func ExportTagsHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	tags, err := GetTags(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	ctx.JSON(200, gin.H{
+		"tags": tags,
+	})
+}
+
+func ImportTagsHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	var body importTagsBody
+	if err := ctx.BindJSON(&body); err != nil {
+		ctx.JSON(400, utils.ErrorJson(err))
+		return
+	}
+
+	if len(body.Tags) == 0 {
+		validation.RespondField(ctx, "/tags", validation.CodeRequired, "No tags provided")
+		return
+	}
+
+	if len(body.Tags) > MaxTags {
+		validation.RespondField(ctx, "/tags", validation.CodeTooLong, "You can only import up to %d tags at once", MaxTags)
+		return
+	}
+
+	existing, err := dbclient.Client.Tag.GetByGuild(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	formInputIds, err := getFormInputIds(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	res := importTagsResponse{
+		Imported: make([]string, 0),
+		Skipped:  make([]string, 0),
+		Warnings: make([]string, 0),
+	}
+
+	toImport := make([]Tag, 0, len(body.Tags))
+	var overwritten []Tag
+	seen := make(map[string]struct{})
+	count := len(existing)
+	var errs []error
+	for i := range body.Tags {
+		tag := &body.Tags[i]
+		tag.Normalise()
+
+		pointer := validation.IndexPointer("/tags", i)
+		if err := validation.Join(tag.resolveTemplate(ctx, guildId), tag.validate(formInputIds)); err != nil {
+			if _, ok := validation.Failures(err); !ok {
+				ctx.JSON(500, utils.ErrorJson(err))
+				return
+			}
+
+			errs = append(errs, validation.WithPrefix(err, pointer))
+			continue
+		}
+
+		if _, ok := seen[tag.Id]; ok {
+			errs = append(errs, validation.NewFieldErrorf(pointer+"/id", validation.CodeDuplicate, "Tag \"%s\" is included more than once", tag.Id))
+			continue
+		}
+
+		seen[tag.Id] = struct{}{}
+
+		if current, ok := existing[tag.Id]; ok {
+			if !body.Overwrite {
+				res.Skipped = append(res.Skipped, tag.Id)
+				continue
+			}
+
+			overwritten = append(overwritten, TagFromDatabase(current))
+		} else {
+			count++
+		}
+
+		toImport = append(toImport, *tag)
+	}
+
+	if count > MaxTags {
+		errs = append(errs, validation.NewFieldErrorf("/tags", validation.CodeLimitExceeded, "Tag limit (%d) reached", MaxTags))
+	}
+
+	if err := validation.Join(errs...); err != nil {
+		validation.RespondError(ctx, err)
+		return
+	}
+
+	if dryrun.Requested(ctx) {
+		res.Imported = utils.Map(toImport, func(tag Tag) string {
+			return tag.Id
+		})
+
+		dryrun.Respond(ctx, nil, res)
+		return
+	}
+
+	audit.SetBefore(ctx, overwritten)
+
+	botContext, err := botcontext.ContextForGuild(guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	for _, tag := range toImport {
+		if err := StoreTag(ctx, guildId, botContext, tag); err != nil {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("Failed to import tag \"%s\": %s", tag.Id, err.Error()))
+			continue
+		}
+
+		res.Imported = append(res.Imported, tag.Id)
+	}
+
+	ctx.JSON(200, res)
+}
+
+func BulkDeleteTagsHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	var body bulkDeleteTagsBody
+	if err := ctx.BindJSON(&body); err != nil {
+		ctx.JSON(400, utils.ErrorJson(err))
+		return
+	}
+
+	if len(body.TagIds) == 0 {
+		validation.RespondField(ctx, "/tag_ids", validation.CodeRequired, "No tags provided")
+		return
+	}
+
+	if len(body.TagIds) > MaxTags {
+		validation.RespondField(ctx, "/tag_ids", validation.CodeTooLong, "You can only delete up to %d tags at once", MaxTags)
+		return
+	}
+
+	tags, err := dbclient.Client.Tag.GetByGuild(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	tagIds := utils.Unique(utils.Map(body.TagIds, strings.ToLower))
+	for _, tagId := range tagIds {
+		if _, ok := tags[tagId]; !ok {
+			ctx.JSON(404, utils.ErrorStr("Tag \"%s\" not found", tagId))
+			return
+		}
+	}
+
+	deleted := utils.Map(tagIds, func(tagId string) Tag {
+		return TagFromDatabase(tags[tagId])
+	})
+
+	audit.SetBefore(ctx, deleted)
+
+	if dryrun.Requested(ctx) {
+		dryrun.Respond(ctx, deleted, nil)
+		return
+	}
+
+	botContext, err := botcontext.ContextForGuild(guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	for _, tagId := range tagIds {
+		if commandId := tags[tagId].ApplicationCommandId; commandId != nil {
+			if err := botContext.DeleteGuildCommand(ctx, guildId, *commandId); err != nil {
+				ctx.JSON(500, utils.ErrorJson(err))
+				return
+			}
+		}
+
+		if err := dbclient.Client.Tag.Delete(ctx, guildId, tagId); err != nil {
+			ctx.JSON(500, utils.ErrorJson(err))
+			return
+		}
+	}
+
+	if err := dbclient.Dashboard.TagMetadata.DeleteMulti(ctx, guildId, tagIds); err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if err := dbclient.Dashboard.EmbedTemplateReferences.DeleteMulti(ctx, guildId, dbclient.EmbedTemplateTargetTag, tagIds); err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	ctx.Status(204)
+}
@@ -1,15 +1,21 @@
 package api
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v4"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/dryrun"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/embedtemplates"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/placeholders"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
 	database "github.com/jadevelopmentgrp/Tickets-Database"
@@ -17,14 +23,18 @@ import (
 	"github.com/rxdn/gdl/rest"
 )
 
-type tag struct {
+type Tag struct {
 	Id              string             `json:"id" validate:"required,min=1,max=16"`
 	UseGuildCommand bool               `json:"use_guild_command"`
 	Content         *string            `json:"content" validate:"omitempty,min=1,max=4096"`
 	UseEmbed        bool               `json:"use_embed"`
 	Embed           *types.CustomEmbed `json:"embed" validate:"omitempty,dive"`
+	Category        *string            `json:"category" validate:"omitempty,min=1,max=32"`
+	EmbedTemplateId *int               `json:"embed_template_id"`
 }
 
+const MaxTags = 200
+
 var (
 	validate          = validator.New()
 	slashCommandRegex = regexp.MustCompile(`^[-_a-zA-Z0-9]{1,32}$`)
@@ -33,102 +43,193 @@ var (
 func CreateTag(ctx *gin.Context) {
 	guildId := ctx.Keys["guildid"].(uint64)
 
-	// Max of 200 tags
 	count, err := dbclient.Client.Tag.GetTagCount(ctx, guildId)
 	if err != nil {
 		ctx.JSON(500, utils.ErrorJson(err))
 		return
 	}
 
-	if count >= 200 {
-		ctx.JSON(400, utils.ErrorStr("Tag limit (200) reached"))
+	if count >= MaxTags {
+		validation.RespondField(ctx, "", validation.CodeLimitExceeded, "Tag limit (%d) reached", MaxTags)
 		return
 	}
 
-	var data tag
+	var data Tag
 	if err := ctx.BindJSON(&data); err != nil {
 		ctx.JSON(400, utils.ErrorJson(err))
 		return
 	}
 
-	data.Id = strings.ToLower(data.Id)
+	data.Normalise()
+
+	if err := data.resolveTemplate(ctx, guildId); err != nil {
+		if !validation.RespondError(ctx, err) {
+			ctx.JSON(500, utils.ErrorJson(err))
+		}
+
+		return
+	}
 
-	if !data.UseEmbed {
-		data.Embed = nil
+	formInputIds, err := getFormInputIds(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
 	}
 
 	// TODO: Limit command amount
-	if err := validate.Struct(data); err != nil {
-		var validationErrors validator.ValidationErrors
-		if ok := errors.As(err, &validationErrors); !ok {
-			ctx.JSON(500, utils.ErrorStr("An error occurred while validating the integration"))
-			return
+	if err := data.validate(formInputIds); err != nil {
+		if !validation.RespondError(ctx, err) {
+			ctx.JSON(500, utils.ErrorStr("An error occurred while validating the tag"))
 		}
 
-		formatted := "Your input contained the following errors:\n" + utils.FormatValidationErrors(validationErrors)
-		ctx.JSON(400, utils.ErrorStr(formatted))
 		return
 	}
 
-	if !data.verifyId() {
-		ctx.JSON(400, utils.ErrorStr("Tag IDs must be alphanumeric (including hyphens and underscores), and be between 1 and 16 characters long"))
+	existing, ok, err := dbclient.Client.Tag.Get(ctx, guildId, data.Id)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
 		return
 	}
 
-	if !data.verifyContent() {
-		ctx.JSON(400, utils.ErrorStr("You have not provided any content for the tag"))
+	// Creating a tag with an existing ID overwrites it
+	var before *Tag
+	if ok {
+		before = utils.Ptr(TagFromDatabase(existing))
+	}
+
+	if dryrun.Requested(ctx) {
+		dryrun.Respond(ctx, before, data)
 		return
 	}
 
+	audit.SetBefore(ctx, before)
+
 	botContext, err := botcontext.ContextForGuild(guildId)
 	if err != nil {
 		ctx.JSON(500, utils.ErrorJson(err))
 		return
 	}
 
-	var embed *database.CustomEmbedWithFields
-	if data.Embed != nil {
-		customEmbed, fields := data.Embed.IntoDatabaseStruct()
-		embed = &database.CustomEmbedWithFields{
-			CustomEmbed: customEmbed,
-			Fields:      fields,
-		}
+	if err := StoreTag(ctx, guildId, botContext, data); err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
 	}
 
-	var applicationCommandId *uint64
-	if data.UseGuildCommand {
-		cmd, err := botContext.CreateGuildCommand(ctx, guildId, rest.CreateCommandData{
-			Name:        data.Id,
-			Description: fmt.Sprintf("Alias for /tag %s", data.Id),
-			Options:     nil,
-			Type:        interaction.ApplicationCommandTypeChatInput,
-		})
+	ctx.Status(204)
+}
 
+// StoreTag creates or overwrites the tag, registering a guild command for it if requested. The tag must already have
+// been validated.
+func StoreTag(ctx context.Context, guildId uint64, botContext *botcontext.BotContext, tag Tag) error {
+	var applicationCommandId *uint64
+	if tag.UseGuildCommand {
+		commandId, err := createTagCommand(ctx, guildId, botContext, tag.Id)
 		if err != nil {
-			ctx.JSON(500, utils.ErrorJson(err))
-			return
+			return err
 		}
 
-		applicationCommandId = &cmd.Id
+		applicationCommandId = &commandId
+	}
+
+	if err := dbclient.Client.Tag.Set(ctx, tag.intoDatabase(guildId, applicationCommandId)); err != nil {
+		return err
+	}
+
+	if err := dbclient.Dashboard.TagMetadata.SetCategory(ctx, guildId, tag.Id, tag.Category); err != nil {
+		return err
+	}
+
+	return dbclient.Dashboard.EmbedTemplateReferences.Set(ctx, guildId, dbclient.EmbedTemplateTargetTag, tag.Id, tag.EmbedTemplateId)
+}
+
+// StoreTagTx creates or overwrites the tag in the caller's transaction. Guild commands cannot be registered as part of
+// a transaction, so RegisterTagCommand must be called once it has been committed. The tag must already have been
+// validated.
+func StoreTagTx(ctx context.Context, tx pgx.Tx, guildId uint64, tag Tag) error {
+	if err := dbclient.TxClient.SetTag(ctx, tx, tag.intoDatabase(guildId, nil)); err != nil {
+		return err
 	}
 
-	wrapped := database.Tag{
-		Id:                   data.Id,
+	if err := dbclient.Dashboard.TagMetadata.SetCategoryTx(ctx, tx, guildId, tag.Id, tag.Category); err != nil {
+		return err
+	}
+
+	return dbclient.Dashboard.EmbedTemplateReferences.SetTx(ctx, tx, guildId, dbclient.EmbedTemplateTargetTag, tag.Id, tag.EmbedTemplateId)
+}
+
+// RegisterTagCommand registers the guild command for a tag stored by StoreTagTx, if it uses one
+func RegisterTagCommand(ctx context.Context, guildId uint64, botContext *botcontext.BotContext, tag Tag) error {
+	if !tag.UseGuildCommand {
+		return nil
+	}
+
+	commandId, err := createTagCommand(ctx, guildId, botContext, tag.Id)
+	if err != nil {
+		return err
+	}
+
+	return dbclient.TxClient.SetTagCommandId(ctx, guildId, tag.Id, commandId)
+}
+
+func createTagCommand(ctx context.Context, guildId uint64, botContext *botcontext.BotContext, tagId string) (uint64, error) {
+	cmd, err := botContext.CreateGuildCommand(ctx, guildId, rest.CreateCommandData{
+		Name:        tagId,
+		Description: fmt.Sprintf("Alias for /tag %s", tagId),
+		Options:     nil,
+		Type:        interaction.ApplicationCommandTypeChatInput,
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return cmd.Id, nil
+}
+
+func (t *Tag) intoDatabase(guildId uint64, applicationCommandId *uint64) database.Tag {
+	var embed *database.CustomEmbedWithFields
+	if t.Embed != nil {
+		customEmbed, fields := t.Embed.IntoDatabaseStruct()
+		embed = &database.CustomEmbedWithFields{
+			CustomEmbed: customEmbed,
+			Fields:      fields,
+		}
+	}
+
+	return database.Tag{
+		Id:                   t.Id,
 		GuildId:              guildId,
-		Content:              data.Content,
+		Content:              t.Content,
 		Embed:                embed,
 		ApplicationCommandId: applicationCommandId,
 	}
+}
 
-	if err := dbclient.Client.Tag.Set(ctx, wrapped); err != nil {
-		ctx.JSON(500, utils.ErrorJson(err))
-		return
+// Normalise lower-cases the ID and clears fields that the tag does not use
+func (t *Tag) Normalise() {
+	t.Id = strings.ToLower(t.Id)
+
+	if !t.UseEmbed {
+		t.Embed = nil
+		t.EmbedTemplateId = nil
 	}
 
-	ctx.Status(204)
+	if t.Category != nil {
+		if category := strings.TrimSpace(*t.Category); category == "" {
+			t.Category = nil
+		} else {
+			t.Category = &category
+		}
+	}
 }
 
-func (t *tag) verifyId() bool {
+// resolveTemplate replaces the embed with the embed template that the tag references, if any. The template takes
+// precedence over an embed submitted alongside it.
+func (t *Tag) resolveTemplate(ctx context.Context, guildId uint64) error {
+	return embedtemplates.Resolve(ctx, guildId, t.EmbedTemplateId, &t.Embed, "/embed_template_id")
+}
+
+func (t *Tag) verifyId() bool {
 	if len(t.Id) == 0 || len(t.Id) > 16 || strings.Contains(t.Id, " ") {
 		return false
 	}
@@ -140,7 +241,7 @@ func (t *tag) verifyId() bool {
 	}
 }
 
-func (t *tag) verifyContent() bool {
+func (t *Tag) verifyContent() bool {
 	if t.Content != nil { // validator ensures that if this is not nil, > 0 length
 		return true
 	}
@@ -153,3 +254,57 @@ func (t *tag) verifyContent() bool {
 
 	return false
 }
+
+// Validate runs the same checks as CreateTag, for callers that create tags in bulk. Forms may be created alongside the
+// tag, so only the placeholder names can be checked here.
+func (t *Tag) Validate() error {
+	return t.validate(nil)
+}
+
+// validate checks the tag, collecting every invalid field. If formInputIds is nil, form placeholders are not checked
+// against the guild's forms.
+func (t *Tag) validate(formInputIds map[string]struct{}) error {
+	errs := []error{validation.Struct(validate, t)}
+
+	if !t.verifyId() {
+		errs = append(errs, validation.NewFieldError("/id", validation.CodeInvalid, "Tag IDs must be alphanumeric (including hyphens and underscores), and be between 1 and 16 characters long"))
+	}
+
+	if !t.verifyContent() {
+		errs = append(errs, validation.NewFieldError("", validation.CodeRequired, "You have not provided any content for the tag"))
+	}
+
+	return validation.Join(append(errs, t.verifyPlaceholders(formInputIds))...)
+}
+
+// verifyPlaceholders checks that the tag only uses placeholders that can be expanded when it is sent. If formInputIds
+// is nil, form placeholders are not checked against the guild's forms.
+func (t *Tag) verifyPlaceholders(formInputIds map[string]struct{}) error {
+	var errs []error
+	if t.Content != nil {
+		errs = append(errs, validation.WithPrefix(placeholders.Validate(*t.Content, formInputIds), "/content"))
+	}
+
+	if t.Embed != nil {
+		errs = append(errs, validation.WithPrefix(placeholders.ValidateEmbed(t.Embed, formInputIds), "/embed"))
+	}
+
+	return validation.Join(errs...)
+}
+
+// getFormInputIds returns the custom IDs of the inputs of all forms in the guild
+func getFormInputIds(ctx context.Context, guildId uint64) (map[string]struct{}, error) {
+	inputs, err := dbclient.Client.FormInput.GetInputsForGuild(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]struct{})
+	for _, formInputs := range inputs {
+		for _, input := range formInputs {
+			ids[input.CustomId] = struct{}{}
+		}
+	}
+
+	return ids, nil
+}
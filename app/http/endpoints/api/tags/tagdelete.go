@@ -2,6 +2,9 @@ package api
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/dryrun"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
@@ -22,7 +25,7 @@ func DeleteTag(ctx *gin.Context) {
 
 	// Increase max length for characters from other alphabets
 	if body.TagId == "" || len(body.TagId) > 100 {
-		ctx.JSON(400, utils.ErrorStr("Invalid tag"))
+		validation.RespondField(ctx, "/tag_id", validation.CodeInvalid, "Invalid tag")
 		return
 	}
 
@@ -38,6 +41,13 @@ func DeleteTag(ctx *gin.Context) {
 		return
 	}
 
+	if dryrun.Requested(ctx) {
+		dryrun.Respond(ctx, TagFromDatabase(tag), nil)
+		return
+	}
+
+	audit.SetBefore(ctx, TagFromDatabase(tag))
+
 	if tag.ApplicationCommandId != nil {
 		botContext, err := botcontext.ContextForGuild(guildId)
 		if err != nil {
@@ -56,5 +66,15 @@ func DeleteTag(ctx *gin.Context) {
 		return
 	}
 
+	if err := database.Dashboard.TagMetadata.Delete(ctx, guildId, body.TagId); err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if err := database.Dashboard.EmbedTemplateReferences.Delete(ctx, guildId, database.EmbedTemplateTargetTag, body.TagId); err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
 	ctx.Status(204)
 }
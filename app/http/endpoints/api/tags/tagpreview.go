@@ -0,0 +1,91 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	api_ticket "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/ticket"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/rxdn/gdl/objects/channel/embed"
+)
+
+type (
+	previewBody struct {
+		TicketId int `json:"ticket_id" binding:"required"`
+	}
+
+	previewResponse struct {
+		Content string         `json:"content"`
+		Embeds  []*embed.Embed `json:"embeds"`
+	}
+)
+
+// PreviewTagHandler renders the tag as it would be sent to the given ticket, without sending it
+func PreviewTagHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+	userId := ctx.Keys["userid"].(uint64)
+
+	// POST is only used so that we can take a body, nothing is modified
+	audit.Skip(ctx)
+
+	var body previewBody
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(400, utils.ErrorStr("Invalid request body"))
+		return
+	}
+
+	tag, ok, err := dbclient.Client.Tag.Get(ctx, guildId, ctx.Param("tag"))
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if !ok {
+		ctx.JSON(404, utils.ErrorStr("Tag not found"))
+		return
+	}
+
+	ticket, err := dbclient.Client.Tickets.Get(ctx, body.TicketId, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if ticket.UserId == 0 || ticket.GuildId != guildId {
+		ctx.JSON(404, utils.ErrorStr("Ticket not found"))
+		return
+	}
+
+	hasPermission, requestErr := utils.HasPermissionToViewTicket(ctx, guildId, userId, ticket)
+	if requestErr != nil {
+		ctx.JSON(requestErr.StatusCode, utils.ErrorJson(requestErr))
+		return
+	}
+
+	if !hasPermission {
+		ctx.JSON(403, utils.ErrorStr("You do not have permission to view this ticket"))
+		return
+	}
+
+	botContext, err := botcontext.ContextForGuild(guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	content, embeds, err := api_ticket.RenderTag(ctx, botContext, ticket, tag)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if embeds == nil {
+		embeds = []*embed.Embed{}
+	}
+
+	ctx.JSON(200, previewResponse{
+		Content: content,
+		Embeds:  embeds,
+	})
+}
@@ -1,36 +1,115 @@
 package api
 
 import (
+	"context"
+	"sort"
+	"time"
+
 	"github.com/gin-gonic/gin"
-	"github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
 )
 
+type tagResponse struct {
+	Tag
+	Uses       int        `json:"uses"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
 func TagsListHandler(ctx *gin.Context) {
 	guildId := ctx.Keys["guildid"].(uint64)
 
-	tags, err := database.Client.Tag.GetByGuild(ctx, guildId)
+	tags, err := dbclient.Client.Tag.GetByGuild(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	metadata, err := dbclient.Dashboard.TagMetadata.GetByGuild(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	templateIds, err := dbclient.Dashboard.EmbedTemplateReferences.GetByKind(ctx, guildId, dbclient.EmbedTemplateTargetTag)
 	if err != nil {
 		ctx.JSON(500, utils.ErrorJson(err))
 		return
 	}
 
-	wrapped := make(map[string]tag)
+	wrapped := make(map[string]tagResponse)
 	for id, data := range tags {
-		var embed *types.CustomEmbed
-		if data.Embed != nil {
-			embed = types.NewCustomEmbed(data.Embed.CustomEmbed, data.Embed.Fields)
-		}
+		tagMetadata := metadata[id]
+
+		tag := TagFromDatabase(data)
+		tag.Category = tagMetadata.Category
+		tag.EmbedTemplateId = templateIdPtr(templateIds, id)
 
-		wrapped[id] = tag{
-			Id:              data.Id,
-			UseGuildCommand: data.ApplicationCommandId != nil,
-			Content:         data.Content,
-			UseEmbed:        data.Embed != nil,
-			Embed:           embed,
+		wrapped[id] = tagResponse{
+			Tag:        tag,
+			Uses:       tagMetadata.Uses,
+			LastUsedAt: tagMetadata.LastUsedAt,
 		}
 	}
 
 	ctx.JSON(200, wrapped)
 }
+
+// GetTags returns all tags in the guild along with their categories and embed templates, sorted by ID
+func GetTags(ctx context.Context, guildId uint64) ([]Tag, error) {
+	tags, err := dbclient.Client.Tag.GetByGuild(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := dbclient.Dashboard.TagMetadata.GetByGuild(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	templateIds, err := dbclient.Dashboard.EmbedTemplateReferences.GetByKind(ctx, guildId, dbclient.EmbedTemplateTargetTag)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped := make([]Tag, 0, len(tags))
+	for id, data := range tags {
+		tag := TagFromDatabase(data)
+		tag.Category = metadata[id].Category
+		tag.EmbedTemplateId = templateIdPtr(templateIds, id)
+
+		wrapped = append(wrapped, tag)
+	}
+
+	// Tags are stored in a map, so sort them to keep the output stable
+	sort.Slice(wrapped, func(i, j int) bool {
+		return wrapped[i].Id < wrapped[j].Id
+	})
+
+	return wrapped, nil
+}
+
+func templateIdPtr(templateIds map[string]int, tagId string) *int {
+	if templateId, ok := templateIds[tagId]; ok {
+		return &templateId
+	}
+
+	return nil
+}
+
+func TagFromDatabase(data database.Tag) Tag {
+	var embed *types.CustomEmbed
+	if data.Embed != nil {
+		embed = types.NewCustomEmbed(data.Embed.CustomEmbed, data.Embed.Fields)
+	}
+
+	return Tag{
+		Id:              data.Id,
+		UseGuildCommand: data.ApplicationCommandId != nil,
+		Content:         data.Content,
+		UseEmbed:        data.Embed != nil,
+		Embed:           embed,
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	database "github.com/jadevelopmentgrp/Tickets-Database"
@@ -42,9 +43,12 @@ func CreateTeam(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(200, database.SupportTeam{
+	team := database.SupportTeam{
 		Id:      id,
 		GuildId: guildId,
 		Name:    data.Name,
-	})
+	}
+
+	audit.SetAfter(ctx, team)
+	ctx.JSON(200, team)
 }
@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	"strconv"
@@ -17,7 +18,7 @@ func DeleteTeam(ctx *gin.Context) {
 	}
 
 	// check team belongs to guild
-	exists, err := dbclient.Client.SupportTeam.Exists(ctx, teamId, guildId)
+	team, exists, err := dbclient.Client.SupportTeam.GetById(ctx, guildId, teamId)
 	if err != nil {
 		ctx.JSON(500, utils.ErrorJson(err))
 		return
@@ -28,6 +29,8 @@ func DeleteTeam(ctx *gin.Context) {
 		return
 	}
 
+	audit.SetBefore(ctx, team)
+
 	if err := dbclient.Client.SupportTeam.Delete(ctx, teamId); err != nil {
 		ctx.JSON(500, utils.ErrorJson(err))
 		return
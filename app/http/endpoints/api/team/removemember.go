@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
@@ -37,6 +38,8 @@ func RemoveMember(ctx *gin.Context) {
 		return
 	}
 
+	audit.SetBefore(ctx, entity{Id: snowflake, Type: entityType})
+
 	teamId := ctx.Param("teamid")
 	if teamId == "default" {
 		removeDefaultMember(ctx, guildId, selfId, snowflake, entityType)
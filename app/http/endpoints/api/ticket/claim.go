@@ -0,0 +1,224 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/redis"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+	"github.com/jadevelopmentgrp/Tickets-Utilities/permission"
+	"github.com/rxdn/gdl/rest/request"
+)
+
+type (
+	transferBody struct {
+		UserId uint64 `json:"user_id,string" binding:"required"`
+	}
+
+	claimResponse struct {
+		ClaimedBy *uint64 `json:"claimed_by,string"`
+	}
+
+	claimEventData struct {
+		ClaimedBy         *uint64 `json:"claimed_by,string"`
+		PreviousClaimedBy *uint64 `json:"previous_claimed_by,string"`
+	}
+)
+
+func ClaimTicket(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	ticket, claimer, ok := getClaimTicket(c, guildId, userId)
+	if !ok {
+		return
+	}
+
+	if claimer != 0 {
+		c.JSON(http.StatusConflict, utils.ErrorStr("This ticket has already been claimed"))
+		return
+	}
+
+	setClaim(c, ticket, claimer, &userId, userId)
+}
+
+func UnclaimTicket(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	ticket, claimer, ok := getClaimTicket(c, guildId, userId)
+	if !ok {
+		return
+	}
+
+	if claimer == 0 {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("This ticket is not claimed"))
+		return
+	}
+
+	if !canChangeClaim(c, guildId, userId, claimer) {
+		return
+	}
+
+	setClaim(c, ticket, claimer, nil, userId)
+}
+
+func TransferTicket(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	var body transferBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid request body"))
+		return
+	}
+
+	ticket, claimer, ok := getClaimTicket(c, guildId, userId)
+	if !ok {
+		return
+	}
+
+	if body.UserId == claimer {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("This ticket is already claimed by that user"))
+		return
+	}
+
+	if claimer != 0 && !canChangeClaim(c, guildId, userId, claimer) {
+		return
+	}
+
+	targetPermissionLevel, err := utils.GetPermissionLevel(c, guildId, body.UserId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	if targetPermissionLevel < permission.Support {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Tickets can only be transferred to staff members"))
+		return
+	}
+
+	setClaim(c, ticket, claimer, &body.UserId, userId)
+}
+
+// getClaimTicket returns the open ticket from the request path, and the ID of the user who has claimed it, or 0 if it
+// is unclaimed. If ok is false, a response has already been written.
+func getClaimTicket(c *gin.Context, guildId, userId uint64) (ticket database.Ticket, claimer uint64, ok bool) {
+	ticket, ok = getOpenTicket(c, guildId, userId)
+	if !ok {
+		return database.Ticket{}, 0, false
+	}
+
+	claimer, err := dbclient.Client.TicketClaims.Get(c, guildId, ticket.Id)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return database.Ticket{}, 0, false
+	}
+
+	return ticket, claimer, true
+}
+
+// canChangeClaim returns whether the user can unclaim or transfer a ticket claimed by claimer, which is limited to the
+// claimer themselves and admins, as in the bot. If false, a response has already been written.
+func canChangeClaim(c *gin.Context, guildId, userId, claimer uint64) bool {
+	if userId == claimer {
+		return true
+	}
+
+	permissionLevel, err := utils.GetPermissionLevel(c, guildId, userId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return false
+	}
+
+	if permissionLevel < permission.Admin {
+		c.JSON(http.StatusForbidden, utils.ErrorStr("Only the staff member who claimed this ticket, or an admin, can do this"))
+		return false
+	}
+
+	return true
+}
+
+// setClaim stores the new claimer, or removes the claim if claimer is nil, updates the ticket channel's permissions to
+// match the guild's claim settings, and publishes the change to webhooks and live chat. The worker reads claims from
+// ticket_claims when it needs them, so it sees the change without being notified.
+func setClaim(c *gin.Context, ticket database.Ticket, previousClaimer uint64, claimer *uint64, userId uint64) {
+	botContext, err := botcontext.ContextForGuild(ticket.GuildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	var previous *uint64
+	if previousClaimer != 0 {
+		previous = &previousClaimer
+	}
+
+	audit.SetBefore(c, claimResponse{ClaimedBy: previous})
+
+	// The claim may have changed since it was checked, e.g. if two staff members claim the ticket at the same time
+	swapped, err := dbclient.Dashboard.TicketClaims.Swap(c, ticket.GuildId, ticket.Id, previous, claimer)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	if !swapped {
+		c.JSON(http.StatusConflict, utils.ErrorStr("This ticket's claim has been changed by someone else, please try again"))
+		return
+	}
+
+	if err := updateClaimOverwrites(c, botContext, ticket, previous, claimer); err != nil {
+		// Keep the stored claim consistent with the channel
+		if _, rollbackErr := dbclient.Dashboard.TicketClaims.Swap(c, ticket.GuildId, ticket.Id, claimer, previous); rollbackErr != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(rollbackErr))
+			return
+		}
+
+		var restError request.RestError
+		if errors.As(err, &restError) && restError.StatusCode == http.StatusForbidden {
+			c.JSON(http.StatusBadRequest, utils.ErrorStr("I do not have permission to update the ticket channel's permissions"))
+			return
+		}
+
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Error updating the ticket channel's permissions"))
+		return
+	}
+
+	eventType := redis.TicketEventClaimed
+	if claimer == nil {
+		eventType = redis.TicketEventUnclaimed
+	}
+
+	data, err := json.Marshal(claimEventData{
+		ClaimedBy:         claimer,
+		PreviousClaimedBy: previous,
+	})
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	// The claim has already been made, so failing to publish the event should not fail the request
+	_ = redis.Client.PublishTicketEvent(c, redis.TicketEvent{
+		Type:     eventType,
+		GuildId:  ticket.GuildId,
+		TicketId: ticket.Id,
+		UserId:   userId,
+		Data:     data,
+	})
+
+	res := claimResponse{
+		ClaimedBy: claimer,
+	}
+
+	audit.SetAfter(c, res)
+	c.JSON(200, res)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/redis"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
@@ -58,6 +59,8 @@ func CloseTicket(c *gin.Context) {
 		return
 	}
 
+	audit.SetBefore(c, ticket)
+
 	data := closerelay.TicketClose{
 		GuildId:  guildId,
 		TicketId: ticket.Id,
@@ -77,10 +77,18 @@ func GetTicket(c *gin.Context) {
 		return
 	}
 
+	// Notes are only ever returned to staff, this route requires the support permission level
+	notes, err := dbclient.Dashboard.TicketNotes.GetByTicket(c, guildId, ticket.Id)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
 	c.JSON(200, gin.H{
 		"success":  true,
 		"ticket":   ticket,
 		"messages": messages,
+		"notes":    notes,
 	})
 }
 
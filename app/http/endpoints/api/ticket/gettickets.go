@@ -5,6 +5,9 @@ import (
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/rpc/cache"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/sla"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
 	"github.com/rxdn/gdl/objects/user"
 	"net/http"
 	"time"
@@ -12,20 +15,35 @@ import (
 
 type (
 	listTicketsResponse struct {
-		Tickets       []ticketData         `json:"tickets"`
-		PanelTitles   map[int]string       `json:"panel_titles"`
-		ResolvedUsers map[uint64]user.User `json:"resolved_users"`
-		SelfId        uint64               `json:"self_id,string"`
+		Tickets       []ticketData              `json:"tickets"`
+		PanelTitles   map[int]string            `json:"panel_titles"`
+		ResolvedUsers map[uint64]user.User      `json:"resolved_users"`
+		SelfId        uint64                    `json:"self_id,string"`
+		NextCursor    *string                   `json:"next_cursor,omitempty"`
+		Labels        map[int]types.TicketLabel `json:"labels"`
 	}
 
 	ticketData struct {
-		TicketId            int        `json:"id"`
-		PanelId             *int       `json:"panel_id"`
-		UserId              uint64     `json:"user_id,string"`
-		ClaimedBy           *uint64    `json:"claimed_by,string"`
-		OpenedAt            time.Time  `json:"opened_at"`
-		LastResponseTime    *time.Time `json:"last_response_time"`
-		LastResponseIsStaff *bool      `json:"last_response_is_staff"`
+		TicketId            int                      `json:"id"`
+		PanelId             *int                     `json:"panel_id"`
+		UserId              uint64                   `json:"user_id,string"`
+		ClaimedBy           *uint64                  `json:"claimed_by,string"`
+		OpenedAt            time.Time                `json:"opened_at"`
+		LastResponseTime    *time.Time               `json:"last_response_time"`
+		LastResponseIsStaff *bool                    `json:"last_response_is_staff"`
+		LabelIds            []int                    `json:"label_ids"`
+		Priority            *database.TicketPriority `json:"priority"`
+		Sla                 *ticketSlaData           `json:"sla"`
+	}
+
+	ticketSlaData struct {
+		FirstResponse *slaTargetData `json:"first_response"`
+		Resolution    *slaTargetData `json:"resolution"`
+	}
+
+	slaTargetData struct {
+		Status database.SlaStatus `json:"status"`
+		DueAt  time.Time          `json:"due_at"`
 	}
 )
 
@@ -33,12 +51,59 @@ func GetTickets(c *gin.Context) {
 	userId := c.Keys["userid"].(uint64)
 	guildId := c.Keys["guildid"].(uint64)
 
+	var query ticketListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(400, utils.ErrorJson(err))
+		return
+	}
+
+	if err := query.validate(); err != nil {
+		c.JSON(400, utils.ErrorJson(err))
+		return
+	}
+
 	tickets, err := database.Client.Tickets.GetGuildOpenTicketsWithMetadata(c, guildId)
 	if err != nil {
 		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
 		return
 	}
 
+	ticketIds := make([]int, len(tickets))
+	for i, ticket := range tickets {
+		ticketIds[i] = ticket.Id
+	}
+
+	var attributes ticketAttributes
+	attributes.labels, err = database.Dashboard.TicketLabelAssignments.GetMulti(c, guildId, ticketIds)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	attributes.priorities, err = database.Dashboard.TicketPriorities.GetMulti(c, guildId, ticketIds)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	attributes.sla, err = database.Dashboard.TicketSla.GetMulti(c, guildId, ticketIds, time.Now().Add(-sla.StateTtl))
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	labels, err := database.Dashboard.TicketLabels.GetByGuild(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	tickets, nextCursor, err := query.apply(tickets, attributes)
+	if err != nil {
+		c.JSON(400, utils.ErrorJson(err))
+		return
+	}
+
 	panels, err := database.Client.Panel.GetByGuild(c, guildId)
 	if err != nil {
 		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
@@ -76,6 +141,22 @@ func GetTickets(c *gin.Context) {
 			OpenedAt:            ticket.OpenTime,
 			LastResponseTime:    ticket.LastMessageTime,
 			LastResponseIsStaff: ticket.UserIsStaff,
+			LabelIds:            attributes.labels[ticket.Id],
+		}
+
+		if data[i].LabelIds == nil {
+			data[i].LabelIds = []int{}
+		}
+
+		if priority, ok := attributes.priorities[ticket.Id]; ok {
+			data[i].Priority = &priority
+		}
+
+		if state, ok := attributes.sla[ticket.Id]; ok {
+			data[i].Sla = &ticketSlaData{
+				FirstResponse: newSlaTargetData(state.FirstResponseStatus, state.FirstResponseDueAt),
+				Resolution:    newSlaTargetData(state.ResolutionStatus, state.ResolutionDueAt),
+			}
 		}
 	}
 
@@ -84,5 +165,18 @@ func GetTickets(c *gin.Context) {
 		PanelTitles:   panelTitles,
 		ResolvedUsers: users,
 		SelfId:        userId,
+		NextCursor:    nextCursor,
+		Labels:        types.NewTicketLabels(labels),
 	})
 }
+
+func newSlaTargetData(status *database.SlaStatus, dueAt *time.Time) *slaTargetData {
+	if status == nil || dueAt == nil {
+		return nil
+	}
+
+	return &slaTargetData{
+		Status: *status,
+		DueAt:  *dueAt,
+	}
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+)
+
+const maxLabelsPerTicket = 10
+
+type (
+	setLabelsBody struct {
+		LabelIds []int `json:"label_ids"`
+	}
+
+	setPriorityBody struct {
+		// Priority is nil to remove the ticket's priority
+		Priority *dbclient.TicketPriority `json:"priority"`
+	}
+
+	labelsResponse struct {
+		LabelIds []int `json:"label_ids"`
+	}
+
+	priorityResponse struct {
+		Priority *dbclient.TicketPriority `json:"priority"`
+	}
+)
+
+// SetTicketLabels replaces the labels attached to an open ticket
+func SetTicketLabels(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	var body setLabelsBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid request body"))
+		return
+	}
+
+	labelIds := utils.Unique(body.LabelIds)
+	if len(labelIds) > maxLabelsPerTicket {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Tickets cannot have more than %d labels", maxLabelsPerTicket))
+		return
+	}
+
+	ticket, ok := getOpenTicket(c, guildId, userId)
+	if !ok {
+		return
+	}
+
+	labels, err := dbclient.Dashboard.TicketLabels.GetByGuild(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	valid := make(map[int]struct{}, len(labels))
+	for _, label := range labels {
+		valid[label.Id] = struct{}{}
+	}
+
+	for _, labelId := range labelIds {
+		if _, ok := valid[labelId]; !ok {
+			c.JSON(http.StatusBadRequest, utils.ErrorStr("Label %d does not exist", labelId))
+			return
+		}
+	}
+
+	existing, err := dbclient.Dashboard.TicketLabelAssignments.GetByTicket(c, guildId, ticket.Id)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	audit.SetBefore(c, labelsResponse{LabelIds: existing})
+
+	if err := dbclient.Dashboard.TicketLabelAssignments.Set(c, guildId, ticket.Id, labelIds); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	res := labelsResponse{LabelIds: labelIds}
+	audit.SetAfter(c, res)
+
+	c.JSON(http.StatusOK, res)
+}
+
+// SetTicketPriority sets or removes the priority of an open ticket
+func SetTicketPriority(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	var body setPriorityBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid request body"))
+		return
+	}
+
+	if body.Priority != nil && !utils.Exists(dbclient.TicketPriorities, *body.Priority) {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid priority"))
+		return
+	}
+
+	ticket, ok := getOpenTicket(c, guildId, userId)
+	if !ok {
+		return
+	}
+
+	existing, ok, err := dbclient.Dashboard.TicketPriorities.Get(c, guildId, ticket.Id)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	if ok {
+		audit.SetBefore(c, priorityResponse{Priority: &existing})
+	} else {
+		audit.SetBefore(c, priorityResponse{})
+	}
+
+	if body.Priority == nil {
+		err = dbclient.Dashboard.TicketPriorities.Delete(c, guildId, ticket.Id)
+	} else {
+		err = dbclient.Dashboard.TicketPriorities.Set(c, guildId, ticket.Id, *body.Priority)
+	}
+
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	res := priorityResponse{Priority: body.Priority}
+	audit.SetAfter(c, res)
+
+	c.JSON(http.StatusOK, res)
+}
@@ -13,8 +13,14 @@ type Client struct {
 	Authenticated bool
 	GuildId       uint64
 	TicketId      int
-	tx            chan any
-	flush         chan chan struct{}
+	// Feed is set for guild feed clients, which receive updates for every ticket the user can view, and have no TicketId
+	Feed bool
+	// UserId and IsStaff are set once the client has authenticated
+	UserId     uint64
+	IsStaff    bool
+	tx         chan any
+	flush      chan chan struct{}
+	lastTyping time.Time
 }
 
 const (
@@ -22,6 +28,8 @@ const (
 	keepaliveFrequency = 45 * time.Second
 	keepaliveTimeout   = 60 * time.Second
 	writeTimeout       = 10 * time.Second
+	sendTimeout        = 10 * time.Second
+	typingThrottle     = 3 * time.Second
 )
 
 func NewClient(manager *SocketManager, ws *websocket.Conn, c *gin.Context, guildId uint64, ticketId int) *Client {
@@ -37,6 +45,12 @@ func NewClient(manager *SocketManager, ws *websocket.Conn, c *gin.Context, guild
 	}
 }
 
+func NewFeedClient(manager *SocketManager, ws *websocket.Conn, c *gin.Context, guildId uint64) *Client {
+	client := NewClient(manager, ws, c, guildId, 0)
+	client.Feed = true
+	return client
+}
+
 func (c *Client) Close() {
 	close(c.tx)
 }
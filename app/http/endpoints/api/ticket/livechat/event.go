@@ -2,13 +2,19 @@ package livechat
 
 import (
 	"encoding/json"
+	"time"
+
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/redis"
 )
 
 type (
 	EventType string
 
 	Event struct {
-		Type EventType       `json:"type"`
+		Type EventType `json:"type"`
+		// Seq is set on message events, and increases with each message relayed in the ticket. Messages may be
+		// received more than once after resuming, so clients should ignore any with a Seq they have already seen.
+		Seq  uint64          `json:"seq,omitempty"`
 		Data json.RawMessage `json:"data,omitempty"`
 	}
 
@@ -16,6 +22,58 @@ type (
 		Token string `json:"token"`
 	}
 
+	// SendMessageData is sent by the client to reply to the ticket. Nonce is optional, and is echoed back in the
+	// resulting sent or send_failed event so the client can match the result to the message.
+	SendMessageData struct {
+		Nonce   string `json:"nonce,omitempty"`
+		Content string `json:"content"`
+	}
+
+	SendTagData struct {
+		Nonce string `json:"nonce,omitempty"`
+		TagId string `json:"tag_id"`
+	}
+
+	SendResultData struct {
+		Nonce string `json:"nonce,omitempty"`
+		Error string `json:"error,omitempty"`
+	}
+
+	ResumeData struct {
+		LastSeq uint64 `json:"last_seq"`
+	}
+
+	// ResumedData is sent after any missed messages have been replayed. If Complete is false, some of the missed
+	// messages were no longer buffered, and the client should reload the ticket.
+	ResumedData struct {
+		LatestSeq uint64 `json:"latest_seq"`
+		Complete  bool   `json:"complete"`
+	}
+
+	PresenceData struct {
+		UserId uint64                      `json:"user_id,string"`
+		State  redis.LiveChatPresenceState `json:"state"`
+	}
+
+	// TicketEventData is sent to guild feed clients when a ticket is opened, claimed or closed
+	TicketEventData struct {
+		Type      redis.TicketEventType `json:"type"`
+		TicketId  int                   `json:"ticket_id"`
+		UserId    uint64                `json:"user_id,string"`
+		Timestamp time.Time             `json:"timestamp"`
+		Data      json.RawMessage       `json:"data,omitempty"`
+	}
+
+	// TicketMessageData is sent to guild feed clients when a message is sent in a ticket
+	TicketMessageData struct {
+		TicketId   int       `json:"ticket_id"`
+		MessageId  uint64    `json:"message_id,string"`
+		AuthorId   uint64    `json:"author_id,string"`
+		AuthorName string    `json:"author_name"`
+		Preview    string    `json:"preview"`
+		Timestamp  time.Time `json:"timestamp"`
+	}
+
 	ErrorMessage struct {
 		Error string `json:"error"`
 	}
@@ -25,8 +83,30 @@ const (
 	EventTypeAuth          EventType = "auth"
 	EventTypeAuthenticated EventType = "authenticated"
 	EventTypeMessage       EventType = "message"
+	EventTypeSendMessage   EventType = "send_message"
+	EventTypeSendTag       EventType = "send_tag"
+	EventTypeSent          EventType = "sent"
+	EventTypeSendFailed    EventType = "send_failed"
+	EventTypeResume        EventType = "resume"
+	EventTypeResumed       EventType = "resumed"
+	EventTypeTyping        EventType = "typing"
+	EventTypePresence      EventType = "presence"
+	EventTypeTicketEvent   EventType = "ticket_event"
+	EventTypeTicketMessage EventType = "ticket_message"
 )
 
 func NewErrorMessage(message string) ErrorMessage {
 	return ErrorMessage{message}
 }
+
+func newEvent(eventType EventType, data any) (Event, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		Type: eventType,
+		Data: encoded,
+	}, nil
+}
@@ -7,28 +7,75 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/golang-jwt/jwt"
+	api_ticket "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/ticket"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/middleware"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/config"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/internal/api"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/redis"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Utilities/permission"
 )
 
 func (c *Client) HandleEvent(event Event) error {
+	// Guild feed clients only receive events, so there is nothing to handle once authenticated
+	if c.Feed && event.Type != EventTypeAuth {
+		return nil
+	}
+
 	switch event.Type {
 	case EventTypeAuth:
 		var data AuthData
-		if err := json.Unmarshal(event.Data, &data); err != nil {
-			c.Write(NewErrorMessage("Malformed event payload"))
-			_ = c.Ws.Close()
-			c.Flush()
+		if err := c.decodeEventData(event, &data); err != nil {
 			return err
 		}
 
 		if err := c.handleAuthEvent(data); err != nil {
 			return err
 		}
+	case EventTypeSendMessage:
+		var data SendMessageData
+		if err := c.decodeEventData(event, &data); err != nil {
+			return err
+		}
+
+		c.handleSend(data.Nonce, func(ctx context.Context) *api.RequestError {
+			return api_ticket.SendTicketMessage(ctx, c.GuildId, c.UserId, c.TicketId, data.Content)
+		})
+	case EventTypeSendTag:
+		var data SendTagData
+		if err := c.decodeEventData(event, &data); err != nil {
+			return err
+		}
+
+		c.handleSend(data.Nonce, func(ctx context.Context) *api.RequestError {
+			return api_ticket.SendTicketTag(ctx, c.GuildId, c.UserId, c.TicketId, data.TagId)
+		})
+	case EventTypeResume:
+		var data ResumeData
+		if err := c.decodeEventData(event, &data); err != nil {
+			return err
+		}
+
+		if err := c.handleResumeEvent(data); err != nil {
+			return err
+		}
+	case EventTypeTyping:
+		c.handleTypingEvent()
+	}
+
+	return nil
+}
+
+func (c *Client) decodeEventData(event Event, data any) error {
+	if err := json.Unmarshal(event.Data, data); err != nil {
+		c.Write(NewErrorMessage("Malformed event payload"))
+		_ = c.Ws.Close()
+		c.Flush()
+		return err
 	}
 
 	return nil
@@ -39,7 +86,57 @@ func (c *Client) handleAuthEvent(data AuthData) error {
 		return api.NewErrorWithMessage(http.StatusBadRequest, errors.New("Already authenticated"), "Already authenticated")
 	}
 
-	token, err := jwt.Parse(data.Token, func(token *jwt.Token) (interface{}, error) {
+	userId, requestErr := parseAuthToken(data.Token)
+	if requestErr != nil {
+		return requestErr
+	}
+
+	if c.Feed {
+		return c.authenticateFeed(userId)
+	}
+
+	// Get the ticket
+	ticket, err := dbclient.Client.Tickets.Get(context.Background(), c.TicketId, c.GuildId)
+	if err != nil {
+		return api.NewErrorWithMessage(http.StatusInternalServerError, err, "Error retrieving ticket data")
+	}
+
+	if ticket.Id == 0 || ticket.GuildId == 0 || ticket.GuildId != c.GuildId {
+		return api.NewErrorWithMessage(http.StatusNotFound, err, "Ticket not found")
+	}
+
+	// Verify the user has permissions to be here
+	hasPermission, requestErr := utils.HasPermissionToViewTicket(context.Background(), c.GuildId, userId, ticket)
+	if requestErr != nil {
+		return api.NewErrorWithMessage(http.StatusInternalServerError, err, "Error retrieving permission data")
+	}
+
+	if !hasPermission {
+		return api.NewErrorWithMessage(http.StatusForbidden, err, "You do not have permission to view this ticket")
+	}
+
+	permissionLevel, err := utils.GetPermissionLevel(context.Background(), c.GuildId, userId)
+	if err != nil {
+		return api.NewErrorWithMessage(http.StatusInternalServerError, err, "Error retrieving permission data")
+	}
+
+	c.UserId = userId
+	c.IsStaff = permissionLevel >= permission.Support
+	c.Authenticated = true
+
+	c.Write(Event{
+		Type: EventTypeAuthenticated,
+	})
+
+	if c.IsStaff {
+		c.publishPresence(redis.LiveChatPresenceViewing, true)
+	}
+
+	return nil
+}
+
+func parseAuthToken(tokenStr string) (uint64, *api.RequestError) {
+	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
@@ -47,49 +144,168 @@ func (c *Client) handleAuthEvent(data AuthData) error {
 		return []byte(config.Conf.Server.Secret), nil
 	})
 	if err != nil {
-		return api.NewErrorWithMessage(http.StatusUnauthorized, err, "Invalid token")
+		return 0, api.NewErrorWithMessage(http.StatusUnauthorized, err, "Invalid token")
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return api.NewErrorWithMessage(http.StatusUnauthorized, err, "Invalid token data")
+		return 0, api.NewErrorWithMessage(http.StatusUnauthorized, err, "Invalid token data")
 	}
 
 	userIdStr, ok := claims["userid"].(string)
 	if !ok {
-		return api.NewErrorWithMessage(http.StatusUnauthorized, err, "Invalid token data")
+		return 0, api.NewErrorWithMessage(http.StatusUnauthorized, err, "Invalid token data")
 	}
 
 	userId, err := strconv.ParseUint(userIdStr, 10, 64)
 	if err != nil {
-		return api.NewErrorWithMessage(http.StatusUnauthorized, err, "Invalid token data")
+		return 0, api.NewErrorWithMessage(http.StatusUnauthorized, err, "Invalid token data")
 	}
 
-	// Get the ticket
-	ticket, err := dbclient.Client.Tickets.Get(context.Background(), c.TicketId, c.GuildId)
+	return userId, nil
+}
+
+// authenticateFeed authenticates a guild feed client. The feed is limited to staff, as with the ticket list, and
+// events are further filtered per ticket before being sent.
+func (c *Client) authenticateFeed(userId uint64) error {
+	permissionLevel, err := utils.GetPermissionLevel(context.Background(), c.GuildId, userId)
 	if err != nil {
-		return api.NewErrorWithMessage(http.StatusInternalServerError, err, "Error retrieving ticket data")
+		return api.NewErrorWithMessage(http.StatusInternalServerError, err, "Error retrieving permission data")
 	}
 
-	if ticket.Id == 0 || ticket.GuildId == 0 || ticket.GuildId != c.GuildId {
-		return api.NewErrorWithMessage(http.StatusNotFound, err, "Ticket not found")
+	if permissionLevel < permission.Support {
+		return api.NewErrorWithMessage(http.StatusForbidden, errors.New("unauthorized"), "You do not have permission to view tickets in this server")
 	}
 
-	// Verify the user has permissions to be here
-	hasPermission, requestErr := utils.HasPermissionToViewTicket(context.Background(), c.GuildId, userId, ticket)
-	if requestErr != nil {
+	c.UserId = userId
+	c.IsStaff = true
+	c.Authenticated = true
+
+	// Don't wait for the next refresh, so that changes made by the worker are published straight away
+	markFeedGuilds(c.GuildId)
+
+	c.Write(Event{
+		Type: EventTypeAuthenticated,
+	})
+
+	return nil
+}
+
+// handleSend runs send on behalf of the client, applying the same permission level and rate limit as the HTTP
+// endpoints. Failures are reported to the client with a send_failed event, rather than closing the connection.
+func (c *Client) handleSend(nonce string, send func(ctx context.Context) *api.RequestError) {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	if err := c.checkCanSend(ctx); err != nil {
+		c.writeSendResult(EventTypeSendFailed, SendResultData{Nonce: nonce, Error: err.Error()})
+		return
+	}
+
+	if err := send(ctx); err != nil {
+		if err.StatusCode >= 500 {
+			c.RequestCtx.Error(err)
+		}
+
+		c.writeSendResult(EventTypeSendFailed, SendResultData{Nonce: nonce, Error: err.Error()})
+		return
+	}
+
+	// The message has been sent, so the user is no longer typing
+	c.publishPresence(redis.LiveChatPresenceViewing, false)
+	c.writeSendResult(EventTypeSent, SendResultData{Nonce: nonce})
+}
+
+func (c *Client) checkCanSend(ctx context.Context) *api.RequestError {
+	// Permissions may have changed since the client authenticated, so check again on every send
+	permissionLevel, err := utils.GetPermissionLevel(ctx, c.GuildId, c.UserId)
+	if err != nil {
 		return api.NewErrorWithMessage(http.StatusInternalServerError, err, "Error retrieving permission data")
 	}
 
-	if !hasPermission {
-		return api.NewErrorWithMessage(http.StatusForbidden, err, "You do not have permission to view this ticket")
+	if permissionLevel < permission.Support {
+		return api.NewErrorWithMessage(http.StatusForbidden, errors.New("unauthorized"), "Unauthorized")
 	}
 
-	c.Authenticated = true
+	// Sending over live chat takes from the same bucket as the HTTP routes, so that a guild cannot double its rate
+	res, err := middleware.AllowGuild(ctx, c.GuildId, api_ticket.SendRateLimit)
+	if err != nil {
+		return api.NewInternalServerError(err, "Error checking ratelimit")
+	}
 
-	c.Write(Event{
-		Type: EventTypeAuthenticated,
+	if res.Allowed <= 0 {
+		return api.NewErrorWithMessage(http.StatusTooManyRequests, errors.New("ratelimited"), "You are being ratelimited")
+	}
+
+	return nil
+}
+
+func (c *Client) writeSendResult(eventType EventType, data SendResultData) {
+	event, err := newEvent(eventType, data)
+	if err != nil {
+		c.RequestCtx.Error(err)
+		return
+	}
+
+	c.Write(event)
+}
+
+// handleResumeEvent replays the messages sent in the ticket after data.LastSeq. The buffer is shared between servers,
+// so the client may resume on a different server to the one it was connected to.
+func (c *Client) handleResumeEvent(data ResumeData) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	replay, err := redis.Client.GetLiveChatReplay(ctx, c.GuildId, c.TicketId, data.LastSeq)
+	if err != nil {
+		return api.NewInternalServerError(err, "Error retrieving missed messages")
+	}
+
+	for _, message := range replay.Messages {
+		c.Write(Event{
+			Type: EventTypeMessage,
+			Seq:  message.Seq,
+			Data: message.Message,
+		})
+	}
+
+	event, err := newEvent(EventTypeResumed, ResumedData{
+		LatestSeq: replay.LatestSeq,
+		Complete:  replay.Complete,
 	})
+	if err != nil {
+		return err
+	}
 
+	c.Write(event)
 	return nil
 }
+
+func (c *Client) handleTypingEvent() {
+	if !c.IsStaff || time.Since(c.lastTyping) < typingThrottle {
+		return
+	}
+
+	c.lastTyping = time.Now()
+	c.publishPresence(redis.LiveChatPresenceTyping, false)
+}
+
+// publishPresence publishes the client's presence to every replica, so it reaches all clients viewing the ticket
+func (c *Client) publishPresence(state redis.LiveChatPresenceState, sync bool) {
+	publishPresence(redis.LiveChatPresence{
+		GuildId:  c.GuildId,
+		TicketId: c.TicketId,
+		UserId:   c.UserId,
+		State:    state,
+		Sync:     sync,
+	})
+}
+
+func publishPresence(presence redis.LiveChatPresence) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		defer cancel()
+
+		_ = redis.Client.PublishLiveChatPresence(ctx, presence)
+	}()
+}
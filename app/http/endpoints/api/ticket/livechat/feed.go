@@ -0,0 +1,299 @@
+package livechat
+
+import (
+	"context"
+	"time"
+
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/redis"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Utilities/chatrelay"
+	"go.uber.org/zap"
+)
+
+const (
+	feedPermissionTimeout = 10 * time.Second
+	messagePreviewLength  = 100
+
+	// feedVisibilityTtl is how long whether a user can view a ticket is cached for, so that changes to their roles
+	// are picked up
+	feedVisibilityTtl = 5 * time.Minute
+
+	// feedGuildsInterval is how often the guilds with feed clients connected to this server are recorded in Redis, so
+	// that changes made by the worker in them are published
+	feedGuildsInterval = time.Minute
+	feedGuildsTtl      = 3 * feedGuildsInterval
+)
+
+// feedEventTypes are the ticket events that are sent to guild feed clients
+var feedEventTypes = map[redis.TicketEventType]bool{
+	redis.TicketEventOpened:    true,
+	redis.TicketEventClaimed:   true,
+	redis.TicketEventUnclaimed: true,
+	redis.TicketEventClosed:    true,
+}
+
+type (
+	// feedEntry is an event waiting to be written to a guild's feed clients. Entries are written in the order that the
+	// events were received, once it is known which of the clients can view the ticket.
+	feedEntry struct {
+		ticketId int
+		event    Event
+		clients  []*Client
+		visible  map[*Client]bool
+		ready    bool
+	}
+
+	// feedCheck is the result of checking which clients can view the ticket of a feed entry. Clients whose
+	// permissions could not be checked are omitted, and are not sent the event.
+	feedCheck struct {
+		guildId uint64
+		entry   *feedEntry
+		visible map[*Client]bool
+	}
+
+	feedVisibility struct {
+		visible   bool
+		checkedAt time.Time
+	}
+)
+
+// sendToFeed queues the event for each guild feed client whose user can view the ticket. Whether a user can view a
+// ticket is cached for feedVisibilityTtl. Otherwise, checking requires fetching data, so it is done in the background,
+// and the results are passed back to Run, which writes the guild's queued events in order.
+func (sm *SocketManager) sendToFeed(guildId uint64, ticketId int, eventType EventType, data any) {
+	var clients []*Client
+	for _, client := range sm.clients[guildId] {
+		if client.Authenticated && client.Feed {
+			clients = append(clients, client)
+		}
+	}
+
+	if len(clients) == 0 {
+		return
+	}
+
+	event, err := newEvent(eventType, data)
+	if err != nil {
+		sm.logger.Error("Failed to encode feed event", zap.Error(err), zap.String("type", string(eventType)), zap.Uint64("guild_id", guildId), zap.Int("ticket_id", ticketId))
+		return
+	}
+
+	entry := &feedEntry{
+		ticketId: ticketId,
+		event:    event,
+		clients:  clients,
+		visible:  make(map[*Client]bool, len(clients)),
+	}
+
+	var unchecked []*Client
+	for _, client := range clients {
+		if visible, ok := sm.cachedVisibility(client, ticketId); ok {
+			entry.visible[client] = visible
+		} else {
+			unchecked = append(unchecked, client)
+		}
+	}
+
+	sm.feedQueues[guildId] = append(sm.feedQueues[guildId], entry)
+
+	if len(unchecked) == 0 {
+		entry.ready = true
+		sm.flushFeed(guildId)
+		return
+	}
+
+	go sm.checkFeedVisibility(guildId, entry, unchecked)
+}
+
+func (sm *SocketManager) checkFeedVisibility(guildId uint64, entry *feedEntry, clients []*Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), feedPermissionTimeout)
+	defer cancel()
+
+	check := feedCheck{
+		guildId: guildId,
+		entry:   entry,
+		visible: make(map[*Client]bool, len(clients)),
+	}
+
+	// Always pass the result back, so that the entry does not hold up the rest of the guild's queue
+	defer func() {
+		sm.feed <- check
+	}()
+
+	ticket, err := dbclient.Client.Tickets.Get(ctx, entry.ticketId, guildId)
+	if err != nil {
+		sm.logger.Warn("Failed to fetch ticket for feed event", zap.Error(err), zap.Uint64("guild_id", guildId), zap.Int("ticket_id", entry.ticketId))
+		return
+	}
+
+	if ticket.Id == 0 {
+		return
+	}
+
+	for _, client := range clients {
+		hasPermission, requestErr := utils.HasPermissionToViewTicket(ctx, guildId, client.UserId, ticket)
+		if requestErr != nil {
+			continue
+		}
+
+		check.visible[client] = hasPermission
+	}
+}
+
+// handleFeedCheck stores the result of a permission check, and writes any of the guild's events that are now ready
+func (sm *SocketManager) handleFeedCheck(check feedCheck) {
+	for client, visible := range check.visible {
+		check.entry.visible[client] = visible
+		sm.cacheVisibility(client, check.entry.ticketId, visible)
+	}
+
+	check.entry.ready = true
+	sm.flushFeed(check.guildId)
+}
+
+// flushFeed writes the events at the front of the guild's queue that are ready, stopping at the first that is not
+func (sm *SocketManager) flushFeed(guildId uint64) {
+	queue := sm.feedQueues[guildId]
+	for len(queue) > 0 && queue[0].ready {
+		entry := queue[0]
+		for _, client := range entry.clients {
+			// The client may have disconnected while permissions were being checked
+			if entry.visible[client] && sm.isRegistered(client) {
+				client.Write(entry.event)
+			}
+		}
+
+		queue[0] = nil
+		queue = queue[1:]
+	}
+
+	if len(queue) == 0 {
+		delete(sm.feedQueues, guildId)
+	} else {
+		sm.feedQueues[guildId] = queue
+	}
+}
+
+func (sm *SocketManager) cachedVisibility(client *Client, ticketId int) (bool, bool) {
+	cached, ok := sm.feedVisibility[client][ticketId]
+	if !ok || time.Since(cached.checkedAt) > feedVisibilityTtl {
+		return false, false
+	}
+
+	return cached.visible, true
+}
+
+func (sm *SocketManager) cacheVisibility(client *Client, ticketId int, visible bool) {
+	if !sm.isRegistered(client) {
+		return
+	}
+
+	tickets, ok := sm.feedVisibility[client]
+	if !ok {
+		tickets = make(map[int]feedVisibility)
+		sm.feedVisibility[client] = tickets
+	}
+
+	tickets[ticketId] = feedVisibility{
+		visible:   visible,
+		checkedAt: time.Now(),
+	}
+}
+
+// invalidateVisibility removes the cached permissions for the ticket, for changes such as claims that can change who
+// can view it
+func (sm *SocketManager) invalidateVisibility(guildId uint64, ticketId int) {
+	for _, client := range sm.clients[guildId] {
+		delete(sm.feedVisibility[client], ticketId)
+	}
+}
+
+// pruneVisibility removes cached permissions that have expired
+func (sm *SocketManager) pruneVisibility() {
+	for client, tickets := range sm.feedVisibility {
+		for ticketId, cached := range tickets {
+			if time.Since(cached.checkedAt) > feedVisibilityTtl {
+				delete(tickets, ticketId)
+			}
+		}
+
+		if len(tickets) == 0 {
+			delete(sm.feedVisibility, client)
+		}
+	}
+}
+
+// markFeedGuilds records the guilds that have an authenticated feed client connected to this server
+func (sm *SocketManager) markFeedGuilds() {
+	var guildIds []uint64
+	for guildId, clients := range sm.clients {
+		for _, client := range clients {
+			if client.Authenticated && client.Feed {
+				guildIds = append(guildIds, guildId)
+				break
+			}
+		}
+	}
+
+	markFeedGuilds(guildIds...)
+}
+
+func markFeedGuilds(guildIds ...uint64) {
+	if len(guildIds) == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), feedPermissionTimeout)
+		defer cancel()
+
+		_ = redis.Client.MarkLiveFeedGuilds(ctx, guildIds, feedGuildsTtl)
+	}()
+}
+
+// sendToTicket sends the event to the clients viewing the ticket, who have already been authenticated for it
+func (sm *SocketManager) sendToTicket(guildId uint64, ticketId int, eventType EventType, data any) {
+	event, err := newEvent(eventType, data)
+	if err != nil {
+		sm.logger.Error("Failed to encode ticket event", zap.Error(err), zap.String("type", string(eventType)), zap.Uint64("guild_id", guildId), zap.Int("ticket_id", ticketId))
+		return
+	}
+
+	for _, client := range sm.clients[guildId] {
+		if client.Authenticated && !client.Feed && client.TicketId == ticketId {
+			client.Write(event)
+		}
+	}
+}
+
+func (sm *SocketManager) isRegistered(client *Client) bool {
+	for _, el := range sm.clients[client.GuildId] {
+		if el == client {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newTicketEventData(event redis.TicketEvent) TicketEventData {
+	return TicketEventData{
+		Type:      event.Type,
+		TicketId:  event.TicketId,
+		UserId:    event.UserId,
+		Timestamp: event.Timestamp,
+		Data:      event.Data,
+	}
+}
+
+func newTicketMessageData(msg chatrelay.MessageData) TicketMessageData {
+	return TicketMessageData{
+		TicketId:   msg.Ticket.Id,
+		MessageId:  msg.Message.Id,
+		AuthorId:   msg.Message.Author.Id,
+		AuthorName: msg.Message.Author.EffectiveName(),
+		Preview:    utils.StringMax(msg.Message.Content, messagePreviewLength, "..."),
+		Timestamp:  msg.Message.Timestamp,
+	}
+}
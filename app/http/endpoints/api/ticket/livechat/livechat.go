@@ -42,3 +42,25 @@ func GetLiveChatHandler(sm *SocketManager) gin.HandlerFunc {
 		go client.StartWriteLoop()
 	}
 }
+
+// GetLiveFeedHandler upgrades the request to a guild feed websocket, which receives updates for all tickets in the
+// guild that the user can view
+func GetLiveFeedHandler(sm *SocketManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			return
+		}
+
+		guildId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(400, utils.ErrorJson(err))
+			return
+		}
+
+		client := NewFeedClient(sm, conn, c, guildId)
+		sm.register <- client
+		go client.StartReadLoop()
+		go client.StartWriteLoop()
+	}
+}
@@ -1,12 +1,16 @@
 package livechat
 
 import (
+	"context"
 	"encoding/json"
 	"strconv"
+	"time"
 
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/redis"
 	"github.com/jadevelopmentgrp/Tickets-Utilities/chatrelay"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
 )
 
 var (
@@ -26,26 +30,54 @@ var (
 )
 
 type (
+	sequencedMessage struct {
+		chatrelay.MessageData
+		encoded json.RawMessage
+		seq     uint64
+	}
+
 	SocketManager struct {
-		clients    map[uint64][]*Client // Remember: A client might not be authenticated!
-		messages   chan chatrelay.MessageData
-		register   chan *Client
-		unregister chan *Client
+		clients      map[uint64][]*Client // Remember: A client might not be authenticated!
+		messages     chan sequencedMessage
+		presence     chan redis.LiveChatPresence
+		ticketEvents chan redis.TicketEvent
+		feed         chan feedCheck
+		register     chan *Client
+		unregister   chan *Client
+		logger       *zap.Logger
+
+		// feedQueues and feedVisibility are only accessed from Run
+		feedQueues     map[uint64][]*feedEntry
+		feedVisibility map[*Client]map[int]feedVisibility
 	}
 )
 
-func NewSocketManager() *SocketManager {
+const sequenceTimeout = 3 * time.Second
+
+func NewSocketManager(logger *zap.Logger) *SocketManager {
 	return &SocketManager{
-		clients:    map[uint64][]*Client{},
-		messages:   make(chan chatrelay.MessageData),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
+		clients:        map[uint64][]*Client{},
+		messages:       make(chan sequencedMessage),
+		presence:       make(chan redis.LiveChatPresence),
+		ticketEvents:   make(chan redis.TicketEvent),
+		feed:           make(chan feedCheck),
+		feedQueues:     map[uint64][]*feedEntry{},
+		feedVisibility: map[*Client]map[int]feedVisibility{},
+		register:       make(chan *Client),
+		unregister:     make(chan *Client),
+		logger:         logger,
 	}
 }
 
 func (sm *SocketManager) Run() {
+	feedGuildsTicker := time.NewTicker(feedGuildsInterval)
+	defer feedGuildsTicker.Stop()
+
 	for {
 		select {
+		case <-feedGuildsTicker.C:
+			sm.markFeedGuilds()
+			sm.pruneVisibility()
 		case client := <-sm.register:
 			guildClients := sm.clients[client.GuildId]
 			guildClients = append(guildClients, client)
@@ -71,21 +103,28 @@ func (sm *SocketManager) Run() {
 			}
 
 			sm.clients[client.GuildId] = guildClients
+			delete(sm.feedVisibility, client)
 
 			activeWebsockets.Dec()
+
+			if client.Authenticated && client.IsStaff && !sm.isViewing(client.GuildId, client.TicketId, client.UserId) {
+				publishPresence(redis.LiveChatPresence{
+					GuildId:  client.GuildId,
+					TicketId: client.TicketId,
+					UserId:   client.UserId,
+					State:    redis.LiveChatPresenceLeft,
+				})
+			}
 		case msg := <-sm.messages:
 			guildClients, ok := sm.clients[msg.Ticket.GuildId]
 			if !ok || len(guildClients) == 0 { // No clients connected to this API server for this guild
 				continue
 			}
 
-			encoded, err := json.Marshal(msg.Message)
-			if err != nil {
-				continue // TODO: Warn
-			}
+			sm.sendToFeed(msg.Ticket.GuildId, msg.Ticket.Id, EventTypeTicketMessage, newTicketMessageData(msg.MessageData))
 
 			for _, client := range guildClients {
-				if !client.Authenticated {
+				if !client.Authenticated || client.Feed {
 					continue
 				}
 
@@ -98,13 +137,108 @@ func (sm *SocketManager) Run() {
 
 				client.Write(Event{
 					Type: EventTypeMessage,
-					Data: encoded,
+					Seq:  msg.seq,
+					Data: msg.encoded,
 				})
 			}
+		case presence := <-sm.presence:
+			sm.handlePresence(presence)
+		case event := <-sm.ticketEvents:
+			if feedEventTypes[event.Type] {
+				// Claims can change who can view the ticket
+				if event.Type != redis.TicketEventOpened {
+					sm.invalidateVisibility(event.GuildId, event.TicketId)
+				}
+
+				sm.sendToFeed(event.GuildId, event.TicketId, EventTypeTicketEvent, newTicketEventData(event))
+				sm.sendToTicket(event.GuildId, event.TicketId, EventTypeTicketEvent, newTicketEventData(event))
+			}
+		case check := <-sm.feed:
+			sm.handleFeedCheck(check)
 		}
 	}
 }
 
+// handlePresence sends a presence update to the other clients viewing the ticket. If the update is from a user who has
+// just started viewing the ticket, the staff connected to this server republish their own presence for the new viewer.
+func (sm *SocketManager) handlePresence(presence redis.LiveChatPresence) {
+	event, err := newEvent(EventTypePresence, PresenceData{
+		UserId: presence.UserId,
+		State:  presence.State,
+	})
+	if err != nil {
+		sm.logger.Error("Failed to encode live chat presence", zap.Error(err), zap.Uint64("guild_id", presence.GuildId), zap.Int("ticket_id", presence.TicketId))
+		return
+	}
+
+	viewers := make(map[uint64]struct{})
+	for _, client := range sm.clients[presence.GuildId] {
+		if !client.Authenticated || client.TicketId != presence.TicketId || client.UserId == presence.UserId {
+			continue
+		}
+
+		client.Write(event)
+
+		if client.IsStaff {
+			viewers[client.UserId] = struct{}{}
+		}
+	}
+
+	if presence.Sync {
+		for userId := range viewers {
+			publishPresence(redis.LiveChatPresence{
+				GuildId:  presence.GuildId,
+				TicketId: presence.TicketId,
+				UserId:   userId,
+				State:    redis.LiveChatPresenceViewing,
+			})
+		}
+	}
+}
+
+// isViewing returns whether the user has another authenticated connection to the ticket on this server
+func (sm *SocketManager) isViewing(guildId uint64, ticketId int, userId uint64) bool {
+	for _, client := range sm.clients[guildId] {
+		if client.Authenticated && client.TicketId == ticketId && client.UserId == userId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BroadcastMessage sends the message to the clients viewing the ticket. Messages are numbered and buffered in Redis
+// first, so that clients which reconnect to any server can resume from the last message they received.
 func (sm *SocketManager) BroadcastMessage(message chatrelay.MessageData) {
-	sm.messages <- message
+	encoded, err := json.Marshal(message.Message)
+	if err != nil {
+		sm.logger.Error("Failed to encode relayed message", zap.Error(err), zap.Uint64("guild_id", message.Ticket.GuildId), zap.Int("ticket_id", message.Ticket.Id))
+		return
+	}
+
+	// If sequencing fails, still relay the message: clients will not be able to resume past it, but can still see it
+	var seq uint64
+	if message.Message.Id != 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), sequenceTimeout)
+		defer cancel()
+
+		if seq, err = redis.Client.SequenceLiveChatMessage(ctx, message.Ticket.GuildId, message.Ticket.Id, message.Message.Id, encoded); err != nil {
+			sm.logger.Warn("Failed to sequence relayed message", zap.Error(err), zap.Uint64("guild_id", message.Ticket.GuildId), zap.Int("ticket_id", message.Ticket.Id))
+			seq = 0
+		}
+	}
+
+	sm.messages <- sequencedMessage{
+		MessageData: message,
+		encoded:     encoded,
+		seq:         seq,
+	}
+}
+
+func (sm *SocketManager) BroadcastTicketEvent(event redis.TicketEvent) {
+	sm.ticketEvents <- event
+}
+
+func (sm *SocketManager) BroadcastPresence(presence redis.LiveChatPresence) {
+	sm.presence <- presence
 }
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"context"
+
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+	"github.com/rxdn/gdl/objects/channel"
+	"github.com/rxdn/gdl/permission"
+	"github.com/rxdn/gdl/rest"
+)
+
+var (
+	// standardPermissions are granted to everyone with access to a ticket, matching the worker
+	standardPermissions = []permission.Permission{
+		permission.ViewChannel,
+		permission.SendMessages,
+		permission.AddReactions,
+		permission.AttachFiles,
+		permission.ReadMessageHistory,
+		permission.EmbedLinks,
+	}
+
+	readOnlyPermissions = []permission.Permission{
+		permission.ViewChannel,
+		permission.ReadMessageHistory,
+	}
+)
+
+type (
+	// ticketAccess describes who has access to a ticket, and so has an overwrite on its channel
+	ticketAccess struct {
+		// protected are users and roles whose overwrites are never changed by claiming: the opener, ticket members,
+		// admins and the bot
+		protected map[uint64]struct{}
+		// members are users who have been added to the ticket
+		members map[uint64]bool
+		// support are the staff users and roles that can see the ticket when it is unclaimed
+		supportUsers []uint64
+		supportRoles []uint64
+	}
+)
+
+// getTicketAccess returns the users and roles with access to the ticket. Support staff are taken from the panel's
+// teams if it has any, otherwise from the guild's support users and roles.
+func getTicketAccess(ctx context.Context, botContext *botcontext.BotContext, ticket database.Ticket) (ticketAccess, error) {
+	access := ticketAccess{
+		protected: map[uint64]struct{}{
+			ticket.GuildId:   {}, // @everyone
+			ticket.UserId:    {},
+			botContext.BotId: {},
+		},
+	}
+
+	admins, err := dbclient.Client.Permissions.GetAdmins(ctx, ticket.GuildId)
+	if err != nil {
+		return ticketAccess{}, err
+	}
+
+	adminRoles, err := dbclient.Client.RolePermissions.GetAdminRoles(ctx, ticket.GuildId)
+	if err != nil {
+		return ticketAccess{}, err
+	}
+
+	members, err := dbclient.Client.TicketMembers.Get(ctx, ticket.GuildId, ticket.Id)
+	if err != nil {
+		return ticketAccess{}, err
+	}
+
+	access.members = make(map[uint64]bool, len(members))
+	for _, id := range members {
+		access.members[id] = true
+	}
+
+	for _, id := range append(append(admins, adminRoles...), members...) {
+		access.protected[id] = struct{}{}
+	}
+
+	includeDefaultTeam := true
+	if ticket.PanelId != nil {
+		panel, err := dbclient.Client.Panel.GetById(ctx, *ticket.PanelId)
+		if err != nil {
+			return ticketAccess{}, err
+		}
+
+		if panel.PanelId != 0 {
+			includeDefaultTeam = panel.WithDefaultTeam
+
+			teams, err := dbclient.Client.PanelTeams.GetTeams(ctx, panel.PanelId)
+			if err != nil {
+				return ticketAccess{}, err
+			}
+
+			for _, team := range teams {
+				teamUsers, err := dbclient.Client.SupportTeamMembers.Get(ctx, team.Id)
+				if err != nil {
+					return ticketAccess{}, err
+				}
+
+				teamRoles, err := dbclient.Client.SupportTeamRoles.Get(ctx, team.Id)
+				if err != nil {
+					return ticketAccess{}, err
+				}
+
+				access.supportUsers = append(access.supportUsers, teamUsers...)
+				access.supportRoles = append(access.supportRoles, teamRoles...)
+			}
+		}
+	}
+
+	if includeDefaultTeam {
+		supportUsers, err := dbclient.Client.Permissions.GetSupport(ctx, ticket.GuildId)
+		if err != nil {
+			return ticketAccess{}, err
+		}
+
+		supportRoles, err := dbclient.Client.RolePermissions.GetSupportRoles(ctx, ticket.GuildId)
+		if err != nil {
+			return ticketAccess{}, err
+		}
+
+		access.supportUsers = append(access.supportUsers, supportUsers...)
+		access.supportRoles = append(access.supportRoles, supportRoles...)
+	}
+
+	access.supportUsers = utils.Unique(access.supportUsers)
+	access.supportRoles = utils.Unique(access.supportRoles)
+
+	return access, nil
+}
+
+// claimOverwrites returns the channel's overwrites updated for the ticket being claimed by claimer, or unclaimed if
+// claimer is nil. Overwrites for anyone not affected by claiming, such as roles added to the ticket, are kept as-is.
+func claimOverwrites(existing []channel.PermissionOverwrite, access ticketAccess, settings database.ClaimSettings, previousClaimer, claimer *uint64) []channel.PermissionOverwrite {
+	affected := make(map[uint64]struct{})
+	for _, id := range append(access.supportUsers, access.supportRoles...) {
+		affected[id] = struct{}{}
+	}
+
+	if previousClaimer != nil {
+		affected[*previousClaimer] = struct{}{}
+	}
+
+	if claimer != nil {
+		affected[*claimer] = struct{}{}
+	}
+
+	overwrites := make([]channel.PermissionOverwrite, 0, len(existing)+len(affected))
+	for _, overwrite := range existing {
+		_, isProtected := access.protected[overwrite.Id]
+		if _, ok := affected[overwrite.Id]; ok && !isProtected {
+			continue
+		}
+
+		overwrites = append(overwrites, overwrite)
+	}
+
+	// Decide what the support staff who have not claimed the ticket can do
+	var allow, deny uint64
+	switch {
+	case claimer == nil || (settings.SupportCanView && settings.SupportCanType):
+		allow = permission.BuildPermissions(standardPermissions...)
+	case settings.SupportCanView:
+		allow = permission.BuildPermissions(readOnlyPermissions...)
+		deny = permission.BuildPermissions(permission.SendMessages)
+	}
+
+	add := func(id uint64, overwriteType channel.PermissionOverwriteType, allow, deny uint64) {
+		if _, ok := access.protected[id]; ok {
+			return
+		}
+
+		overwrites = append(overwrites, channel.PermissionOverwrite{
+			Id:    id,
+			Type:  overwriteType,
+			Allow: allow,
+			Deny:  deny,
+		})
+	}
+
+	if allow != 0 {
+		for _, userId := range access.supportUsers {
+			if claimer == nil || userId != *claimer {
+				add(userId, channel.PermissionTypeMember, allow, deny)
+			}
+		}
+
+		for _, roleId := range access.supportRoles {
+			add(roleId, channel.PermissionTypeRole, allow, deny)
+		}
+	}
+
+	if claimer != nil {
+		add(*claimer, channel.PermissionTypeMember, permission.BuildPermissions(standardPermissions...), 0)
+	}
+
+	return overwrites
+}
+
+// updateClaimOverwrites applies claimOverwrites to the ticket channel. Threads do not have overwrites, so are left as-is.
+func updateClaimOverwrites(ctx context.Context, botContext *botcontext.BotContext, ticket database.Ticket, previousClaimer, claimer *uint64) error {
+	if ticket.IsThread || ticket.ChannelId == nil {
+		return nil
+	}
+
+	settings, err := dbclient.Client.ClaimSettings.Get(ctx, ticket.GuildId)
+	if err != nil {
+		return err
+	}
+
+	access, err := getTicketAccess(ctx, botContext, ticket)
+	if err != nil {
+		return err
+	}
+
+	ch, err := botContext.GetChannel(ctx, *ticket.ChannelId)
+	if err != nil {
+		return err
+	}
+
+	_, err = botContext.ModifyChannel(ctx, *ticket.ChannelId, rest.ModifyChannelData{
+		PermissionOverwrites: claimOverwrites(ch.PermissionOverwrites, access, settings, previousClaimer, claimer),
+		Position:             ch.Position,
+	})
+
+	return err
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+	"github.com/rxdn/gdl/objects/channel"
+	"github.com/rxdn/gdl/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	guildId     = 1
+	openerId    = 2
+	supportId   = 3
+	supportRole = 4
+	claimerId   = 5
+	otherRoleId = 6
+	adminRoleId = 7
+)
+
+func testAccess() ticketAccess {
+	return ticketAccess{
+		protected: map[uint64]struct{}{
+			guildId:     {},
+			openerId:    {},
+			adminRoleId: {},
+		},
+		supportUsers: []uint64{supportId, claimerId},
+		supportRoles: []uint64{supportRole, adminRoleId},
+	}
+}
+
+func testExisting() []channel.PermissionOverwrite {
+	standard := permission.BuildPermissions(standardPermissions...)
+
+	return []channel.PermissionOverwrite{
+		{Id: guildId, Type: channel.PermissionTypeRole, Deny: permission.BuildPermissions(permission.ViewChannel)},
+		{Id: openerId, Type: channel.PermissionTypeMember, Allow: standard},
+		{Id: supportId, Type: channel.PermissionTypeMember, Allow: standard},
+		{Id: claimerId, Type: channel.PermissionTypeMember, Allow: standard},
+		{Id: supportRole, Type: channel.PermissionTypeRole, Allow: standard},
+		{Id: adminRoleId, Type: channel.PermissionTypeRole, Allow: standard},
+		{Id: otherRoleId, Type: channel.PermissionTypeRole, Allow: standard},
+	}
+}
+
+func findOverwrite(overwrites []channel.PermissionOverwrite, id uint64) (channel.PermissionOverwrite, bool) {
+	for _, overwrite := range overwrites {
+		if overwrite.Id == id {
+			return overwrite, true
+		}
+	}
+
+	return channel.PermissionOverwrite{}, false
+}
+
+func TestClaimOverwritesHidesSupport(t *testing.T) {
+	overwrites := claimOverwrites(testExisting(), testAccess(), database.ClaimSettings{}, nil, utils.Ptr(uint64(claimerId)))
+
+	_, ok := findOverwrite(overwrites, supportId)
+	assert.False(t, ok)
+
+	_, ok = findOverwrite(overwrites, supportRole)
+	assert.False(t, ok)
+
+	claimer, ok := findOverwrite(overwrites, claimerId)
+	if assert.True(t, ok) {
+		assert.Equal(t, permission.BuildPermissions(standardPermissions...), claimer.Allow)
+	}
+
+	// Protected and unrelated overwrites are kept
+	for _, id := range []uint64{guildId, openerId, adminRoleId, otherRoleId} {
+		_, ok := findOverwrite(overwrites, id)
+		assert.True(t, ok, id)
+	}
+
+	assert.Len(t, overwrites, 5)
+}
+
+func TestClaimOverwritesSupportCanView(t *testing.T) {
+	settings := database.ClaimSettings{SupportCanView: true}
+	overwrites := claimOverwrites(testExisting(), testAccess(), settings, nil, utils.Ptr(uint64(claimerId)))
+
+	support, ok := findOverwrite(overwrites, supportRole)
+	if assert.True(t, ok) {
+		assert.Equal(t, permission.BuildPermissions(readOnlyPermissions...), support.Allow)
+		assert.Equal(t, permission.BuildPermissions(permission.SendMessages), support.Deny)
+	}
+}
+
+func TestClaimOverwritesUnclaim(t *testing.T) {
+	claimed := claimOverwrites(testExisting(), testAccess(), database.ClaimSettings{}, nil, utils.Ptr(uint64(claimerId)))
+	unclaimed := claimOverwrites(claimed, testAccess(), database.ClaimSettings{}, utils.Ptr(uint64(claimerId)), nil)
+
+	assert.ElementsMatch(t, testExisting(), unclaimed)
+}
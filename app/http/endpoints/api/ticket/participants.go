@@ -0,0 +1,401 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/rpc/cache"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+	"github.com/rxdn/gdl/objects/channel"
+	"github.com/rxdn/gdl/objects/channel/message"
+	"github.com/rxdn/gdl/objects/user"
+	"github.com/rxdn/gdl/permission"
+	"github.com/rxdn/gdl/rest"
+	"github.com/rxdn/gdl/rest/request"
+)
+
+type participantType string
+
+const (
+	participantTypeUser participantType = "user"
+	participantTypeRole participantType = "role"
+)
+
+type (
+	participant struct {
+		Id   uint64          `json:"id,string"`
+		Type participantType `json:"type"`
+		// Removable is false for the opener, admins and support staff, whose access is managed by the bot
+		Removable bool `json:"removable"`
+	}
+
+	listParticipantsResponse struct {
+		Participants  []participant        `json:"participants"`
+		ResolvedUsers map[uint64]user.User `json:"resolved_users"`
+	}
+
+	addParticipantBody struct {
+		Type participantType `json:"type" binding:"required,oneof=user role"`
+		Id   uint64          `json:"id,string" binding:"required"`
+	}
+)
+
+func ListParticipants(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	ticket, ok := getOpenTicket(c, guildId, userId)
+	if !ok {
+		return
+	}
+
+	botContext, access, ok := getParticipantContext(c, ticket)
+	if !ok {
+		return
+	}
+
+	participants := []participant{
+		{Id: ticket.UserId, Type: participantTypeUser},
+	}
+
+	if ticket.IsThread {
+		// Threads do not have overwrites, so only ticket members can have been added
+		members, err := dbclient.Client.TicketMembers.Get(c, guildId, ticket.Id)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+			return
+		}
+
+		for _, memberId := range members {
+			participants = append(participants, participant{Id: memberId, Type: participantTypeUser, Removable: true})
+		}
+	} else {
+		ch, err := botContext.GetChannel(c, *ticket.ChannelId)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Error retrieving the ticket channel"))
+			return
+		}
+
+		viewChannel := permission.BuildPermissions(permission.ViewChannel)
+		for _, overwrite := range ch.PermissionOverwrites {
+			if overwrite.Allow&viewChannel == 0 || overwrite.Id == ticket.UserId || overwrite.Id == botContext.BotId {
+				continue
+			}
+
+			participantType := participantTypeUser
+			if overwrite.Type == channel.PermissionTypeRole {
+				participantType = participantTypeRole
+			}
+
+			participants = append(participants, participant{
+				Id:        overwrite.Id,
+				Type:      participantType,
+				Removable: access.isRemovable(overwrite.Id),
+			})
+		}
+	}
+
+	userIds := make([]uint64, 0, len(participants))
+	for _, p := range participants {
+		if p.Type == participantTypeUser {
+			userIds = append(userIds, p.Id)
+		}
+	}
+
+	users, err := cache.Instance.GetUsers(c, userIds)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return
+	}
+
+	c.JSON(200, listParticipantsResponse{
+		Participants:  participants,
+		ResolvedUsers: users,
+	})
+}
+
+// AddParticipant gives a user or role access to the ticket, as the bot's add command does
+func AddParticipant(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	var body addParticipantBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid request body"))
+		return
+	}
+
+	ticket, ok := getOpenTicket(c, guildId, userId)
+	if !ok {
+		return
+	}
+
+	botContext, access, ok := getParticipantContext(c, ticket)
+	if !ok {
+		return
+	}
+
+	if access.hasAccess(body.Id) {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("This %s already has access to the ticket", body.Type))
+		return
+	}
+
+	if !validateParticipant(c, botContext, guildId, body.Type, body.Id) {
+		return
+	}
+
+	var err error
+	if body.Type == participantTypeUser {
+		if err := dbclient.Client.TicketMembers.Add(c, guildId, ticket.Id, body.Id); err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+			return
+		}
+
+		if ticket.IsThread {
+			err = botContext.AddThreadMember(c, *ticket.ChannelId, body.Id)
+		} else {
+			err = addParticipantOverwrite(c, botContext, ticket, channel.PermissionTypeMember, body.Id)
+		}
+	} else {
+		if ticket.IsThread {
+			c.JSON(http.StatusBadRequest, utils.ErrorStr("Roles cannot be added to thread tickets"))
+			return
+		}
+
+		err = addParticipantOverwrite(c, botContext, ticket, channel.PermissionTypeRole, body.Id)
+	}
+
+	if err != nil {
+		if body.Type == participantTypeUser {
+			_ = dbclient.Client.TicketMembers.Delete(c, guildId, ticket.Id, body.Id)
+		}
+
+		writeParticipantError(c, err)
+		return
+	}
+
+	sendParticipantMessage(c, botContext, ticket, fmt.Sprintf("<@%d> added %s to the ticket", userId, mention(body.Type, body.Id)), body.Type, body.Id)
+	c.JSON(200, utils.SuccessResponse)
+}
+
+func RemoveParticipant(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	participantType := participantType(c.Param("type"))
+	if participantType != participantTypeUser && participantType != participantTypeRole {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid participant type"))
+		return
+	}
+
+	participantId, err := strconv.ParseUint(c.Param("participantid"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid participant ID"))
+		return
+	}
+
+	ticket, ok := getOpenTicket(c, guildId, userId)
+	if !ok {
+		return
+	}
+
+	botContext, access, ok := getParticipantContext(c, ticket)
+	if !ok {
+		return
+	}
+
+	if !access.isRemovable(participantId) {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("The ticket opener and staff cannot be removed from the ticket"))
+		return
+	}
+
+	audit.SetBefore(c, participant{Id: participantId, Type: participantType, Removable: true})
+
+	if ticket.IsThread {
+		if participantType == participantTypeRole {
+			c.JSON(http.StatusBadRequest, utils.ErrorStr("Thread tickets do not have roles"))
+			return
+		}
+
+		err = botContext.RemoveThreadMember(c, *ticket.ChannelId, participantId)
+	} else {
+		err = botContext.DeleteChannelPermissions(c, *ticket.ChannelId, participantId)
+	}
+
+	if err != nil {
+		var restError request.RestError
+		if !errors.As(err, &restError) || restError.StatusCode != http.StatusNotFound {
+			writeParticipantError(c, err)
+			return
+		}
+	}
+
+	if participantType == participantTypeUser {
+		if err := dbclient.Client.TicketMembers.Delete(c, guildId, ticket.Id, participantId); err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+			return
+		}
+	}
+
+	sendParticipantMessage(c, botContext, ticket, fmt.Sprintf("<@%d> removed %s from the ticket", userId, mention(participantType, participantId)), participantType, 0)
+	c.JSON(200, utils.SuccessResponse)
+}
+
+// getParticipantContext returns the bot context for the ticket's guild, and who has access to the ticket through the
+// bot. If ok is false, a response has already been written.
+func getParticipantContext(c *gin.Context, ticket database.Ticket) (*botcontext.BotContext, ticketAccess, bool) {
+	if ticket.ChannelId == nil {
+		c.JSON(http.StatusNotFound, utils.ErrorStr("Ticket channel not found"))
+		return nil, ticketAccess{}, false
+	}
+
+	botContext, err := botcontext.ContextForGuild(ticket.GuildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return nil, ticketAccess{}, false
+	}
+
+	access, err := getTicketAccess(c, botContext, ticket)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return nil, ticketAccess{}, false
+	}
+
+	return botContext, access, true
+}
+
+// validateParticipant checks that the user is in the guild, or the role exists. If false, a response has already been
+// written.
+func validateParticipant(c *gin.Context, botContext *botcontext.BotContext, guildId uint64, participantType participantType, id uint64) bool {
+	if participantType == participantTypeUser {
+		member, err := botContext.GetGuildMember(c, guildId, id)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+			return false
+		}
+
+		if member.User.Id == 0 {
+			c.JSON(http.StatusBadRequest, utils.ErrorStr("User is not in this server"))
+			return false
+		}
+
+		if member.User.Bot {
+			c.JSON(http.StatusBadRequest, utils.ErrorStr("Bots cannot be added to tickets"))
+			return false
+		}
+
+		return true
+	}
+
+	roles, err := botContext.GetGuildRoles(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return false
+	}
+
+	for _, role := range roles {
+		if role.Id == id && role.Id != guildId && !role.Managed {
+			return true
+		}
+	}
+
+	c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid role"))
+	return false
+}
+
+// addParticipantOverwrite gives the user or role the same access to the ticket as its opener, which is configured by
+// the guild's ticket permissions
+func addParticipantOverwrite(ctx context.Context, botContext *botcontext.BotContext, ticket database.Ticket, overwriteType channel.PermissionOverwriteType, id uint64) error {
+	settings, err := dbclient.Client.TicketPermissions.Get(ctx, ticket.GuildId)
+	if err != nil {
+		return err
+	}
+
+	return botContext.EditChannelPermissions(ctx, *ticket.ChannelId, channel.PermissionOverwrite{
+		Id:    id,
+		Type:  overwriteType,
+		Allow: participantPermissions(settings),
+	})
+}
+
+func participantPermissions(settings database.TicketPermissions) uint64 {
+	permissions := []permission.Permission{
+		permission.ViewChannel,
+		permission.SendMessages,
+		permission.ReadMessageHistory,
+	}
+
+	if settings.AttachFiles {
+		permissions = append(permissions, permission.AttachFiles)
+	}
+
+	if settings.EmbedLinks {
+		permissions = append(permissions, permission.EmbedLinks)
+	}
+
+	if settings.AddReactions {
+		permissions = append(permissions, permission.AddReactions)
+	}
+
+	return permission.BuildPermissions(permissions...)
+}
+
+func writeParticipantError(c *gin.Context, err error) {
+	var restError request.RestError
+	if errors.As(err, &restError) && restError.StatusCode == http.StatusForbidden {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("I do not have permission to update the ticket channel's permissions"))
+		return
+	}
+
+	_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Error updating the ticket channel's permissions"))
+}
+
+// sendParticipantMessage posts a system message about the change into the ticket. Only a newly added user is pinged.
+// The change has already been made, so failing to send the message does not fail the request.
+func sendParticipantMessage(ctx context.Context, botContext *botcontext.BotContext, ticket database.Ticket, content string, participantType participantType, pingUserId uint64) {
+	var allowedMentions message.AllowedMention
+	if participantType == participantTypeUser && pingUserId != 0 {
+		allowedMentions.Users = []uint64{pingUserId}
+	}
+
+	_, _ = rest.CreateMessage(ctx, botContext.Token, botContext.RateLimiter, *ticket.ChannelId, rest.CreateMessageData{
+		Content:         content,
+		AllowedMentions: allowedMentions,
+	})
+}
+
+func mention(participantType participantType, id uint64) string {
+	if participantType == participantTypeRole {
+		return fmt.Sprintf("<@&%d>", id)
+	}
+
+	return fmt.Sprintf("<@%d>", id)
+}
+
+// hasAccess returns whether the user or role already has access to the ticket through the bot, or as a member
+func (a ticketAccess) hasAccess(id uint64) bool {
+	if _, ok := a.protected[id]; ok {
+		return true
+	}
+
+	return utils.Contains(a.supportUsers, id) || utils.Contains(a.supportRoles, id)
+}
+
+// isRemovable returns whether the user or role's access to the ticket can be managed through the participant endpoints,
+// rather than being granted by the bot
+func (a ticketAccess) isRemovable(id uint64) bool {
+	if _, ok := a.protected[id]; ok {
+		return a.members[id]
+	}
+
+	return !utils.Contains(a.supportUsers, id) && !utils.Contains(a.supportRoles, id)
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/internal/api"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/placeholders"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+	"github.com/rxdn/gdl/objects/channel/embed"
+	"github.com/rxdn/gdl/rest"
+	"github.com/rxdn/gdl/rest/request"
+)
+
+const maxMessageLength = 2000
+
+// SendTicketMessage sends a message to the ticket on behalf of the user. It is shared by the HTTP endpoint and the
+// live-chat websocket, so callers are responsible for checking the user's permission level in the guild.
+func SendTicketMessage(ctx context.Context, guildId, userId uint64, ticketId int, content string) *api.RequestError {
+	if len(content) == 0 {
+		return api.NewErrorWithMessage(http.StatusBadRequest, errors.New("message is empty"), "You must enter a message")
+	}
+
+	if len(content) > maxMessageLength {
+		content = content[0 : maxMessageLength-1]
+	}
+
+	botContext, err := botcontext.ContextForGuild(guildId)
+	if err != nil {
+		return api.NewInternalServerError(err, err.Error())
+	}
+
+	ticket, requestErr := getSendTicket(ctx, guildId, ticketId)
+	if requestErr != nil {
+		return requestErr
+	}
+
+	return send(ctx, botContext, ticket, userId, content, nil)
+}
+
+// SendTicketTag sends a tag to the ticket on behalf of the user, expanding any placeholders. As with
+// SendTicketMessage, callers are responsible for checking the user's permission level in the guild.
+func SendTicketTag(ctx context.Context, guildId, userId uint64, ticketId int, tagId string) *api.RequestError {
+	tag, ok, err := dbclient.Client.Tag.Get(ctx, guildId, tagId)
+	if err != nil {
+		return api.NewDatabaseError(err)
+	}
+
+	if !ok {
+		return api.NewErrorWithMessage(http.StatusNotFound, errors.New("tag not found"), "Tag not found")
+	}
+
+	botContext, err := botcontext.ContextForGuild(guildId)
+	if err != nil {
+		return api.NewInternalServerError(err, err.Error())
+	}
+
+	ticket, requestErr := getSendTicket(ctx, guildId, ticketId)
+	if requestErr != nil {
+		return requestErr
+	}
+
+	content, embeds, err := RenderTag(ctx, botContext, ticket, tag)
+	if err != nil {
+		return api.NewInternalServerError(err, "Failed to expand tag placeholders")
+	}
+
+	if requestErr := send(ctx, botContext, ticket, userId, content, embeds); requestErr != nil {
+		return requestErr
+	}
+
+	// Usage statistics are best-effort, the tag has already been sent
+	_ = dbclient.Dashboard.TagMetadata.IncrementUses(ctx, guildId, tag.Id, time.Now())
+
+	return nil
+}
+
+// RenderTag returns the content and embeds of the tag as they would be sent to the ticket
+func RenderTag(ctx context.Context, botContext *botcontext.BotContext, ticket database.Ticket, tag database.Tag) (string, []*embed.Embed, error) {
+	content := utils.ValueOrZero(tag.Content)
+
+	var customEmbed *types.CustomEmbed
+	if tag.Embed != nil {
+		customEmbed = types.NewCustomEmbed(tag.Embed.CustomEmbed, tag.Embed.Fields)
+	}
+
+	if placeholders.HasPlaceholders(tag.Content, customEmbed) {
+		values, err := placeholders.Load(ctx, botContext, ticket)
+		if err != nil {
+			return "", nil, err
+		}
+
+		content = values.Expand(content)
+		if customEmbed != nil {
+			customEmbed = values.ExpandEmbed(customEmbed)
+		}
+	}
+
+	var embeds []*embed.Embed
+	if customEmbed != nil {
+		embeds = []*embed.Embed{customEmbed.IntoDiscordEmbed()}
+	}
+
+	return content, embeds, nil
+}
+
+// getSendTicket fetches the ticket that a message is being sent to, checking that it belongs to the guild
+func getSendTicket(ctx context.Context, guildId uint64, ticketId int) (database.Ticket, *api.RequestError) {
+	ticket, err := dbclient.Client.Tickets.Get(ctx, ticketId, guildId)
+	if err != nil {
+		return database.Ticket{}, api.NewDatabaseError(err)
+	}
+
+	// Verify the ticket exists
+	if ticket.UserId == 0 {
+		return database.Ticket{}, api.NewErrorWithMessage(http.StatusNotFound, errors.New("ticket not found"), "Ticket not found")
+	}
+
+	// Verify the user has permission to send to this guild
+	if ticket.GuildId != guildId {
+		return database.Ticket{}, api.NewErrorWithMessage(http.StatusForbidden, errors.New("guild ID doesn't match"), "Guild ID doesn't match")
+	}
+
+	return ticket, nil
+}
+
+func send(ctx context.Context, botContext *botcontext.BotContext, ticket database.Ticket, userId uint64, content string, embeds []*embed.Embed) *api.RequestError {
+	guildId, ticketId := ticket.GuildId, ticket.Id
+
+	// Preferably send via a webhook
+	webhook, err := dbclient.Client.Webhooks.Get(ctx, guildId, ticketId)
+	if err != nil {
+		return api.NewInternalServerError(err, err.Error())
+	}
+
+	settings, err := dbclient.Client.Settings.Get(ctx, guildId)
+	if err != nil {
+		return api.NewInternalServerError(err, "Failed to fetch settings")
+	}
+
+	if webhook.Id != 0 {
+		var webhookData rest.WebhookBody
+		if settings.AnonymiseDashboardResponses {
+			guild, err := botContext.GetGuild(context.Background(), guildId)
+			if err != nil {
+				return api.NewInternalServerError(err, "Failed to fetch guild")
+			}
+
+			webhookData = rest.WebhookBody{
+				Content:   content,
+				Embeds:    embeds,
+				Username:  guild.Name,
+				AvatarUrl: guild.IconUrl(),
+			}
+		} else {
+			user, err := botContext.GetUser(context.Background(), userId)
+			if err != nil {
+				return api.NewInternalServerError(err, "Failed to fetch user")
+			}
+
+			webhookData = rest.WebhookBody{
+				Content:   content,
+				Embeds:    embeds,
+				Username:  user.EffectiveName(),
+				AvatarUrl: user.AvatarUrl(256),
+			}
+		}
+
+		// TODO: Ratelimit
+		_, err = rest.ExecuteWebhook(ctx, webhook.Token, nil, webhook.Id, true, webhookData)
+
+		if err != nil {
+			// We can delete the webhook in this case
+			var unwrapped request.RestError
+			if errors.As(err, &unwrapped); unwrapped.StatusCode == 403 || unwrapped.StatusCode == 404 {
+				go dbclient.Client.Webhooks.Delete(context.Background(), guildId, ticketId)
+			}
+		} else {
+			return nil
+		}
+	}
+
+	message := content
+	if !settings.AnonymiseDashboardResponses {
+		user, err := botContext.GetUser(context.Background(), userId)
+		if err != nil {
+			return api.NewInternalServerError(err, "Failed to fetch user")
+		}
+
+		message = fmt.Sprintf("**%s**: %s", user.EffectiveName(), message)
+	}
+
+	if len(message) > maxMessageLength {
+		message = message[0 : maxMessageLength-1]
+	}
+
+	if ticket.ChannelId == nil {
+		return api.NewErrorWithMessage(http.StatusNotFound, errors.New("ticket channel ID is nil"), "Ticket channel ID is nil")
+	}
+
+	if _, err = rest.CreateMessage(ctx, botContext.Token, botContext.RateLimiter, *ticket.ChannelId, rest.CreateMessageData{Content: message, Embeds: embeds}); err != nil {
+		return api.NewInternalServerError(err, err.Error())
+	}
+
+	return nil
+}
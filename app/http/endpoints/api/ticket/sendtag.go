@@ -1,19 +1,10 @@
 package api
 
 import (
-	"context"
-	"errors"
-	"fmt"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
-	"github.com/jadevelopmentgrp/Tickets-Dashboard/database"
-	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
-	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
-	"github.com/rxdn/gdl/objects/channel/embed"
-	"github.com/rxdn/gdl/rest"
-	"github.com/rxdn/gdl/rest/request"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
 )
 
 type sendTagBody struct {
@@ -24,14 +15,9 @@ func SendTag(ctx *gin.Context) {
 	guildId := ctx.Keys["guildid"].(uint64)
 	userId := ctx.Keys["userid"].(uint64)
 
-	botContext, err := botcontext.ContextForGuild(guildId)
-	if err != nil {
-		ctx.JSON(500, gin.H{
-			"success": false,
-			"error":   err.Error(),
-		})
-		return
-	}
+	// Messages sent to tickets are part of the ticket's transcript, not configuration changes, so their content is kept
+	// out of the audit log
+	audit.Skip(ctx)
 
 	// Get ticket ID
 	ticketId, err := strconv.Atoi(ctx.Param("ticketId"))
@@ -52,137 +38,8 @@ func SendTag(ctx *gin.Context) {
 		return
 	}
 
-	// Get ticket
-	ticket, err := database.Client.Tickets.Get(ctx, ticketId, guildId)
-
-	// Verify the ticket exists
-	if ticket.UserId == 0 {
-		ctx.JSON(404, gin.H{
-			"success": false,
-			"error":   "Ticket not found",
-		})
-		return
-	}
-
-	// Verify the user has permission to send to this guild
-	if ticket.GuildId != guildId {
-		ctx.JSON(403, gin.H{
-			"success": false,
-			"error":   "Guild ID doesn't match",
-		})
-		return
-	}
-
-	// Get tag
-	tag, ok, err := database.Client.Tag.Get(ctx, guildId, body.TagId)
-	if err != nil {
-		ctx.JSON(500, utils.ErrorJson(err))
-		return
-	}
-
-	if !ok {
-		ctx.JSON(404, gin.H{
-			"success": false,
-			"error":   "Tag not found",
-		})
-		return
-	}
-
-	// Preferably send via a webhook
-	webhook, err := database.Client.Webhooks.Get(ctx, guildId, ticketId)
-	if err != nil {
-		ctx.JSON(500, gin.H{
-			"success": false,
-			"error":   err.Error(),
-		})
-		return
-	}
-
-	settings, err := database.Client.Settings.Get(ctx, guildId)
-	if err != nil {
-		ctx.JSON(500, utils.ErrorStr("Failed to fetch settings"))
-		return
-	}
-
-	var embeds []*embed.Embed
-	if tag.Embed != nil {
-		embeds = []*embed.Embed{
-			types.NewCustomEmbed(tag.Embed.CustomEmbed, tag.Embed.Fields).IntoDiscordEmbed(),
-		}
-	}
-
-	if webhook.Id != 0 {
-		var webhookData rest.WebhookBody
-		if settings.AnonymiseDashboardResponses {
-			guild, err := botContext.GetGuild(context.Background(), guildId)
-			if err != nil {
-				ctx.JSON(500, utils.ErrorStr("Failed to fetch guild"))
-				return
-			}
-
-			webhookData = rest.WebhookBody{
-				Content:   utils.ValueOrZero(tag.Content),
-				Embeds:    embeds,
-				Username:  guild.Name,
-				AvatarUrl: guild.IconUrl(),
-			}
-		} else {
-			user, err := botContext.GetUser(context.Background(), userId)
-			if err != nil {
-				ctx.JSON(500, utils.ErrorStr("Failed to fetch user"))
-				return
-			}
-
-			webhookData = rest.WebhookBody{
-				Content:   utils.ValueOrZero(tag.Content),
-				Embeds:    embeds,
-				Username:  user.EffectiveName(),
-				AvatarUrl: user.AvatarUrl(256),
-			}
-		}
-
-		// TODO: Ratelimit
-		_, err = rest.ExecuteWebhook(ctx, webhook.Token, nil, webhook.Id, true, webhookData)
-
-		if err != nil {
-			// We can delete the webhook in this case
-			var unwrapped request.RestError
-			if errors.As(err, &unwrapped); unwrapped.StatusCode == 403 || unwrapped.StatusCode == 404 {
-				go database.Client.Webhooks.Delete(ctx, guildId, ticketId)
-			}
-		} else {
-			ctx.JSON(200, gin.H{
-				"success": true,
-			})
-			return
-		}
-	}
-
-	message := utils.ValueOrZero(tag.Content)
-	if !settings.AnonymiseDashboardResponses {
-		user, err := botContext.GetUser(context.Background(), userId)
-		if err != nil {
-			ctx.JSON(500, utils.ErrorStr("Failed to fetch user"))
-			return
-		}
-
-		message = fmt.Sprintf("**%s**: %s", user.EffectiveName(), message)
-	}
-
-	if len(message) > 2000 {
-		message = message[0:1999]
-	}
-
-	if ticket.ChannelId == nil {
-		ctx.JSON(404, gin.H{
-			"success": false,
-			"error":   "Ticket channel ID is nil",
-		})
-		return
-	}
-
-	if _, err = rest.CreateMessage(ctx, botContext.Token, botContext.RateLimiter, *ticket.ChannelId, rest.CreateMessageData{Content: message, Embeds: embeds}); err != nil {
-		ctx.JSON(500, gin.H{
+	if err := SendTicketTag(ctx, guildId, userId, ticketId, body.TagId); err != nil {
+		ctx.JSON(err.StatusCode, gin.H{
 			"success": false,
 			"error":   err.Error(),
 		})
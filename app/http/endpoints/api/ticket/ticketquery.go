@@ -0,0 +1,286 @@
+package api
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+)
+
+const maxTicketsPageLimit = 100
+
+type ticketSortField string
+
+const (
+	ticketSortOpenedAt     ticketSortField = "opened_at"
+	ticketSortLastResponse ticketSortField = "last_response"
+)
+
+// ticketListQuery holds the query string parameters accepted by GET /api/:id/tickets. All fields are optional; when
+// none are set every open ticket is returned, as before. label_id may be repeated, and selects tickets that have all
+// of the labels. sla_status selects tickets where either SLA target has the status. When limit is omitted, every
+// matching ticket is returned.
+type ticketListQuery struct {
+	PanelId       *int                     `form:"panel_id"`
+	ClaimedBy     *uint64                  `form:"claimed_by"`
+	Claimed       *bool                    `form:"claimed"`
+	OpenedBy      *uint64                  `form:"opened_by"`
+	AwaitingStaff *bool                    `form:"awaiting_staff"`
+	LabelIds      []int                    `form:"label_id"`
+	Priority      *dbclient.TicketPriority `form:"priority"`
+	SlaStatus     *dbclient.SlaStatus      `form:"sla_status"`
+	MinAge        time.Duration            `form:"min_age"`
+	MaxAge        time.Duration            `form:"max_age"`
+	Sort          string                   `form:"sort"`
+	Order         string                   `form:"order"`
+	Limit         *int                     `form:"limit"`
+	Cursor        string                   `form:"cursor"`
+}
+
+// ticketAttributes holds the dashboard-owned metadata of the tickets being listed, keyed by ticket ID
+type ticketAttributes struct {
+	labels     map[int][]int
+	priorities map[int]dbclient.TicketPriority
+	sla        map[int]dbclient.TicketSla
+}
+
+var slaStatuses = []dbclient.SlaStatus{
+	dbclient.SlaStatusOk,
+	dbclient.SlaStatusAtRisk,
+	dbclient.SlaStatusBreached,
+	dbclient.SlaStatusMet,
+}
+
+func hasSlaStatus(status *dbclient.SlaStatus, expected dbclient.SlaStatus) bool {
+	return status != nil && *status == expected
+}
+
+type ticketCursor struct {
+	SortValue int64
+	TicketId  int
+}
+
+func (q *ticketListQuery) validate() error {
+	switch ticketSortField(q.Sort) {
+	case "":
+		q.Sort = string(ticketSortOpenedAt)
+	case ticketSortOpenedAt, ticketSortLastResponse:
+	default:
+		return fmt.Errorf("Invalid sort field: %s", q.Sort)
+	}
+
+	switch strings.ToLower(q.Order) {
+	case "":
+		q.Order = "asc"
+	case "asc", "desc":
+		q.Order = strings.ToLower(q.Order)
+	default:
+		return fmt.Errorf("Invalid sort order: %s", q.Order)
+	}
+
+	if q.Limit != nil && (*q.Limit < 1 || *q.Limit > maxTicketsPageLimit) {
+		return fmt.Errorf("Limit must be between 1 and %d", maxTicketsPageLimit)
+	}
+
+	if q.MinAge < 0 || q.MaxAge < 0 {
+		return errors.New("Ticket age must not be negative")
+	}
+
+	if q.MaxAge > 0 && q.MinAge > q.MaxAge {
+		return errors.New("min_age must be less than max_age")
+	}
+
+	if q.Priority != nil && !utils.Exists(dbclient.TicketPriorities, *q.Priority) {
+		return fmt.Errorf("Invalid priority: %s", *q.Priority)
+	}
+
+	if q.SlaStatus != nil && !utils.Exists(slaStatuses, *q.SlaStatus) {
+		return fmt.Errorf("Invalid SLA status: %s", *q.SlaStatus)
+	}
+
+	if q.Cursor != "" && q.Limit == nil {
+		return errors.New("A limit must be provided when using a cursor")
+	}
+
+	return nil
+}
+
+func (q *ticketListQuery) matches(ticket database.TicketWithMetadata, attributes ticketAttributes, now time.Time) bool {
+	if q.PanelId != nil {
+		// panel_id=0 selects tickets that were not opened from a panel
+		if *q.PanelId == 0 {
+			if ticket.PanelId != nil {
+				return false
+			}
+		} else if ticket.PanelId == nil || *ticket.PanelId != *q.PanelId {
+			return false
+		}
+	}
+
+	if q.ClaimedBy != nil && (ticket.ClaimedBy == nil || *ticket.ClaimedBy != *q.ClaimedBy) {
+		return false
+	}
+
+	if q.Claimed != nil && (ticket.ClaimedBy != nil) != *q.Claimed {
+		return false
+	}
+
+	if q.OpenedBy != nil && ticket.Ticket.UserId != *q.OpenedBy {
+		return false
+	}
+
+	// A ticket with no messages yet is waiting on staff
+	if q.AwaitingStaff != nil {
+		awaitingStaff := ticket.UserIsStaff == nil || !*ticket.UserIsStaff
+		if awaitingStaff != *q.AwaitingStaff {
+			return false
+		}
+	}
+
+	if len(q.LabelIds) > 0 {
+		labels := attributes.labels[ticket.Id]
+		for _, labelId := range q.LabelIds {
+			if !utils.Exists(labels, labelId) {
+				return false
+			}
+		}
+	}
+
+	if q.Priority != nil {
+		if priority, ok := attributes.priorities[ticket.Id]; !ok || priority != *q.Priority {
+			return false
+		}
+	}
+
+	if q.SlaStatus != nil {
+		state, ok := attributes.sla[ticket.Id]
+		if !ok || !(hasSlaStatus(state.FirstResponseStatus, *q.SlaStatus) || hasSlaStatus(state.ResolutionStatus, *q.SlaStatus)) {
+			return false
+		}
+	}
+
+	age := now.Sub(ticket.OpenTime)
+	if q.MinAge > 0 && age < q.MinAge {
+		return false
+	}
+
+	if q.MaxAge > 0 && age > q.MaxAge {
+		return false
+	}
+
+	return true
+}
+
+func (q *ticketListQuery) sortValue(ticket database.TicketWithMetadata) int64 {
+	if ticketSortField(q.Sort) == ticketSortLastResponse && ticket.LastMessageTime != nil {
+		return ticket.LastMessageTime.UnixNano()
+	}
+
+	return ticket.OpenTime.UnixNano()
+}
+
+// less orders tickets by the sort field, falling back to the ticket ID so that the ordering is total and cursors are
+// stable.
+func (q *ticketListQuery) less(a, b ticketCursor) bool {
+	if a.SortValue == b.SortValue {
+		if q.Order == "desc" {
+			return a.TicketId > b.TicketId
+		}
+
+		return a.TicketId < b.TicketId
+	}
+
+	if q.Order == "desc" {
+		return a.SortValue > b.SortValue
+	}
+
+	return a.SortValue < b.SortValue
+}
+
+func (q *ticketListQuery) cursorFor(ticket database.TicketWithMetadata) ticketCursor {
+	return ticketCursor{
+		SortValue: q.sortValue(ticket),
+		TicketId:  ticket.Id,
+	}
+}
+
+// apply filters and sorts the tickets, returning the requested page, and the cursor for the next page if there is one.
+func (q *ticketListQuery) apply(tickets []database.TicketWithMetadata, attributes ticketAttributes) ([]database.TicketWithMetadata, *string, error) {
+	var after *ticketCursor
+	if q.Cursor != "" {
+		cursor, err := decodeTicketCursor(q.Cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		after = &cursor
+	}
+
+	now := time.Now()
+
+	filtered := make([]database.TicketWithMetadata, 0, len(tickets))
+	for _, ticket := range tickets {
+		if !q.matches(ticket, attributes, now) {
+			continue
+		}
+
+		if after != nil && !q.less(*after, q.cursorFor(ticket)) {
+			continue
+		}
+
+		filtered = append(filtered, ticket)
+	}
+
+	sort.Slice(filtered, func(i, j int) bool {
+		return q.less(q.cursorFor(filtered[i]), q.cursorFor(filtered[j]))
+	})
+
+	if q.Limit == nil || len(filtered) <= *q.Limit {
+		return filtered, nil, nil
+	}
+
+	page := filtered[:*q.Limit]
+	next := encodeTicketCursor(q.cursorFor(page[len(page)-1]))
+	return page, &next, nil
+}
+
+func encodeTicketCursor(cursor ticketCursor) string {
+	raw := fmt.Sprintf("%d:%d", cursor.SortValue, cursor.TicketId)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeTicketCursor(encoded string) (ticketCursor, error) {
+	invalid := errors.New("Invalid cursor")
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return ticketCursor{}, invalid
+	}
+
+	sortValueStr, ticketIdStr, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return ticketCursor{}, invalid
+	}
+
+	sortValue, err := strconv.ParseInt(sortValueStr, 10, 64)
+	if err != nil {
+		return ticketCursor{}, invalid
+	}
+
+	ticketId, err := strconv.Atoi(ticketIdStr)
+	if err != nil {
+		return ticketCursor{}, invalid
+	}
+
+	return ticketCursor{
+		SortValue: sortValue,
+		TicketId:  ticketId,
+	}, nil
+}
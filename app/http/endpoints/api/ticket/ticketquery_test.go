@@ -0,0 +1,65 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchesLabelsAndPriority(t *testing.T) {
+	now := time.Now()
+	ticket := func(id int) database.TicketWithMetadata {
+		return database.TicketWithMetadata{Ticket: database.Ticket{Id: id, OpenTime: now}}
+	}
+
+	attributes := ticketAttributes{
+		labels: map[int][]int{
+			1: {10, 11},
+			2: {10},
+		},
+		priorities: map[int]dbclient.TicketPriority{
+			1: dbclient.TicketPriorityUrgent,
+			3: dbclient.TicketPriorityLow,
+		},
+	}
+
+	query := ticketListQuery{LabelIds: []int{10, 11}}
+	assert.True(t, query.matches(ticket(1), attributes, now))
+	assert.False(t, query.matches(ticket(2), attributes, now), "tickets must have every label")
+	assert.False(t, query.matches(ticket(3), attributes, now))
+
+	query = ticketListQuery{Priority: utils.Ptr(dbclient.TicketPriorityUrgent)}
+	assert.True(t, query.matches(ticket(1), attributes, now))
+	assert.False(t, query.matches(ticket(2), attributes, now), "tickets without a priority must not match")
+	assert.False(t, query.matches(ticket(3), attributes, now))
+}
+
+func TestValidateRejectsUnknownPriority(t *testing.T) {
+	query := ticketListQuery{Priority: utils.Ptr(dbclient.TicketPriority("critical"))}
+	assert.Error(t, query.validate())
+
+	query = ticketListQuery{Priority: utils.Ptr(dbclient.TicketPriorityHigh)}
+	assert.NoError(t, query.validate())
+}
+
+func TestValidateLimitBounds(t *testing.T) {
+	for _, limit := range []int{0, -1, maxTicketsPageLimit + 1} {
+		query := ticketListQuery{Limit: utils.Ptr(limit)}
+		assert.Error(t, query.validate(), "limit %d should be rejected", limit)
+	}
+
+	for _, limit := range []int{1, maxTicketsPageLimit} {
+		query := ticketListQuery{Limit: utils.Ptr(limit)}
+		assert.NoError(t, query.validate(), "limit %d should be accepted", limit)
+	}
+
+	query := ticketListQuery{}
+	assert.NoError(t, query.validate(), "the limit is optional")
+
+	query = ticketListQuery{Cursor: "abc"}
+	assert.Error(t, query.validate(), "a limit is required with a cursor")
+}
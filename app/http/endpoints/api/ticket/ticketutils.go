@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+)
+
+// getOpenTicket returns the open ticket from the request path, checking that the user can view it. If ok is false, a
+// response has already been written.
+func getOpenTicket(c *gin.Context, guildId, userId uint64) (database.Ticket, bool) {
+	ticketId, err := strconv.Atoi(c.Param("ticketId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid ticket ID"))
+		return database.Ticket{}, false
+	}
+
+	ticket, err := dbclient.Client.Tickets.Get(c, ticketId, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		return database.Ticket{}, false
+	}
+
+	if ticket.UserId == 0 || ticket.GuildId != guildId {
+		c.JSON(http.StatusNotFound, utils.ErrorStr("Ticket not found"))
+		return database.Ticket{}, false
+	}
+
+	if !ticket.Open {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Ticket is closed"))
+		return database.Ticket{}, false
+	}
+
+	hasPermission, requestErr := utils.HasPermissionToViewTicket(c, guildId, userId, ticket)
+	if requestErr != nil {
+		c.JSON(requestErr.StatusCode, utils.ErrorJson(requestErr))
+		return database.Ticket{}, false
+	}
+
+	if !hasPermission {
+		c.JSON(http.StatusForbidden, utils.ErrorStr("You do not have permission to view this ticket"))
+		return database.Ticket{}, false
+	}
+
+	return ticket, true
+}
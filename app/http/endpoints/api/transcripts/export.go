@@ -0,0 +1,425 @@
+package api
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	archiverclient "github.com/jadevelopmentgrp/Tickets-Archiver-Client"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/chatreplica"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/config"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+)
+
+const (
+	exportBatchSize  = 100
+	maxExportTickets = 5000
+	exportExpiry     = 24 * time.Hour
+
+	// Running exports record their progress at least this often. An export that has not done so for exportStaleAfter
+	// is treated as interrupted, as the replica running it has most likely stopped.
+	exportHeartbeatInterval = 10 * time.Second
+	exportStaleAfter        = 5 * time.Minute
+)
+
+type (
+	exportBody struct {
+		wrappedQueryOptions
+		IncludeHtml bool `json:"include_html"`
+	}
+
+	// exportJob holds the state of an export while it runs. It is only accessed by the goroutine running the export;
+	// handlers read the status from the database, which the job updates as it progresses.
+	exportJob struct {
+		dbclient.TranscriptExport
+		lastHeartbeat time.Time
+	}
+
+	exportJobResponse struct {
+		Id          string                          `json:"id"`
+		Status      dbclient.TranscriptExportStatus `json:"status"`
+		RequestedBy uint64                          `json:"requested_by,string"`
+		CreatedAt   time.Time                       `json:"created_at"`
+		Total       int                             `json:"total"`
+		Completed   int                             `json:"completed"`
+		Failed      int                             `json:"failed"`
+		Truncated   bool                            `json:"truncated"`
+		Warning     *string                         `json:"warning,omitempty"`
+		Error       *string                         `json:"error,omitempty"`
+		DownloadUrl *string                         `json:"download_url,omitempty"`
+	}
+)
+
+func CreateTranscriptExportHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+	userId := ctx.Keys["userid"].(uint64)
+
+	var body exportBody
+	if err := ctx.BindJSON(&body); err != nil {
+		ctx.JSON(400, utils.ErrorJson(err))
+		return
+	}
+
+	query, err := body.toQueryOptions(guildId)
+	if err != nil {
+		if !validation.RespondError(ctx, err) {
+			_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		}
+
+		return
+	}
+
+	if err := removeExpiredExports(ctx); err != nil {
+		_ = ctx.AbortWithError(500, app.NewServerError(err))
+		return
+	}
+
+	export, ok, err := dbclient.Dashboard.TranscriptExports.Create(ctx, dbclient.TranscriptExport{
+		Id:          uuid.NewString(),
+		GuildId:     guildId,
+		RequestedBy: userId,
+		IncludeHtml: body.IncludeHtml,
+	}, time.Now().Add(-exportStaleAfter))
+	if err != nil {
+		_ = ctx.AbortWithError(500, app.NewServerError(err))
+		return
+	}
+
+	if !ok {
+		ctx.JSON(409, utils.ErrorStr("An export is already running for this server"))
+		return
+	}
+
+	job := &exportJob{TranscriptExport: export, lastHeartbeat: time.Now()}
+	go job.run(query)
+
+	ctx.JSON(200, newExportJobResponse(export))
+}
+
+func GetTranscriptExportHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	export, ok, err := dbclient.Dashboard.TranscriptExports.Get(ctx, guildId, ctx.Param("jobid"))
+	if err != nil {
+		_ = ctx.AbortWithError(500, app.NewServerError(err))
+		return
+	}
+
+	if !ok {
+		ctx.JSON(404, utils.ErrorStr("Export not found"))
+		return
+	}
+
+	ctx.JSON(200, newExportJobResponse(export))
+}
+
+func DownloadTranscriptExportHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	export, ok, err := dbclient.Dashboard.TranscriptExports.Get(ctx, guildId, ctx.Param("jobid"))
+	if err != nil {
+		_ = ctx.AbortWithError(500, app.NewServerError(err))
+		return
+	}
+
+	if !ok {
+		ctx.JSON(404, utils.ErrorStr("Export not found"))
+		return
+	}
+
+	if newExportJobResponse(export).Status != dbclient.TranscriptExportStatusComplete {
+		ctx.JSON(409, utils.ErrorStr("Export has not finished yet"))
+		return
+	}
+
+	path := exportPath(export.Id)
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			ctx.JSON(404, utils.ErrorStr("Export archive not found"))
+		} else {
+			_ = ctx.AbortWithError(500, app.NewServerError(err))
+		}
+
+		return
+	}
+
+	fileName := fmt.Sprintf("transcripts-%d-%s.zip", guildId, export.CreatedAt.Format("2006-01-02"))
+	ctx.FileAttachment(path, fileName)
+}
+
+// exportPath returns the location of the archive in the shared export directory, so that it can be downloaded from
+// any replica
+func exportPath(id string) string {
+	return filepath.Join(config.Conf.TranscriptExport.Path, id+".zip")
+}
+
+func removeExpiredExports(ctx context.Context) error {
+	ids, err := dbclient.Dashboard.TranscriptExports.DeleteExpired(ctx, time.Now().Add(-exportExpiry))
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := os.Remove(exportPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// run has nowhere to report errors recording the result; if the result cannot be stored, the export stops sending
+// heartbeats and is reported as interrupted once it becomes stale.
+func (j *exportJob) run(query transcriptQuery) {
+	ctx := context.Background()
+	path := exportPath(j.Id)
+
+	if err := j.export(ctx, query, path); err != nil {
+		_ = os.Remove(path + ".tmp")
+		_ = dbclient.Dashboard.TranscriptExports.SetResult(ctx, j.Id, dbclient.TranscriptExportStatusFailed, utils.Ptr(err.Error()))
+		return
+	}
+
+	_ = dbclient.Dashboard.TranscriptExports.SetResult(ctx, j.Id, dbclient.TranscriptExportStatusComplete, nil)
+}
+
+// export writes the archive to a temporary file, which is only moved into place once it is complete, so that a
+// partially written archive is never served
+func (j *exportJob) export(ctx context.Context, query transcriptQuery, path string) error {
+	tickets, truncated, err := fetchExportTickets(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := getTranscriptMetadata(ctx, j.GuildId, tickets)
+	if err != nil {
+		return err
+	}
+
+	j.Total = len(tickets)
+	j.Truncated = truncated
+	if err := dbclient.Dashboard.TranscriptExports.SetTotal(ctx, j.Id, j.Total, j.Truncated); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+
+	for _, ticket := range tickets {
+		if ticket.HasTranscript {
+			if err := j.writeTranscript(ctx, w, ticket.Id); err != nil {
+				return err
+			}
+		}
+
+		j.Completed++
+		if err := j.heartbeat(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := writeManifest(w, metadata); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := dbclient.Dashboard.TranscriptExports.SetProgress(ctx, j.Id, j.Completed, j.Failed); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// heartbeat records the progress of the export, throttled to exportHeartbeatInterval
+func (j *exportJob) heartbeat(ctx context.Context) error {
+	if time.Since(j.lastHeartbeat) < exportHeartbeatInterval {
+		return nil
+	}
+
+	j.lastHeartbeat = time.Now()
+	return dbclient.Dashboard.TranscriptExports.SetProgress(ctx, j.Id, j.Completed, j.Failed)
+}
+
+// writeTranscript adds the transcript for a single ticket to the archive. Transcripts that cannot be retrieved are
+// counted as failed rather than aborting the whole export; only errors writing the archive itself are returned.
+func (j *exportJob) writeTranscript(ctx context.Context, w *zip.Writer, ticketId int) error {
+	transcript, err := utils.ArchiverClient.Get(ctx, j.GuildId, ticketId)
+	if err != nil {
+		if !errors.Is(err, archiverclient.ErrNotFound) {
+			j.Failed++
+		}
+
+		return nil
+	}
+
+	encoded, err := json.Marshal(transcript)
+	if err != nil {
+		j.Failed++
+		return nil
+	}
+
+	file, err := w.Create(fmt.Sprintf("transcripts/%d.json", ticketId))
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(encoded); err != nil {
+		return err
+	}
+
+	if j.IncludeHtml {
+		html, err := chatreplica.Render(chatreplica.FromTranscript(transcript, ticketId))
+		if err != nil {
+			j.Failed++
+			return nil
+		}
+
+		file, err := w.Create(fmt.Sprintf("transcripts/%d.html", ticketId))
+		if err != nil {
+			return err
+		}
+
+		if _, err := file.Write(html); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newExportJobResponse(export dbclient.TranscriptExport) exportJobResponse {
+	res := exportJobResponse{
+		Id:          export.Id,
+		Status:      export.Status,
+		RequestedBy: export.RequestedBy,
+		CreatedAt:   export.CreatedAt,
+		Total:       export.Total,
+		Completed:   export.Completed,
+		Failed:      export.Failed,
+		Truncated:   export.Truncated,
+		Error:       export.Error,
+	}
+
+	if res.Status == dbclient.TranscriptExportStatusRunning && time.Since(export.UpdatedAt) > exportStaleAfter {
+		res.Status = dbclient.TranscriptExportStatusFailed
+		res.Error = utils.Ptr("The export was interrupted")
+	}
+
+	if export.Truncated {
+		res.Warning = utils.Ptr(fmt.Sprintf("More than %d transcripts matched the filters; only the newest %d were exported", maxExportTickets, maxExportTickets))
+	}
+
+	if res.Status == dbclient.TranscriptExportStatusComplete {
+		res.DownloadUrl = utils.Ptr(fmt.Sprintf("/api/%d/transcripts/export/%s/download", export.GuildId, export.Id))
+	}
+
+	return res
+}
+
+// fetchExportTickets returns up to maxExportTickets tickets matching the query, and whether any further tickets were
+// left out
+func fetchExportTickets(ctx context.Context, query transcriptQuery) ([]database.Ticket, bool, error) {
+	query.Limit = exportBatchSize
+	query.Offset = 0
+
+	var tickets []database.Ticket
+	for len(tickets) <= maxExportTickets {
+		batch, err := query.getTickets(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+
+		tickets = append(tickets, batch...)
+
+		if len(batch) < exportBatchSize {
+			break
+		}
+
+		query.Offset += exportBatchSize
+	}
+
+	if len(tickets) > maxExportTickets {
+		return tickets[:maxExportTickets], true, nil
+	}
+
+	return tickets, false, nil
+}
+
+func writeManifest(w *zip.Writer, metadata []transcriptMetadata) error {
+	file, err := w.Create("manifest.csv")
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"ticket_id", "username", "close_reason", "closed_by", "rating", "has_transcript", "labels", "priority"}); err != nil {
+		return err
+	}
+
+	for _, transcript := range metadata {
+		var closedBy, rating string
+		if transcript.ClosedBy != nil {
+			closedBy = strconv.FormatUint(*transcript.ClosedBy, 10)
+		}
+
+		if transcript.Rating != nil {
+			rating = strconv.Itoa(int(*transcript.Rating))
+		}
+
+		labelNames := make([]string, len(transcript.Labels))
+		for i, label := range transcript.Labels {
+			labelNames[i] = label.Name
+		}
+
+		row := []string{
+			strconv.Itoa(transcript.TicketId),
+			transcript.Username,
+			utils.ValueOrZero(transcript.CloseReason),
+			closedBy,
+			rating,
+			strconv.FormatBool(transcript.HasTranscript),
+			strings.Join(labelNames, ";"),
+			string(utils.ValueOrZero(transcript.Priority)),
+		}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	archiverclient "github.com/jadevelopmentgrp/Tickets-Archiver-Client"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/transcriptsearch"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 )
 
@@ -65,5 +66,8 @@ func GetTranscriptHandler(ctx *gin.Context) {
 		return
 	}
 
+	// Index the transcript so it can be found by SearchTranscripts
+	transcriptsearch.Instance.AddInBackground(guildId, ticketId, messages)
+
 	ctx.JSON(200, messages)
 }
@@ -4,52 +4,73 @@ import (
 	"context"
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/rpc/cache"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
 	cache2 "github.com/rxdn/gdl/cache"
+	"net/http"
+
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
 )
 
 const pageLimit = 15
 
 type transcriptMetadata struct {
-	TicketId      int     `json:"ticket_id"`
-	Username      string  `json:"username"`
-	CloseReason   *string `json:"close_reason"`
-	ClosedBy      *uint64 `json:"closed_by"`
-	Rating        *uint8  `json:"rating"`
-	HasTranscript bool    `json:"has_transcript"`
+	TicketId      int                      `json:"ticket_id"`
+	Username      string                   `json:"username"`
+	CloseReason   *string                  `json:"close_reason"`
+	ClosedBy      *uint64                  `json:"closed_by"`
+	Rating        *uint8                   `json:"rating"`
+	HasTranscript bool                     `json:"has_transcript"`
+	Labels        []types.TicketLabel      `json:"labels"`
+	Priority      *dbclient.TicketPriority `json:"priority"`
 }
 
 func ListTranscripts(ctx *gin.Context) {
 	guildId := ctx.Keys["guildid"].(uint64)
 
+	// POST is only used so that we can take a body, nothing is modified
+	audit.Skip(ctx)
+
 	var queryOptions wrappedQueryOptions
 	if err := ctx.BindJSON(&queryOptions); err != nil {
 		ctx.JSON(400, utils.ErrorJson(err))
 		return
 	}
 
-	opts, err := queryOptions.toQueryOptions(guildId)
+	query, err := queryOptions.toQueryOptions(guildId)
+	if err != nil {
+		if !validation.RespondError(ctx, err) {
+			_ = ctx.AbortWithError(http.StatusInternalServerError, app.NewServerError(err))
+		}
+
+		return
+	}
+
+	tickets, err := query.getTickets(ctx)
 	if err != nil {
 		ctx.JSON(500, utils.ErrorJson(err))
 		return
 	}
 
-	tickets, err := dbclient.Client.Tickets.GetByOptions(ctx, opts)
+	transcripts, err := getTranscriptMetadata(ctx, guildId, tickets)
 	if err != nil {
 		ctx.JSON(500, utils.ErrorJson(err))
 		return
 	}
 
+	ctx.JSON(200, transcripts)
+}
+
+func getTranscriptMetadata(ctx context.Context, guildId uint64, tickets []database.Ticket) ([]transcriptMetadata, error) {
 	botContext, err := botcontext.ContextForGuild(guildId)
 	if err != nil {
-		ctx.JSON(500, gin.H{
-			"success": false,
-			"error":   err.Error(),
-		})
-		return
+		return nil, err
 	}
 
 	// Create a mapping user_id -> username so we can skip duplicates
@@ -60,19 +81,18 @@ func ListTranscripts(ctx *gin.Context) {
 		}
 
 		// check cache, for some reason botContext.GetUser doesn't do this
-		user, err := cache.Instance.GetUser(context.Background(), ticket.UserId)
+		user, err := cache.Instance.GetUser(ctx, ticket.UserId)
 		if err == nil {
 			usernames[ticket.UserId] = user.Username
 		} else if errors.Is(err, cache2.ErrNotFound) {
-			user, err = botContext.GetUser(context.Background(), ticket.UserId)
+			user, err = botContext.GetUser(ctx, ticket.UserId)
 			if err != nil { // TODO: Log
 				usernames[ticket.UserId] = "Unknown User"
 			} else {
 				usernames[ticket.UserId] = user.Username
 			}
 		} else {
-			ctx.JSON(500, utils.ErrorJson(err))
-			return
+			return nil, err
 		}
 	}
 
@@ -84,23 +104,49 @@ func ListTranscripts(ctx *gin.Context) {
 
 	ratings, err := dbclient.Client.ServiceRatings.GetMulti(ctx, guildId, ticketIds)
 	if err != nil {
-		ctx.JSON(500, utils.ErrorJson(err))
-		return
+		return nil, err
 	}
 
 	// Get close reasons
 	closeReasons, err := dbclient.Client.CloseReason.GetMulti(ctx, guildId, ticketIds)
 	if err != nil {
-		ctx.JSON(500, utils.ErrorJson(err))
-		return
+		return nil, err
 	}
 
+	labelIds, err := dbclient.Dashboard.TicketLabelAssignments.GetMulti(ctx, guildId, ticketIds)
+	if err != nil {
+		return nil, err
+	}
+
+	priorities, err := dbclient.Dashboard.TicketPriorities.GetMulti(ctx, guildId, ticketIds)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, err := dbclient.Dashboard.TicketLabels.GetByGuild(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	labelsById := types.NewTicketLabels(labels)
+
 	transcripts := make([]transcriptMetadata, len(tickets))
 	for i, ticket := range tickets {
 		transcript := transcriptMetadata{
 			TicketId:      ticket.Id,
 			Username:      usernames[ticket.UserId],
 			HasTranscript: ticket.HasTranscript,
+			Labels:        make([]types.TicketLabel, 0, len(labelIds[ticket.Id])),
+		}
+
+		for _, labelId := range labelIds[ticket.Id] {
+			if label, ok := labelsById[labelId]; ok {
+				transcript.Labels = append(transcript.Labels, label)
+			}
+		}
+
+		if v, ok := priorities[ticket.Id]; ok {
+			transcript.Priority = &v
 		}
 
 		if v, ok := ratings[ticket.Id]; ok {
@@ -115,5 +161,5 @@ func ListTranscripts(ctx *gin.Context) {
 		transcripts[i] = transcript
 	}
 
-	ctx.JSON(200, transcripts)
+	return transcripts, nil
 }
@@ -2,34 +2,54 @@ package api
 
 import (
 	"context"
-	"errors"
 
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 	database "github.com/jadevelopmentgrp/Tickets-Database"
-	"github.com/rxdn/gdl/utils"
+	gdlutils "github.com/rxdn/gdl/utils"
 )
 
 type wrappedQueryOptions struct {
-	Id       int    `json:"id,string"`
-	Username string `json:"username"`
-	UserId   uint64 `json:"user_id,string"`
-	PanelId  int    `json:"panel_id"`
-	Page     int    `json:"page"`
-	Rating   int    `json:"rating,string"`
+	Id       int                      `json:"id,string"`
+	Username string                   `json:"username"`
+	UserId   uint64                   `json:"user_id,string"`
+	PanelId  int                      `json:"panel_id"`
+	Page     int                      `json:"page"`
+	Rating   int                      `json:"rating,string"`
+	LabelIds []int                    `json:"label_ids"`
+	Priority *dbclient.TicketPriority `json:"priority"`
 }
 
-func (o *wrappedQueryOptions) toQueryOptions(guildId uint64) (database.TicketQueryOptions, error) {
+// transcriptQuery combines the options supported by the bot's ticket table with filters on dashboard-owned metadata
+type transcriptQuery struct {
+	database.TicketQueryOptions
+	Filter dbclient.TicketMetadataFilter
+}
+
+// getTickets returns the tickets matching the query. Client.Tickets.GetByOptions cannot see dashboard-owned metadata, so
+// queries that filter on it are run against the tables directly.
+func (q transcriptQuery) getTickets(ctx context.Context) ([]database.Ticket, error) {
+	if q.Filter.IsEmpty() {
+		return dbclient.Client.Tickets.GetByOptions(ctx, q.TicketQueryOptions)
+	}
+
+	return dbclient.Dashboard.TicketFilter.GetTickets(ctx, q.TicketQueryOptions, q.Filter)
+}
+
+// toQueryOptions resolves the options to a query. Errors caused by the input are returned as validation errors.
+func (o *wrappedQueryOptions) toQueryOptions(guildId uint64) (transcriptQuery, error) {
 	var userIds []uint64
 	if len(o.Username) > 0 {
 		var err error
 		userIds, err = usernameToIds(guildId, o.Username)
 		if err != nil {
-			return database.TicketQueryOptions{}, err
+			return transcriptQuery{}, err
 		}
 
-		// TODO: Do this better
 		if len(userIds) == 0 {
-			return database.TicketQueryOptions{}, errors.New("User not found")
+			return transcriptQuery{}, validation.NewFieldError("/username", validation.CodeNotFound, "User not found")
 		}
 	}
 
@@ -46,23 +66,33 @@ func (o *wrappedQueryOptions) toQueryOptions(guildId uint64) (database.TicketQue
 		o.Rating = 0
 	}
 
+	if o.Priority != nil && !utils.Exists(dbclient.TicketPriorities, *o.Priority) {
+		return transcriptQuery{}, validation.NewFieldError("/priority", validation.CodeInvalid, "Invalid priority")
+	}
+
 	opts := database.TicketQueryOptions{
 		Id:      o.Id,
 		GuildId: guildId,
 		UserIds: userIds,
-		Open:    utils.BoolPtr(false),
+		Open:    gdlutils.BoolPtr(false),
 		PanelId: o.PanelId,
 		Rating:  o.Rating,
 		Order:   database.OrderTypeDescending,
 		Limit:   pageLimit,
 		Offset:  offset,
 	}
-	return opts, nil
+
+	filter := dbclient.TicketMetadataFilter{
+		LabelIds: o.LabelIds,
+		Priority: o.Priority,
+	}
+
+	return transcriptQuery{TicketQueryOptions: opts, Filter: filter}, nil
 }
 
 func usernameToIds(guildId uint64, username string) ([]uint64, error) {
 	if len(username) > 32 {
-		return nil, errors.New("username too long")
+		return nil, validation.NewFieldError("/username", validation.CodeTooLong, "Username is too long")
 	}
 
 	botContext, err := botcontext.ContextForGuild(guildId)
@@ -8,6 +8,7 @@ import (
 	archiverclient "github.com/jadevelopmentgrp/Tickets-Archiver-Client"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/chatreplica"
 	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/transcriptsearch"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 )
 
@@ -65,6 +66,9 @@ func GetTranscriptRenderHandler(ctx *gin.Context) {
 		return
 	}
 
+	// Index the transcript so it can be found by SearchTranscripts
+	transcriptsearch.Instance.AddInBackground(guildId, ticketId, transcript)
+
 	// Render
 	payload := chatreplica.FromTranscript(transcript, ticketId)
 	html, err := chatreplica.Render(payload)
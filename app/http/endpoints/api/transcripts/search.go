@@ -0,0 +1,102 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/transcriptsearch"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+)
+
+const (
+	searchResultLimit = 25
+	snippetsPerTicket = 3
+)
+
+type searchResponse struct {
+	Results []transcriptsearch.Result `json:"results"`
+}
+
+func SearchTranscripts(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+	userId := ctx.Keys["userid"].(uint64)
+
+	query := strings.TrimSpace(ctx.Query("q"))
+	if len(query) == 0 {
+		ctx.JSON(400, utils.ErrorStr("Search query is required"))
+		return
+	}
+
+	if len(query) > 100 {
+		ctx.JSON(400, utils.ErrorStr("Search query must be 100 characters or less"))
+		return
+	}
+
+	matches, err := transcriptsearch.Instance.Search(guildId, query, snippetsPerTicket)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	// Only tickets opened by the user are visible without staff permissions, and staff permissions only depend on the
+	// panel that the ticket was opened from, so cache the result per panel (0 for tickets not opened from a panel).
+	panelPermissions := make(map[int]bool)
+
+	ticketIds := make([]int, len(matches))
+	for i, match := range matches {
+		ticketIds[i] = match.TicketId
+	}
+
+	tickets, err := dbclient.Dashboard.BotTickets.GetAccess(ctx, guildId, ticketIds)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	results := make([]transcriptsearch.Result, 0, searchResultLimit)
+	for _, match := range matches {
+		if len(results) >= searchResultLimit {
+			break
+		}
+
+		// Same checks as GetTranscriptHandler
+		ticket, ok := tickets[match.TicketId]
+		if !ok || ticket.UserId == 0 || ticket.Open {
+			continue
+		}
+
+		if ticket.UserId != userId {
+			panelId := utils.ValueOrZero(ticket.PanelId)
+
+			hasPermission, ok := panelPermissions[panelId]
+			if !ok {
+				canView, err := utils.HasPermissionToViewTicket(ctx, guildId, userId, database.Ticket{
+					Id:      ticket.Id,
+					GuildId: guildId,
+					UserId:  ticket.UserId,
+					Open:    ticket.Open,
+					PanelId: ticket.PanelId,
+				})
+				if err != nil {
+					ctx.JSON(err.StatusCode, utils.ErrorJson(err))
+					return
+				}
+
+				hasPermission = canView
+				panelPermissions[panelId] = canView
+			}
+
+			if !hasPermission {
+				continue
+			}
+		}
+
+		results = append(results, match)
+	}
+
+	ctx.JSON(200, searchResponse{
+		Results: results,
+	})
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+)
+
+type createResponse struct {
+	webhookResponse
+	// Secret is only returned when the webhook is created, or when the secret is rotated
+	Secret string `json:"secret"`
+}
+
+func CreateWebhookHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+	userId := ctx.Keys["userid"].(uint64)
+
+	var body webhookBody
+	if err := ctx.BindJSON(&body); err != nil {
+		ctx.JSON(400, utils.ErrorJson(err))
+		return
+	}
+
+	if err := body.validate(); err != nil {
+		if !validation.RespondError(ctx, err) {
+			ctx.JSON(500, utils.ErrorStr("An error occurred while validating the webhook"))
+		}
+
+		return
+	}
+
+	count, err := dbclient.Dashboard.Webhooks.Count(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if count >= maxWebhooks {
+		ctx.JSON(400, utils.ErrorStr("You have reached the webhook limit (%d/%d)", maxWebhooks, maxWebhooks))
+		return
+	}
+
+	secret, err := utils.RandString(secretLength)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	webhook, err := dbclient.Dashboard.Webhooks.Create(ctx, dbclient.Webhook{
+		GuildId:   guildId,
+		Url:       body.Url,
+		Secret:    secret,
+		Events:    body.eventStrings(),
+		Enabled:   body.Enabled,
+		CreatedBy: userId,
+	})
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	res := createResponse{
+		webhookResponse: webhookToResponse(webhook),
+		Secret:          webhook.Secret,
+	}
+
+	audit.SetAfter(ctx, res)
+
+	ctx.JSON(200, res)
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+)
+
+func DeleteWebhookHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	webhook, ok := getWebhook(ctx, guildId)
+	if !ok {
+		return
+	}
+
+	audit.SetBefore(ctx, webhookToResponse(webhook))
+
+	// Deliveries are removed by the foreign key
+	if err := dbclient.Dashboard.Webhooks.Delete(ctx, guildId, webhook.Id); err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	ctx.JSON(200, utils.SuccessResponse)
+}
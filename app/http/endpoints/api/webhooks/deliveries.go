@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+)
+
+const (
+	defaultPageLimit = 25
+	maxPageLimit     = 100
+)
+
+type (
+	deliveriesQuery struct {
+		Cursor int64 `form:"cursor"`
+		Limit  int   `form:"limit"`
+	}
+
+	deliveriesResponse struct {
+		Deliveries []delivery `json:"deliveries"`
+		NextCursor *string    `json:"next_cursor,omitempty"`
+	}
+
+	delivery struct {
+		Id             int64                          `json:"id"`
+		EventId        string                         `json:"event_id"`
+		EventType      string                         `json:"event_type"`
+		Payload        json.RawMessage                `json:"payload"`
+		Status         dbclient.WebhookDeliveryStatus `json:"status"`
+		Attempts       int                            `json:"attempts"`
+		NextAttemptAt  *time.Time                     `json:"next_attempt_at,omitempty"`
+		ResponseStatus *int                           `json:"response_status,omitempty"`
+		Error          *string                        `json:"error,omitempty"`
+		CreatedAt      time.Time                      `json:"created_at"`
+		DeliveredAt    *time.Time                     `json:"delivered_at,omitempty"`
+	}
+)
+
+func ListWebhookDeliveriesHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	var query deliveriesQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(400, utils.ErrorJson(err))
+		return
+	}
+
+	if query.Limit == 0 {
+		query.Limit = defaultPageLimit
+	} else if query.Limit < 0 || query.Limit > maxPageLimit {
+		ctx.JSON(400, utils.ErrorStr("Limit must be between 1 and %d", maxPageLimit))
+		return
+	}
+
+	webhook, ok := getWebhook(ctx, guildId)
+	if !ok {
+		return
+	}
+
+	deliveries, err := dbclient.Dashboard.WebhookDeliveries.GetByWebhook(ctx, webhook.Id, query.Cursor, query.Limit)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	data := make([]delivery, len(deliveries))
+	for i, d := range deliveries {
+		data[i] = delivery{
+			Id:             d.Id,
+			EventId:        d.EventId,
+			EventType:      d.EventType,
+			Payload:        d.Payload,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			ResponseStatus: d.ResponseStatus,
+			Error:          d.Error,
+			CreatedAt:      d.CreatedAt,
+			DeliveredAt:    d.DeliveredAt,
+		}
+
+		// The next attempt time is only meaningful while the delivery is pending
+		if d.Status == dbclient.WebhookDeliveryStatusPending {
+			data[i].NextAttemptAt = utils.Ptr(d.NextAttemptAt)
+		}
+	}
+
+	var nextCursor *string
+	if len(deliveries) == query.Limit {
+		nextCursor = utils.Ptr(strconv.FormatInt(deliveries[len(deliveries)-1].Id, 10))
+	}
+
+	ctx.JSON(200, deliveriesResponse{
+		Deliveries: data,
+		NextCursor: nextCursor,
+	})
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+)
+
+func ListWebhooksHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	webhooks, err := dbclient.Dashboard.Webhooks.GetByGuild(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	res := make([]webhookResponse, len(webhooks))
+	for i, webhook := range webhooks {
+		res[i] = webhookToResponse(webhook)
+	}
+
+	ctx.JSON(200, res)
+}
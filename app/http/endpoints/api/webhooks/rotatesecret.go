@@ -0,0 +1,40 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+)
+
+// RotateWebhookSecretHandler replaces the signing secret. Deliveries that have not been sent yet are signed with the new
+// secret.
+func RotateWebhookSecretHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	webhook, ok := getWebhook(ctx, guildId)
+	if !ok {
+		return
+	}
+
+	audit.SetBefore(ctx, webhookToResponse(webhook))
+
+	secret, err := utils.RandString(secretLength)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if err := dbclient.Dashboard.Webhooks.SetSecret(ctx, guildId, webhook.Id, secret); err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	// The secret itself is only returned to the user, never recorded
+	audit.SetAfter(ctx, webhookToResponse(webhook))
+
+	ctx.JSON(200, createResponse{
+		webhookResponse: webhookToResponse(webhook),
+		Secret:          secret,
+	})
+}
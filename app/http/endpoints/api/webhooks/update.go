@@ -0,0 +1,48 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+)
+
+func UpdateWebhookHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	var body webhookBody
+	if err := ctx.BindJSON(&body); err != nil {
+		ctx.JSON(400, utils.ErrorJson(err))
+		return
+	}
+
+	if err := body.validate(); err != nil {
+		if !validation.RespondError(ctx, err) {
+			ctx.JSON(500, utils.ErrorStr("An error occurred while validating the webhook"))
+		}
+
+		return
+	}
+
+	webhook, ok := getWebhook(ctx, guildId)
+	if !ok {
+		return
+	}
+
+	audit.SetBefore(ctx, webhookToResponse(webhook))
+
+	webhook.Url = body.Url
+	webhook.Events = body.eventStrings()
+	webhook.Enabled = body.Enabled
+
+	if err := dbclient.Dashboard.Webhooks.Update(ctx, webhook); err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	res := webhookToResponse(webhook)
+	audit.SetAfter(ctx, res)
+
+	ctx.JSON(200, res)
+}
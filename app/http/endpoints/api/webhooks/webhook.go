@@ -0,0 +1,92 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/redis"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+)
+
+const (
+	maxWebhooks  = 5
+	secretLength = 48
+)
+
+type (
+	webhookBody struct {
+		Url     string                  `json:"url" validate:"required,url,max=255,startswith=https://,startsnotwith=https://discord.com,startsnotwith=https://discord.gg"`
+		Events  []redis.TicketEventType `json:"events" validate:"required,min=1,unique"`
+		Enabled bool                    `json:"enabled"`
+	}
+
+	webhookResponse struct {
+		Id        int64                   `json:"id"`
+		Url       string                  `json:"url"`
+		Events    []redis.TicketEventType `json:"events"`
+		Enabled   bool                    `json:"enabled"`
+		CreatedBy uint64                  `json:"created_by,string"`
+		CreatedAt time.Time               `json:"created_at"`
+	}
+)
+
+var validate = validator.New()
+
+func (b *webhookBody) validate() error {
+	errs := []error{validation.Struct(validate, b)}
+
+	// Together with the unique tag, this also bounds the number of events
+	valid := utils.ToSet(redis.TicketEventTypes)
+	for i, event := range b.Events {
+		if !valid.Contains(event) {
+			errs = append(errs, validation.NewFieldErrorf(validation.IndexPointer("/events", i), validation.CodeInvalid, "Unknown event type: %s", event))
+		}
+	}
+
+	return validation.Join(errs...)
+}
+
+func (b *webhookBody) eventStrings() []string {
+	return utils.Map(b.Events, func(event redis.TicketEventType) string {
+		return string(event)
+	})
+}
+
+// getWebhook loads the webhook from the path, writing an error response and returning false if it cannot be used
+func getWebhook(ctx *gin.Context, guildId uint64) (dbclient.Webhook, bool) {
+	webhookId, err := strconv.ParseInt(ctx.Param("webhookid"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, utils.ErrorStr("Invalid webhook ID"))
+		return dbclient.Webhook{}, false
+	}
+
+	webhook, ok, err := dbclient.Dashboard.Webhooks.Get(ctx, guildId, webhookId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorJson(err))
+		return dbclient.Webhook{}, false
+	}
+
+	if !ok {
+		ctx.JSON(404, utils.ErrorStr("Webhook not found"))
+		return dbclient.Webhook{}, false
+	}
+
+	return webhook, true
+}
+
+func webhookToResponse(webhook dbclient.Webhook) webhookResponse {
+	return webhookResponse{
+		Id:  webhook.Id,
+		Url: webhook.Url,
+		Events: utils.Map(webhook.Events, func(event string) redis.TicketEventType {
+			return redis.TicketEventType(event)
+		}),
+		Enabled:   webhook.Enabled,
+		CreatedBy: webhook.CreatedBy,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
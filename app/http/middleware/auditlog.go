@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/audit"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"go.uber.org/zap"
+)
+
+const maxAuditBodySize = 64 * 1024
+
+// AuditLog records mutating requests to guild routes. Must be run after the guild ID has been parsed.
+func AuditLog(logger *zap.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.Request.Method == http.MethodGet || ctx.Request.Method == http.MethodHead || ctx.Request.Method == http.MethodOptions {
+			return
+		}
+
+		// Capture the request body, and restore it for the handler
+		var body []byte
+		if ctx.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(io.LimitReader(ctx.Request.Body, maxAuditBodySize+1))
+			if err != nil {
+				ctx.JSON(400, utils.ErrorJson(err))
+				ctx.Abort()
+				return
+			}
+
+			ctx.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), ctx.Request.Body))
+		}
+
+		ctx.Next()
+
+		if ctx.Writer.Status() >= 400 || len(ctx.Errors) > 0 || audit.IsSkipped(ctx) {
+			return
+		}
+
+		guildId, ok := ctx.Keys["guildid"].(uint64)
+		if !ok {
+			return
+		}
+
+		userId := ctx.Keys["userid"].(uint64)
+
+		before, err := audit.GetBefore(ctx)
+		if err != nil {
+			logger.Error("Failed to encode audit log state", zap.Error(err), zap.Uint64("guild_id", guildId))
+			return
+		}
+
+		after, err := audit.GetAfter(ctx)
+		if err != nil {
+			logger.Error("Failed to encode audit log state", zap.Error(err), zap.Uint64("guild_id", guildId))
+			return
+		}
+
+		// Fall back to the request body if the handler did not record the new state itself
+		if after == nil && len(body) > 0 && len(body) <= maxAuditBodySize && json.Valid(body) {
+			after, err = audit.Redact(body)
+			if err != nil {
+				logger.Error("Failed to redact audit log body", zap.Error(err), zap.Uint64("guild_id", guildId))
+				return
+			}
+		}
+
+		prefix := fmt.Sprintf("/api/%d", guildId)
+		entry := dbclient.AuditLogEntry{
+			GuildId: guildId,
+			UserId:  userId,
+			Action:  fmt.Sprintf("%s %s", ctx.Request.Method, strings.TrimPrefix(ctx.FullPath(), "/api/:id")),
+			Path:    strings.TrimPrefix(ctx.Request.URL.Path, prefix),
+			Before:  before,
+			After:   after,
+		}
+
+		if err := dbclient.Dashboard.AuditLog.Create(context.Background(), entry); err != nil {
+			logger.Error("Failed to write audit log entry", zap.Error(err), zap.Uint64("guild_id", guildId))
+		}
+	}
+}
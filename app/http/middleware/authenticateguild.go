@@ -25,6 +25,10 @@ func AuthenticateGuild(requiredPermissionLevel permission.PermissionLevel) gin.H
 
 			ctx.Keys["guildid"] = parsed
 
+			if !verifyApiTokenScope(ctx, parsed) {
+				return
+			}
+
 			// TODO: Do we need this? Only really serves as a check whether the bot is in the server
 			// TODO: Use proper context
 			if _, err := cache.Instance.GetGuildOwner(context.Background(), parsed); err != nil {
@@ -3,16 +3,24 @@ package middleware
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/apitoken"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/config"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
 )
 
 func AuthenticateToken(ctx *gin.Context) {
 	header := ctx.GetHeader("Authorization")
 
+	if strings.HasPrefix(header, apitoken.Prefix) {
+		authenticateApiToken(ctx, header)
+		return
+	}
+
 	token, err := jwt.Parse(header, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -49,3 +57,62 @@ func AuthenticateToken(ctx *gin.Context) {
 		return
 	}
 }
+
+// authenticateApiToken authenticates a request made with a personal access token. Tokens can only be used with guild
+// routes: the guild and scope restrictions are checked by verifyApiTokenScope once the guild ID has been parsed.
+func authenticateApiToken(ctx *gin.Context, header string) {
+	if _, ok := ctx.Params.Get("id"); !ok {
+		ctx.AbortWithStatusJSON(403, utils.ErrorStr("API tokens can only be used to access server routes"))
+		return
+	}
+
+	token, ok, err := dbclient.Dashboard.ApiTokens.GetByHash(ctx, apitoken.Hash(header))
+	if err != nil {
+		ctx.AbortWithStatusJSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if !ok {
+		ctx.AbortWithStatusJSON(401, utils.ErrorStr("Token is invalid"))
+		return
+	}
+
+	if err := dbclient.Dashboard.ApiTokens.UpdateLastUsed(ctx, token.Id); err != nil {
+		ctx.AbortWithStatusJSON(500, utils.ErrorJson(err))
+		return
+	}
+
+	if ctx.Keys == nil {
+		ctx.Keys = make(map[string]interface{})
+	}
+
+	ctx.Keys["userid"] = token.UserId
+	ctx.Keys["apitoken"] = token
+}
+
+// verifyApiTokenScope checks that the personal access token used for the request, if any, has been granted access to
+// the guild and route. The response is written if access is denied.
+func verifyApiTokenScope(ctx *gin.Context, guildId uint64) bool {
+	token, ok := ctx.Keys["apitoken"].(dbclient.ApiToken)
+	if !ok {
+		return true
+	}
+
+	if !utils.Contains(token.Guilds, guildId) {
+		ctx.AbortWithStatusJSON(403, utils.ErrorStr("This API token does not have access to this server"))
+		return false
+	}
+
+	required, ok := apitoken.RequiredScope(ctx.Request.Method, ctx.FullPath())
+	if !ok {
+		ctx.AbortWithStatusJSON(403, utils.ErrorStr("This route cannot be accessed with an API token"))
+		return false
+	}
+
+	if !apitoken.HasScope(token.Scopes, required) {
+		ctx.AbortWithStatusJSON(403, utils.ErrorStr("This API token is missing the %s scope", required))
+		return false
+	}
+
+	return true
+}
@@ -21,4 +21,6 @@ func ParseGuildId(ctx *gin.Context) {
 	}
 
 	ctx.Keys["guildid"] = parsed
+
+	verifyApiTokenScope(ctx, parsed)
 }
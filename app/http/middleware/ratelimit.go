@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"hash/fnv"
 	"strconv"
@@ -20,17 +21,52 @@ const (
 	RateLimitTypeGuild
 )
 
+// RateLimitBucket is a ratelimit shared by several routes, or by a route and callers outside of the HTTP middleware,
+// rather than one per route
+type RateLimitBucket struct {
+	Name   string
+	Max    int
+	Period time.Duration
+}
+
+func (b RateLimitBucket) limit() redis_rate.Limit {
+	return redis_rate.Limit{
+		Rate:   b.Max,
+		Burst:  b.Max,
+		Period: b.Period,
+	}
+}
+
 func CreateRateLimiter(rlType RateLimitType, max int, period time.Duration) gin.HandlerFunc {
+	limit := redis_rate.Limit{
+		Rate:   max,
+		Burst:  max,
+		Period: period,
+	}
+
+	return createRateLimiter(rlType, limit, func(ctx *gin.Context) string {
+		return ctx.FullPath()
+	})
+}
+
+// CreateSharedRateLimiter is CreateRateLimiter, taking tokens from the bucket instead of from a bucket for the route
+func CreateSharedRateLimiter(rlType RateLimitType, bucket RateLimitBucket) gin.HandlerFunc {
+	return createRateLimiter(rlType, bucket.limit(), func(*gin.Context) string {
+		return sharedBucketName(bucket)
+	})
+}
+
+// AllowGuild takes a token from the guild's shared bucket, for work that is not ratelimited by the HTTP middleware
+func AllowGuild(ctx context.Context, guildId uint64, bucket RateLimitBucket) (*redis_rate.Result, error) {
+	name := rateLimitKey(RateLimitTypeGuild, strconv.FormatUint(guildId, 10), bucket.limit(), sharedBucketName(bucket))
+	return redis_rate.NewLimiter(redis.Client).Allow(ctx, name, bucket.limit())
+}
+
+func createRateLimiter(rlType RateLimitType, limit redis_rate.Limit, scope func(*gin.Context) string) gin.HandlerFunc {
 	limiter := redis_rate.NewLimiter(redis.Client)
 
 	return func(ctx *gin.Context) {
-		limit := redis_rate.Limit{
-			Rate:   max,
-			Burst:  max,
-			Period: period,
-		}
-
-		name, skip := getKey(ctx, rlType, limit)
+		name, skip := getKey(ctx, rlType, limit, scope(ctx))
 		if skip {
 			ctx.Next()
 			return
@@ -66,6 +102,12 @@ func CreateRateLimiter(rlType RateLimitType, max int, period time.Duration) gin.
 	}
 }
 
+// sharedBucketName is the scope of a shared bucket. Routes are scoped by their path, which always starts with a slash,
+// so the two cannot collide.
+func sharedBucketName(bucket RateLimitBucket) string {
+	return "bucket:" + bucket.Name
+}
+
 func writeHeaders(ctx *gin.Context, res *redis_rate.Result) {
 	ctx.Set("rl_sr", res.Remaining)
 
@@ -75,7 +117,7 @@ func writeHeaders(ctx *gin.Context, res *redis_rate.Result) {
 }
 
 // Returns (key, skip)
-func getKey(ctx *gin.Context, rlType RateLimitType, limit redis_rate.Limit) (string, bool) {
+func getKey(ctx *gin.Context, rlType RateLimitType, limit redis_rate.Limit, scope string) (string, bool) {
 	userId := ctx.Keys["userid"]
 	guildId := ctx.Keys["guildid"]
 
@@ -94,11 +136,15 @@ func getKey(ctx *gin.Context, rlType RateLimitType, limit redis_rate.Limit) (str
 		key = strconv.FormatUint(guildId.(uint64), 10)
 	}
 
+	return rateLimitKey(rlType, key, limit, scope), false
+}
+
+func rateLimitKey(rlType RateLimitType, key string, limit redis_rate.Limit, scope string) string {
 	target := fmt.Sprintf("%d:%s", rlType, key)
 	bucket := fmt.Sprintf("%d/%d", limit.Rate, limit.Period.Milliseconds())
-	full := fmt.Sprintf("%s:%s:%s", target, bucket, ctx.FullPath())
+	full := fmt.Sprintf("%s:%s:%s", target, bucket, scope)
 
-	return strconv.FormatUint(uint64(hash(full)), 16), false
+	return strconv.FormatUint(uint64(hash(full)), 16)
 }
 
 func hash(str string) uint32 {
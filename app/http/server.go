@@ -1,14 +1,24 @@
 package http
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/admin/botstaff"
+	api_analytics "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/analytics"
+	api_apitokens "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/apitokens"
+	api_auditlog "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/auditlog"
 	api_blacklist "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/blacklist"
+	api_config "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/config"
+	api_embedtemplates "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/embedtemplates"
 	api_forms "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/forms"
 	api_integrations "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/integrations"
+	api_labels "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/labels"
+	api_notes "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/notes"
 	api_panels "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/panel"
 	api_premium "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/premium"
 	api_settings "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/settings"
@@ -18,6 +28,7 @@ import (
 	api_ticket "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/ticket"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/ticket/livechat"
 	api_transcripts "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/transcripts"
+	api_webhooks "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/webhooks"
 	api_whitelabel "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/whitelabel"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/root"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/middleware"
@@ -28,7 +39,10 @@ import (
 	"go.uber.org/zap"
 )
 
-func StartServer(logger *zap.Logger, sm *livechat.SocketManager) {
+// shutdownTimeout is how long in-flight requests are given to complete once the server is shutting down
+const shutdownTimeout = 10 * time.Second
+
+func StartServer(ctx context.Context, logger *zap.Logger, sm *livechat.SocketManager) {
 	logger.Info("Starting HTTP server")
 
 	router := gin.New()
@@ -100,9 +114,9 @@ func StartServer(logger *zap.Logger, sm *livechat.SocketManager) {
 		}
 	}
 
-	guildAuthApiAdmin := apiGroup.Group("/:id", middleware.AuthenticateGuild(permission.Admin))
-	guildAuthApiSupport := apiGroup.Group("/:id", middleware.AuthenticateGuild(permission.Support))
-	guildApiNoAuth := apiGroup.Group("/:id", middleware.ParseGuildId)
+	guildAuthApiAdmin := apiGroup.Group("/:id", middleware.AuthenticateGuild(permission.Admin), middleware.AuditLog(logger))
+	guildAuthApiSupport := apiGroup.Group("/:id", middleware.AuthenticateGuild(permission.Support), middleware.AuditLog(logger))
+	guildApiNoAuth := apiGroup.Group("/:id", middleware.ParseGuildId, middleware.AuditLog(logger))
 	{
 		guildAuthApiSupport.GET("/guild", api.GuildHandler)
 		guildAuthApiSupport.GET("/channels", api.ChannelsHandler)
@@ -129,13 +143,22 @@ func StartServer(logger *zap.Logger, sm *livechat.SocketManager) {
 		// Must be readable to load transcripts page
 		guildAuthApiSupport.GET("/panels", api_panels.ListPanels)
 		guildAuthApiAdmin.POST("/panels", api_panels.CreatePanel)
-		guildAuthApiAdmin.POST("/panels/:panelid", rl(middleware.RateLimitTypeGuild, 5, 5*time.Second), api_panels.ResendPanel)
+		guildAuthApiAdmin.POST("/panels/resend", rl(middleware.RateLimitTypeGuild, 2, time.Minute), api_panels.BulkResendPanels)
+		guildAuthApiAdmin.POST("/panels/:panelid", middleware.CreateSharedRateLimiter(middleware.RateLimitTypeGuild, api_panels.ResendRateLimit), api_panels.ResendPanel)
 		guildAuthApiAdmin.PATCH("/panels/:panelid", api_panels.UpdatePanel)
 		guildAuthApiAdmin.DELETE("/panels/:panelid", api_panels.DeletePanel)
+		guildAuthApiAdmin.POST("/panels/:panelid/clone", rl(middleware.RateLimitTypeGuild, 5, time.Minute), api_config.ClonePanelHandler)
+		guildAuthApiAdmin.POST("/panels/:panelid/copy", rl(middleware.RateLimitTypeGuild, 5, time.Minute), api_config.CopyPanelHandler)
+		guildAuthApiAdmin.GET("/panels/:panelid/schedules", api_panels.ListPanelSchedules)
+		guildAuthApiAdmin.POST("/panels/:panelid/schedules", rl(middleware.RateLimitTypeGuild, 10, time.Minute), api_panels.CreatePanelSchedule)
+		guildAuthApiAdmin.DELETE("/panels/:panelid/schedules/:scheduleid", api_panels.CancelPanelSchedule)
+		guildAuthApiAdmin.PUT("/panels/:panelid/sla", api_panels.SetPanelSlaPolicy)
+		guildAuthApiAdmin.DELETE("/panels/:panelid/sla", api_panels.DeletePanelSlaPolicy)
+		guildAuthApiAdmin.GET("/sla-policies", api_panels.ListSlaPolicies)
 
 		guildAuthApiAdmin.GET("/multipanels", api_panels.MultiPanelList)
 		guildAuthApiAdmin.POST("/multipanels", api_panels.MultiPanelCreate)
-		guildAuthApiAdmin.POST("/multipanels/:panelid", rl(middleware.RateLimitTypeGuild, 5, 5*time.Second), api_panels.MultiPanelResend)
+		guildAuthApiAdmin.POST("/multipanels/:panelid", middleware.CreateSharedRateLimiter(middleware.RateLimitTypeGuild, api_panels.ResendRateLimit), api_panels.MultiPanelResend)
 		guildAuthApiAdmin.PATCH("/multipanels/:panelid", api_panels.MultiPanelUpdate)
 		guildAuthApiAdmin.DELETE("/multipanels/:panelid", api_panels.MultiPanelDelete)
 
@@ -144,6 +167,8 @@ func StartServer(logger *zap.Logger, sm *livechat.SocketManager) {
 		guildAuthApiAdmin.PATCH("/forms/:form_id", rl(middleware.RateLimitTypeGuild, 30, time.Hour), api_forms.UpdateForm)
 		guildAuthApiAdmin.DELETE("/forms/:form_id", api_forms.DeleteForm)
 		guildAuthApiAdmin.PATCH("/forms/:form_id/inputs", api_forms.UpdateInputs)
+		guildAuthApiSupport.GET("/forms/:form_id/schema", api_forms.GetSchema)
+		guildAuthApiAdmin.PUT("/forms/:form_id/schema", rl(middleware.RateLimitTypeGuild, 30, time.Hour), api_forms.UpdateSchema)
 
 		// Should be a GET, but easier to take a body for development purposes
 		guildAuthApiSupport.POST("/transcripts",
@@ -152,22 +177,45 @@ func StartServer(logger *zap.Logger, sm *livechat.SocketManager) {
 			api_transcripts.ListTranscripts,
 		)
 
+		guildAuthApiAdmin.POST("/transcripts/export", rl(middleware.RateLimitTypeGuild, 5, time.Hour), api_transcripts.CreateTranscriptExportHandler)
+		guildAuthApiAdmin.GET("/transcripts/export/:jobid", api_transcripts.GetTranscriptExportHandler)
+		guildAuthApiAdmin.GET("/transcripts/export/:jobid/download", api_transcripts.DownloadTranscriptExportHandler)
+
 		// Allow regular users to get their own transcripts, make sure you check perms inside
+		guildApiNoAuth.GET("/transcripts/search", rl(middleware.RateLimitTypeGuild, 10, 10*time.Second), api_transcripts.SearchTranscripts)
 		guildApiNoAuth.GET("/transcripts/:ticketId", rl(middleware.RateLimitTypeGuild, 10, 10*time.Second), api_transcripts.GetTranscriptHandler)
 		guildApiNoAuth.GET("/transcripts/:ticketId/render", rl(middleware.RateLimitTypeGuild, 10, 10*time.Second), api_transcripts.GetTranscriptRenderHandler)
+		guildAuthApiSupport.GET("/transcripts/:ticketId/notes", api_notes.ListNotesHandler)
 
 		guildAuthApiSupport.GET("/tickets", api_ticket.GetTickets)
 		guildAuthApiSupport.GET("/tickets/:ticketId", api_ticket.GetTicket)
-		guildAuthApiSupport.POST("/tickets/:ticketId", rl(middleware.RateLimitTypeGuild, 5, time.Second*5), api_ticket.SendMessage)
-		guildAuthApiSupport.POST("/tickets/:ticketId/tag", rl(middleware.RateLimitTypeGuild, 5, time.Second*5), api_ticket.SendTag)
+		guildAuthApiSupport.POST("/tickets/:ticketId", middleware.CreateSharedRateLimiter(middleware.RateLimitTypeGuild, api_ticket.SendRateLimit), api_ticket.SendMessage)
+		guildAuthApiSupport.POST("/tickets/:ticketId/tag", middleware.CreateSharedRateLimiter(middleware.RateLimitTypeGuild, api_ticket.SendRateLimit), api_ticket.SendTag)
 		guildAuthApiSupport.DELETE("/tickets/:ticketId", api_ticket.CloseTicket)
+		guildAuthApiSupport.POST("/tickets/:ticketId/claim", rl(middleware.RateLimitTypeGuild, 10, time.Second*10), api_ticket.ClaimTicket)
+		guildAuthApiSupport.DELETE("/tickets/:ticketId/claim", rl(middleware.RateLimitTypeGuild, 10, time.Second*10), api_ticket.UnclaimTicket)
+		guildAuthApiSupport.POST("/tickets/:ticketId/transfer", rl(middleware.RateLimitTypeGuild, 10, time.Second*10), api_ticket.TransferTicket)
+		guildAuthApiSupport.GET("/tickets/:ticketId/participants", api_ticket.ListParticipants)
+		guildAuthApiSupport.POST("/tickets/:ticketId/participants", rl(middleware.RateLimitTypeGuild, 10, time.Second*10), api_ticket.AddParticipant)
+		guildAuthApiSupport.DELETE("/tickets/:ticketId/participants/:type/:participantid", rl(middleware.RateLimitTypeGuild, 10, time.Second*10), api_ticket.RemoveParticipant)
+		guildAuthApiSupport.PUT("/tickets/:ticketId/labels", rl(middleware.RateLimitTypeGuild, 10, time.Second*10), api_ticket.SetTicketLabels)
+		guildAuthApiSupport.PUT("/tickets/:ticketId/priority", rl(middleware.RateLimitTypeGuild, 10, time.Second*10), api_ticket.SetTicketPriority)
+		guildAuthApiSupport.GET("/tickets/:ticketId/notes", api_notes.ListNotesHandler)
+		guildAuthApiSupport.POST("/tickets/:ticketId/notes", rl(middleware.RateLimitTypeGuild, 10, time.Second*10), api_notes.CreateNoteHandler)
+		guildAuthApiSupport.PATCH("/tickets/:ticketId/notes/:noteid", rl(middleware.RateLimitTypeGuild, 10, time.Second*10), api_notes.UpdateNoteHandler)
+		guildAuthApiSupport.DELETE("/tickets/:ticketId/notes/:noteid", rl(middleware.RateLimitTypeGuild, 10, time.Second*10), api_notes.DeleteNoteHandler)
 
 		// Websockets do not support headers: so we must implement authentication over the WS connection
 		router.GET("/api/:id/tickets/:ticketId/live-chat", livechat.GetLiveChatHandler(sm))
+		router.GET("/api/:id/tickets/live-feed", livechat.GetLiveFeedHandler(sm))
 
 		guildAuthApiSupport.GET("/tags", api_tags.TagsListHandler)
 		guildAuthApiSupport.PUT("/tags", api_tags.CreateTag)
 		guildAuthApiSupport.DELETE("/tags", api_tags.DeleteTag)
+		guildAuthApiSupport.GET("/tags/export", rl(middleware.RateLimitTypeGuild, 5, time.Second*10), api_tags.ExportTagsHandler)
+		guildAuthApiSupport.POST("/tags/import", rl(middleware.RateLimitTypeGuild, 2, time.Minute), api_tags.ImportTagsHandler)
+		guildAuthApiSupport.POST("/tags/bulk-delete", rl(middleware.RateLimitTypeGuild, 2, time.Minute), api_tags.BulkDeleteTagsHandler)
+		guildAuthApiSupport.POST("/tags/:tag/preview", rl(middleware.RateLimitTypeGuild, 10, time.Second*10), api_tags.PreviewTagHandler)
 
 		guildAuthApiAdmin.GET("/team", api_team.GetTeams)
 		guildAuthApiAdmin.GET("/team/:teamid", rl(middleware.RateLimitTypeUser, 10, time.Second*30), api_team.GetMembers)
@@ -191,6 +239,29 @@ func StartServer(logger *zap.Logger, sm *livechat.SocketManager) {
 		)
 		guildAuthApiAdmin.PATCH("/integrations/:integrationid", api_integrations.UpdateIntegrationSecretsHandler)
 		guildAuthApiAdmin.DELETE("/integrations/:integrationid", api_integrations.RemoveIntegrationHandler)
+
+		guildAuthApiAdmin.GET("/audit-log", api_auditlog.ListAuditLogHandler)
+		guildAuthApiAdmin.GET("/analytics", rl(middleware.RateLimitTypeGuild, 10, time.Minute), api_analytics.GetAnalyticsHandler)
+
+		guildAuthApiAdmin.GET("/config/export", rl(middleware.RateLimitTypeGuild, 5, time.Minute), api_config.ExportConfigHandler)
+		guildAuthApiAdmin.POST("/config/import", rl(middleware.RateLimitTypeGuild, 3, time.Hour), api_config.ImportConfigHandler)
+
+		guildAuthApiSupport.GET("/ticket-labels", api_labels.ListLabelsHandler)
+		guildAuthApiAdmin.POST("/ticket-labels", rl(middleware.RateLimitTypeGuild, 10, time.Minute), api_labels.CreateLabelHandler)
+		guildAuthApiAdmin.PATCH("/ticket-labels/:labelid", api_labels.UpdateLabelHandler)
+		guildAuthApiAdmin.DELETE("/ticket-labels/:labelid", api_labels.DeleteLabelHandler)
+
+		guildAuthApiSupport.GET("/embed-templates", api_embedtemplates.ListTemplatesHandler)
+		guildAuthApiAdmin.POST("/embed-templates", rl(middleware.RateLimitTypeGuild, 10, time.Minute), api_embedtemplates.CreateTemplateHandler)
+		guildAuthApiAdmin.PATCH("/embed-templates/:templateid", rl(middleware.RateLimitTypeGuild, 5, time.Minute), api_embedtemplates.UpdateTemplateHandler)
+		guildAuthApiAdmin.DELETE("/embed-templates/:templateid", api_embedtemplates.DeleteTemplateHandler)
+
+		guildAuthApiAdmin.GET("/webhooks", api_webhooks.ListWebhooksHandler)
+		guildAuthApiAdmin.POST("/webhooks", rl(middleware.RateLimitTypeGuild, 10, time.Minute), api_webhooks.CreateWebhookHandler)
+		guildAuthApiAdmin.PATCH("/webhooks/:webhookid", api_webhooks.UpdateWebhookHandler)
+		guildAuthApiAdmin.DELETE("/webhooks/:webhookid", api_webhooks.DeleteWebhookHandler)
+		guildAuthApiAdmin.POST("/webhooks/:webhookid/secret", rl(middleware.RateLimitTypeGuild, 5, time.Minute), api_webhooks.RotateWebhookSecretHandler)
+		guildAuthApiAdmin.GET("/webhooks/:webhookid/deliveries", api_webhooks.ListWebhookDeliveriesHandler)
 	}
 
 	userGroup := router.Group("/user", middleware.AuthenticateToken, middleware.UpdateLastSeen)
@@ -198,6 +269,10 @@ func StartServer(logger *zap.Logger, sm *livechat.SocketManager) {
 		userGroup.POST("/guilds/reload", api.ReloadGuildsHandler)
 		userGroup.GET("/permissionlevel", api.GetPermissionLevel)
 
+		userGroup.GET("/tokens", api_apitokens.ListTokensHandler)
+		userGroup.POST("/tokens", rl(middleware.RateLimitTypeUser, 5, time.Minute), api_apitokens.CreateTokenHandler)
+		userGroup.DELETE("/tokens/:tokenid", api_apitokens.DeleteTokenHandler)
+
 		{
 			whitelabelGroup := userGroup.Group("/whitelabel")
 
@@ -219,7 +294,25 @@ func StartServer(logger *zap.Logger, sm *livechat.SocketManager) {
 		adminGroup.DELETE("/bot-staff/:userid", botstaff.RemoveBotStaffHandler)
 	}
 
-	if err := router.Run(config.Conf.Server.Host); err != nil {
+	server := &http.Server{
+		Addr:    config.Conf.Server.Host,
+		Handler: router,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		logger.Info("Shutting down HTTP server")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Failed to shut down HTTP server", zap.Error(err))
+		}
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		panic(err)
 	}
 }
@@ -1,8 +1,31 @@
 package validation
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorCode is a machine-readable description of why a field failed validation
+type ErrorCode string
+
+const (
+	CodeInvalid       ErrorCode = "invalid"
+	CodeRequired      ErrorCode = "required"
+	CodeTooShort      ErrorCode = "too_short"
+	CodeTooLong       ErrorCode = "too_long"
+	CodeTooSmall      ErrorCode = "too_small"
+	CodeTooLarge      ErrorCode = "too_large"
+	CodeNotFound      ErrorCode = "not_found"
+	CodeDuplicate     ErrorCode = "duplicate"
+	CodeLimitExceeded ErrorCode = "limit_exceeded"
+)
 
 type InvalidInputError struct {
+	Code ErrorCode
+	// Pointer is a JSON pointer (RFC 6901) to the offending field in the request body. It is empty if the error is not
+	// specific to a single field.
+	Pointer string
 	Message string
 }
 
@@ -10,10 +33,126 @@ func (e *InvalidInputError) Error() string {
 	return e.Message
 }
 
+// WithPrefix returns a copy of the error with the pointer nested under prefix, for input validated as part of a larger
+// request body
+func (e *InvalidInputError) WithPrefix(prefix string) *InvalidInputError {
+	wrapped := *e
+	wrapped.Pointer = prefix + e.Pointer
+	return &wrapped
+}
+
 func NewInvalidInputError(message string) *InvalidInputError {
-	return &InvalidInputError{Message: message}
+	return &InvalidInputError{Code: CodeInvalid, Message: message}
 }
 
 func NewInvalidInputErrorf(message string, args ...any) *InvalidInputError {
-	return &InvalidInputError{Message: fmt.Sprintf(message, args...)}
+	return NewInvalidInputError(fmt.Sprintf(message, args...))
+}
+
+// NewFieldError returns an error for the field at pointer
+func NewFieldError(pointer string, code ErrorCode, message string) *InvalidInputError {
+	return &InvalidInputError{Code: code, Pointer: pointer, Message: message}
+}
+
+func NewFieldErrorf(pointer string, code ErrorCode, message string, args ...any) *InvalidInputError {
+	return NewFieldError(pointer, code, fmt.Sprintf(message, args...))
+}
+
+// InvalidInputErrors is returned when more than one field fails validation
+type InvalidInputErrors []*InvalidInputError
+
+func (e InvalidInputErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+func (e InvalidInputErrors) Unwrap() []error {
+	unwrapped := make([]error, len(e))
+	for i, err := range e {
+		unwrapped[i] = err
+	}
+
+	return unwrapped
+}
+
+// Join collects the validation failures in errs, ignoring nil errors. If any of errs is not a validation error, it is
+// returned instead, as the input could not be fully validated.
+func Join(errs ...error) error {
+	var collected InvalidInputErrors
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		failures, ok := Failures(err)
+		if !ok {
+			return err
+		}
+
+		collected = append(collected, failures...)
+	}
+
+	switch len(collected) {
+	case 0:
+		return nil
+	case 1:
+		return collected[0]
+	default:
+		return collected
+	}
+}
+
+// Failures returns the individual validation failures that make up err, or false if err is not a validation error
+func Failures(err error) (InvalidInputErrors, bool) {
+	var multiple InvalidInputErrors
+	if errors.As(err, &multiple) {
+		return multiple, true
+	}
+
+	var single *InvalidInputError
+	if errors.As(err, &single) {
+		return InvalidInputErrors{single}, true
+	}
+
+	return nil, false
+}
+
+// WithPrefix nests the pointers of all validation failures in err under prefix. Other errors are returned unchanged.
+func WithPrefix(err error, prefix string) error {
+	failures, ok := Failures(err)
+	if !ok {
+		return err
+	}
+
+	prefixed := make(InvalidInputErrors, len(failures))
+	for i, failure := range failures {
+		prefixed[i] = failure.WithPrefix(prefix)
+	}
+
+	return Join(prefixed.Unwrap()...)
+}
+
+// TrimPrefix removes prefix from the pointers of the validation failures in err that are nested under it, for input
+// that was validated as part of a larger document than the request body. Other errors are returned unchanged.
+func TrimPrefix(err error, prefix string) error {
+	failures, ok := Failures(err)
+	if !ok {
+		return err
+	}
+
+	trimmed := make(InvalidInputErrors, len(failures))
+	for i, failure := range failures {
+		trimmed[i] = failure
+		if failure.Pointer == prefix || strings.HasPrefix(failure.Pointer, prefix+"/") {
+			copied := *failure
+			copied.Pointer = strings.TrimPrefix(failure.Pointer, prefix)
+			trimmed[i] = &copied
+		}
+	}
+
+	return Join(trimmed.Unwrap()...)
 }
@@ -0,0 +1,63 @@
+package validation
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	// ErrorResponse is the body returned when a request fails validation. Error is kept alongside Errors for clients
+	// that only display a single message.
+	ErrorResponse struct {
+		Success bool         `json:"success"`
+		Error   string       `json:"error"`
+		Errors  []FieldError `json:"errors"`
+	}
+
+	FieldError struct {
+		Code    ErrorCode `json:"code"`
+		Pointer string    `json:"pointer"`
+		Message string    `json:"message"`
+	}
+)
+
+// NewErrorResponse returns the response body for err, or false if err is not a validation error
+func NewErrorResponse(err error) (ErrorResponse, bool) {
+	failures, ok := Failures(err)
+	if !ok {
+		return ErrorResponse{}, false
+	}
+
+	fieldErrors := make([]FieldError, len(failures))
+	for i, failure := range failures {
+		fieldErrors[i] = FieldError{
+			Code:    failure.Code,
+			Pointer: failure.Pointer,
+			Message: failure.Message,
+		}
+	}
+
+	return ErrorResponse{
+		Success: false,
+		Error:   failures.Error(),
+		Errors:  fieldErrors,
+	}, true
+}
+
+// RespondError writes a 400 response describing err if it is a validation error. If it is not, nothing is written and
+// false is returned, so that the caller can handle it as a server error.
+func RespondError(ctx *gin.Context, err error) bool {
+	res, ok := NewErrorResponse(err)
+	if !ok {
+		return false
+	}
+
+	ctx.JSON(http.StatusBadRequest, res)
+	return true
+}
+
+// RespondField writes a 400 response for a single invalid field
+func RespondField(ctx *gin.Context, pointer string, code ErrorCode, message string, args ...any) {
+	RespondError(ctx, NewFieldErrorf(pointer, code, message, args...))
+}
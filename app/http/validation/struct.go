@@ -0,0 +1,146 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+)
+
+// Struct validates v using its struct tags, returning a failure for each invalid field with a pointer to the field as
+// it appears in the JSON request body
+func Struct(validate *validator.Validate, v any) error {
+	err := validate.Struct(v)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	root := reflect.TypeOf(v)
+	failures := make(InvalidInputErrors, len(validationErrors))
+	for i, fieldError := range validationErrors {
+		failures[i] = NewFieldError(jsonPointer(root, fieldError.StructNamespace()), errorCode(fieldError), utils.FormatValidationError(fieldError))
+	}
+
+	return Join(failures.Unwrap()...)
+}
+
+func errorCode(err validator.FieldError) ErrorCode {
+	kind := err.Kind()
+	isLength := kind == reflect.String || kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map
+
+	switch err.Tag() {
+	case "required", "required_if", "required_unless", "required_with", "required_without":
+		return CodeRequired
+	case "min", "gte", "gt":
+		if isLength {
+			return CodeTooShort
+		}
+
+		return CodeTooSmall
+	case "max", "lte", "lt":
+		if isLength {
+			return CodeTooLong
+		}
+
+		return CodeTooLarge
+	case "unique":
+		return CodeDuplicate
+	default:
+		return CodeInvalid
+	}
+}
+
+// jsonPointer converts a validator struct namespace, such as "PanelBody.WelcomeMessage.Fields[0].Name", to a JSON
+// pointer, such as "/welcome_message/fields/0/name", using the json tags of the fields in root
+func jsonPointer(root reflect.Type, namespace string) string {
+	segments := strings.Split(namespace, ".")
+	if len(segments) <= 1 {
+		return ""
+	}
+
+	var pointer strings.Builder
+
+	current := root
+	for _, segment := range segments[1:] { // The first segment is the name of the root type
+		name, indexes := splitIndexes(segment)
+
+		current = derefType(current)
+		if current.Kind() != reflect.Struct {
+			break
+		}
+
+		field, ok := current.FieldByName(name)
+		if !ok {
+			break
+		}
+
+		// Fields of embedded structs are flattened into the parent object
+		if jsonName := jsonFieldName(field); !field.Anonymous || jsonName != field.Name {
+			pointer.WriteString("/" + escapePointerToken(jsonName))
+		}
+
+		current = field.Type
+		for _, index := range indexes {
+			pointer.WriteString("/" + escapePointerToken(index))
+
+			if current = derefType(current); current.Kind() == reflect.Slice || current.Kind() == reflect.Array || current.Kind() == reflect.Map {
+				current = current.Elem()
+			}
+		}
+	}
+
+	return pointer.String()
+}
+
+// splitIndexes splits a namespace segment such as "Fields[0]" into the field name and its indexes
+func splitIndexes(segment string) (string, []string) {
+	start := strings.IndexByte(segment, '[')
+	if start == -1 {
+		return segment, nil
+	}
+
+	name := segment[:start]
+
+	var indexes []string
+	for _, part := range strings.Split(segment[start:], "[") {
+		if part = strings.TrimSuffix(part, "]"); part != "" {
+			indexes = append(indexes, part)
+		}
+	}
+
+	return name, indexes
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if tag == "" || tag == "-" {
+		return field.Name
+	}
+
+	return tag
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+func escapePointerToken(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// IndexPointer returns a JSON pointer to the element at index of the array at pointer
+func IndexPointer(pointer string, index int) string {
+	return pointer + "/" + strconv.Itoa(index)
+}
@@ -0,0 +1,54 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	testField struct {
+		Name string `json:"name" validate:"required,max=5"`
+	}
+
+	testEmbedded struct {
+		Label string `json:"label" validate:"max=3"`
+	}
+
+	testBody struct {
+		testEmbedded
+		Title  string      `json:"title" validate:"required"`
+		Fields []testField `json:"fields" validate:"dive"`
+		Nested *testField  `json:"nested,omitempty" validate:"omitempty"`
+	}
+)
+
+func TestStructPointers(t *testing.T) {
+	body := testBody{
+		testEmbedded: testEmbedded{Label: "long"},
+		Fields:       []testField{{Name: "ok"}, {Name: "too long"}},
+		Nested:       &testField{},
+	}
+
+	failures, ok := Failures(Struct(validator.New(), body))
+	if !ok {
+		t.Fatal("expected validation error")
+	}
+
+	pointers := make(map[string]ErrorCode)
+	for _, failure := range failures {
+		pointers[failure.Pointer] = failure.Code
+	}
+
+	assert.Equal(t, map[string]ErrorCode{
+		"/label":         CodeTooLong,
+		"/title":         CodeRequired,
+		"/fields/1/name": CodeTooLong,
+		"/nested/name":   CodeRequired,
+	}, pointers)
+}
+
+func TestStructValid(t *testing.T) {
+	assert.NoError(t, Struct(validator.New(), testField{Name: "ok"}))
+}
@@ -79,3 +79,56 @@ func TestDualFail(t *testing.T) {
 		t.Errorf("got wrong error message: %s", validationError.Message)
 	}
 }
+
+func TestAllFailuresCollected(t *testing.T) {
+	ctx := testCtx{a: 0, b: ""}
+	err := Validate(context.Background(), ctx, validateGreaterThanZero, validateStringNotEmpty)
+
+	failures, ok := Failures(err)
+	if !ok {
+		t.Fatal("expected validation error")
+	}
+
+	// Failures are returned in the order of the validators
+	assert.Len(t, failures, 2)
+	assert.Equal(t, "a must be greater than 0", failures[0].Message)
+	assert.Equal(t, "b must be greater than 0", failures[1].Message)
+}
+
+func TestJoinReturnsInternalError(t *testing.T) {
+	internal := errors.New("database unavailable")
+	err := Join(NewInvalidInputError("invalid"), internal)
+
+	assert.Equal(t, internal, err)
+}
+
+func TestWithPrefix(t *testing.T) {
+	err := WithPrefix(Join(NewFieldError("/name", CodeRequired, "required"), NewFieldError("", CodeInvalid, "invalid")), "/tags/1")
+
+	failures, ok := Failures(err)
+	if !ok {
+		t.Fatal("expected validation error")
+	}
+
+	assert.Equal(t, "/tags/1/name", failures[0].Pointer)
+	assert.Equal(t, "/tags/1", failures[1].Pointer)
+}
+
+func TestTrimPrefix(t *testing.T) {
+	err := TrimPrefix(Join(
+		NewFieldError("/panels/0/title", CodeRequired, "required"),
+		NewFieldError("/panels/0", CodeInvalid, "invalid"),
+		NewFieldError("/panels/01", CodeInvalid, "invalid"),
+		NewFieldError("/forms/0", CodeInvalid, "invalid"),
+	), "/panels/0")
+
+	failures, ok := Failures(err)
+	if !ok {
+		t.Fatal("expected validation error")
+	}
+
+	assert.Equal(t, "/title", failures[0].Pointer)
+	assert.Equal(t, "", failures[1].Pointer)
+	assert.Equal(t, "/panels/01", failures[2].Pointer)
+	assert.Equal(t, "/forms/0", failures[3].Pointer)
+}
@@ -2,19 +2,30 @@ package validation
 
 import (
 	"context"
+
 	"golang.org/x/sync/errgroup"
 )
 
 type Validator[T any] func(validationContext T) ValidationFunc
 type ValidationFunc func() error
 
+// Validate runs the validators concurrently, collecting every failure. Failures are returned in the order that the
+// validators were passed, so that the response is stable.
 func Validate[T any](ctx context.Context, validationContext T, validators ...Validator[T]) error {
 	group, _ := errgroup.WithContext(ctx)
 
-	for _, validator := range validators {
-		validator := validator
-		group.Go(validator(validationContext))
+	results := make([]error, len(validators))
+	for i, validator := range validators {
+		i := i
+		f := validator(validationContext)
+
+		group.Go(func() error {
+			results[i] = f()
+			return nil
+		})
 	}
 
-	return group.Wait()
+	_ = group.Wait()
+
+	return Join(results...)
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/jadevelopmentgrp/Tickets-Utilities/restcache"
 	cache "github.com/rxdn/gdl/cache"
 	"github.com/rxdn/gdl/objects/channel"
+	"github.com/rxdn/gdl/objects/channel/message"
 	"github.com/rxdn/gdl/objects/guild"
 	"github.com/rxdn/gdl/objects/guild/emoji"
 	"github.com/rxdn/gdl/objects/interaction"
@@ -178,6 +179,35 @@ func (c *BotContext) GetGuildChannels(ctx context.Context, guildId uint64) ([]ch
 	}
 }
 
+// GetChannel fetches the channel from Discord rather than the cache, as callers use it to modify the channel
+func (c *BotContext) GetChannel(ctx context.Context, channelId uint64) (channel.Channel, error) {
+	return rest.GetChannel(ctx, c.Token, c.RateLimiter, channelId)
+}
+
+func (c *BotContext) GetChannelMessage(ctx context.Context, channelId, messageId uint64) (message.Message, error) {
+	return rest.GetChannelMessage(ctx, c.Token, c.RateLimiter, channelId, messageId)
+}
+
+func (c *BotContext) ModifyChannel(ctx context.Context, channelId uint64, data rest.ModifyChannelData) (channel.Channel, error) {
+	return rest.ModifyChannel(ctx, c.Token, c.RateLimiter, channelId, data)
+}
+
+func (c *BotContext) EditChannelPermissions(ctx context.Context, channelId uint64, overwrite channel.PermissionOverwrite) error {
+	return rest.EditChannelPermissions(ctx, c.Token, c.RateLimiter, channelId, overwrite)
+}
+
+func (c *BotContext) DeleteChannelPermissions(ctx context.Context, channelId, overwriteId uint64) error {
+	return rest.DeleteChannelPermissions(ctx, c.Token, c.RateLimiter, channelId, overwriteId)
+}
+
+func (c *BotContext) AddThreadMember(ctx context.Context, channelId, userId uint64) error {
+	return rest.AddThreadMember(ctx, c.Token, c.RateLimiter, channelId, userId)
+}
+
+func (c *BotContext) RemoveThreadMember(ctx context.Context, channelId, userId uint64) error {
+	return rest.RemoveThreadMember(ctx, c.Token, c.RateLimiter, channelId, userId)
+}
+
 func (c *BotContext) GetGuildEmoji(ctx context.Context, guildId, emojiId uint64) (emoji.Emoji, error) {
 	e, err := cacheclient.Instance.GetEmoji(ctx, guildId)
 	switch {
@@ -1,18 +1,26 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/pprof"
+	"os"
+	"os/signal"
+	"syscall"
 
 	archiverclient "github.com/jadevelopmentgrp/Tickets-Archiver-Client"
 	app "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http"
+	api_panels "github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/panel"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/endpoints/api/ticket/livechat"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/config"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/database"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/redis"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/rpc/cache"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/sla"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/transcriptsearch"
 	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/webhooks"
 	"github.com/jadevelopmentgrp/Tickets-Utilities/chatrelay"
 	"github.com/jadevelopmentgrp/Tickets-Utilities/observability"
 	"github.com/jadevelopmentgrp/Tickets-Utilities/secureproxy"
@@ -61,6 +69,10 @@ func main() {
 	utils.ArchiverClient = archiverclient.NewArchiverClient(archiverclient.NewProxyRetriever(config.Conf.Bot.ObjectStore), []byte(config.Conf.Bot.AesKey))
 	utils.SecureProxyClient = secureproxy.NewSecureProxy(config.Conf.SecureProxyUrl)
 
+	logger.Info("Loading transcript search index")
+	transcriptsearch.Instance, err = transcriptsearch.NewIndex(config.Conf.TranscriptIndex.Path, config.Conf.TranscriptIndex.MaxGuilds, logger)
+	utils.Must(err)
+
 	utils.LoadEmoji()
 
 	i18n.Init()
@@ -72,13 +84,26 @@ func main() {
 	logger.Info("Connecting to Redis")
 	redis.Client = redis.NewRedisClient()
 
-	socketManager := livechat.NewSocketManager()
+	socketManager := livechat.NewSocketManager(logger)
 	go socketManager.Run()
 
 	go ListenChat(redis.Client, socketManager)
+	go ListenLiveChatPresence(redis.Client, socketManager)
+	go ListenTicketEvents(redis.Client, socketManager)
+
+	// Cancelled on shutdown, so that the background workers stop before the process exits
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go api_panels.RunPanelScheduler(ctx, logger)
+	go api_panels.RunPanelHealthVerifier(ctx, redis.Client, logger)
+	go webhooks.ListenEvents(ctx, redis.Client, logger)
+	go webhooks.RunEventSource(ctx, redis.Client, logger)
+	go webhooks.RunDeliveryWorker(ctx, logger)
+	go sla.RunEvaluator(ctx, redis.Client, logger)
 
 	logger.Info("Starting server")
-	app.StartServer(logger, socketManager)
+	app.StartServer(ctx, logger, socketManager)
 }
 
 func ListenChat(client *redis.RedisClient, sm *livechat.SocketManager) {
@@ -90,6 +115,24 @@ func ListenChat(client *redis.RedisClient, sm *livechat.SocketManager) {
 	}
 }
 
+func ListenTicketEvents(client *redis.RedisClient, sm *livechat.SocketManager) {
+	ch := make(chan redis.TicketEvent)
+	go client.ListenTicketEvents(context.Background(), ch)
+
+	for event := range ch {
+		sm.BroadcastTicketEvent(event)
+	}
+}
+
+func ListenLiveChatPresence(client *redis.RedisClient, sm *livechat.SocketManager) {
+	ch := make(chan redis.LiveChatPresence)
+	go client.ListenLiveChatPresence(context.Background(), ch)
+
+	for presence := range ch {
+		sm.BroadcastPresence(presence)
+	}
+}
+
 func startPprof() {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/debug/pprof/", pprof.Index)
@@ -54,6 +54,15 @@ type Config struct {
 	Cache struct {
 		Uri string `env:"URI,required"`
 	} `envPrefix:"CACHE_"`
+	TranscriptIndex struct {
+		Path string `env:"PATH" envDefault:"transcript-index" toml:"path"`
+		// MaxGuilds is the number of guild indexes kept in memory on each replica
+		MaxGuilds int `env:"MAX_GUILDS" envDefault:"200" toml:"max-guilds"`
+	} `envPrefix:"TRANSCRIPT_INDEX_"`
+	// TranscriptExport.Path must be shared between all replicas, as an export may be downloaded from any of them
+	TranscriptExport struct {
+		Path string `env:"PATH" envDefault:"transcript-exports" toml:"path"`
+	} `envPrefix:"TRANSCRIPT_EXPORT_"`
 	SecureProxyUrl string `env:"SECURE_PROXY_URL"`
 }
 
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// AnalyticsTable reads the ticket statistics from the tables owned by the bot, which Client has no way to fetch in bulk. It
+// has no schema of its own, so it is not included in CreateTables.
+type AnalyticsTable struct {
+	*pgxpool.Pool
+}
+
+// TicketStatistics holds the figures for a single ticket that are aggregated for analytics
+type TicketStatistics struct {
+	TicketId             int
+	OpenTime             time.Time
+	PanelId              *int
+	ClaimedBy            *uint64
+	FirstResponseSeconds *float64
+	ResolutionSeconds    *float64
+	Rating               *int16
+	CloseReason          *string
+}
+
+func newAnalyticsTable(pool *pgxpool.Pool) *AnalyticsTable {
+	return &AnalyticsTable{
+		pool,
+	}
+}
+
+// GetTicketStatistics returns the statistics for up to limit of the guild's tickets opened within [from, to), the most
+// recent first
+func (t *AnalyticsTable) GetTicketStatistics(ctx context.Context, guildId uint64, from, to time.Time, limit int) ([]TicketStatistics, error) {
+	query := `
+SELECT
+	tickets."id",
+	tickets."open_time",
+	tickets."panel_id",
+	ticket_claims."user_id",
+	EXTRACT(EPOCH FROM first_response_time."response_time")::float8,
+	CASE WHEN tickets."open" = FALSE AND tickets."close_time" IS NOT NULL
+		THEN EXTRACT(EPOCH FROM tickets."close_time" - tickets."open_time")::float8
+	END,
+	service_ratings."rating"::int2,
+	close_reason."close_reason"
+FROM tickets
+LEFT OUTER JOIN ticket_claims
+	ON ticket_claims."guild_id" = tickets."guild_id" AND ticket_claims."ticket_id" = tickets."id"
+LEFT OUTER JOIN first_response_time
+	ON first_response_time."guild_id" = tickets."guild_id" AND first_response_time."ticket_id" = tickets."id"
+LEFT OUTER JOIN service_ratings
+	ON service_ratings."guild_id" = tickets."guild_id" AND service_ratings."ticket_id" = tickets."id"
+LEFT OUTER JOIN close_reason
+	ON close_reason."guild_id" = tickets."guild_id" AND close_reason."ticket_id" = tickets."id"
+WHERE tickets."guild_id" = $1 AND tickets."open_time" >= $2 AND tickets."open_time" < $3
+ORDER BY tickets."open_time" DESC, tickets."id" DESC
+LIMIT $4;`
+
+	rows, err := t.Query(ctx, query, guildId, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var statistics []TicketStatistics
+	for rows.Next() {
+		var ticket TicketStatistics
+		if err := rows.Scan(
+			&ticket.TicketId,
+			&ticket.OpenTime,
+			&ticket.PanelId,
+			&ticket.ClaimedBy,
+			&ticket.FirstResponseSeconds,
+			&ticket.ResolutionSeconds,
+			&ticket.Rating,
+			&ticket.CloseReason,
+		); err != nil {
+			return nil, err
+		}
+
+		statistics = append(statistics, ticket)
+	}
+
+	return statistics, rows.Err()
+}
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type ApiTokensTable struct {
+	*pgxpool.Pool
+}
+
+type ApiToken struct {
+	Id         int64
+	UserId     uint64
+	Name       string
+	Prefix     string
+	Guilds     []uint64
+	Scopes     []string
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+}
+
+func newApiTokensTable(pool *pgxpool.Pool) *ApiTokensTable {
+	return &ApiTokensTable{
+		pool,
+	}
+}
+
+// Only a SHA-256 hash of each token is stored
+func (ApiTokensTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS dashboard_api_tokens(
+	"id" BIGSERIAL NOT NULL,
+	"user_id" int8 NOT NULL,
+	"name" VARCHAR(32) NOT NULL,
+	"token_hash" bytea NOT NULL,
+	"prefix" VARCHAR(16) NOT NULL,
+	"guilds" int8[] NOT NULL,
+	"scopes" TEXT[] NOT NULL,
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	"expires_at" TIMESTAMPTZ,
+	"last_used_at" TIMESTAMPTZ,
+	UNIQUE("token_hash"),
+	PRIMARY KEY("id")
+);
+CREATE INDEX IF NOT EXISTS dashboard_api_tokens_user_id ON dashboard_api_tokens("user_id");`
+}
+
+const apiTokenColumns = `"id", "user_id", "name", "prefix", "guilds", "scopes", "created_at", "expires_at", "last_used_at"`
+
+// GetByHash returns the token with the given hash, if it exists and has not expired
+func (t *ApiTokensTable) GetByHash(ctx context.Context, hash []byte) (ApiToken, bool, error) {
+	query := `
+SELECT ` + apiTokenColumns + `
+FROM dashboard_api_tokens
+WHERE "token_hash" = $1 AND ("expires_at" IS NULL OR "expires_at" > NOW());`
+
+	token, err := scanApiToken(t.QueryRow(ctx, query, hash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ApiToken{}, false, nil
+		}
+
+		return ApiToken{}, false, err
+	}
+
+	return token, true, nil
+}
+
+func (t *ApiTokensTable) GetByUser(ctx context.Context, userId uint64) ([]ApiToken, error) {
+	query := `
+SELECT ` + apiTokenColumns + `
+FROM dashboard_api_tokens
+WHERE "user_id" = $1
+ORDER BY "id";`
+
+	rows, err := t.Query(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var tokens []ApiToken
+	for rows.Next() {
+		token, err := scanApiToken(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+func (t *ApiTokensTable) Count(ctx context.Context, userId uint64) (int, error) {
+	query := `SELECT COUNT(*) FROM dashboard_api_tokens WHERE "user_id" = $1;`
+
+	var count int
+	err := t.QueryRow(ctx, query, userId).Scan(&count)
+	return count, err
+}
+
+func (t *ApiTokensTable) Create(ctx context.Context, token ApiToken, hash []byte) (ApiToken, error) {
+	query := `
+INSERT INTO dashboard_api_tokens("user_id", "name", "token_hash", "prefix", "guilds", "scopes", "expires_at")
+VALUES($1, $2, $3, $4, $5, $6, $7)
+RETURNING ` + apiTokenColumns + `;`
+
+	return scanApiToken(t.QueryRow(ctx, query, token.UserId, token.Name, hash, token.Prefix, token.Guilds, token.Scopes, token.ExpiresAt))
+}
+
+// Delete revokes a token, returning false if the user has no token with the ID
+func (t *ApiTokensTable) Delete(ctx context.Context, userId uint64, id int64) (bool, error) {
+	query := `DELETE FROM dashboard_api_tokens WHERE "id" = $1 AND "user_id" = $2;`
+
+	res, err := t.Exec(ctx, query, id, userId)
+	if err != nil {
+		return false, err
+	}
+
+	return res.RowsAffected() > 0, nil
+}
+
+// UpdateLastUsed sets the last used time to now. To avoid a write on every request, the time is only updated if it is
+// more than a minute old.
+func (t *ApiTokensTable) UpdateLastUsed(ctx context.Context, id int64) error {
+	query := `
+UPDATE dashboard_api_tokens
+SET "last_used_at" = NOW()
+WHERE "id" = $1 AND ("last_used_at" IS NULL OR "last_used_at" < NOW() - INTERVAL '1 minute');`
+
+	_, err := t.Exec(ctx, query, id)
+	return err
+}
+
+func scanApiToken(row pgx.Row) (ApiToken, error) {
+	var token ApiToken
+	err := row.Scan(
+		&token.Id,
+		&token.UserId,
+		&token.Name,
+		&token.Prefix,
+		&token.Guilds,
+		&token.Scopes,
+		&token.CreatedAt,
+		&token.ExpiresAt,
+		&token.LastUsedAt,
+	)
+
+	return token, err
+}
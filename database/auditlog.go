@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type AuditLogTable struct {
+	*pgxpool.Pool
+}
+
+type AuditLogEntry struct {
+	Id        int64           `json:"id"`
+	GuildId   uint64          `json:"guild_id,string"`
+	UserId    uint64          `json:"user_id,string"`
+	Action    string          `json:"action"`
+	Path      string          `json:"path"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type AuditLogQueryOptions struct {
+	UserId   *uint64
+	Action   string
+	Since    *time.Time
+	Until    *time.Time
+	BeforeId int64
+	Limit    int
+}
+
+func newAuditLogTable(pool *pgxpool.Pool) *AuditLogTable {
+	return &AuditLogTable{
+		pool,
+	}
+}
+
+func (AuditLogTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS dashboard_audit_log(
+	"id" BIGSERIAL NOT NULL,
+	"guild_id" int8 NOT NULL,
+	"user_id" int8 NOT NULL,
+	"action" VARCHAR(255) NOT NULL,
+	"path" VARCHAR(255) NOT NULL,
+	"before" jsonb,
+	"after" jsonb,
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY("id")
+);
+CREATE INDEX IF NOT EXISTS dashboard_audit_log_guild_id ON dashboard_audit_log("guild_id", "id" DESC);`
+}
+
+const createAuditLogEntryQuery = `
+INSERT INTO dashboard_audit_log("guild_id", "user_id", "action", "path", "before", "after")
+VALUES($1, $2, $3, $4, $5, $6);`
+
+func (t *AuditLogTable) Create(ctx context.Context, entry AuditLogEntry) error {
+	// Convert to []byte so that a nil value is stored as NULL, rather than a JSON null
+	_, err := t.Exec(ctx, createAuditLogEntryQuery, entry.GuildId, entry.UserId, entry.Action, entry.Path, []byte(entry.Before), []byte(entry.After))
+	return err
+}
+
+// CreateTx records the entry within the transaction, for changes whose entry must only be kept if they are committed
+func (t *AuditLogTable) CreateTx(ctx context.Context, tx pgx.Tx, entry AuditLogEntry) error {
+	_, err := tx.Exec(ctx, createAuditLogEntryQuery, entry.GuildId, entry.UserId, entry.Action, entry.Path, []byte(entry.Before), []byte(entry.After))
+	return err
+}
+
+// GetByGuild returns the entries matching the options, newest first
+func (t *AuditLogTable) GetByGuild(ctx context.Context, guildId uint64, opts AuditLogQueryOptions) ([]AuditLogEntry, error) {
+	conditions := []string{`"guild_id" = $1`}
+	args := []any{guildId}
+
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if opts.UserId != nil {
+		addCondition(`"user_id" = $%d`, *opts.UserId)
+	}
+
+	if opts.Action != "" {
+		addCondition(`"action" = $%d`, opts.Action)
+	}
+
+	if opts.Since != nil {
+		addCondition(`"created_at" >= $%d`, *opts.Since)
+	}
+
+	if opts.Until != nil {
+		addCondition(`"created_at" < $%d`, *opts.Until)
+	}
+
+	if opts.BeforeId > 0 {
+		addCondition(`"id" < $%d`, opts.BeforeId)
+	}
+
+	args = append(args, opts.Limit)
+	query := fmt.Sprintf(`
+SELECT "id", "guild_id", "user_id", "action", "path", "before", "after", "created_at"
+FROM dashboard_audit_log
+WHERE %s
+ORDER BY "id" DESC
+LIMIT $%d;`, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := t.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var entries []AuditLogEntry
+	for rows.Next() {
+		var entry AuditLogEntry
+		if err := rows.Scan(
+			&entry.Id,
+			&entry.GuildId,
+			&entry.UserId,
+			&entry.Action,
+			&entry.Path,
+			&entry.Before,
+			&entry.After,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
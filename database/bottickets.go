@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// BotTicketsTable reads tickets from the bot's tickets table in bulk, which Client.Tickets can only do one ticket at a
+// time. It only reads, and only the columns it needs. It has no schema of its own, so it is not included in
+// CreateTables.
+type BotTicketsTable struct {
+	*pgxpool.Pool
+}
+
+func newBotTicketsTable(pool *pgxpool.Pool) *BotTicketsTable {
+	return &BotTicketsTable{
+		pool,
+	}
+}
+
+// TicketAccess holds the fields of a ticket that decide whether a user can view its transcript
+type TicketAccess struct {
+	Id      int
+	UserId  uint64
+	Open    bool
+	PanelId *int
+}
+
+// GetAccess returns the tickets with the given IDs in a single query, keyed by ticket ID. Tickets that do not exist in
+// the guild are omitted.
+func (t *BotTicketsTable) GetAccess(ctx context.Context, guildId uint64, ticketIds []int) (map[int]TicketAccess, error) {
+	query := `
+SELECT "id", "user_id", "open", "panel_id"
+FROM tickets
+WHERE "guild_id" = $1 AND "id" = ANY($2);`
+
+	rows, err := t.Query(ctx, query, guildId, ticketIds)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tickets := make(map[int]TicketAccess, len(ticketIds))
+	for rows.Next() {
+		var ticket TicketAccess
+		if err := rows.Scan(&ticket.Id, &ticket.UserId, &ticket.Open, &ticket.PanelId); err != nil {
+			return nil, err
+		}
+
+		tickets[ticket.Id] = ticket
+	}
+
+	return tickets, rows.Err()
+}
@@ -0,0 +1,437 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+)
+
+// ClientTx writes to tables owned by Tickets-Database within a caller's transaction, for the writes that the library
+// only offers outside of a transaction. It is used to apply config imports atomically. The queries must be kept in line
+// with the library's schema, which Verify checks at startup. Methods should be removed as the library gains
+// transaction-aware equivalents.
+type ClientTx struct{}
+
+var TxClient ClientTx
+
+const (
+	createFormQuery = `
+INSERT INTO forms("guild_id", "title", "custom_id")
+VALUES($1, $2, $3)
+RETURNING "form_id";`
+
+	createSupportTeamQuery = `
+INSERT INTO support_team("guild_id", "name")
+VALUES($1, $2)
+RETURNING "id";`
+
+	addSupportTeamMemberQuery = `
+INSERT INTO support_team_members("team_id", "user_id")
+VALUES($1, $2)
+ON CONFLICT DO NOTHING;`
+
+	addSupportTeamRoleQuery = `
+INSERT INTO support_team_roles("team_id", "role_id")
+VALUES($1, $2)
+ON CONFLICT DO NOTHING;`
+
+	addBlacklistedUserQuery = `
+INSERT INTO blacklist("guild_id", "user_id")
+VALUES($1, $2)
+ON CONFLICT DO NOTHING;`
+
+	addBlacklistedRoleQuery = `
+INSERT INTO role_blacklist("guild_id", "role_id")
+VALUES($1, $2)
+ON CONFLICT DO NOTHING;`
+
+	setTagQuery = `
+INSERT INTO tags("guild_id", "tag_id", "content", "embed_id", "application_command_id")
+VALUES($1, $2, $3, $4, $5)
+ON CONFLICT("guild_id", "tag_id") DO UPDATE
+SET "content" = EXCLUDED."content", "embed_id" = EXCLUDED."embed_id", "application_command_id" = EXCLUDED."application_command_id";`
+
+	setTagCommandIdQuery = `
+UPDATE tags
+SET "application_command_id" = $3
+WHERE "guild_id" = $1 AND "tag_id" = $2;`
+
+	createMultiPanelQuery = `
+INSERT INTO multi_panels("message_id", "channel_id", "guild_id", "select_menu", "select_menu_placeholder", "embed_id")
+VALUES($1, $2, $3, $4, $5, $6)
+RETURNING "id";`
+
+	addMultiPanelTargetQuery = `
+INSERT INTO multi_panel_targets("multi_panel_id", "panel_id")
+VALUES($1, $2)
+ON CONFLICT DO NOTHING;`
+
+	setSettingsQuery = `
+INSERT INTO settings(
+	"guild_id",
+	"hide_claim_button",
+	"disable_open_command",
+	"context_menu_permission_level",
+	"context_menu_add_sender",
+	"context_menu_panel",
+	"store_transcripts",
+	"use_threads",
+	"thread_archive_duration",
+	"ticket_notification_channel",
+	"overflow_enabled",
+	"overflow_category_id",
+	"anonymise_dashboard_responses"
+)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+ON CONFLICT("guild_id") DO UPDATE SET
+	"hide_claim_button" = EXCLUDED."hide_claim_button",
+	"disable_open_command" = EXCLUDED."disable_open_command",
+	"context_menu_permission_level" = EXCLUDED."context_menu_permission_level",
+	"context_menu_add_sender" = EXCLUDED."context_menu_add_sender",
+	"context_menu_panel" = EXCLUDED."context_menu_panel",
+	"store_transcripts" = EXCLUDED."store_transcripts",
+	"use_threads" = EXCLUDED."use_threads",
+	"thread_archive_duration" = EXCLUDED."thread_archive_duration",
+	"ticket_notification_channel" = EXCLUDED."ticket_notification_channel",
+	"overflow_enabled" = EXCLUDED."overflow_enabled",
+	"overflow_category_id" = EXCLUDED."overflow_category_id",
+	"anonymise_dashboard_responses" = EXCLUDED."anonymise_dashboard_responses";`
+
+	setClaimSettingsQuery = `
+INSERT INTO claim_settings("guild_id", "support_can_view", "support_can_type")
+VALUES($1, $2, $3)
+ON CONFLICT("guild_id") DO UPDATE SET "support_can_view" = EXCLUDED."support_can_view", "support_can_type" = EXCLUDED."support_can_type";`
+
+	setTicketPermissionsQuery = `
+INSERT INTO ticket_permissions("guild_id", "attach_files", "embed_links", "add_reactions")
+VALUES($1, $2, $3, $4)
+ON CONFLICT("guild_id") DO UPDATE SET
+	"attach_files" = EXCLUDED."attach_files",
+	"embed_links" = EXCLUDED."embed_links",
+	"add_reactions" = EXCLUDED."add_reactions";`
+
+	setAutoCloseQuery = `
+INSERT INTO auto_close("guild_id", "enabled", "since_open_with_no_response", "since_last_message", "on_user_leave")
+VALUES($1, $2, $3, $4, $5)
+ON CONFLICT("guild_id") DO UPDATE SET
+	"enabled" = EXCLUDED."enabled",
+	"since_open_with_no_response" = EXCLUDED."since_open_with_no_response",
+	"since_last_message" = EXCLUDED."since_last_message",
+	"on_user_leave" = EXCLUDED."on_user_leave";`
+
+	setCustomColoursQuery = `
+INSERT INTO custom_colours("guild_id", "colour_id", "colour_code")
+VALUES($1, $2, $3)
+ON CONFLICT("guild_id", "colour_id") DO UPDATE SET "colour_code" = EXCLUDED."colour_code";`
+
+	getTagEmbedQuery = `SELECT "embed_id" FROM tags WHERE "guild_id" = $1 AND "tag_id" = $2 FOR UPDATE;`
+
+	deleteEmbedQuery = `DELETE FROM embeds WHERE "id" = $1;`
+
+	deleteActiveLanguageQuery = `DELETE FROM active_language WHERE "guild_id" = $1;`
+
+	getSupportTeamByNameQuery = `SELECT "id" FROM support_team WHERE "guild_id" = $1 AND "name" = $2;`
+)
+
+var (
+	activeLanguageValue    = guildValue{"active_language", "language"}
+	welcomeMessagesValue   = guildValue{"welcome_messages", "welcome_message"}
+	ticketLimitValue       = guildValue{"ticket_limit", "limit"}
+	channelCategoryValue   = guildValue{"channel_category", "category_id"}
+	archiveChannelValue    = guildValue{"archive_channel", "channel_id"}
+	namingSchemeValue      = guildValue{"naming_scheme", "naming_scheme"}
+	usersCanCloseValue     = guildValue{"users_can_close", "users_can_close"}
+	closeConfirmationValue = guildValue{"close_confirmation", "confirm"}
+	feedbackEnabledValue   = guildValue{"feedback_enabled", "feedback_enabled"}
+)
+
+// guildValues lists every guildValue, so that they are checked by Verify
+var guildValues = []guildValue{
+	activeLanguageValue,
+	welcomeMessagesValue,
+	ticketLimitValue,
+	channelCategoryValue,
+	archiveChannelValue,
+	namingSchemeValue,
+	usersCanCloseValue,
+	closeConfirmationValue,
+	feedbackEnabledValue,
+}
+
+// Verify prepares every query against the database, so that a change to the library's schema that breaks them stops
+// the dashboard at startup rather than failing an import part way through
+func (ClientTx) Verify(ctx context.Context) error {
+	queries := []string{
+		createFormQuery,
+		createSupportTeamQuery,
+		addSupportTeamMemberQuery,
+		addSupportTeamRoleQuery,
+		addBlacklistedUserQuery,
+		addBlacklistedRoleQuery,
+		setTagQuery,
+		setTagCommandIdQuery,
+		createMultiPanelQuery,
+		addMultiPanelTargetQuery,
+		setSettingsQuery,
+		setClaimSettingsQuery,
+		setTicketPermissionsQuery,
+		setAutoCloseQuery,
+		setCustomColoursQuery,
+		getTagEmbedQuery,
+		deleteEmbedQuery,
+		deleteActiveLanguageQuery,
+		getSupportTeamByNameQuery,
+	}
+
+	for _, v := range guildValues {
+		queries = append(queries, v.query())
+	}
+
+	conn, err := Dashboard.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Release()
+
+	for _, query := range queries {
+		if _, err := conn.Conn().Prepare(ctx, "", query); err != nil {
+			return fmt.Errorf("query does not match the Tickets-Database schema: %w\n%s", err, query)
+		}
+	}
+
+	return nil
+}
+
+// GetSupportTeamByName returns the ID of the guild's support team with the name, if there is one
+func (ClientTx) GetSupportTeamByName(ctx context.Context, tx pgx.Tx, guildId uint64, name string) (int, bool, error) {
+	var id int
+	if err := tx.QueryRow(ctx, getSupportTeamByNameQuery, guildId, name).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, nil
+		}
+
+		return 0, false, err
+	}
+
+	return id, true, nil
+}
+
+func (ClientTx) CreateForm(ctx context.Context, tx pgx.Tx, guildId uint64, title, customId string) (int, error) {
+	var id int
+	err := tx.QueryRow(ctx, createFormQuery, guildId, title, customId).Scan(&id)
+	return id, err
+}
+
+func (ClientTx) CreateSupportTeam(ctx context.Context, tx pgx.Tx, guildId uint64, name string) (int, error) {
+	var id int
+	err := tx.QueryRow(ctx, createSupportTeamQuery, guildId, name).Scan(&id)
+	return id, err
+}
+
+func (ClientTx) AddSupportTeamMember(ctx context.Context, tx pgx.Tx, teamId int, userId uint64) error {
+	_, err := tx.Exec(ctx, addSupportTeamMemberQuery, teamId, userId)
+	return err
+}
+
+func (ClientTx) AddSupportTeamRole(ctx context.Context, tx pgx.Tx, teamId int, roleId uint64) error {
+	_, err := tx.Exec(ctx, addSupportTeamRoleQuery, teamId, roleId)
+	return err
+}
+
+func (ClientTx) AddBlacklistedUser(ctx context.Context, tx pgx.Tx, guildId, userId uint64) error {
+	_, err := tx.Exec(ctx, addBlacklistedUserQuery, guildId, userId)
+	return err
+}
+
+func (ClientTx) AddBlacklistedRole(ctx context.Context, tx pgx.Tx, guildId, roleId uint64) error {
+	_, err := tx.Exec(ctx, addBlacklistedRoleQuery, guildId, roleId)
+	return err
+}
+
+// SetTag creates or overwrites the tag, replacing the embed of the tag it overwrites
+func (ClientTx) SetTag(ctx context.Context, tx pgx.Tx, tag database.Tag) error {
+	var previousEmbedId *int
+	if err := tx.QueryRow(ctx, getTagEmbedQuery, tag.GuildId, tag.Id).Scan(&previousEmbedId); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	var embedId *int
+	if tag.Embed != nil {
+		tag.Embed.GuildId = tag.GuildId
+
+		id, err := Client.Embeds.CreateWithFieldsTx(ctx, tx, tag.Embed.CustomEmbed, tag.Embed.Fields)
+		if err != nil {
+			return err
+		}
+
+		embedId = &id
+	}
+
+	if _, err := tx.Exec(ctx, setTagQuery, tag.GuildId, tag.Id, tag.Content, embedId, tag.ApplicationCommandId); err != nil {
+		return err
+	}
+
+	if previousEmbedId != nil {
+		if _, err := tx.Exec(ctx, deleteEmbedQuery, *previousEmbedId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetTagCommandId records the guild command registered for a tag stored by SetTag, once the transaction has been
+// committed
+func (ClientTx) SetTagCommandId(ctx context.Context, guildId uint64, tagId string, applicationCommandId uint64) error {
+	_, err := Dashboard.pool.Exec(ctx, setTagCommandIdQuery, guildId, tagId, applicationCommandId)
+	return err
+}
+
+// CreateMultiPanel stores the multi-panel and its embed without a message: the message is sent once the transaction
+// has been committed
+func (ClientTx) CreateMultiPanel(ctx context.Context, tx pgx.Tx, multiPanel database.MultiPanel) (int, error) {
+	var embedId *int
+	if multiPanel.Embed != nil {
+		multiPanel.Embed.GuildId = multiPanel.GuildId
+
+		id, err := Client.Embeds.CreateWithFieldsTx(ctx, tx, multiPanel.Embed.CustomEmbed, multiPanel.Embed.Fields)
+		if err != nil {
+			return 0, err
+		}
+
+		embedId = &id
+	}
+
+	var id int
+	err := tx.QueryRow(ctx, createMultiPanelQuery,
+		multiPanel.MessageId,
+		multiPanel.ChannelId,
+		multiPanel.GuildId,
+		multiPanel.SelectMenu,
+		multiPanel.SelectMenuPlaceholder,
+		embedId,
+	).Scan(&id)
+
+	return id, err
+}
+
+func (ClientTx) AddMultiPanelTarget(ctx context.Context, tx pgx.Tx, multiPanelId, panelId int) error {
+	_, err := tx.Exec(ctx, addMultiPanelTargetQuery, multiPanelId, panelId)
+	return err
+}
+
+func (ClientTx) SetSettings(ctx context.Context, tx pgx.Tx, guildId uint64, settings database.Settings) error {
+	_, err := tx.Exec(ctx, setSettingsQuery,
+		guildId,
+		settings.HideClaimButton,
+		settings.DisableOpenCommand,
+		settings.ContextMenuPermissionLevel,
+		settings.ContextMenuAddSender,
+		settings.ContextMenuPanel,
+		settings.StoreTranscripts,
+		settings.UseThreads,
+		settings.ThreadArchiveDuration,
+		settings.TicketNotificationChannel,
+		settings.OverflowEnabled,
+		settings.OverflowCategoryId,
+		settings.AnonymiseDashboardResponses,
+	)
+
+	return err
+}
+
+func (ClientTx) SetClaimSettings(ctx context.Context, tx pgx.Tx, guildId uint64, settings database.ClaimSettings) error {
+	_, err := tx.Exec(ctx, setClaimSettingsQuery, guildId, settings.SupportCanView, settings.SupportCanType)
+	return err
+}
+
+func (ClientTx) SetTicketPermissions(ctx context.Context, tx pgx.Tx, guildId uint64, permissions database.TicketPermissions) error {
+	_, err := tx.Exec(ctx, setTicketPermissionsQuery, guildId, permissions.AttachFiles, permissions.EmbedLinks, permissions.AddReactions)
+	return err
+}
+
+func (ClientTx) SetAutoClose(ctx context.Context, tx pgx.Tx, guildId uint64, settings database.AutoCloseSettings) error {
+	_, err := tx.Exec(ctx, setAutoCloseQuery,
+		guildId,
+		settings.Enabled,
+		settings.SinceOpenWithNoResponse,
+		settings.SinceLastMessage,
+		settings.OnUserLeave,
+	)
+
+	return err
+}
+
+// SetCustomColours sets the given colours, keyed by colour ID. Colours that are not included are left unchanged.
+func (ClientTx) SetCustomColours(ctx context.Context, tx pgx.Tx, guildId uint64, colours map[int16]int) error {
+	for colourId, colour := range colours {
+		if _, err := tx.Exec(ctx, setCustomColoursQuery, guildId, colourId, colour); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetActiveLanguage sets the guild's language, or reverts it to the default if language is nil
+func (ClientTx) SetActiveLanguage(ctx context.Context, tx pgx.Tx, guildId uint64, language *string) error {
+	if language == nil {
+		_, err := tx.Exec(ctx, deleteActiveLanguageQuery, guildId)
+		return err
+	}
+
+	return setGuildValue(ctx, tx, activeLanguageValue, guildId, *language)
+}
+
+func (ClientTx) SetWelcomeMessage(ctx context.Context, tx pgx.Tx, guildId uint64, welcomeMessage string) error {
+	return setGuildValue(ctx, tx, welcomeMessagesValue, guildId, welcomeMessage)
+}
+
+func (ClientTx) SetTicketLimit(ctx context.Context, tx pgx.Tx, guildId uint64, limit uint8) error {
+	return setGuildValue(ctx, tx, ticketLimitValue, guildId, limit)
+}
+
+func (ClientTx) SetChannelCategory(ctx context.Context, tx pgx.Tx, guildId, categoryId uint64) error {
+	return setGuildValue(ctx, tx, channelCategoryValue, guildId, categoryId)
+}
+
+func (ClientTx) SetArchiveChannel(ctx context.Context, tx pgx.Tx, guildId uint64, channelId *uint64) error {
+	return setGuildValue(ctx, tx, archiveChannelValue, guildId, channelId)
+}
+
+func (ClientTx) SetNamingScheme(ctx context.Context, tx pgx.Tx, guildId uint64, scheme database.NamingScheme) error {
+	return setGuildValue(ctx, tx, namingSchemeValue, guildId, scheme)
+}
+
+func (ClientTx) SetUsersCanClose(ctx context.Context, tx pgx.Tx, guildId uint64, usersCanClose bool) error {
+	return setGuildValue(ctx, tx, usersCanCloseValue, guildId, usersCanClose)
+}
+
+func (ClientTx) SetCloseConfirmation(ctx context.Context, tx pgx.Tx, guildId uint64, confirm bool) error {
+	return setGuildValue(ctx, tx, closeConfirmationValue, guildId, confirm)
+}
+
+func (ClientTx) SetFeedbackEnabled(ctx context.Context, tx pgx.Tx, guildId uint64, enabled bool) error {
+	return setGuildValue(ctx, tx, feedbackEnabledValue, guildId, enabled)
+}
+
+// guildValue is a table holding a single value per guild
+type guildValue struct {
+	table, column string
+}
+
+func (v guildValue) query() string {
+	return `
+INSERT INTO ` + v.table + `("guild_id", "` + v.column + `")
+VALUES($1, $2)
+ON CONFLICT("guild_id") DO UPDATE SET "` + v.column + `" = EXCLUDED."` + v.column + `";`
+}
+
+func setGuildValue(ctx context.Context, tx pgx.Tx, v guildValue, guildId uint64, value any) error {
+	_, err := tx.Exec(ctx, v.query(), guildId, value)
+	return err
+}
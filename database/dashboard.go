@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// DashboardDatabase holds the tables that are only used by the dashboard. Tables shared with the bot live in
+// Tickets-Database, and are accessed through Client.
+type DashboardDatabase struct {
+	pool *pgxpool.Pool
+
+	AuditLog                *AuditLogTable
+	PanelSchedules          *PanelSchedulesTable
+	Webhooks                *WebhooksTable
+	WebhookDeliveries       *WebhookDeliveriesTable
+	ApiTokens               *ApiTokensTable
+	Analytics               *AnalyticsTable
+	TicketNotes             *TicketNotesTable
+	TicketLabels            *TicketLabelsTable
+	TicketLabelAssignments  *TicketLabelAssignmentsTable
+	TicketPriorities        *TicketPrioritiesTable
+	TicketFilter            *TicketFilterTable
+	BotTickets              *BotTicketsTable
+	TicketClaims            *TicketClaimsTable
+	SlaPolicies             *SlaPoliciesTable
+	TicketSla               *TicketSlaTable
+	TagMetadata             *TagMetadataTable
+	EmbedTemplates          *EmbedTemplatesTable
+	EmbedTemplateReferences *EmbedTemplateReferencesTable
+	PanelHealth             *PanelHealthTable
+	FormSchemas             *FormSchemasTable
+	TranscriptExports       *TranscriptExportsTable
+	TicketEventLog          *TicketEventLogTable
+	TicketClaimState        *TicketClaimStateTable
+}
+
+var Dashboard *DashboardDatabase
+
+type table interface {
+	Schema() string
+}
+
+func newDashboardDatabase(pool *pgxpool.Pool) *DashboardDatabase {
+	return &DashboardDatabase{
+		pool:                    pool,
+		AuditLog:                newAuditLogTable(pool),
+		PanelSchedules:          newPanelSchedulesTable(pool),
+		Webhooks:                newWebhooksTable(pool),
+		WebhookDeliveries:       newWebhookDeliveriesTable(pool),
+		ApiTokens:               newApiTokensTable(pool),
+		Analytics:               newAnalyticsTable(pool),
+		TicketNotes:             newTicketNotesTable(pool),
+		TicketLabels:            newTicketLabelsTable(pool),
+		TicketLabelAssignments:  newTicketLabelAssignmentsTable(pool),
+		TicketPriorities:        newTicketPrioritiesTable(pool),
+		TicketFilter:            newTicketFilterTable(pool),
+		BotTickets:              newBotTicketsTable(pool),
+		TicketClaims:            newTicketClaimsTable(pool),
+		SlaPolicies:             newSlaPoliciesTable(pool),
+		TicketSla:               newTicketSlaTable(pool),
+		TagMetadata:             newTagMetadataTable(pool),
+		EmbedTemplates:          newEmbedTemplatesTable(pool),
+		EmbedTemplateReferences: newEmbedTemplateReferencesTable(pool),
+		PanelHealth:             newPanelHealthTable(pool),
+		FormSchemas:             newFormSchemasTable(pool),
+		TranscriptExports:       newTranscriptExportsTable(pool),
+		TicketEventLog:          newTicketEventLogTable(pool),
+		TicketClaimState:        newTicketClaimStateTable(pool),
+	}
+}
+
+func (d *DashboardDatabase) CreateTables(ctx context.Context) error {
+	tables := []table{
+		d.AuditLog,
+		d.PanelSchedules,
+		d.Webhooks,
+		d.WebhookDeliveries, // Must be created after webhooks
+		d.ApiTokens,
+		d.TicketNotes,
+		d.TicketLabels,
+		d.TicketLabelAssignments, // Must be created after labels
+		d.TicketPriorities,
+		d.SlaPolicies,
+		d.TicketSla,
+		d.TagMetadata,
+		d.EmbedTemplates,
+		d.EmbedTemplateReferences, // Must be created after embed templates
+		d.PanelHealth,
+		d.FormSchemas,
+		d.TranscriptExports,
+		d.TicketEventLog,
+		d.TicketClaimState,
+	}
+
+	for _, table := range tables {
+		if _, err := d.pool.Exec(ctx, table.Schema()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -37,4 +37,13 @@ func ConnectToDatabase() {
 	}
 
 	Client = database.NewDatabase(pool)
+
+	Dashboard = newDashboardDatabase(pool)
+	if err := Dashboard.CreateTables(context.Background()); err != nil {
+		panic(err)
+	}
+
+	if err := TxClient.Verify(context.Background()); err != nil {
+		panic(err)
+	}
 }
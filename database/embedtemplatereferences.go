@@ -0,0 +1,173 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// EmbedTemplateReferencesTable records which panels, multi-panels and tags use an embed template, so that editing the
+// template can be applied to each of them. Targets are owned by the bot, so they are stored by kind and ID rather than
+// referencing their tables.
+type EmbedTemplateReferencesTable struct {
+	*pgxpool.Pool
+}
+
+type EmbedTemplateTargetKind string
+
+const (
+	// EmbedTemplateTargetPanel targets the welcome message of a panel, by panel ID
+	EmbedTemplateTargetPanel EmbedTemplateTargetKind = "panel"
+	// EmbedTemplateTargetMultiPanel targets the message embed of a multi-panel, by multi-panel ID
+	EmbedTemplateTargetMultiPanel EmbedTemplateTargetKind = "multi_panel"
+	// EmbedTemplateTargetTag targets the embed of a tag, by tag ID
+	EmbedTemplateTargetTag EmbedTemplateTargetKind = "tag"
+)
+
+type EmbedTemplateReference struct {
+	Kind     EmbedTemplateTargetKind
+	TargetId string
+}
+
+func newEmbedTemplateReferencesTable(pool *pgxpool.Pool) *EmbedTemplateReferencesTable {
+	return &EmbedTemplateReferencesTable{
+		pool,
+	}
+}
+
+func (EmbedTemplateReferencesTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS dashboard_embed_template_references(
+	"guild_id" int8 NOT NULL,
+	"kind" VARCHAR(16) NOT NULL,
+	"target_id" VARCHAR(32) NOT NULL,
+	"template_id" int4 NOT NULL,
+	FOREIGN KEY("template_id") REFERENCES dashboard_embed_templates("id") ON DELETE CASCADE,
+	PRIMARY KEY("guild_id", "kind", "target_id")
+);
+CREATE INDEX IF NOT EXISTS dashboard_embed_template_references_template_id ON dashboard_embed_template_references("template_id");`
+}
+
+// Get returns the ID of the template used by the target, if any
+func (t *EmbedTemplateReferencesTable) Get(ctx context.Context, guildId uint64, kind EmbedTemplateTargetKind, targetId string) (*int, error) {
+	query := `
+SELECT "template_id"
+FROM dashboard_embed_template_references
+WHERE "guild_id" = $1 AND "kind" = $2 AND "target_id" = $3;`
+
+	var templateId int
+	if err := t.QueryRow(ctx, query, guildId, kind, targetId).Scan(&templateId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &templateId, nil
+}
+
+// GetByKind returns the template used by each target of the given kind in the guild, keyed by target ID
+func (t *EmbedTemplateReferencesTable) GetByKind(ctx context.Context, guildId uint64, kind EmbedTemplateTargetKind) (map[string]int, error) {
+	query := `
+SELECT "target_id", "template_id"
+FROM dashboard_embed_template_references
+WHERE "guild_id" = $1 AND "kind" = $2;`
+
+	rows, err := t.Query(ctx, query, guildId, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	references := make(map[string]int)
+	for rows.Next() {
+		var targetId string
+		var templateId int
+		if err := rows.Scan(&targetId, &templateId); err != nil {
+			return nil, err
+		}
+
+		references[targetId] = templateId
+	}
+
+	return references, rows.Err()
+}
+
+// GetByTemplate returns the targets that use the template
+func (t *EmbedTemplateReferencesTable) GetByTemplate(ctx context.Context, guildId uint64, templateId int) ([]EmbedTemplateReference, error) {
+	query := `
+SELECT "kind", "target_id"
+FROM dashboard_embed_template_references
+WHERE "guild_id" = $1 AND "template_id" = $2
+ORDER BY "kind", "target_id";`
+
+	rows, err := t.Query(ctx, query, guildId, templateId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	references := make([]EmbedTemplateReference, 0)
+	for rows.Next() {
+		var reference EmbedTemplateReference
+		if err := rows.Scan(&reference.Kind, &reference.TargetId); err != nil {
+			return nil, err
+		}
+
+		references = append(references, reference)
+	}
+
+	return references, rows.Err()
+}
+
+// Set records that the target uses the template. A nil template ID removes the reference, for when the target's embed
+// has been edited directly.
+func (t *EmbedTemplateReferencesTable) Set(ctx context.Context, guildId uint64, kind EmbedTemplateTargetKind, targetId string, templateId *int) error {
+	if templateId == nil {
+		return t.Delete(ctx, guildId, kind, targetId)
+	}
+
+	_, err := t.Exec(ctx, setEmbedTemplateReferenceQuery, guildId, kind, targetId, *templateId)
+	return err
+}
+
+// SetTx is the transactional variant of Set
+func (t *EmbedTemplateReferencesTable) SetTx(ctx context.Context, tx pgx.Tx, guildId uint64, kind EmbedTemplateTargetKind, targetId string, templateId *int) error {
+	if templateId == nil {
+		_, err := tx.Exec(ctx, deleteEmbedTemplateReferenceQuery, guildId, kind, targetId)
+		return err
+	}
+
+	_, err := tx.Exec(ctx, setEmbedTemplateReferenceQuery, guildId, kind, targetId, *templateId)
+	return err
+}
+
+const (
+	setEmbedTemplateReferenceQuery = `
+INSERT INTO dashboard_embed_template_references("guild_id", "kind", "target_id", "template_id")
+VALUES($1, $2, $3, $4)
+ON CONFLICT("guild_id", "kind", "target_id") DO UPDATE SET "template_id" = EXCLUDED."template_id";`
+
+	deleteEmbedTemplateReferenceQuery = `
+DELETE FROM dashboard_embed_template_references
+WHERE "guild_id" = $1 AND "kind" = $2 AND "target_id" = $3;`
+)
+
+func (t *EmbedTemplateReferencesTable) Delete(ctx context.Context, guildId uint64, kind EmbedTemplateTargetKind, targetId string) error {
+	_, err := t.Exec(ctx, deleteEmbedTemplateReferenceQuery, guildId, kind, targetId)
+	return err
+}
+
+func (t *EmbedTemplateReferencesTable) DeleteMulti(ctx context.Context, guildId uint64, kind EmbedTemplateTargetKind, targetIds []string) error {
+	query := `
+DELETE FROM dashboard_embed_template_references
+WHERE "guild_id" = $1 AND "kind" = $2 AND "target_id" = ANY($3);`
+
+	_, err := t.Exec(ctx, query, guildId, kind, targetIds)
+	return err
+}
@@ -0,0 +1,166 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// EmbedTemplatesTable stores named embeds that a guild can reuse across panels, multi-panels and tags. The embed is
+// stored as JSON in the same shape that it is submitted in, as the bot never reads templates directly: the embed is
+// copied to each object that references the template.
+type EmbedTemplatesTable struct {
+	*pgxpool.Pool
+}
+
+type EmbedTemplate struct {
+	Id        int
+	GuildId   uint64
+	Name      string
+	Data      json.RawMessage
+	UpdatedAt time.Time
+}
+
+func newEmbedTemplatesTable(pool *pgxpool.Pool) *EmbedTemplatesTable {
+	return &EmbedTemplatesTable{
+		pool,
+	}
+}
+
+func (EmbedTemplatesTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS dashboard_embed_templates(
+	"id" SERIAL NOT NULL,
+	"guild_id" int8 NOT NULL,
+	"name" VARCHAR(32) NOT NULL,
+	"data" jsonb NOT NULL,
+	"updated_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	UNIQUE("guild_id", "name"),
+	PRIMARY KEY("id")
+);`
+}
+
+const embedTemplateColumns = `"id", "guild_id", "name", "data", "updated_at"`
+
+func (t *EmbedTemplatesTable) Get(ctx context.Context, guildId uint64, id int) (EmbedTemplate, bool, error) {
+	query := `
+SELECT ` + embedTemplateColumns + `
+FROM dashboard_embed_templates
+WHERE "id" = $1 AND "guild_id" = $2;`
+
+	template, err := scanEmbedTemplate(t.QueryRow(ctx, query, id, guildId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return EmbedTemplate{}, false, nil
+		}
+
+		return EmbedTemplate{}, false, err
+	}
+
+	return template, true, nil
+}
+
+func (t *EmbedTemplatesTable) GetByGuild(ctx context.Context, guildId uint64) ([]EmbedTemplate, error) {
+	query := `
+SELECT ` + embedTemplateColumns + `
+FROM dashboard_embed_templates
+WHERE "guild_id" = $1
+ORDER BY "name";`
+
+	rows, err := t.Query(ctx, query, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	templates := make([]EmbedTemplate, 0)
+	for rows.Next() {
+		template, err := scanEmbedTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		templates = append(templates, template)
+	}
+
+	return templates, rows.Err()
+}
+
+func (t *EmbedTemplatesTable) Count(ctx context.Context, guildId uint64) (int, error) {
+	query := `SELECT COUNT(*) FROM dashboard_embed_templates WHERE "guild_id" = $1;`
+
+	var count int
+	err := t.QueryRow(ctx, query, guildId).Scan(&count)
+	return count, err
+}
+
+// Create inserts the template, returning false if the guild already has a template with the same name
+func (t *EmbedTemplatesTable) Create(ctx context.Context, template EmbedTemplate) (EmbedTemplate, bool, error) {
+	query := `
+INSERT INTO dashboard_embed_templates("guild_id", "name", "data")
+VALUES($1, $2, $3)
+ON CONFLICT("guild_id", "name") DO NOTHING
+RETURNING ` + embedTemplateColumns + `;`
+
+	created, err := scanEmbedTemplate(t.QueryRow(ctx, query, template.GuildId, template.Name, []byte(template.Data)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return EmbedTemplate{}, false, nil
+		}
+
+		return EmbedTemplate{}, false, err
+	}
+
+	return created, true, nil
+}
+
+// Update sets the name and embed of the template, returning false if the guild already has a template with the new
+// name
+func (t *EmbedTemplatesTable) Update(ctx context.Context, template EmbedTemplate) (EmbedTemplate, bool, error) {
+	query := `
+UPDATE dashboard_embed_templates
+SET "name" = $3, "data" = $4, "updated_at" = NOW()
+WHERE "id" = $1 AND "guild_id" = $2 AND NOT EXISTS (
+	SELECT 1 FROM dashboard_embed_templates WHERE "guild_id" = $2 AND "name" = $3 AND "id" != $1
+)
+RETURNING ` + embedTemplateColumns + `;`
+
+	updated, err := scanEmbedTemplate(t.QueryRow(ctx, query, template.Id, template.GuildId, template.Name, []byte(template.Data)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return EmbedTemplate{}, false, nil
+		}
+
+		return EmbedTemplate{}, false, err
+	}
+
+	return updated, true, nil
+}
+
+// Delete removes the template. Objects that referenced it keep their copy of the embed.
+func (t *EmbedTemplatesTable) Delete(ctx context.Context, guildId uint64, id int) error {
+	query := `DELETE FROM dashboard_embed_templates WHERE "id" = $1 AND "guild_id" = $2;`
+
+	_, err := t.Exec(ctx, query, id, guildId)
+	return err
+}
+
+func scanEmbedTemplate(row pgx.Row) (EmbedTemplate, error) {
+	var template EmbedTemplate
+	var data []byte
+	err := row.Scan(
+		&template.Id,
+		&template.GuildId,
+		&template.Name,
+		&data,
+		&template.UpdatedAt,
+	)
+
+	template.Data = data
+	return template, err
+}
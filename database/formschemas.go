@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// FormSchemasTable stores versioned schemas for forms that use features beyond the text inputs held in the form_input
+// table, such as select menus, pattern validation and conditional pages. Forms without a row use the original schema,
+// defined entirely by their form_input rows.
+type FormSchemasTable struct {
+	*pgxpool.Pool
+}
+
+type FormSchema struct {
+	FormId    int
+	GuildId   uint64
+	Version   int
+	Data      json.RawMessage
+	UpdatedAt time.Time
+}
+
+func newFormSchemasTable(pool *pgxpool.Pool) *FormSchemasTable {
+	return &FormSchemasTable{
+		pool,
+	}
+}
+
+func (FormSchemasTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS dashboard_form_schemas(
+	"form_id" int4 NOT NULL,
+	"guild_id" int8 NOT NULL,
+	"version" int2 NOT NULL,
+	"data" jsonb NOT NULL,
+	"updated_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY("form_id")
+);
+CREATE INDEX IF NOT EXISTS dashboard_form_schemas_guild_id ON dashboard_form_schemas("guild_id");`
+}
+
+const formSchemaColumns = `"form_id", "guild_id", "version", "data", "updated_at"`
+
+func (t *FormSchemasTable) Get(ctx context.Context, formId int) (FormSchema, bool, error) {
+	query := `
+SELECT ` + formSchemaColumns + `
+FROM dashboard_form_schemas
+WHERE "form_id" = $1;`
+
+	schema, err := scanFormSchema(t.QueryRow(ctx, query, formId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return FormSchema{}, false, nil
+		}
+
+		return FormSchema{}, false, err
+	}
+
+	return schema, true, nil
+}
+
+// GetByGuild returns the schemas of the guild's forms, keyed by form ID
+func (t *FormSchemasTable) GetByGuild(ctx context.Context, guildId uint64) (map[int]FormSchema, error) {
+	query := `
+SELECT ` + formSchemaColumns + `
+FROM dashboard_form_schemas
+WHERE "guild_id" = $1;`
+
+	rows, err := t.Query(ctx, query, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	schemas := make(map[int]FormSchema)
+	for rows.Next() {
+		schema, err := scanFormSchema(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		schemas[schema.FormId] = schema
+	}
+
+	return schemas, rows.Err()
+}
+
+// SetTx stores the schema in the same transaction as the form_input rows that mirror it
+func (t *FormSchemasTable) SetTx(ctx context.Context, tx pgx.Tx, schema FormSchema) error {
+	query := `
+INSERT INTO dashboard_form_schemas("form_id", "guild_id", "version", "data")
+VALUES($1, $2, $3, $4)
+ON CONFLICT("form_id") DO UPDATE SET "version" = $3, "data" = $4, "updated_at" = NOW();`
+
+	_, err := tx.Exec(ctx, query, schema.FormId, schema.GuildId, schema.Version, []byte(schema.Data))
+	return err
+}
+
+func (t *FormSchemasTable) Delete(ctx context.Context, formId int) error {
+	query := `DELETE FROM dashboard_form_schemas WHERE "form_id" = $1;`
+
+	_, err := t.Exec(ctx, query, formId)
+	return err
+}
+
+func scanFormSchema(row pgx.Row) (FormSchema, error) {
+	var schema FormSchema
+	var data []byte
+	err := row.Scan(
+		&schema.FormId,
+		&schema.GuildId,
+		&schema.Version,
+		&data,
+		&schema.UpdatedAt,
+	)
+
+	schema.Data = data
+	return schema, err
+}
@@ -0,0 +1,165 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// PanelHealthTable stores the result of the last check of each panel and multi-panel message. Panels are owned by the
+// bot, so rows are keyed by kind and ID rather than referencing the panel tables.
+type PanelHealthTable struct {
+	*pgxpool.Pool
+}
+
+type PanelKind string
+
+const (
+	PanelKindPanel      PanelKind = "panel"
+	PanelKindMultiPanel PanelKind = "multi_panel"
+)
+
+type PanelHealthStatus string
+
+const (
+	PanelHealthStatusOk PanelHealthStatus = "ok"
+	// PanelHealthStatusMessageMissing means that the channel exists, but the panel message was deleted
+	PanelHealthStatusMessageMissing PanelHealthStatus = "message_missing"
+	// PanelHealthStatusChannelMissing means that the panel channel was deleted, so the panel cannot be resent until a
+	// new channel is chosen
+	PanelHealthStatusChannelMissing PanelHealthStatus = "channel_missing"
+	// PanelHealthStatusForbidden means that the bot can no longer view the panel channel
+	PanelHealthStatusForbidden PanelHealthStatus = "forbidden"
+	// PanelHealthStatusUnknown means that the panel has not been checked successfully yet
+	PanelHealthStatusUnknown PanelHealthStatus = "unknown"
+)
+
+type PanelHealth struct {
+	Kind      PanelKind
+	PanelId   int
+	GuildId   uint64
+	Status    PanelHealthStatus
+	CheckedAt time.Time
+}
+
+// PanelMessage identifies the message of a panel or multi-panel that is due to be checked
+type PanelMessage struct {
+	Kind      PanelKind
+	PanelId   int
+	GuildId   uint64
+	ChannelId uint64
+	MessageId uint64
+}
+
+func newPanelHealthTable(pool *pgxpool.Pool) *PanelHealthTable {
+	return &PanelHealthTable{
+		pool,
+	}
+}
+
+func (PanelHealthTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS dashboard_panel_health(
+	"kind" VARCHAR(16) NOT NULL,
+	"panel_id" int4 NOT NULL,
+	"guild_id" int8 NOT NULL,
+	"status" VARCHAR(16) NOT NULL,
+	"checked_at" TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY("kind", "panel_id")
+);
+CREATE INDEX IF NOT EXISTS dashboard_panel_health_guild_id ON dashboard_panel_health("guild_id");`
+}
+
+// GetDue returns the panels and multi-panels that have not been checked since checkedBefore, those that have never been
+// checked first
+func (t *PanelHealthTable) GetDue(ctx context.Context, checkedBefore time.Time, limit int) ([]PanelMessage, error) {
+	query := `
+SELECT targets."kind", targets."panel_id", targets."guild_id", targets."channel_id", targets."message_id"
+FROM (
+	SELECT 'panel' AS "kind", "panel_id", "guild_id", "channel_id", "message_id" FROM panels
+	UNION ALL
+	SELECT 'multi_panel' AS "kind", "id", "guild_id", "channel_id", "message_id" FROM multi_panels
+) AS targets
+LEFT OUTER JOIN dashboard_panel_health
+	ON dashboard_panel_health."kind" = targets."kind" AND dashboard_panel_health."panel_id" = targets."panel_id"
+WHERE dashboard_panel_health."checked_at" IS NULL OR dashboard_panel_health."checked_at" < $1
+ORDER BY dashboard_panel_health."checked_at" ASC NULLS FIRST
+LIMIT $2;`
+
+	rows, err := t.Query(ctx, query, checkedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var messages []PanelMessage
+	for rows.Next() {
+		var message PanelMessage
+		if err := rows.Scan(&message.Kind, &message.PanelId, &message.GuildId, &message.ChannelId, &message.MessageId); err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+// GetByGuild returns the health of each panel of the given kind in the guild, keyed by panel ID. Panels that have not
+// been checked yet are omitted.
+func (t *PanelHealthTable) GetByGuild(ctx context.Context, guildId uint64, kind PanelKind) (map[int]PanelHealth, error) {
+	query := `
+SELECT "kind", "panel_id", "guild_id", "status", "checked_at"
+FROM dashboard_panel_health
+WHERE "guild_id" = $1 AND "kind" = $2;`
+
+	rows, err := t.Query(ctx, query, guildId, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	health := make(map[int]PanelHealth)
+	for rows.Next() {
+		var data PanelHealth
+		if err := rows.Scan(&data.Kind, &data.PanelId, &data.GuildId, &data.Status, &data.CheckedAt); err != nil {
+			return nil, err
+		}
+
+		health[data.PanelId] = data
+	}
+
+	return health, rows.Err()
+}
+
+func (t *PanelHealthTable) Set(ctx context.Context, health PanelHealth) error {
+	query := `
+INSERT INTO dashboard_panel_health("kind", "panel_id", "guild_id", "status", "checked_at")
+VALUES($1, $2, $3, $4, $5)
+ON CONFLICT("kind", "panel_id") DO UPDATE SET "status" = EXCLUDED."status", "checked_at" = EXCLUDED."checked_at";`
+
+	_, err := t.Exec(ctx, query, health.Kind, health.PanelId, health.GuildId, health.Status, health.CheckedAt)
+	return err
+}
+
+// SetCheckFailed records that a check of the panel was attempted, so that it moves to the back of the queue returned by
+// GetDue. The previous status is kept, or set to unknown if the panel has not been checked before.
+func (t *PanelHealthTable) SetCheckFailed(ctx context.Context, kind PanelKind, panelId int, guildId uint64, checkedAt time.Time) error {
+	query := `
+INSERT INTO dashboard_panel_health("kind", "panel_id", "guild_id", "status", "checked_at")
+VALUES($1, $2, $3, $4, $5)
+ON CONFLICT("kind", "panel_id") DO UPDATE SET "checked_at" = EXCLUDED."checked_at";`
+
+	_, err := t.Exec(ctx, query, kind, panelId, guildId, PanelHealthStatusUnknown, checkedAt)
+	return err
+}
+
+func (t *PanelHealthTable) Delete(ctx context.Context, kind PanelKind, panelId int) error {
+	query := `DELETE FROM dashboard_panel_health WHERE "kind" = $1 AND "panel_id" = $2;`
+
+	_, err := t.Exec(ctx, query, kind, panelId)
+	return err
+}
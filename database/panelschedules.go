@@ -0,0 +1,196 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type PanelSchedulesTable struct {
+	*pgxpool.Pool
+}
+
+type PanelScheduleStatus string
+
+const (
+	PanelScheduleStatusPending   PanelScheduleStatus = "pending"
+	PanelScheduleStatusRunning   PanelScheduleStatus = "running"
+	PanelScheduleStatusComplete  PanelScheduleStatus = "complete"
+	PanelScheduleStatusFailed    PanelScheduleStatus = "failed"
+	PanelScheduleStatusCancelled PanelScheduleStatus = "cancelled"
+)
+
+type PanelSchedule struct {
+	Id         int64
+	GuildId    uint64
+	PanelId    int
+	CreatedBy  uint64
+	ExecuteAt  time.Time
+	Data       json.RawMessage
+	Status     PanelScheduleStatus
+	Error      *string
+	CreatedAt  time.Time
+	ExecutedAt *time.Time
+}
+
+func newPanelSchedulesTable(pool *pgxpool.Pool) *PanelSchedulesTable {
+	return &PanelSchedulesTable{
+		pool,
+	}
+}
+
+func (PanelSchedulesTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS dashboard_panel_schedules(
+	"id" BIGSERIAL NOT NULL,
+	"guild_id" int8 NOT NULL,
+	"panel_id" int4 NOT NULL,
+	"created_by" int8 NOT NULL,
+	"execute_at" TIMESTAMPTZ NOT NULL,
+	"data" jsonb NOT NULL,
+	"status" VARCHAR(16) NOT NULL DEFAULT 'pending',
+	"error" TEXT,
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	"executed_at" TIMESTAMPTZ,
+	"claimed_at" TIMESTAMPTZ,
+	PRIMARY KEY("id")
+);
+CREATE INDEX IF NOT EXISTS dashboard_panel_schedules_panel_id ON dashboard_panel_schedules("guild_id", "panel_id");
+CREATE INDEX IF NOT EXISTS dashboard_panel_schedules_pending ON dashboard_panel_schedules("execute_at") WHERE "status" = 'pending';
+CREATE INDEX IF NOT EXISTS dashboard_panel_schedules_running ON dashboard_panel_schedules("claimed_at") WHERE "status" = 'running';`
+}
+
+const panelScheduleColumns = `"id", "guild_id", "panel_id", "created_by", "execute_at", "data", "status", "error", "created_at", "executed_at"`
+
+func (t *PanelSchedulesTable) Create(ctx context.Context, schedule PanelSchedule) (PanelSchedule, error) {
+	query := `
+INSERT INTO dashboard_panel_schedules("guild_id", "panel_id", "created_by", "execute_at", "data")
+VALUES($1, $2, $3, $4, $5)
+RETURNING ` + panelScheduleColumns + `;`
+
+	return scanPanelSchedule(t.QueryRow(ctx, query, schedule.GuildId, schedule.PanelId, schedule.CreatedBy, schedule.ExecuteAt, []byte(schedule.Data)))
+}
+
+// GetByPanel returns the schedules for a panel, the latest execution time first
+func (t *PanelSchedulesTable) GetByPanel(ctx context.Context, guildId uint64, panelId int, limit int) ([]PanelSchedule, error) {
+	query := `
+SELECT ` + panelScheduleColumns + `
+FROM dashboard_panel_schedules
+WHERE "guild_id" = $1 AND "panel_id" = $2
+ORDER BY "execute_at" DESC, "id" DESC
+LIMIT $3;`
+
+	rows, err := t.Query(ctx, query, guildId, panelId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var schedules []PanelSchedule
+	for rows.Next() {
+		schedule, err := scanPanelSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+func (t *PanelSchedulesTable) CountPending(ctx context.Context, guildId uint64, panelId int) (int, error) {
+	query := `
+SELECT COUNT(*)
+FROM dashboard_panel_schedules
+WHERE "guild_id" = $1 AND "panel_id" = $2 AND "status" = 'pending';`
+
+	var count int
+	err := t.QueryRow(ctx, query, guildId, panelId).Scan(&count)
+	return count, err
+}
+
+// Cancel marks a pending schedule as cancelled, returning the schedule as it was before it was cancelled, or false if no
+// pending schedule with the ID exists for the panel
+func (t *PanelSchedulesTable) Cancel(ctx context.Context, guildId uint64, panelId int, id int64) (PanelSchedule, bool, error) {
+	query := `
+UPDATE dashboard_panel_schedules
+SET "status" = 'cancelled'
+WHERE "id" = $1 AND "guild_id" = $2 AND "panel_id" = $3 AND "status" = 'pending'
+RETURNING ` + panelScheduleColumns + `;`
+
+	schedule, err := scanPanelSchedule(t.QueryRow(ctx, query, id, guildId, panelId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return PanelSchedule{}, false, nil
+		}
+
+		return PanelSchedule{}, false, err
+	}
+
+	// Only pending schedules can be cancelled
+	schedule.Status = PanelScheduleStatusPending
+	return schedule, true, nil
+}
+
+// ClaimNext marks the oldest due schedule as running and returns it. Rows locked by another replica are skipped, so
+// each schedule is only claimed once. Schedules that have been running for longer than lease are claimed again, as the
+// replica that claimed them stopped before recording the result.
+func (t *PanelSchedulesTable) ClaimNext(ctx context.Context, lease time.Duration) (PanelSchedule, bool, error) {
+	query := `
+UPDATE dashboard_panel_schedules
+SET "status" = 'running', "claimed_at" = NOW()
+WHERE "id" = (
+	SELECT "id"
+	FROM dashboard_panel_schedules
+	WHERE ("status" = 'pending' AND "execute_at" <= NOW()) OR ("status" = 'running' AND "claimed_at" < NOW() - $1::interval)
+	ORDER BY "execute_at", "id"
+	LIMIT 1
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING ` + panelScheduleColumns + `;`
+
+	schedule, err := scanPanelSchedule(t.QueryRow(ctx, query, lease))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return PanelSchedule{}, false, nil
+		}
+
+		return PanelSchedule{}, false, err
+	}
+
+	return schedule, true, nil
+}
+
+func (t *PanelSchedulesTable) SetResult(ctx context.Context, id int64, status PanelScheduleStatus, errorMessage *string) error {
+	query := `
+UPDATE dashboard_panel_schedules
+SET "status" = $2, "error" = $3, "executed_at" = NOW()
+WHERE "id" = $1;`
+
+	_, err := t.Exec(ctx, query, id, status, errorMessage)
+	return err
+}
+
+func scanPanelSchedule(row pgx.Row) (PanelSchedule, error) {
+	var schedule PanelSchedule
+	err := row.Scan(
+		&schedule.Id,
+		&schedule.GuildId,
+		&schedule.PanelId,
+		&schedule.CreatedBy,
+		&schedule.ExecuteAt,
+		&schedule.Data,
+		&schedule.Status,
+		&schedule.Error,
+		&schedule.CreatedAt,
+		&schedule.ExecutedAt,
+	)
+
+	return schedule, err
+}
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// SlaPoliciesTable stores the response time targets that admins set for tickets opened from each panel
+type SlaPoliciesTable struct {
+	*pgxpool.Pool
+}
+
+type SlaPolicy struct {
+	GuildId uint64
+	PanelId int
+	// FirstResponseSeconds is the time allowed for the first staff response, or nil if it is not tracked
+	FirstResponseSeconds *int
+	// ResolutionSeconds is the time allowed for the ticket to be closed, or nil if it is not tracked
+	ResolutionSeconds *int
+	// AtRiskPercent is the percentage of a target that can elapse before the ticket is considered at risk
+	AtRiskPercent   int
+	NotifyChannelId *uint64
+}
+
+func newSlaPoliciesTable(pool *pgxpool.Pool) *SlaPoliciesTable {
+	return &SlaPoliciesTable{
+		pool,
+	}
+}
+
+func (SlaPoliciesTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS dashboard_sla_policies(
+	"guild_id" int8 NOT NULL,
+	"panel_id" int4 NOT NULL,
+	"first_response_seconds" int4,
+	"resolution_seconds" int4,
+	"at_risk_percent" int2 NOT NULL,
+	"notify_channel_id" int8,
+	PRIMARY KEY("guild_id", "panel_id")
+);`
+}
+
+const slaPolicyColumns = `"guild_id", "panel_id", "first_response_seconds", "resolution_seconds", "at_risk_percent", "notify_channel_id"`
+
+func (t *SlaPoliciesTable) Get(ctx context.Context, guildId uint64, panelId int) (SlaPolicy, bool, error) {
+	query := `
+SELECT ` + slaPolicyColumns + `
+FROM dashboard_sla_policies
+WHERE "guild_id" = $1 AND "panel_id" = $2;`
+
+	policy, err := scanSlaPolicy(t.QueryRow(ctx, query, guildId, panelId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return SlaPolicy{}, false, nil
+		}
+
+		return SlaPolicy{}, false, err
+	}
+
+	return policy, true, nil
+}
+
+func (t *SlaPoliciesTable) GetByGuild(ctx context.Context, guildId uint64) ([]SlaPolicy, error) {
+	query := `
+SELECT ` + slaPolicyColumns + `
+FROM dashboard_sla_policies
+WHERE "guild_id" = $1
+ORDER BY "panel_id";`
+
+	rows, err := t.Query(ctx, query, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	policies := make([]SlaPolicy, 0)
+	for rows.Next() {
+		policy, err := scanSlaPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		policies = append(policies, policy)
+	}
+
+	return policies, rows.Err()
+}
+
+func (t *SlaPoliciesTable) Set(ctx context.Context, policy SlaPolicy) error {
+	query := `
+INSERT INTO dashboard_sla_policies(` + slaPolicyColumns + `)
+VALUES($1, $2, $3, $4, $5, $6)
+ON CONFLICT("guild_id", "panel_id") DO UPDATE SET
+	"first_response_seconds" = EXCLUDED."first_response_seconds",
+	"resolution_seconds" = EXCLUDED."resolution_seconds",
+	"at_risk_percent" = EXCLUDED."at_risk_percent",
+	"notify_channel_id" = EXCLUDED."notify_channel_id";`
+
+	_, err := t.Exec(ctx, query, policy.GuildId, policy.PanelId, policy.FirstResponseSeconds, policy.ResolutionSeconds, policy.AtRiskPercent, policy.NotifyChannelId)
+	return err
+}
+
+func (t *SlaPoliciesTable) Delete(ctx context.Context, guildId uint64, panelId int) error {
+	query := `DELETE FROM dashboard_sla_policies WHERE "guild_id" = $1 AND "panel_id" = $2;`
+
+	_, err := t.Exec(ctx, query, guildId, panelId)
+	return err
+}
+
+func scanSlaPolicy(row pgx.Row) (SlaPolicy, error) {
+	var policy SlaPolicy
+	err := row.Scan(
+		&policy.GuildId,
+		&policy.PanelId,
+		&policy.FirstResponseSeconds,
+		&policy.ResolutionSeconds,
+		&policy.AtRiskPercent,
+		&policy.NotifyChannelId,
+	)
+
+	return policy, err
+}
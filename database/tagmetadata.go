@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// TagMetadataTable stores dashboard-only details about tags. The tags themselves are owned by the bot, so rows are keyed
+// by tag ID rather than referencing the tags table.
+type TagMetadataTable struct {
+	*pgxpool.Pool
+}
+
+type TagMetadata struct {
+	TagId      string
+	Category   *string
+	Uses       int
+	LastUsedAt *time.Time
+}
+
+func newTagMetadataTable(pool *pgxpool.Pool) *TagMetadataTable {
+	return &TagMetadataTable{
+		pool,
+	}
+}
+
+func (TagMetadataTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS dashboard_tag_metadata(
+	"guild_id" int8 NOT NULL,
+	"tag_id" VARCHAR(16) NOT NULL,
+	"category" VARCHAR(32),
+	"uses" int4 NOT NULL DEFAULT 0,
+	"last_used_at" TIMESTAMPTZ,
+	PRIMARY KEY("guild_id", "tag_id")
+);`
+}
+
+// GetByGuild returns the metadata of each tag in the guild, keyed by tag ID. Tags without metadata are omitted.
+func (t *TagMetadataTable) GetByGuild(ctx context.Context, guildId uint64) (map[string]TagMetadata, error) {
+	query := `
+SELECT "tag_id", "category", "uses", "last_used_at"
+FROM dashboard_tag_metadata
+WHERE "guild_id" = $1;`
+
+	rows, err := t.Query(ctx, query, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	metadata := make(map[string]TagMetadata)
+	for rows.Next() {
+		var data TagMetadata
+		if err := rows.Scan(&data.TagId, &data.Category, &data.Uses, &data.LastUsedAt); err != nil {
+			return nil, err
+		}
+
+		metadata[data.TagId] = data
+	}
+
+	return metadata, rows.Err()
+}
+
+// SetCategory sets the category of the tag, keeping its usage statistics. A nil category removes the tag from its
+// category.
+func (t *TagMetadataTable) SetCategory(ctx context.Context, guildId uint64, tagId string, category *string) error {
+	_, err := t.Exec(ctx, setTagCategoryQuery, guildId, tagId, category)
+	return err
+}
+
+// SetCategoryTx sets the category of the tag in the same transaction as the tag itself
+func (t *TagMetadataTable) SetCategoryTx(ctx context.Context, tx pgx.Tx, guildId uint64, tagId string, category *string) error {
+	_, err := tx.Exec(ctx, setTagCategoryQuery, guildId, tagId, category)
+	return err
+}
+
+const setTagCategoryQuery = `
+INSERT INTO dashboard_tag_metadata("guild_id", "tag_id", "category")
+VALUES($1, $2, $3)
+ON CONFLICT("guild_id", "tag_id") DO UPDATE SET "category" = EXCLUDED."category";`
+
+// IncrementUses records that the tag was used at the given time
+func (t *TagMetadataTable) IncrementUses(ctx context.Context, guildId uint64, tagId string, usedAt time.Time) error {
+	query := `
+INSERT INTO dashboard_tag_metadata("guild_id", "tag_id", "uses", "last_used_at")
+VALUES($1, $2, 1, $3)
+ON CONFLICT("guild_id", "tag_id") DO UPDATE
+SET "uses" = dashboard_tag_metadata."uses" + 1,
+	"last_used_at" = GREATEST(dashboard_tag_metadata."last_used_at", EXCLUDED."last_used_at");`
+
+	_, err := t.Exec(ctx, query, guildId, tagId, usedAt)
+	return err
+}
+
+func (t *TagMetadataTable) Delete(ctx context.Context, guildId uint64, tagId string) error {
+	query := `DELETE FROM dashboard_tag_metadata WHERE "guild_id" = $1 AND "tag_id" = $2;`
+
+	_, err := t.Exec(ctx, query, guildId, tagId)
+	return err
+}
+
+func (t *TagMetadataTable) DeleteMulti(ctx context.Context, guildId uint64, tagIds []string) error {
+	query := `DELETE FROM dashboard_tag_metadata WHERE "guild_id" = $1 AND "tag_id" = ANY($2);`
+
+	_, err := t.Exec(ctx, query, guildId, tagIds)
+	return err
+}
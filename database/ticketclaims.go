@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// TicketClaimsTable changes the claims stored in the bot's ticket_claims table atomically, which Client.TicketClaims
+// cannot do, as Set overwrites any existing claim. It has no schema of its own, so it is not included in CreateTables.
+// Changes are also recorded in TicketClaimStateTable, so that they are not published a second time as changes made by
+// the worker.
+type TicketClaimsTable struct {
+	*pgxpool.Pool
+}
+
+func newTicketClaimsTable(pool *pgxpool.Pool) *TicketClaimsTable {
+	return &TicketClaimsTable{
+		pool,
+	}
+}
+
+// Swap replaces the claim on the ticket with claimer, or removes it if claimer is nil, only if the ticket is still
+// claimed by expected, or unclaimed if expected is nil. Returns false if the claim has changed in the meantime.
+func (t *TicketClaimsTable) Swap(ctx context.Context, guildId uint64, ticketId int, expected, claimer *uint64) (bool, error) {
+	var query string
+	var args []any
+	switch {
+	case expected == nil && claimer == nil:
+		return true, nil
+	case expected == nil:
+		query = `
+INSERT INTO ticket_claims("guild_id", "ticket_id", "user_id")
+VALUES($1, $2, $3)
+ON CONFLICT DO NOTHING
+RETURNING "guild_id", "ticket_id", "user_id"`
+		args = []any{guildId, ticketId, *claimer}
+	case claimer == nil:
+		query = `
+DELETE FROM ticket_claims
+WHERE "guild_id" = $1 AND "ticket_id" = $2 AND "user_id" = $3
+RETURNING "guild_id", "ticket_id", 0::int8 AS "user_id"`
+		args = []any{guildId, ticketId, *expected}
+	default:
+		query = `
+UPDATE ticket_claims
+SET "user_id" = $4
+WHERE "guild_id" = $1 AND "ticket_id" = $2 AND "user_id" = $3
+RETURNING "guild_id", "ticket_id", "user_id"`
+		args = []any{guildId, ticketId, *expected, *claimer}
+	}
+
+	// Record the new claimer in dashboard_ticket_claim_state too, as the dashboard publishes its own claim events
+	query = `
+WITH swapped AS (` + query + `)
+INSERT INTO dashboard_ticket_claim_state("guild_id", "ticket_id", "user_id")
+SELECT "guild_id", "ticket_id", "user_id"
+FROM swapped
+ON CONFLICT("guild_id", "ticket_id") DO UPDATE SET "user_id" = EXCLUDED."user_id";`
+
+	res, err := t.Exec(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return res.RowsAffected() == 1, nil
+}
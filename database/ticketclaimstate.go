@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// TicketClaimStateTable stores the claimer of each open ticket as of the last time ticket_claims was polled, so that
+// claims made by the worker can be published as events. ticket_claims has no timestamps, so changes are found by
+// comparing against this table.
+type TicketClaimStateTable struct {
+	*pgxpool.Pool
+}
+
+func newTicketClaimStateTable(pool *pgxpool.Pool) *TicketClaimStateTable {
+	return &TicketClaimStateTable{
+		pool,
+	}
+}
+
+func (TicketClaimStateTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS dashboard_ticket_claim_state(
+	"guild_id" int8 NOT NULL,
+	"ticket_id" int4 NOT NULL,
+	"user_id" int8 NOT NULL,
+	PRIMARY KEY("guild_id", "ticket_id")
+);`
+}
+
+// ClaimChanges returns the open tickets whose claim has changed since the last call, as ticket.claimed and
+// ticket.unclaimed activity, and stores their new claimers. Only guilds with an enabled webhook, or in feedGuildIds,
+// are included. The first time a ticket is seen, its claim is only returned if it was opened since recentSince, as
+// otherwise it was claimed before its guild was included.
+func (t *TicketClaimStateTable) ClaimChanges(ctx context.Context, recentSince time.Time, feedGuildIds []uint64) ([]TicketActivity, error) {
+	query := `
+WITH guilds AS (` + eventSourceGuilds("$2") + `
+), current AS (
+	SELECT tickets."guild_id", tickets."id" AS "ticket_id", tickets."open_time",
+		COALESCE(ticket_claims."user_id", 0) AS "user_id", dashboard_ticket_claim_state."user_id" AS "previous_user_id"
+	FROM tickets
+	INNER JOIN guilds ON guilds."guild_id" = tickets."guild_id"
+	LEFT OUTER JOIN ticket_claims
+		ON ticket_claims."guild_id" = tickets."guild_id" AND ticket_claims."ticket_id" = tickets."id"
+	LEFT OUTER JOIN dashboard_ticket_claim_state
+		ON dashboard_ticket_claim_state."guild_id" = tickets."guild_id" AND dashboard_ticket_claim_state."ticket_id" = tickets."id"
+	WHERE tickets."open" = TRUE
+), stored AS (
+	INSERT INTO dashboard_ticket_claim_state("guild_id", "ticket_id", "user_id")
+	SELECT "guild_id", "ticket_id", "user_id"
+	FROM current
+	WHERE "previous_user_id" IS DISTINCT FROM "user_id"
+	ON CONFLICT("guild_id", "ticket_id") DO UPDATE SET "user_id" = EXCLUDED."user_id"
+)
+SELECT "guild_id", "ticket_id", "user_id", COALESCE("previous_user_id", 0)
+FROM current
+WHERE ("previous_user_id" IS NOT NULL AND "previous_user_id" <> "user_id")
+	OR ("previous_user_id" IS NULL AND "user_id" <> 0 AND "open_time" >= $1)
+ORDER BY "guild_id", "ticket_id";`
+
+	rows, err := t.Query(ctx, query, recentSince, feedGuildIds)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	now := time.Now()
+
+	var activity []TicketActivity
+	for rows.Next() {
+		var guildId, claimedBy, previousClaimedBy uint64
+		var ticketId int
+		if err := rows.Scan(&guildId, &ticketId, &claimedBy, &previousClaimedBy); err != nil {
+			return nil, err
+		}
+
+		change := TicketActivity{
+			GuildId:    guildId,
+			TicketId:   ticketId,
+			Type:       "ticket.claimed",
+			OccurredAt: now,
+		}
+
+		if claimedBy == 0 {
+			change.Type = "ticket.unclaimed"
+		} else {
+			change.ClaimedBy = &claimedBy
+		}
+
+		if previousClaimedBy != 0 {
+			change.PreviousClaimedBy = &previousClaimedBy
+		}
+
+		activity = append(activity, change)
+	}
+
+	return activity, rows.Err()
+}
+
+// DeleteClosed removes the state of tickets that have been closed
+func (t *TicketClaimStateTable) DeleteClosed(ctx context.Context) error {
+	query := `
+DELETE FROM dashboard_ticket_claim_state
+WHERE NOT EXISTS(
+	SELECT 1
+	FROM tickets
+	WHERE tickets."guild_id" = dashboard_ticket_claim_state."guild_id"
+		AND tickets."id" = dashboard_ticket_claim_state."ticket_id"
+		AND tickets."open" = TRUE
+);`
+
+	_, err := t.Exec(ctx, query)
+	return err
+}
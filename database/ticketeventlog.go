@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// TicketEventLogTable records the ticket lifecycle events that have been published for changes made by the worker,
+// so that each event is only published once
+type TicketEventLogTable struct {
+	*pgxpool.Pool
+}
+
+// TicketActivity is a lifecycle change to a ticket found in the worker's tables. Type is the name of the event, e.g.
+// ticket.opened.
+type TicketActivity struct {
+	GuildId           uint64
+	TicketId          int
+	Type              string
+	UserId            uint64
+	Rating            *int16
+	Reason            *string
+	ClaimedBy         *uint64
+	PreviousClaimedBy *uint64
+	OccurredAt        time.Time
+}
+
+// eventSourceGuilds selects the guilds that ticket activity is looked for in: those with an enabled webhook, and
+// those passed in the given parameter, which have a live feed client connected
+func eventSourceGuilds(param string) string {
+	return `
+	SELECT "guild_id" FROM dashboard_webhooks WHERE "enabled" = TRUE
+	UNION
+	SELECT UNNEST(` + param + `::int8[])`
+}
+
+func newTicketEventLogTable(pool *pgxpool.Pool) *TicketEventLogTable {
+	return &TicketEventLogTable{
+		pool,
+	}
+}
+
+func (TicketEventLogTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS dashboard_ticket_event_log(
+	"guild_id" int8 NOT NULL,
+	"ticket_id" int4 NOT NULL,
+	"type" VARCHAR(32) NOT NULL,
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY("guild_id", "ticket_id", "type")
+);
+CREATE INDEX IF NOT EXISTS dashboard_ticket_event_log_created_at ON dashboard_ticket_event_log("created_at");`
+}
+
+// ClaimNew returns the tickets opened, closed or given a transcript since recentSince, and the tickets closed since
+// ratedSince that have been rated, excluding any that have already been claimed. Only guilds with an enabled webhook,
+// or in feedGuildIds, are included. Each change is only returned once, so it is safe to call from multiple replicas.
+func (t *TicketEventLogTable) ClaimNew(ctx context.Context, recentSince, ratedSince time.Time, feedGuildIds []uint64) ([]TicketActivity, error) {
+	query := `
+WITH guilds AS (` + eventSourceGuilds("$3") + `
+), activity AS (
+	SELECT tickets."guild_id", tickets."id" AS "ticket_id", 'ticket.opened' AS "type", tickets."user_id",
+		NULL::int2 AS "rating", NULL::text AS "reason", tickets."open_time" AS "occurred_at"
+	FROM tickets
+	WHERE tickets."open_time" >= $1
+	UNION ALL
+	SELECT tickets."guild_id", tickets."id", 'ticket.closed', COALESCE(close_reason."closed_by", 0),
+		NULL, close_reason."close_reason", tickets."close_time"
+	FROM tickets
+	LEFT OUTER JOIN close_reason
+		ON close_reason."guild_id" = tickets."guild_id" AND close_reason."ticket_id" = tickets."id"
+	WHERE tickets."open" = FALSE AND tickets."close_time" >= $1
+	UNION ALL
+	SELECT tickets."guild_id", tickets."id", 'transcript.available', 0, NULL, NULL, tickets."close_time"
+	FROM tickets
+	WHERE tickets."has_transcript" = TRUE AND tickets."close_time" >= $1
+	UNION ALL
+	SELECT tickets."guild_id", tickets."id", 'ticket.rated', tickets."user_id", service_ratings."rating", NULL,
+		tickets."close_time"
+	FROM tickets
+	INNER JOIN service_ratings
+		ON service_ratings."guild_id" = tickets."guild_id" AND service_ratings."ticket_id" = tickets."id"
+	WHERE tickets."close_time" >= $2
+), claimed AS (
+	INSERT INTO dashboard_ticket_event_log("guild_id", "ticket_id", "type")
+	SELECT activity."guild_id", activity."ticket_id", activity."type"
+	FROM activity
+	INNER JOIN guilds ON guilds."guild_id" = activity."guild_id"
+	ON CONFLICT DO NOTHING
+	RETURNING "guild_id", "ticket_id", "type"
+)
+SELECT activity."guild_id", activity."ticket_id", activity."type", activity."user_id", activity."rating",
+	activity."reason", activity."occurred_at"
+FROM activity
+INNER JOIN claimed
+	ON claimed."guild_id" = activity."guild_id" AND claimed."ticket_id" = activity."ticket_id" AND claimed."type" = activity."type"
+ORDER BY activity."occurred_at";`
+
+	rows, err := t.Query(ctx, query, recentSince, ratedSince, feedGuildIds)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var activity []TicketActivity
+	for rows.Next() {
+		var change TicketActivity
+		if err := rows.Scan(
+			&change.GuildId,
+			&change.TicketId,
+			&change.Type,
+			&change.UserId,
+			&change.Rating,
+			&change.Reason,
+			&change.OccurredAt,
+		); err != nil {
+			return nil, err
+		}
+
+		activity = append(activity, change)
+	}
+
+	return activity, rows.Err()
+}
+
+// DeleteOlderThan removes claims that are older than any change that ClaimNew can return
+func (t *TicketEventLogTable) DeleteOlderThan(ctx context.Context, before time.Time) error {
+	query := `DELETE FROM dashboard_ticket_event_log WHERE "created_at" < $1;`
+
+	_, err := t.Exec(ctx, query, before)
+	return err
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+)
+
+// TicketFilterTable fetches tickets from the bot's tables filtered on the dashboard's ticket metadata, which
+// Client.Tickets cannot see. It has no table of its own.
+type TicketFilterTable struct {
+	*pgxpool.Pool
+}
+
+// TicketMetadataFilter holds the filters on dashboard-owned ticket metadata. Empty fields are ignored.
+type TicketMetadataFilter struct {
+	// LabelIds selects tickets that have all the labels
+	LabelIds []int
+	Priority *TicketPriority
+}
+
+func (f TicketMetadataFilter) IsEmpty() bool {
+	return len(f.LabelIds) == 0 && f.Priority == nil
+}
+
+func newTicketFilterTable(pool *pgxpool.Pool) *TicketFilterTable {
+	return &TicketFilterTable{
+		pool,
+	}
+}
+
+// GetTickets returns the tickets that match both the options and the filter in a single query. The options are
+// interpreted in the same way as by Client.Tickets.GetByOptions.
+func (t *TicketFilterTable) GetTickets(ctx context.Context, opts database.TicketQueryOptions, filter TicketMetadataFilter) ([]database.Ticket, error) {
+	conditions := []string{`tickets."guild_id" = $1`}
+	args := []any{opts.GuildId}
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if opts.Id != 0 {
+		conditions = append(conditions, `tickets."id" = `+arg(opts.Id))
+	}
+
+	if len(opts.UserIds) > 0 {
+		conditions = append(conditions, `tickets."user_id" = ANY(`+arg(opts.UserIds)+`)`)
+	}
+
+	if opts.Open != nil {
+		conditions = append(conditions, `tickets."open" = `+arg(*opts.Open))
+	}
+
+	if opts.PanelId != 0 {
+		conditions = append(conditions, `tickets."panel_id" = `+arg(opts.PanelId))
+	}
+
+	if opts.Rating != 0 {
+		conditions = append(conditions, `EXISTS(
+	SELECT 1 FROM service_ratings
+	WHERE service_ratings."guild_id" = tickets."guild_id" AND service_ratings."ticket_id" = tickets."id" AND service_ratings."rating" = `+arg(opts.Rating)+`
+)`)
+	}
+
+	if opts.ClaimedById != 0 {
+		conditions = append(conditions, `EXISTS(
+	SELECT 1 FROM ticket_claims
+	WHERE ticket_claims."guild_id" = tickets."guild_id" AND ticket_claims."ticket_id" = tickets."id" AND ticket_claims."user_id" = `+arg(opts.ClaimedById)+`
+)`)
+	}
+
+	if len(filter.LabelIds) > 0 {
+		conditions = append(conditions, `tickets."id" IN (
+	SELECT "ticket_id"
+	FROM dashboard_ticket_label_assignments
+	WHERE "guild_id" = $1 AND "label_id" = ANY(`+arg(filter.LabelIds)+`)
+	GROUP BY "ticket_id"
+	HAVING COUNT(DISTINCT "label_id") = `+arg(len(filter.LabelIds))+`
+)`)
+	}
+
+	if filter.Priority != nil {
+		conditions = append(conditions, `EXISTS(
+	SELECT 1 FROM dashboard_ticket_priorities
+	WHERE dashboard_ticket_priorities."guild_id" = tickets."guild_id" AND dashboard_ticket_priorities."ticket_id" = tickets."id"
+		AND dashboard_ticket_priorities."priority" = `+arg(*filter.Priority)+`
+)`)
+	}
+
+	order := opts.Order
+	if order != database.OrderTypeDescending {
+		order = database.OrderTypeAscending
+	}
+
+	query := `
+SELECT tickets."id", tickets."guild_id", tickets."channel_id", tickets."user_id", tickets."open", tickets."open_time",
+	tickets."welcome_message_id", tickets."panel_id", tickets."has_transcript", tickets."close_time", tickets."is_thread",
+	tickets."join_message_id"
+FROM tickets
+WHERE ` + strings.Join(conditions, "\nAND ") + `
+ORDER BY tickets."id" ` + string(order)
+
+	if opts.Limit > 0 {
+		query += `
+LIMIT ` + arg(opts.Limit)
+	}
+
+	if opts.Offset > 0 {
+		query += `
+OFFSET ` + arg(opts.Offset)
+	}
+
+	rows, err := t.Query(ctx, query+";", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var tickets []database.Ticket
+	for rows.Next() {
+		var ticket database.Ticket
+		if err := rows.Scan(
+			&ticket.Id,
+			&ticket.GuildId,
+			&ticket.ChannelId,
+			&ticket.UserId,
+			&ticket.Open,
+			&ticket.OpenTime,
+			&ticket.WelcomeMessageId,
+			&ticket.PanelId,
+			&ticket.HasTranscript,
+			&ticket.CloseTime,
+			&ticket.IsThread,
+			&ticket.JoinMessageId,
+		); err != nil {
+			return nil, err
+		}
+
+		tickets = append(tickets, ticket)
+	}
+
+	return tickets, rows.Err()
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type TicketLabelAssignmentsTable struct {
+	*pgxpool.Pool
+}
+
+func newTicketLabelAssignmentsTable(pool *pgxpool.Pool) *TicketLabelAssignmentsTable {
+	return &TicketLabelAssignmentsTable{
+		pool,
+	}
+}
+
+func (TicketLabelAssignmentsTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS dashboard_ticket_label_assignments(
+	"guild_id" int8 NOT NULL,
+	"ticket_id" int4 NOT NULL,
+	"label_id" int4 NOT NULL,
+	FOREIGN KEY("label_id") REFERENCES dashboard_ticket_labels("id") ON DELETE CASCADE,
+	PRIMARY KEY("guild_id", "ticket_id", "label_id")
+);
+CREATE INDEX IF NOT EXISTS dashboard_ticket_label_assignments_label_id ON dashboard_ticket_label_assignments("label_id");`
+}
+
+func (t *TicketLabelAssignmentsTable) GetByTicket(ctx context.Context, guildId uint64, ticketId int) ([]int, error) {
+	query := `
+SELECT "label_id"
+FROM dashboard_ticket_label_assignments
+WHERE "guild_id" = $1 AND "ticket_id" = $2
+ORDER BY "label_id";`
+
+	rows, err := t.Query(ctx, query, guildId, ticketId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	labelIds := make([]int, 0)
+	for rows.Next() {
+		var labelId int
+		if err := rows.Scan(&labelId); err != nil {
+			return nil, err
+		}
+
+		labelIds = append(labelIds, labelId)
+	}
+
+	return labelIds, rows.Err()
+}
+
+// GetMulti returns the IDs of the labels attached to each of the tickets. Tickets without labels are omitted.
+func (t *TicketLabelAssignmentsTable) GetMulti(ctx context.Context, guildId uint64, ticketIds []int) (map[int][]int, error) {
+	query := `
+SELECT "ticket_id", "label_id"
+FROM dashboard_ticket_label_assignments
+WHERE "guild_id" = $1 AND "ticket_id" = ANY($2)
+ORDER BY "label_id";`
+
+	rows, err := t.Query(ctx, query, guildId, ticketIds)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	labels := make(map[int][]int)
+	for rows.Next() {
+		var ticketId, labelId int
+		if err := rows.Scan(&ticketId, &labelId); err != nil {
+			return nil, err
+		}
+
+		labels[ticketId] = append(labels[ticketId], labelId)
+	}
+
+	return labels, rows.Err()
+}
+
+// Set replaces the labels attached to the ticket
+func (t *TicketLabelAssignmentsTable) Set(ctx context.Context, guildId uint64, ticketId int, labelIds []int) error {
+	tx, err := t.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM dashboard_ticket_label_assignments WHERE "guild_id" = $1 AND "ticket_id" = $2;`, guildId, ticketId); err != nil {
+		return err
+	}
+
+	batch := &pgx.Batch{}
+	for _, labelId := range labelIds {
+		batch.Queue(`
+INSERT INTO dashboard_ticket_label_assignments("guild_id", "ticket_id", "label_id")
+VALUES($1, $2, $3)
+ON CONFLICT DO NOTHING;`, guildId, ticketId, labelId)
+	}
+
+	if batch.Len() > 0 {
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,153 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// TicketLabelsTable stores the labels defined by each guild, which staff can then attach to tickets
+type TicketLabelsTable struct {
+	*pgxpool.Pool
+}
+
+type TicketLabel struct {
+	Id      int
+	GuildId uint64
+	Name    string
+	Colour  uint32
+}
+
+func newTicketLabelsTable(pool *pgxpool.Pool) *TicketLabelsTable {
+	return &TicketLabelsTable{
+		pool,
+	}
+}
+
+func (TicketLabelsTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS dashboard_ticket_labels(
+	"id" SERIAL NOT NULL,
+	"guild_id" int8 NOT NULL,
+	"name" VARCHAR(32) NOT NULL,
+	"colour" int4 NOT NULL,
+	UNIQUE("guild_id", "name"),
+	PRIMARY KEY("id")
+);`
+}
+
+const ticketLabelColumns = `"id", "guild_id", "name", "colour"`
+
+func (t *TicketLabelsTable) Get(ctx context.Context, guildId uint64, id int) (TicketLabel, bool, error) {
+	query := `
+SELECT ` + ticketLabelColumns + `
+FROM dashboard_ticket_labels
+WHERE "id" = $1 AND "guild_id" = $2;`
+
+	label, err := scanTicketLabel(t.QueryRow(ctx, query, id, guildId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return TicketLabel{}, false, nil
+		}
+
+		return TicketLabel{}, false, err
+	}
+
+	return label, true, nil
+}
+
+func (t *TicketLabelsTable) GetByGuild(ctx context.Context, guildId uint64) ([]TicketLabel, error) {
+	query := `
+SELECT ` + ticketLabelColumns + `
+FROM dashboard_ticket_labels
+WHERE "guild_id" = $1
+ORDER BY "id";`
+
+	rows, err := t.Query(ctx, query, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	labels := make([]TicketLabel, 0)
+	for rows.Next() {
+		label, err := scanTicketLabel(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		labels = append(labels, label)
+	}
+
+	return labels, rows.Err()
+}
+
+func (t *TicketLabelsTable) Count(ctx context.Context, guildId uint64) (int, error) {
+	query := `SELECT COUNT(*) FROM dashboard_ticket_labels WHERE "guild_id" = $1;`
+
+	var count int
+	err := t.QueryRow(ctx, query, guildId).Scan(&count)
+	return count, err
+}
+
+// Create inserts the label, returning false if the guild already has a label with the same name
+func (t *TicketLabelsTable) Create(ctx context.Context, label TicketLabel) (TicketLabel, bool, error) {
+	query := `
+INSERT INTO dashboard_ticket_labels("guild_id", "name", "colour")
+VALUES($1, $2, $3)
+ON CONFLICT("guild_id", "name") DO NOTHING
+RETURNING ` + ticketLabelColumns + `;`
+
+	created, err := scanTicketLabel(t.QueryRow(ctx, query, label.GuildId, label.Name, int32(label.Colour)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return TicketLabel{}, false, nil
+		}
+
+		return TicketLabel{}, false, err
+	}
+
+	return created, true, nil
+}
+
+// Update sets the name and colour of the label, returning false if the guild already has a label with the new name
+func (t *TicketLabelsTable) Update(ctx context.Context, label TicketLabel) (bool, error) {
+	query := `
+UPDATE dashboard_ticket_labels
+SET "name" = $3, "colour" = $4
+WHERE "id" = $1 AND "guild_id" = $2 AND NOT EXISTS (
+	SELECT 1 FROM dashboard_ticket_labels WHERE "guild_id" = $2 AND "name" = $3 AND "id" != $1
+);`
+
+	res, err := t.Exec(ctx, query, label.Id, label.GuildId, label.Name, int32(label.Colour))
+	if err != nil {
+		return false, err
+	}
+
+	return res.RowsAffected() > 0, nil
+}
+
+// Delete removes the label, which also removes it from any tickets it is attached to
+func (t *TicketLabelsTable) Delete(ctx context.Context, guildId uint64, id int) error {
+	query := `DELETE FROM dashboard_ticket_labels WHERE "id" = $1 AND "guild_id" = $2;`
+
+	_, err := t.Exec(ctx, query, id, guildId)
+	return err
+}
+
+func scanTicketLabel(row pgx.Row) (TicketLabel, error) {
+	var label TicketLabel
+	var colour int32
+	err := row.Scan(
+		&label.Id,
+		&label.GuildId,
+		&label.Name,
+		&colour,
+	)
+
+	label.Colour = uint32(colour)
+	return label, err
+}
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// TicketNotesTable stores notes that staff keep about a ticket. Notes are only ever shown to staff on the dashboard.
+type TicketNotesTable struct {
+	*pgxpool.Pool
+}
+
+type TicketNote struct {
+	Id        int64      `json:"id"`
+	GuildId   uint64     `json:"guild_id,string"`
+	TicketId  int        `json:"ticket_id"`
+	AuthorId  uint64     `json:"author_id,string"`
+	Content   string     `json:"content"`
+	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at"`
+}
+
+func newTicketNotesTable(pool *pgxpool.Pool) *TicketNotesTable {
+	return &TicketNotesTable{
+		pool,
+	}
+}
+
+func (TicketNotesTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS dashboard_ticket_notes(
+	"id" BIGSERIAL NOT NULL,
+	"guild_id" int8 NOT NULL,
+	"ticket_id" int4 NOT NULL,
+	"author_id" int8 NOT NULL,
+	"content" VARCHAR(2000) NOT NULL,
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	"edited_at" TIMESTAMPTZ,
+	PRIMARY KEY("id")
+);
+CREATE INDEX IF NOT EXISTS dashboard_ticket_notes_guild_ticket ON dashboard_ticket_notes("guild_id", "ticket_id");`
+}
+
+const ticketNoteColumns = `"id", "guild_id", "ticket_id", "author_id", "content", "created_at", "edited_at"`
+
+func (t *TicketNotesTable) Get(ctx context.Context, guildId uint64, ticketId int, id int64) (TicketNote, bool, error) {
+	query := `
+SELECT ` + ticketNoteColumns + `
+FROM dashboard_ticket_notes
+WHERE "id" = $1 AND "guild_id" = $2 AND "ticket_id" = $3;`
+
+	note, err := scanTicketNote(t.QueryRow(ctx, query, id, guildId, ticketId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return TicketNote{}, false, nil
+		}
+
+		return TicketNote{}, false, err
+	}
+
+	return note, true, nil
+}
+
+// GetByTicket returns the notes on the ticket, oldest first
+func (t *TicketNotesTable) GetByTicket(ctx context.Context, guildId uint64, ticketId int) ([]TicketNote, error) {
+	query := `
+SELECT ` + ticketNoteColumns + `
+FROM dashboard_ticket_notes
+WHERE "guild_id" = $1 AND "ticket_id" = $2
+ORDER BY "id";`
+
+	rows, err := t.Query(ctx, query, guildId, ticketId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	notes := make([]TicketNote, 0)
+	for rows.Next() {
+		note, err := scanTicketNote(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		notes = append(notes, note)
+	}
+
+	return notes, rows.Err()
+}
+
+// Create adds the note, unless the ticket already has limit notes, in which case false is returned. Concurrent creates
+// for the same ticket are serialised, so the limit cannot be exceeded.
+func (t *TicketNotesTable) Create(ctx context.Context, note TicketNote, limit int) (TicketNote, bool, error) {
+	tx, err := t.Begin(ctx)
+	if err != nil {
+		return TicketNote{}, false, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	lockQuery := `SELECT pg_advisory_xact_lock(hashtext('dashboard_ticket_notes:' || $1::text), $2);`
+	if _, err := tx.Exec(ctx, lockQuery, note.GuildId, note.TicketId); err != nil {
+		return TicketNote{}, false, err
+	}
+
+	query := `
+INSERT INTO dashboard_ticket_notes("guild_id", "ticket_id", "author_id", "content")
+SELECT $1, $2, $3, $4
+WHERE (SELECT COUNT(*) FROM dashboard_ticket_notes WHERE "guild_id" = $1 AND "ticket_id" = $2) < $5
+RETURNING ` + ticketNoteColumns + `;`
+
+	created, err := scanTicketNote(tx.QueryRow(ctx, query, note.GuildId, note.TicketId, note.AuthorId, note.Content, limit))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return TicketNote{}, false, nil
+		}
+
+		return TicketNote{}, false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return TicketNote{}, false, err
+	}
+
+	return created, true, nil
+}
+
+// Update sets the content of the note, and returns the updated note
+func (t *TicketNotesTable) Update(ctx context.Context, guildId uint64, ticketId int, id int64, content string) (TicketNote, error) {
+	query := `
+UPDATE dashboard_ticket_notes
+SET "content" = $4, "edited_at" = NOW()
+WHERE "id" = $1 AND "guild_id" = $2 AND "ticket_id" = $3
+RETURNING ` + ticketNoteColumns + `;`
+
+	return scanTicketNote(t.QueryRow(ctx, query, id, guildId, ticketId, content))
+}
+
+func (t *TicketNotesTable) Delete(ctx context.Context, guildId uint64, ticketId int, id int64) error {
+	query := `DELETE FROM dashboard_ticket_notes WHERE "id" = $1 AND "guild_id" = $2 AND "ticket_id" = $3;`
+
+	_, err := t.Exec(ctx, query, id, guildId, ticketId)
+	return err
+}
+
+func scanTicketNote(row pgx.Row) (TicketNote, error) {
+	var note TicketNote
+	err := row.Scan(
+		&note.Id,
+		&note.GuildId,
+		&note.TicketId,
+		&note.AuthorId,
+		&note.Content,
+		&note.CreatedAt,
+		&note.EditedAt,
+	)
+
+	return note, err
+}
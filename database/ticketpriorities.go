@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type TicketPrioritiesTable struct {
+	*pgxpool.Pool
+}
+
+type TicketPriority string
+
+const (
+	TicketPriorityLow    TicketPriority = "low"
+	TicketPriorityNormal TicketPriority = "normal"
+	TicketPriorityHigh   TicketPriority = "high"
+	TicketPriorityUrgent TicketPriority = "urgent"
+)
+
+var TicketPriorities = []TicketPriority{
+	TicketPriorityLow,
+	TicketPriorityNormal,
+	TicketPriorityHigh,
+	TicketPriorityUrgent,
+}
+
+func newTicketPrioritiesTable(pool *pgxpool.Pool) *TicketPrioritiesTable {
+	return &TicketPrioritiesTable{
+		pool,
+	}
+}
+
+func (TicketPrioritiesTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS dashboard_ticket_priorities(
+	"guild_id" int8 NOT NULL,
+	"ticket_id" int4 NOT NULL,
+	"priority" VARCHAR(16) NOT NULL,
+	PRIMARY KEY("guild_id", "ticket_id")
+);`
+}
+
+func (t *TicketPrioritiesTable) Get(ctx context.Context, guildId uint64, ticketId int) (TicketPriority, bool, error) {
+	query := `SELECT "priority" FROM dashboard_ticket_priorities WHERE "guild_id" = $1 AND "ticket_id" = $2;`
+
+	var priority TicketPriority
+	if err := t.QueryRow(ctx, query, guildId, ticketId).Scan(&priority); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", false, nil
+		}
+
+		return "", false, err
+	}
+
+	return priority, true, nil
+}
+
+// GetMulti returns the priority of each of the tickets. Tickets without a priority are omitted.
+func (t *TicketPrioritiesTable) GetMulti(ctx context.Context, guildId uint64, ticketIds []int) (map[int]TicketPriority, error) {
+	query := `
+SELECT "ticket_id", "priority"
+FROM dashboard_ticket_priorities
+WHERE "guild_id" = $1 AND "ticket_id" = ANY($2);`
+
+	rows, err := t.Query(ctx, query, guildId, ticketIds)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	priorities := make(map[int]TicketPriority)
+	for rows.Next() {
+		var ticketId int
+		var priority TicketPriority
+		if err := rows.Scan(&ticketId, &priority); err != nil {
+			return nil, err
+		}
+
+		priorities[ticketId] = priority
+	}
+
+	return priorities, rows.Err()
+}
+
+func (t *TicketPrioritiesTable) Set(ctx context.Context, guildId uint64, ticketId int, priority TicketPriority) error {
+	query := `
+INSERT INTO dashboard_ticket_priorities("guild_id", "ticket_id", "priority")
+VALUES($1, $2, $3)
+ON CONFLICT("guild_id", "ticket_id") DO UPDATE SET "priority" = EXCLUDED."priority";`
+
+	_, err := t.Exec(ctx, query, guildId, ticketId, priority)
+	return err
+}
+
+func (t *TicketPrioritiesTable) Delete(ctx context.Context, guildId uint64, ticketId int) error {
+	query := `DELETE FROM dashboard_ticket_priorities WHERE "guild_id" = $1 AND "ticket_id" = $2;`
+
+	_, err := t.Exec(ctx, query, guildId, ticketId)
+	return err
+}
@@ -0,0 +1,235 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// TicketSlaTable stores the result of the most recent SLA evaluation of each open ticket
+type TicketSlaTable struct {
+	*pgxpool.Pool
+}
+
+type SlaStatus string
+
+const (
+	SlaStatusOk       SlaStatus = "ok"
+	SlaStatusAtRisk   SlaStatus = "at_risk"
+	SlaStatusBreached SlaStatus = "breached"
+	SlaStatusMet      SlaStatus = "met"
+)
+
+type SlaKind string
+
+const (
+	SlaKindFirstResponse SlaKind = "first_response"
+	SlaKindResolution    SlaKind = "resolution"
+)
+
+type TicketSla struct {
+	GuildId             uint64
+	TicketId            int
+	FirstResponseStatus *SlaStatus
+	FirstResponseDueAt  *time.Time
+	ResolutionStatus    *SlaStatus
+	ResolutionDueAt     *time.Time
+	EvaluatedAt         time.Time
+}
+
+// SlaTicket is an open ticket that is covered by an SLA policy, along with the data needed to evaluate it
+type SlaTicket struct {
+	GuildId   uint64
+	TicketId  int
+	ChannelId *uint64
+	OpenTime  time.Time
+	// FirstResponseAt is nil if staff have not responded yet
+	FirstResponseAt *time.Time
+	Policy          SlaPolicy
+}
+
+func newTicketSlaTable(pool *pgxpool.Pool) *TicketSlaTable {
+	return &TicketSlaTable{
+		pool,
+	}
+}
+
+func (TicketSlaTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS dashboard_ticket_sla(
+	"guild_id" int8 NOT NULL,
+	"ticket_id" int4 NOT NULL,
+	"first_response_status" VARCHAR(16),
+	"first_response_due_at" TIMESTAMPTZ,
+	"first_response_notified" BOOLEAN NOT NULL DEFAULT FALSE,
+	"resolution_status" VARCHAR(16),
+	"resolution_due_at" TIMESTAMPTZ,
+	"resolution_notified" BOOLEAN NOT NULL DEFAULT FALSE,
+	"evaluated_at" TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY("guild_id", "ticket_id")
+);
+CREATE INDEX IF NOT EXISTS dashboard_ticket_sla_evaluated_at ON dashboard_ticket_sla("evaluated_at");`
+}
+
+// GetOpenTickets returns every open ticket opened from a panel that has an SLA policy
+func (t *TicketSlaTable) GetOpenTickets(ctx context.Context) ([]SlaTicket, error) {
+	query := `
+SELECT
+	tickets."guild_id",
+	tickets."id",
+	tickets."channel_id",
+	tickets."open_time",
+	tickets."open_time" + first_response_time."response_time",
+	policies."guild_id",
+	policies."panel_id",
+	policies."first_response_seconds",
+	policies."resolution_seconds",
+	policies."at_risk_percent",
+	policies."notify_channel_id"
+FROM dashboard_sla_policies AS policies
+INNER JOIN tickets
+	ON tickets."guild_id" = policies."guild_id" AND tickets."panel_id" = policies."panel_id"
+LEFT OUTER JOIN first_response_time
+	ON first_response_time."guild_id" = tickets."guild_id" AND first_response_time."ticket_id" = tickets."id"
+WHERE tickets."open" = TRUE;`
+
+	rows, err := t.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var tickets []SlaTicket
+	for rows.Next() {
+		var ticket SlaTicket
+		if err := rows.Scan(
+			&ticket.GuildId,
+			&ticket.TicketId,
+			&ticket.ChannelId,
+			&ticket.OpenTime,
+			&ticket.FirstResponseAt,
+			&ticket.Policy.GuildId,
+			&ticket.Policy.PanelId,
+			&ticket.Policy.FirstResponseSeconds,
+			&ticket.Policy.ResolutionSeconds,
+			&ticket.Policy.AtRiskPercent,
+			&ticket.Policy.NotifyChannelId,
+		); err != nil {
+			return nil, err
+		}
+
+		tickets = append(tickets, ticket)
+	}
+
+	return tickets, rows.Err()
+}
+
+// GetMulti returns the SLA state of each of the tickets, omitting tickets that have not been evaluated since
+// evaluatedAfter.
+func (t *TicketSlaTable) GetMulti(ctx context.Context, guildId uint64, ticketIds []int, evaluatedAfter time.Time) (map[int]TicketSla, error) {
+	query := `
+SELECT "guild_id", "ticket_id", "first_response_status", "first_response_due_at", "resolution_status", "resolution_due_at", "evaluated_at"
+FROM dashboard_ticket_sla
+WHERE "guild_id" = $1 AND "ticket_id" = ANY($2) AND "evaluated_at" > $3;`
+
+	rows, err := t.Query(ctx, query, guildId, ticketIds, evaluatedAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	states := make(map[int]TicketSla)
+	for rows.Next() {
+		var state TicketSla
+		if err := rows.Scan(
+			&state.GuildId,
+			&state.TicketId,
+			&state.FirstResponseStatus,
+			&state.FirstResponseDueAt,
+			&state.ResolutionStatus,
+			&state.ResolutionDueAt,
+			&state.EvaluatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		states[state.TicketId] = state
+	}
+
+	return states, rows.Err()
+}
+
+// SetMulti stores the result of evaluating the tickets. Notification state is preserved.
+func (t *TicketSlaTable) SetMulti(ctx context.Context, states []TicketSla) error {
+	query := `
+INSERT INTO dashboard_ticket_sla("guild_id", "ticket_id", "first_response_status", "first_response_due_at", "resolution_status", "resolution_due_at", "evaluated_at")
+VALUES($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT("guild_id", "ticket_id") DO UPDATE SET
+	"first_response_status" = EXCLUDED."first_response_status",
+	"first_response_due_at" = EXCLUDED."first_response_due_at",
+	"resolution_status" = EXCLUDED."resolution_status",
+	"resolution_due_at" = EXCLUDED."resolution_due_at",
+	"evaluated_at" = EXCLUDED."evaluated_at";`
+
+	batch := &pgx.Batch{}
+	for _, state := range states {
+		batch.Queue(query, state.GuildId, state.TicketId, state.FirstResponseStatus, state.FirstResponseDueAt, state.ResolutionStatus, state.ResolutionDueAt, state.EvaluatedAt)
+	}
+
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	return t.SendBatch(ctx, batch).Close()
+}
+
+// ClaimNotification marks the breach of the SLA as notified, returning true if it had not been already. Only one
+// replica will receive true for each breach.
+func (t *TicketSlaTable) ClaimNotification(ctx context.Context, guildId uint64, ticketId int, kind SlaKind) (bool, error) {
+	var query string
+	switch kind {
+	case SlaKindFirstResponse:
+		query = `UPDATE dashboard_ticket_sla SET "first_response_notified" = TRUE WHERE "guild_id" = $1 AND "ticket_id" = $2 AND "first_response_notified" = FALSE;`
+	case SlaKindResolution:
+		query = `UPDATE dashboard_ticket_sla SET "resolution_notified" = TRUE WHERE "guild_id" = $1 AND "ticket_id" = $2 AND "resolution_notified" = FALSE;`
+	default:
+		return false, nil
+	}
+
+	res, err := t.Exec(ctx, query, guildId, ticketId)
+	if err != nil {
+		return false, err
+	}
+
+	return res.RowsAffected() > 0, nil
+}
+
+// ReleaseNotification reverses ClaimNotification after failing to notify of the breach, so that it is tried again on the
+// next evaluation
+func (t *TicketSlaTable) ReleaseNotification(ctx context.Context, guildId uint64, ticketId int, kind SlaKind) error {
+	var query string
+	switch kind {
+	case SlaKindFirstResponse:
+		query = `UPDATE dashboard_ticket_sla SET "first_response_notified" = FALSE WHERE "guild_id" = $1 AND "ticket_id" = $2;`
+	case SlaKindResolution:
+		query = `UPDATE dashboard_ticket_sla SET "resolution_notified" = FALSE WHERE "guild_id" = $1 AND "ticket_id" = $2;`
+	default:
+		return nil
+	}
+
+	_, err := t.Exec(ctx, query, guildId, ticketId)
+	return err
+}
+
+// DeleteStale removes the state of tickets that have not been evaluated since before, i.e. tickets that have been
+// closed or are no longer covered by a policy
+func (t *TicketSlaTable) DeleteStale(ctx context.Context, before time.Time) error {
+	query := `DELETE FROM dashboard_ticket_sla WHERE "evaluated_at" < $1;`
+
+	_, err := t.Exec(ctx, query, before)
+	return err
+}
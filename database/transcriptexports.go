@@ -0,0 +1,194 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// TranscriptExportsTable tracks transcript export jobs, so that their status can be read from any replica. The
+// archives themselves are written to the shared export directory.
+type TranscriptExportsTable struct {
+	*pgxpool.Pool
+}
+
+type TranscriptExportStatus string
+
+const (
+	TranscriptExportStatusRunning  TranscriptExportStatus = "running"
+	TranscriptExportStatusComplete TranscriptExportStatus = "complete"
+	TranscriptExportStatusFailed   TranscriptExportStatus = "failed"
+)
+
+type TranscriptExport struct {
+	Id          string
+	GuildId     uint64
+	RequestedBy uint64
+	IncludeHtml bool
+	Status      TranscriptExportStatus
+	Total       int
+	Completed   int
+	Failed      int
+	Truncated   bool
+	Error       *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func newTranscriptExportsTable(pool *pgxpool.Pool) *TranscriptExportsTable {
+	return &TranscriptExportsTable{
+		pool,
+	}
+}
+
+func (TranscriptExportsTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS dashboard_transcript_exports(
+	"id" VARCHAR(36) NOT NULL,
+	"guild_id" int8 NOT NULL,
+	"requested_by" int8 NOT NULL,
+	"include_html" bool NOT NULL,
+	"status" VARCHAR(16) NOT NULL DEFAULT 'running',
+	"total" int4 NOT NULL DEFAULT 0,
+	"completed" int4 NOT NULL DEFAULT 0,
+	"failed" int4 NOT NULL DEFAULT 0,
+	"truncated" bool NOT NULL DEFAULT false,
+	"error" TEXT,
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	"updated_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS dashboard_transcript_exports_running ON dashboard_transcript_exports("guild_id") WHERE "status" = 'running';`
+}
+
+const transcriptExportColumns = `"id", "guild_id", "requested_by", "include_html", "status", "total", "completed", "failed", "truncated", "error", "created_at", "updated_at"`
+
+// Create inserts a running export, returning false if the guild already has a running export. Running exports that
+// have not reported progress since staleBefore are first marked as failed, as the replica running them has stopped.
+func (t *TranscriptExportsTable) Create(ctx context.Context, export TranscriptExport, staleBefore time.Time) (TranscriptExport, bool, error) {
+	staleQuery := `
+UPDATE dashboard_transcript_exports
+SET "status" = 'failed', "error" = 'The export was interrupted', "updated_at" = NOW()
+WHERE "guild_id" = $1 AND "status" = 'running' AND "updated_at" < $2;`
+
+	if _, err := t.Exec(ctx, staleQuery, export.GuildId, staleBefore); err != nil {
+		return TranscriptExport{}, false, err
+	}
+
+	query := `
+INSERT INTO dashboard_transcript_exports("id", "guild_id", "requested_by", "include_html")
+VALUES($1, $2, $3, $4)
+ON CONFLICT DO NOTHING
+RETURNING ` + transcriptExportColumns + `;`
+
+	created, err := scanTranscriptExport(t.QueryRow(ctx, query, export.Id, export.GuildId, export.RequestedBy, export.IncludeHtml))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return TranscriptExport{}, false, nil
+		}
+
+		return TranscriptExport{}, false, err
+	}
+
+	return created, true, nil
+}
+
+func (t *TranscriptExportsTable) Get(ctx context.Context, guildId uint64, id string) (TranscriptExport, bool, error) {
+	query := `
+SELECT ` + transcriptExportColumns + `
+FROM dashboard_transcript_exports
+WHERE "id" = $1 AND "guild_id" = $2;`
+
+	export, err := scanTranscriptExport(t.QueryRow(ctx, query, id, guildId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return TranscriptExport{}, false, nil
+		}
+
+		return TranscriptExport{}, false, err
+	}
+
+	return export, true, nil
+}
+
+func (t *TranscriptExportsTable) SetTotal(ctx context.Context, id string, total int, truncated bool) error {
+	query := `
+UPDATE dashboard_transcript_exports
+SET "total" = $2, "truncated" = $3, "updated_at" = NOW()
+WHERE "id" = $1;`
+
+	_, err := t.Exec(ctx, query, id, total, truncated)
+	return err
+}
+
+// SetProgress records the number of tickets processed so far, which also serves as a heartbeat for the export
+func (t *TranscriptExportsTable) SetProgress(ctx context.Context, id string, completed, failed int) error {
+	query := `
+UPDATE dashboard_transcript_exports
+SET "completed" = $2, "failed" = $3, "updated_at" = NOW()
+WHERE "id" = $1;`
+
+	_, err := t.Exec(ctx, query, id, completed, failed)
+	return err
+}
+
+func (t *TranscriptExportsTable) SetResult(ctx context.Context, id string, status TranscriptExportStatus, errorMessage *string) error {
+	query := `
+UPDATE dashboard_transcript_exports
+SET "status" = $2, "error" = $3, "updated_at" = NOW()
+WHERE "id" = $1;`
+
+	_, err := t.Exec(ctx, query, id, status, errorMessage)
+	return err
+}
+
+// DeleteExpired removes exports created before the given time that are no longer running, returning their IDs so that
+// the archives can be removed
+func (t *TranscriptExportsTable) DeleteExpired(ctx context.Context, createdBefore time.Time) ([]string, error) {
+	query := `
+DELETE FROM dashboard_transcript_exports
+WHERE "created_at" < $1 AND "status" != 'running'
+RETURNING "id";`
+
+	rows, err := t.Query(ctx, query, createdBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func scanTranscriptExport(row pgx.Row) (TranscriptExport, error) {
+	var export TranscriptExport
+	err := row.Scan(
+		&export.Id,
+		&export.GuildId,
+		&export.RequestedBy,
+		&export.IncludeHtml,
+		&export.Status,
+		&export.Total,
+		&export.Completed,
+		&export.Failed,
+		&export.Truncated,
+		&export.Error,
+		&export.CreatedAt,
+		&export.UpdatedAt,
+	)
+
+	return export, err
+}
@@ -0,0 +1,209 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type WebhookDeliveriesTable struct {
+	*pgxpool.Pool
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
+
+type WebhookDelivery struct {
+	Id             int64
+	WebhookId      int64
+	EventId        string
+	EventType      string
+	Payload        json.RawMessage
+	Status         WebhookDeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	ResponseStatus *int
+	Error          *string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// ClaimedWebhookDelivery is a delivery along with the webhook details required to send it
+type ClaimedWebhookDelivery struct {
+	WebhookDelivery
+	GuildId uint64
+	Url     string
+	Secret  string
+}
+
+func newWebhookDeliveriesTable(pool *pgxpool.Pool) *WebhookDeliveriesTable {
+	return &WebhookDeliveriesTable{
+		pool,
+	}
+}
+
+func (WebhookDeliveriesTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS dashboard_webhook_deliveries(
+	"id" BIGSERIAL NOT NULL,
+	"webhook_id" int8 NOT NULL,
+	"event_id" VARCHAR(64) NOT NULL,
+	"event_type" VARCHAR(32) NOT NULL,
+	"payload" jsonb NOT NULL,
+	"status" VARCHAR(16) NOT NULL DEFAULT 'pending',
+	"attempts" int4 NOT NULL DEFAULT 0,
+	"next_attempt_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	"response_status" int4,
+	"error" TEXT,
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	"delivered_at" TIMESTAMPTZ,
+	FOREIGN KEY("webhook_id") REFERENCES dashboard_webhooks("id") ON DELETE CASCADE,
+	UNIQUE("webhook_id", "event_id"),
+	PRIMARY KEY("id")
+);
+CREATE INDEX IF NOT EXISTS dashboard_webhook_deliveries_webhook_id ON dashboard_webhook_deliveries("webhook_id", "id" DESC);
+CREATE INDEX IF NOT EXISTS dashboard_webhook_deliveries_pending ON dashboard_webhook_deliveries("next_attempt_at") WHERE "status" = 'pending';`
+}
+
+const webhookDeliveryColumns = `"id", "webhook_id", "event_id", "event_type", "payload", "status", "attempts", "next_attempt_at", "response_status", "error", "created_at", "delivered_at"`
+
+// Enqueue creates a pending delivery of the event for each enabled webhook in the guild subscribed to the event type.
+// Events that have already been enqueued are ignored, so it is safe to call once per replica.
+func (t *WebhookDeliveriesTable) Enqueue(ctx context.Context, guildId uint64, eventId, eventType string, payload json.RawMessage) error {
+	query := `
+INSERT INTO dashboard_webhook_deliveries("webhook_id", "event_id", "event_type", "payload")
+SELECT "id", $2, $3, $4
+FROM dashboard_webhooks
+WHERE "guild_id" = $1 AND "enabled" = TRUE AND $3 = ANY("events")
+ON CONFLICT("webhook_id", "event_id") DO NOTHING;`
+
+	_, err := t.Exec(ctx, query, guildId, eventId, eventType, []byte(payload))
+	return err
+}
+
+// GetByWebhook returns deliveries for the webhook, newest first. If beforeId is greater than zero, only deliveries with
+// a lower ID are returned.
+func (t *WebhookDeliveriesTable) GetByWebhook(ctx context.Context, webhookId int64, beforeId int64, limit int) ([]WebhookDelivery, error) {
+	query := `
+SELECT ` + webhookDeliveryColumns + `
+FROM dashboard_webhook_deliveries
+WHERE "webhook_id" = $1 AND ($2::int8 = 0 OR "id" < $2)
+ORDER BY "id" DESC
+LIMIT $3;`
+
+	rows, err := t.Query(ctx, query, webhookId, beforeId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var deliveries []WebhookDelivery
+	for rows.Next() {
+		var delivery WebhookDelivery
+		if err := rows.Scan(delivery.fields()...); err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// ClaimDue returns up to limit deliveries that are due to be attempted, incrementing their attempt counts. Claimed
+// deliveries are not returned again until lease has elapsed, so a delivery is retried if the claiming replica stops
+// before recording the result.
+func (t *WebhookDeliveriesTable) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]ClaimedWebhookDelivery, error) {
+	query := `
+UPDATE dashboard_webhook_deliveries AS d
+SET "attempts" = d."attempts" + 1, "next_attempt_at" = NOW() + $2::interval
+FROM dashboard_webhooks AS w
+WHERE d."webhook_id" = w."id" AND d."id" IN (
+	SELECT deliveries."id"
+	FROM dashboard_webhook_deliveries AS deliveries
+	INNER JOIN dashboard_webhooks AS webhooks ON deliveries."webhook_id" = webhooks."id"
+	WHERE deliveries."status" = 'pending' AND deliveries."next_attempt_at" <= NOW() AND webhooks."enabled" = TRUE
+	ORDER BY deliveries."next_attempt_at"
+	LIMIT $1
+	FOR UPDATE OF deliveries SKIP LOCKED
+)
+RETURNING d."id", d."webhook_id", d."event_id", d."event_type", d."payload", d."status", d."attempts", d."next_attempt_at",
+	d."response_status", d."error", d."created_at", d."delivered_at", w."guild_id", w."url", w."secret";`
+
+	rows, err := t.Query(ctx, query, limit, lease)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var deliveries []ClaimedWebhookDelivery
+	for rows.Next() {
+		var delivery ClaimedWebhookDelivery
+		fields := append(delivery.fields(), &delivery.GuildId, &delivery.Url, &delivery.Secret)
+		if err := rows.Scan(fields...); err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (t *WebhookDeliveriesTable) SetDelivered(ctx context.Context, id int64, responseStatus int) error {
+	query := `
+UPDATE dashboard_webhook_deliveries
+SET "status" = 'delivered', "response_status" = $2, "error" = NULL, "delivered_at" = NOW()
+WHERE "id" = $1;`
+
+	_, err := t.Exec(ctx, query, id, responseStatus)
+	return err
+}
+
+// SetAttemptFailed records a failed attempt. If nextAttemptAt is nil, the delivery is marked as failed and will not be
+// retried.
+func (t *WebhookDeliveriesTable) SetAttemptFailed(ctx context.Context, id int64, responseStatus *int, errorMessage string, nextAttemptAt *time.Time) error {
+	query := `
+UPDATE dashboard_webhook_deliveries
+SET "status" = CASE WHEN $4::TIMESTAMPTZ IS NULL THEN 'failed' ELSE 'pending' END,
+	"response_status" = $2,
+	"error" = $3,
+	"next_attempt_at" = COALESCE($4, "next_attempt_at")
+WHERE "id" = $1;`
+
+	_, err := t.Exec(ctx, query, id, responseStatus, errorMessage, nextAttemptAt)
+	return err
+}
+
+func (t *WebhookDeliveriesTable) DeleteOlderThan(ctx context.Context, before time.Time) error {
+	query := `DELETE FROM dashboard_webhook_deliveries WHERE "created_at" < $1;`
+
+	_, err := t.Exec(ctx, query, before)
+	return err
+}
+
+func (d *WebhookDelivery) fields() []any {
+	return []any{
+		&d.Id,
+		&d.WebhookId,
+		&d.EventId,
+		&d.EventType,
+		&d.Payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.ResponseStatus,
+		&d.Error,
+		&d.CreatedAt,
+		&d.DeliveredAt,
+	}
+}
@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type WebhooksTable struct {
+	*pgxpool.Pool
+}
+
+type Webhook struct {
+	Id        int64
+	GuildId   uint64
+	Url       string
+	Secret    string
+	Events    []string
+	Enabled   bool
+	CreatedBy uint64
+	CreatedAt time.Time
+}
+
+func newWebhooksTable(pool *pgxpool.Pool) *WebhooksTable {
+	return &WebhooksTable{
+		pool,
+	}
+}
+
+func (WebhooksTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS dashboard_webhooks(
+	"id" BIGSERIAL NOT NULL,
+	"guild_id" int8 NOT NULL,
+	"url" VARCHAR(255) NOT NULL,
+	"secret" VARCHAR(64) NOT NULL,
+	"events" TEXT[] NOT NULL,
+	"enabled" BOOLEAN NOT NULL DEFAULT TRUE,
+	"created_by" int8 NOT NULL,
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY("id")
+);
+CREATE INDEX IF NOT EXISTS dashboard_webhooks_guild_id ON dashboard_webhooks("guild_id");`
+}
+
+const webhookColumns = `"id", "guild_id", "url", "secret", "events", "enabled", "created_by", "created_at"`
+
+func (t *WebhooksTable) Get(ctx context.Context, guildId uint64, id int64) (Webhook, bool, error) {
+	query := `
+SELECT ` + webhookColumns + `
+FROM dashboard_webhooks
+WHERE "id" = $1 AND "guild_id" = $2;`
+
+	webhook, err := scanWebhook(t.QueryRow(ctx, query, id, guildId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Webhook{}, false, nil
+		}
+
+		return Webhook{}, false, err
+	}
+
+	return webhook, true, nil
+}
+
+func (t *WebhooksTable) GetByGuild(ctx context.Context, guildId uint64) ([]Webhook, error) {
+	query := `
+SELECT ` + webhookColumns + `
+FROM dashboard_webhooks
+WHERE "guild_id" = $1
+ORDER BY "id";`
+
+	rows, err := t.Query(ctx, query, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var webhooks []Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (t *WebhooksTable) Count(ctx context.Context, guildId uint64) (int, error) {
+	query := `SELECT COUNT(*) FROM dashboard_webhooks WHERE "guild_id" = $1;`
+
+	var count int
+	err := t.QueryRow(ctx, query, guildId).Scan(&count)
+	return count, err
+}
+
+func (t *WebhooksTable) Create(ctx context.Context, webhook Webhook) (Webhook, error) {
+	query := `
+INSERT INTO dashboard_webhooks("guild_id", "url", "secret", "events", "enabled", "created_by")
+VALUES($1, $2, $3, $4, $5, $6)
+RETURNING ` + webhookColumns + `;`
+
+	return scanWebhook(t.QueryRow(ctx, query, webhook.GuildId, webhook.Url, webhook.Secret, webhook.Events, webhook.Enabled, webhook.CreatedBy))
+}
+
+// Update sets the URL, events and enabled state of the webhook. The secret is only changed through SetSecret.
+func (t *WebhooksTable) Update(ctx context.Context, webhook Webhook) error {
+	query := `
+UPDATE dashboard_webhooks
+SET "url" = $3, "events" = $4, "enabled" = $5
+WHERE "id" = $1 AND "guild_id" = $2;`
+
+	_, err := t.Exec(ctx, query, webhook.Id, webhook.GuildId, webhook.Url, webhook.Events, webhook.Enabled)
+	return err
+}
+
+func (t *WebhooksTable) SetSecret(ctx context.Context, guildId uint64, id int64, secret string) error {
+	query := `UPDATE dashboard_webhooks SET "secret" = $3 WHERE "id" = $1 AND "guild_id" = $2;`
+
+	_, err := t.Exec(ctx, query, id, guildId, secret)
+	return err
+}
+
+func (t *WebhooksTable) Delete(ctx context.Context, guildId uint64, id int64) error {
+	query := `DELETE FROM dashboard_webhooks WHERE "id" = $1 AND "guild_id" = $2;`
+
+	_, err := t.Exec(ctx, query, id, guildId)
+	return err
+}
+
+func scanWebhook(row pgx.Row) (Webhook, error) {
+	var webhook Webhook
+	err := row.Scan(
+		&webhook.Id,
+		&webhook.GuildId,
+		&webhook.Url,
+		&webhook.Secret,
+		&webhook.Events,
+		&webhook.Enabled,
+		&webhook.CreatedBy,
+		&webhook.CreatedAt,
+	)
+
+	return webhook, err
+}
@@ -0,0 +1,47 @@
+// Package embedtemplates resolves references to a guild's embed templates. Objects that use a template hold their own
+// copy of the embed, as that is what the bot reads: the template is only consulted when the object is saved, or when
+// the template is edited.
+package embedtemplates
+
+import (
+	"context"
+
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+)
+
+// Load returns the embed stored in the template, or false if the guild has no template with the ID
+func Load(ctx context.Context, guildId uint64, templateId int) (*types.CustomEmbed, bool, error) {
+	template, ok, err := dbclient.Dashboard.EmbedTemplates.Get(ctx, guildId, templateId)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	wrapped, err := types.NewEmbedTemplate(template)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return wrapped.Embed, true, nil
+}
+
+// Resolve replaces embed with the contents of the referenced template. If templateId is nil, embed is left unchanged.
+// A validation error is returned at pointer if the guild has no template with the ID.
+func Resolve(ctx context.Context, guildId uint64, templateId *int, embed **types.CustomEmbed, pointer string) error {
+	if templateId == nil {
+		return nil
+	}
+
+	resolved, ok, err := Load(ctx, guildId, *templateId)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return validation.NewFieldError(pointer, validation.CodeNotFound, "Embed template not found")
+	}
+
+	*embed = resolved
+	return nil
+}
@@ -0,0 +1,93 @@
+package placeholders
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	database "github.com/jadevelopmentgrp/Tickets-Database"
+	"github.com/rxdn/gdl/rest/request"
+)
+
+// Load fetches the values of the placeholders for the ticket
+func Load(ctx context.Context, botContext *botcontext.BotContext, ticket database.Ticket) (Values, error) {
+	values := Values{
+		UserId:      ticket.UserId,
+		TicketId:    ticket.Id,
+		FormAnswers: make(map[string]string),
+	}
+
+	claimer, err := dbclient.Client.TicketClaims.Get(ctx, ticket.GuildId, ticket.Id)
+	if err != nil {
+		return Values{}, err
+	}
+
+	if claimer != 0 {
+		values.ClaimerId = &claimer
+	}
+
+	if ticket.PanelId == nil {
+		return values, nil
+	}
+
+	panel, err := dbclient.Client.Panel.GetById(ctx, *ticket.PanelId)
+	if err != nil {
+		return Values{}, err
+	}
+
+	values.PanelTitle = panel.Title
+
+	if panel.FormId != nil {
+		values.FormAnswers, err = loadFormAnswers(ctx, botContext, ticket, *panel.FormId)
+		if err != nil {
+			return Values{}, err
+		}
+	}
+
+	return values, nil
+}
+
+// loadFormAnswers reads the answers to the panel's form from the ticket's welcome message, where the bot records each
+// answer as an embed field named after the input's label. Answers are keyed by the custom ID of the input.
+func loadFormAnswers(ctx context.Context, botContext *botcontext.BotContext, ticket database.Ticket, formId int) (map[string]string, error) {
+	answers := make(map[string]string)
+	if ticket.ChannelId == nil || ticket.WelcomeMessageId == nil {
+		return answers, nil
+	}
+
+	inputs, err := dbclient.Client.FormInput.GetInputs(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(inputs) == 0 {
+		return answers, nil
+	}
+
+	msg, err := botContext.GetChannelMessage(ctx, *ticket.ChannelId, *ticket.WelcomeMessageId)
+	if err != nil {
+		// The welcome message may have been deleted, in which case form placeholders are left empty
+		var restError request.RestError
+		if errors.As(err, &restError) && restError.StatusCode == 404 {
+			return answers, nil
+		}
+
+		return nil, err
+	}
+
+	byLabel := make(map[string]string)
+	for _, e := range msg.Embeds {
+		for _, field := range e.Fields {
+			byLabel[field.Name] = field.Value
+		}
+	}
+
+	for _, input := range inputs {
+		if answer, ok := byLabel[input.Label]; ok {
+			answers[input.CustomId] = answer
+		}
+	}
+
+	return answers, nil
+}
@@ -0,0 +1,171 @@
+// Package placeholders expands variables such as {user} and {ticket_id} in tags when they are sent to a ticket
+package placeholders
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/app/http/validation"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+)
+
+const (
+	User     = "user"
+	TicketId = "ticket_id"
+	Panel    = "panel"
+	Claimer  = "claimer"
+	// Form placeholders take the custom ID of a form input as an argument, e.g. {form:order_number}
+	Form = "form"
+)
+
+// pattern matches {name} and {name:argument}. Text in braces that does not look like a placeholder, such as JSON, is
+// left alone.
+var pattern = regexp.MustCompile(`\{([a-z_]+)(?::([A-Za-z0-9_-]+))?\}`)
+
+var names = map[string]struct{}{
+	User:     {},
+	TicketId: {},
+	Panel:    {},
+	Claimer:  {},
+}
+
+// Values holds the data placeholders are expanded with
+type Values struct {
+	UserId     uint64
+	TicketId   int
+	PanelTitle string
+	ClaimerId  *uint64
+	// FormAnswers maps the custom ID of each form input to the answer given when the ticket was opened
+	FormAnswers map[string]string
+}
+
+// Validate returns an error if text contains a placeholder that cannot be expanded. If formInputIds is not nil, form
+// placeholders must reference one of the custom IDs in it.
+func Validate(text string, formInputIds map[string]struct{}) error {
+	for _, match := range pattern.FindAllStringSubmatch(text, -1) {
+		name, argument := match[1], match[2]
+
+		if name == Form {
+			if argument == "" {
+				return validation.NewInvalidInputErrorf("The %s placeholder must reference a form input, e.g. {form:input_id}", match[0])
+			}
+
+			if formInputIds != nil {
+				if _, ok := formInputIds[argument]; !ok {
+					return validation.NewInvalidInputErrorf("The %s placeholder references a form input that does not exist", match[0])
+				}
+			}
+
+			continue
+		}
+
+		if _, ok := names[name]; !ok || argument != "" {
+			return validation.NewInvalidInputErrorf("Unknown placeholder %s. Supported placeholders are {user}, {ticket_id}, {panel}, {claimer} and {form:input_id}", match[0])
+		}
+	}
+
+	return nil
+}
+
+// ValidateEmbed runs Validate on each of the embed's text fields
+func ValidateEmbed(e *types.CustomEmbed, formInputIds map[string]struct{}) error {
+	copied := *e
+	for _, text := range embedText(&copied) {
+		if err := Validate(*text, formInputIds); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Expand replaces the placeholders in text with their values
+func (v Values) Expand(text string) string {
+	return pattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		match := pattern.FindStringSubmatch(placeholder)
+		name, argument := match[1], match[2]
+
+		switch name {
+		case User:
+			return fmt.Sprintf("<@%d>", v.UserId)
+		case TicketId:
+			return strconv.Itoa(v.TicketId)
+		case Panel:
+			return v.PanelTitle
+		case Claimer:
+			if v.ClaimerId == nil {
+				return "Unclaimed"
+			}
+
+			return fmt.Sprintf("<@%d>", *v.ClaimerId)
+		case Form:
+			// Answers are written by the ticket opener, so they must not be able to ping anyone through a tag
+			return escapeMentions(v.FormAnswers[argument])
+		default:
+			// Tags are validated when they are saved, but may have been created before placeholders were supported
+			return placeholder
+		}
+	})
+}
+
+// escapeMentions stops Discord from parsing mentions in the text, including @everyone, @here and <@id>, by inserting a
+// zero-width space after each @
+func escapeMentions(text string) string {
+	return strings.ReplaceAll(text, "@", "@\u200b")
+}
+
+// ExpandEmbed returns a copy of the embed with the placeholders in each of its text fields replaced
+func (v Values) ExpandEmbed(e *types.CustomEmbed) *types.CustomEmbed {
+	expanded := *e
+	expanded.Fields = append([]types.Field(nil), e.Fields...)
+
+	for _, text := range embedText(&expanded) {
+		*text = v.Expand(*text)
+	}
+
+	return &expanded
+}
+
+// embedText returns pointers to each of the non-empty text fields of the embed that placeholders may appear in. URLs
+// are excluded.
+func embedText(e *types.CustomEmbed) []*string {
+	var text []*string
+	add := func(s **string) {
+		if *s != nil {
+			copied := **s
+			*s = &copied
+			text = append(text, *s)
+		}
+	}
+
+	add(&e.Title)
+	add(&e.Description)
+	add(&e.Author.Name)
+	add(&e.Footer.Text)
+
+	for i := range e.Fields {
+		text = append(text, &e.Fields[i].Name, &e.Fields[i].Value)
+	}
+
+	return text
+}
+
+// HasPlaceholders returns whether any of the text contains a placeholder
+func HasPlaceholders(content *string, e *types.CustomEmbed) bool {
+	if content != nil && pattern.MatchString(*content) {
+		return true
+	}
+
+	if e != nil {
+		copied := *e
+		for _, text := range embedText(&copied) {
+			if pattern.MatchString(*text) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
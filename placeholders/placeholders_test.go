@@ -0,0 +1,60 @@
+package placeholders
+
+import (
+	"testing"
+
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	formInputIds := map[string]struct{}{"order": {}}
+
+	assert.NoError(t, Validate("Hi {user}, ticket {ticket_id} from {panel} is with {claimer}", formInputIds))
+	assert.NoError(t, Validate("Order {form:order}", formInputIds))
+	assert.NoError(t, Validate(`Literal braces like {"json": true} are ignored`, formInputIds))
+
+	assert.Error(t, Validate("Hi {username}", formInputIds))
+	assert.Error(t, Validate("Hi {user:1}", formInputIds))
+	assert.Error(t, Validate("Order {form}", formInputIds))
+	assert.Error(t, Validate("Order {form:missing}", formInputIds))
+
+	assert.NoError(t, Validate("Order {form:missing}", nil), "form inputs are only checked when provided")
+}
+
+func TestExpand(t *testing.T) {
+	values := Values{
+		UserId:      1,
+		TicketId:    42,
+		PanelTitle:  "Billing",
+		FormAnswers: map[string]string{"order": "#1234"},
+	}
+
+	assert.Equal(t, "<@1> #42 Billing Unclaimed #1234 ", values.Expand("{user} #{ticket_id} {panel} {claimer} {form:order} {form:other}"))
+
+	values.ClaimerId = utils.Ptr(uint64(2))
+	assert.Equal(t, "<@2>", values.Expand("{claimer}"))
+}
+
+func TestExpandEscapesFormMentions(t *testing.T) {
+	values := Values{
+		FormAnswers: map[string]string{"order": "@everyone <@1> <@&2>"},
+	}
+
+	assert.Equal(t, "@\u200beveryone <@\u200b1> <@\u200b&2>", values.Expand("{form:order}"))
+}
+
+func TestExpandEmbedDoesNotModifyOriginal(t *testing.T) {
+	e := &types.CustomEmbed{
+		Title:  utils.Ptr("Ticket {ticket_id}"),
+		Fields: []types.Field{{Name: "Opened by", Value: "{user}"}},
+	}
+
+	expanded := Values{UserId: 1, TicketId: 42}.ExpandEmbed(e)
+
+	assert.Equal(t, "Ticket 42", *expanded.Title)
+	assert.Equal(t, "<@1>", expanded.Fields[0].Value)
+	assert.Equal(t, "Ticket {ticket_id}", *e.Title)
+	assert.Equal(t, "{user}", e.Fields[0].Value)
+}
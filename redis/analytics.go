@@ -0,0 +1,34 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const AnalyticsCacheTtl = 10 * time.Minute
+
+func analyticsKey(guildId uint64, from, to time.Time) string {
+	return fmt.Sprintf("tickets:analytics:%d:%d:%d", guildId, from.Unix(), to.Unix())
+}
+
+// GetCachedAnalytics returns the encoded analytics for the guild and date range, if they have been computed recently
+func (c *RedisClient) GetCachedAnalytics(ctx context.Context, guildId uint64, from, to time.Time) ([]byte, bool, error) {
+	res, err := c.Get(ctx, analyticsKey(guildId, from, to)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+
+		return nil, false, err
+	}
+
+	return res, true, nil
+}
+
+func (c *RedisClient) SetCachedAnalytics(ctx context.Context, guildId uint64, from, to time.Time, data []byte) error {
+	return c.Set(ctx, analyticsKey(guildId, from, to), data, AnalyticsCacheTtl).Err()
+}
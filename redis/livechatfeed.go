@@ -0,0 +1,58 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// liveFeedGuildsKey is a sorted set of the guilds with a live feed client connected to any replica, scored by when
+// each entry expires. Changes made by the worker are only looked for in these guilds and guilds with a webhook.
+const liveFeedGuildsKey = "tickets:livechat:feedguilds"
+
+// MarkLiveFeedGuilds records that the guilds have a live feed client connected, for the next ttl
+func (c *RedisClient) MarkLiveFeedGuilds(ctx context.Context, guildIds []uint64, ttl time.Duration) error {
+	if len(guildIds) == 0 {
+		return nil
+	}
+
+	expiry := float64(time.Now().Add(ttl).Unix())
+
+	members := make([]*redis.Z, len(guildIds))
+	for i, guildId := range guildIds {
+		members[i] = &redis.Z{
+			Score:  expiry,
+			Member: strconv.FormatUint(guildId, 10),
+		}
+	}
+
+	return c.ZAdd(ctx, liveFeedGuildsKey, members...).Err()
+}
+
+// GetLiveFeedGuilds returns the guilds that have had a live feed client connected within their ttl, removing any that
+// have expired
+func (c *RedisClient) GetLiveFeedGuilds(ctx context.Context) ([]uint64, error) {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	if err := c.ZRemRangeByScore(ctx, liveFeedGuildsKey, "-inf", "("+now).Err(); err != nil {
+		return nil, err
+	}
+
+	members, err := c.ZRange(ctx, liveFeedGuildsKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	guildIds := make([]uint64, 0, len(members))
+	for _, member := range members {
+		guildId, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		guildIds = append(guildIds, guildId)
+	}
+
+	return guildIds, nil
+}
@@ -0,0 +1,64 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/apex/log"
+)
+
+// LiveChatPresenceChannel carries live-chat presence updates between API replicas, so that staff viewing the same
+// ticket can see each other regardless of which replica they are connected to.
+const LiveChatPresenceChannel = "tickets:livechat:presence"
+
+type LiveChatPresenceState string
+
+const (
+	LiveChatPresenceViewing LiveChatPresenceState = "viewing"
+	LiveChatPresenceTyping  LiveChatPresenceState = "typing"
+	LiveChatPresenceLeft    LiveChatPresenceState = "left"
+)
+
+type LiveChatPresence struct {
+	GuildId  uint64                `json:"guild_id"`
+	TicketId int                   `json:"ticket_id"`
+	UserId   uint64                `json:"user_id"`
+	State    LiveChatPresenceState `json:"state"`
+	// Sync is set when a user starts viewing a ticket, and asks each replica to republish its existing viewers
+	Sync bool `json:"sync,omitempty"`
+}
+
+func (c *RedisClient) PublishLiveChatPresence(ctx context.Context, presence LiveChatPresence) error {
+	encoded, err := json.Marshal(presence)
+	if err != nil {
+		return err
+	}
+
+	return c.Publish(ctx, LiveChatPresenceChannel, string(encoded)).Err()
+}
+
+// ListenLiveChatPresence sends presence updates received on LiveChatPresenceChannel to ch until ctx is cancelled
+func (c *RedisClient) ListenLiveChatPresence(ctx context.Context, ch chan<- LiveChatPresence) {
+	pubsub := c.Subscribe(ctx, LiveChatPresenceChannel)
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			var presence LiveChatPresence
+			if err := json.Unmarshal([]byte(msg.Payload), &presence); err != nil {
+				log.Error(err.Error())
+				continue
+			}
+
+			ch <- presence
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// LiveChatReplaySize is the number of messages kept per ticket for clients resuming a live-chat session
+	LiveChatReplaySize = 200
+	liveChatReplayTtl  = time.Hour
+	// The sequence outlives the buffer, so that numbers are not reused for a ticket that becomes active again
+	liveChatSequenceTtl = 7 * 24 * time.Hour
+)
+
+// sequenceScript assigns the next sequence number to a message and adds it to the replay buffer. Every replica
+// receives each relayed message, so the number assigned is stored against the message ID, and returned as-is when
+// other replicas sequence the same message.
+var sequenceScript = redis.NewScript(`
+local existing = redis.call("GET", KEYS[2])
+if existing then
+	return tonumber(existing)
+end
+
+local seq = redis.call("INCR", KEYS[1])
+redis.call("EXPIRE", KEYS[1], ARGV[4])
+redis.call("SET", KEYS[2], seq, "EX", ARGV[3])
+
+redis.call("ZADD", KEYS[3], seq, seq .. ":" .. ARGV[1])
+redis.call("ZREMRANGEBYRANK", KEYS[3], 0, -(tonumber(ARGV[2]) + 1))
+redis.call("EXPIRE", KEYS[3], ARGV[3])
+
+return seq
+`)
+
+type (
+	SequencedLiveChatMessage struct {
+		Seq     uint64
+		Message json.RawMessage
+	}
+
+	LiveChatReplay struct {
+		Messages []SequencedLiveChatMessage
+		// LatestSeq is the sequence number of the most recent message relayed in the ticket
+		LatestSeq uint64
+		// Complete is false if some of the messages after the requested sequence number are no longer buffered
+		Complete bool
+	}
+)
+
+func liveChatKey(guildId uint64, ticketId int, suffix string) string {
+	return fmt.Sprintf("tickets:livechat:%d:%d:%s", guildId, ticketId, suffix)
+}
+
+// SequenceLiveChatMessage returns the sequence number of the message within the ticket, storing it in the replay
+// buffer if it has not been seen before
+func (c *RedisClient) SequenceLiveChatMessage(ctx context.Context, guildId uint64, ticketId int, messageId uint64, message json.RawMessage) (uint64, error) {
+	keys := []string{
+		liveChatKey(guildId, ticketId, "seq"),
+		liveChatKey(guildId, ticketId, fmt.Sprintf("message:%d", messageId)),
+		liveChatKey(guildId, ticketId, "replay"),
+	}
+
+	seq, err := sequenceScript.Run(ctx, c, keys, string(message), LiveChatReplaySize, liveChatReplayTtl.Seconds(), liveChatSequenceTtl.Seconds()).Uint64()
+	if err != nil {
+		return 0, err
+	}
+
+	return seq, nil
+}
+
+// GetLiveChatReplay returns the buffered messages in the ticket with a sequence number greater than afterSeq
+func (c *RedisClient) GetLiveChatReplay(ctx context.Context, guildId uint64, ticketId int, afterSeq uint64) (LiveChatReplay, error) {
+	var latest *redis.StringCmd
+	var oldest, messages *redis.ZSliceCmd
+	_, err := c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		latest = pipe.Get(ctx, liveChatKey(guildId, ticketId, "seq"))
+		oldest = pipe.ZRangeWithScores(ctx, liveChatKey(guildId, ticketId, "replay"), 0, 0)
+		messages = pipe.ZRangeByScoreWithScores(ctx, liveChatKey(guildId, ticketId, "replay"), &redis.ZRangeBy{
+			Min: fmt.Sprintf("(%d", afterSeq),
+			Max: "+inf",
+		})
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return LiveChatReplay{}, err
+	}
+
+	var replay LiveChatReplay
+	if latestSeq, err := latest.Uint64(); err == nil {
+		replay.LatestSeq = latestSeq
+	} else if !errors.Is(err, redis.Nil) {
+		return LiveChatReplay{}, err
+	}
+
+	for _, member := range messages.Val() {
+		encoded, ok := member.Member.(string)
+		if !ok {
+			continue
+		}
+
+		seqStr, message, ok := strings.Cut(encoded, ":")
+		if !ok {
+			continue
+		}
+
+		seq, err := strconv.ParseUint(seqStr, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		replay.Messages = append(replay.Messages, SequencedLiveChatMessage{
+			Seq:     seq,
+			Message: json.RawMessage(message),
+		})
+	}
+
+	switch {
+	case afterSeq == replay.LatestSeq:
+		replay.Complete = true
+	case afterSeq > replay.LatestSeq: // The sequence has expired and restarted since the client last saw a message
+		replay.Complete = false
+	default:
+		oldestSeq := uint64(0)
+		if members := oldest.Val(); len(members) > 0 {
+			oldestSeq = uint64(members[0].Score)
+		}
+
+		replay.Complete = oldestSeq != 0 && oldestSeq <= afterSeq+1
+	}
+
+	return replay, nil
+}
@@ -0,0 +1,33 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// extendLockScript extends the lock only if it is still held by the owner, so that an expired lock taken over by
+// another replica is not extended
+var extendLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// HoldLock acquires the lock for owner, or extends it if owner already holds it, returning whether owner holds the
+// lock. Used to elect a single replica to run background work: the lock is released by letting it expire, so ttl
+// should be a few times longer than the interval at which it is renewed.
+func (c *RedisClient) HoldLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	acquired, err := c.SetNX(ctx, key, owner, ttl).Result()
+	if err != nil || acquired {
+		return acquired, err
+	}
+
+	extended, err := extendLockScript.Run(ctx, c.Client, []string{key}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return extended == 1, nil
+}
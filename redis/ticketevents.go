@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/google/uuid"
+)
+
+// TicketEventChannel carries ticket lifecycle events. Events for actions that the dashboard performs itself are
+// published when they are made, and events for changes made by the worker are published by webhooks.RunEventSource.
+const TicketEventChannel = "tickets:events"
+
+type TicketEventType string
+
+const (
+	TicketEventOpened              TicketEventType = "ticket.opened"
+	TicketEventClaimed             TicketEventType = "ticket.claimed"
+	TicketEventUnclaimed           TicketEventType = "ticket.unclaimed"
+	TicketEventClosed              TicketEventType = "ticket.closed"
+	TicketEventRated               TicketEventType = "ticket.rated"
+	TicketEventTranscriptAvailable TicketEventType = "transcript.available"
+	TicketEventSlaBreached         TicketEventType = "ticket.sla_breached"
+)
+
+var TicketEventTypes = []TicketEventType{
+	TicketEventOpened,
+	TicketEventClaimed,
+	TicketEventUnclaimed,
+	TicketEventClosed,
+	TicketEventRated,
+	TicketEventTranscriptAvailable,
+	TicketEventSlaBreached,
+}
+
+type TicketEvent struct {
+	// Id is unique per event, and is used to deduplicate deliveries when multiple replicas receive the same event
+	Id        string          `json:"id"`
+	Type      TicketEventType `json:"type"`
+	GuildId   uint64          `json:"guild_id"`
+	TicketId  int             `json:"ticket_id"`
+	UserId    uint64          `json:"user_id"`
+	Timestamp time.Time       `json:"timestamp"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+func (c *RedisClient) PublishTicketEvent(ctx context.Context, event TicketEvent) error {
+	if event.Id == "" {
+		event.Id = uuid.NewString()
+	}
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return c.Publish(ctx, TicketEventChannel, string(encoded)).Err()
+}
+
+// ListenTicketEvents sends events received on TicketEventChannel to ch until ctx is cancelled
+func (c *RedisClient) ListenTicketEvents(ctx context.Context, ch chan<- TicketEvent) {
+	pubsub := c.Subscribe(ctx, TicketEventChannel)
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			var event TicketEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				log.Error(err.Error())
+				continue
+			}
+
+			ch <- event
+		}
+	}
+}
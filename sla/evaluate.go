@@ -0,0 +1,69 @@
+package sla
+
+import (
+	"time"
+
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+)
+
+// Evaluate returns the status of a target that must be met within target of start, and the time it is due. completedAt
+// is nil if the target has not been met yet.
+func Evaluate(start time.Time, target time.Duration, atRiskPercent int, completedAt *time.Time, now time.Time) (dbclient.SlaStatus, time.Time) {
+	dueAt := start.Add(target)
+
+	if completedAt != nil {
+		if completedAt.After(dueAt) {
+			return dbclient.SlaStatusBreached, dueAt
+		}
+
+		return dbclient.SlaStatusMet, dueAt
+	}
+
+	if now.After(dueAt) {
+		return dbclient.SlaStatusBreached, dueAt
+	}
+
+	if now.Sub(start) >= target*time.Duration(atRiskPercent)/100 {
+		return dbclient.SlaStatusAtRisk, dueAt
+	}
+
+	return dbclient.SlaStatusOk, dueAt
+}
+
+// evaluateTicket returns the SLA state of the ticket, and the kinds of target that have been breached
+func evaluateTicket(ticket dbclient.SlaTicket, now time.Time) (dbclient.TicketSla, []dbclient.SlaKind) {
+	state := dbclient.TicketSla{
+		GuildId:     ticket.GuildId,
+		TicketId:    ticket.TicketId,
+		EvaluatedAt: now,
+	}
+
+	var breached []dbclient.SlaKind
+
+	if ticket.Policy.FirstResponseSeconds != nil {
+		target := time.Duration(*ticket.Policy.FirstResponseSeconds) * time.Second
+		status, dueAt := Evaluate(ticket.OpenTime, target, ticket.Policy.AtRiskPercent, ticket.FirstResponseAt, now)
+
+		state.FirstResponseStatus = &status
+		state.FirstResponseDueAt = &dueAt
+
+		if status == dbclient.SlaStatusBreached {
+			breached = append(breached, dbclient.SlaKindFirstResponse)
+		}
+	}
+
+	// Only open tickets are evaluated, so the resolution target can never have been met
+	if ticket.Policy.ResolutionSeconds != nil {
+		target := time.Duration(*ticket.Policy.ResolutionSeconds) * time.Second
+		status, dueAt := Evaluate(ticket.OpenTime, target, ticket.Policy.AtRiskPercent, nil, now)
+
+		state.ResolutionStatus = &status
+		state.ResolutionDueAt = &dueAt
+
+		if status == dbclient.SlaStatusBreached {
+			breached = append(breached, dbclient.SlaKindResolution)
+		}
+	}
+
+	return state, breached
+}
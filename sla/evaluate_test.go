@@ -0,0 +1,54 @@
+package sla
+
+import (
+	"testing"
+	"time"
+
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	target := time.Hour
+
+	status, dueAt := Evaluate(start, target, 75, nil, start.Add(30*time.Minute))
+	assert.Equal(t, dbclient.SlaStatusOk, status)
+	assert.Equal(t, start.Add(target), dueAt)
+
+	status, _ = Evaluate(start, target, 75, nil, start.Add(45*time.Minute))
+	assert.Equal(t, dbclient.SlaStatusAtRisk, status)
+
+	status, _ = Evaluate(start, target, 75, nil, start.Add(61*time.Minute))
+	assert.Equal(t, dbclient.SlaStatusBreached, status)
+
+	status, _ = Evaluate(start, target, 75, utils.Ptr(start.Add(50*time.Minute)), start.Add(2*time.Hour))
+	assert.Equal(t, dbclient.SlaStatusMet, status)
+
+	status, _ = Evaluate(start, target, 75, utils.Ptr(start.Add(70*time.Minute)), start.Add(2*time.Hour))
+	assert.Equal(t, dbclient.SlaStatusBreached, status, "responding late must still count as a breach")
+}
+
+func TestEvaluateTicket(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ticket := dbclient.SlaTicket{
+		GuildId:  1,
+		TicketId: 2,
+		OpenTime: start,
+		Policy: dbclient.SlaPolicy{
+			FirstResponseSeconds: utils.Ptr(600),
+			AtRiskPercent:        80,
+		},
+	}
+
+	state, breached := evaluateTicket(ticket, start.Add(20*time.Minute))
+	assert.Equal(t, dbclient.SlaStatusBreached, *state.FirstResponseStatus)
+	assert.Nil(t, state.ResolutionStatus, "untracked targets must not be evaluated")
+	assert.Equal(t, []dbclient.SlaKind{dbclient.SlaKindFirstResponse}, breached)
+
+	ticket.FirstResponseAt = utils.Ptr(start.Add(5 * time.Minute))
+	state, breached = evaluateTicket(ticket, start.Add(20*time.Minute))
+	assert.Equal(t, dbclient.SlaStatusMet, *state.FirstResponseStatus)
+	assert.Empty(t, breached)
+}
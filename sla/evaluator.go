@@ -0,0 +1,120 @@
+package sla
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/redis"
+	"go.uber.org/zap"
+)
+
+const (
+	evaluationInterval = time.Minute
+	notifyTimeout      = 10 * time.Second
+	evaluatorLockKey   = "tickets:sla:evaluator"
+	evaluatorLockTtl   = 3 * evaluationInterval
+
+	// StateTtl is how long the result of an evaluation is shown for. Results are refreshed every evaluationInterval
+	// while the ticket is open and covered by a policy, so older results belong to tickets that are not.
+	StateTtl = 5 * evaluationInterval
+)
+
+type breach struct {
+	ticket dbclient.SlaTicket
+	kind   dbclient.SlaKind
+	dueAt  time.Time
+}
+
+// RunEvaluator evaluates open tickets against their panel's SLA policy until ctx is cancelled. Every replica runs the
+// evaluator, but one is elected to evaluate at a time. Notifications are also claimed in the database, so each breach
+// is only notified once if the election changes hands mid-evaluation.
+func RunEvaluator(ctx context.Context, client *redis.RedisClient, logger *zap.Logger) {
+	ticker := time.NewTicker(evaluationInterval)
+	defer ticker.Stop()
+
+	owner := uuid.NewString()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		elected, err := client.HoldLock(ctx, evaluatorLockKey, owner, evaluatorLockTtl)
+		if err != nil {
+			logger.Error("Failed to acquire the SLA evaluator lock", zap.Error(err))
+			continue
+		}
+
+		if !elected {
+			continue
+		}
+
+		breaches, err := evaluate(ctx, time.Now())
+		if err != nil {
+			logger.Error("Failed to evaluate SLA policies", zap.Error(err))
+			continue
+		}
+
+		for _, breach := range breaches {
+			claimed, err := dbclient.Dashboard.TicketSla.ClaimNotification(ctx, breach.ticket.GuildId, breach.ticket.TicketId, breach.kind)
+			if err != nil {
+				logger.Error("Failed to claim SLA breach notification", zap.Error(err), zap.Uint64("guild_id", breach.ticket.GuildId), zap.Int("ticket_id", breach.ticket.TicketId))
+				continue
+			}
+
+			if !claimed {
+				continue
+			}
+
+			if err := notify(ctx, client, breach); err != nil {
+				logger.Warn("Failed to send SLA breach notification", zap.Error(err), zap.Uint64("guild_id", breach.ticket.GuildId), zap.Int("ticket_id", breach.ticket.TicketId))
+
+				// Try again on the next evaluation
+				if err := dbclient.Dashboard.TicketSla.ReleaseNotification(ctx, breach.ticket.GuildId, breach.ticket.TicketId, breach.kind); err != nil {
+					logger.Error("Failed to release SLA breach notification", zap.Error(err), zap.Uint64("guild_id", breach.ticket.GuildId), zap.Int("ticket_id", breach.ticket.TicketId))
+				}
+			}
+		}
+	}
+}
+
+// evaluate stores the SLA state of every open ticket covered by a policy, returning the breaches found
+func evaluate(ctx context.Context, now time.Time) ([]breach, error) {
+	tickets, err := dbclient.Dashboard.TicketSla.GetOpenTickets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]dbclient.TicketSla, len(tickets))
+	var breaches []breach
+	for i, ticket := range tickets {
+		state, breached := evaluateTicket(ticket, now)
+		states[i] = state
+
+		for _, kind := range breached {
+			dueAt := state.ResolutionDueAt
+			if kind == dbclient.SlaKindFirstResponse {
+				dueAt = state.FirstResponseDueAt
+			}
+
+			breaches = append(breaches, breach{
+				ticket: ticket,
+				kind:   kind,
+				dueAt:  *dueAt,
+			})
+		}
+	}
+
+	if err := dbclient.Dashboard.TicketSla.SetMulti(ctx, states); err != nil {
+		return nil, err
+	}
+
+	if err := dbclient.Dashboard.TicketSla.DeleteStale(ctx, now.Add(-StateTtl)); err != nil {
+		return nil, err
+	}
+
+	return breaches, nil
+}
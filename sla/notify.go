@@ -0,0 +1,88 @@
+package sla
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/botcontext"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/redis"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/rxdn/gdl/objects/channel/embed"
+	"github.com/rxdn/gdl/rest"
+)
+
+const breachColour = 0xed4245
+
+type breachEventData struct {
+	Kind  dbclient.SlaKind `json:"kind"`
+	DueAt time.Time        `json:"due_at"`
+}
+
+// notify publishes a ticket.sla_breached event, which is delivered to any webhooks subscribed to it, and posts to the
+// policy's notification channel if it has one. The event ID is derived from the breach, so that if posting fails and the
+// notification is retried, webhooks do not receive the event twice.
+func notify(ctx context.Context, client *redis.RedisClient, breach breach) error {
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+
+	data, err := json.Marshal(breachEventData{
+		Kind:  breach.kind,
+		DueAt: breach.dueAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := client.PublishTicketEvent(ctx, redis.TicketEvent{
+		Id:       breachEventId(breach),
+		Type:     redis.TicketEventSlaBreached,
+		GuildId:  breach.ticket.GuildId,
+		TicketId: breach.ticket.TicketId,
+		Data:     data,
+	}); err != nil {
+		return err
+	}
+
+	if breach.ticket.Policy.NotifyChannelId == nil {
+		return nil
+	}
+
+	botContext, err := botcontext.ContextForGuild(breach.ticket.GuildId)
+	if err != nil {
+		return err
+	}
+
+	_, err = rest.CreateMessage(ctx, botContext.Token, botContext.RateLimiter, *breach.ticket.Policy.NotifyChannelId, rest.CreateMessageData{
+		Embeds: utils.Slice(breachEmbed(breach)),
+	})
+
+	return err
+}
+
+func breachEventId(breach breach) string {
+	name := fmt.Sprintf("sla:%d:%d:%s:%d", breach.ticket.GuildId, breach.ticket.TicketId, breach.kind, breach.dueAt.Unix())
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(name)).String()
+}
+
+func breachEmbed(breach breach) *embed.Embed {
+	var target string
+	if breach.kind == dbclient.SlaKindFirstResponse {
+		target = "first response"
+	} else {
+		target = "resolution"
+	}
+
+	ticket := fmt.Sprintf("#%d", breach.ticket.TicketId)
+	if breach.ticket.ChannelId != nil {
+		ticket = fmt.Sprintf("#%d (<#%d>)", breach.ticket.TicketId, *breach.ticket.ChannelId)
+	}
+
+	return embed.NewEmbed().
+		SetTitle("SLA Breached").
+		SetColor(breachColour).
+		SetDescription(fmt.Sprintf("Ticket %s missed its %s target, which was due <t:%d:R>.", ticket, target, breach.dueAt.Unix()))
+}
@@ -0,0 +1,28 @@
+package sla
+
+import (
+	"testing"
+	"time"
+
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreachEventId(t *testing.T) {
+	dueAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	first := breach{
+		ticket: dbclient.SlaTicket{GuildId: 1, TicketId: 2},
+		kind:   dbclient.SlaKindFirstResponse,
+		dueAt:  dueAt,
+	}
+
+	assert.Equal(t, breachEventId(first), breachEventId(first), "retried notifications must reuse the event ID")
+
+	resolution := first
+	resolution.kind = dbclient.SlaKindResolution
+	assert.NotEqual(t, breachEventId(first), breachEventId(resolution))
+
+	otherTicket := first
+	otherTicket.ticket.TicketId = 3
+	assert.NotEqual(t, breachEventId(first), breachEventId(otherTicket))
+}
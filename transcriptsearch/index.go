@@ -0,0 +1,295 @@
+package transcriptsearch
+
+import (
+	"container/list"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	v2 "github.com/jadevelopmentgrp/Tickets-Archiver/pkg/model/v2"
+	"go.uber.org/zap"
+)
+
+// Index is a local full-text index of transcript message content. Transcripts are added as they are fetched from the
+// archiver, and each one is persisted as a JSON document under dir/<guild id>/<ticket id>.json so that the index
+// survives restarts. The inverted index for a guild is built in memory the first time that guild is searched, and at
+// most maxGuilds are kept in memory, evicting the least recently searched.
+type Index struct {
+	dir       string
+	maxGuilds int
+	logger    *zap.Logger
+
+	mu     sync.RWMutex
+	guilds map[uint64]*list.Element
+	// recent holds the loaded guilds, most recently searched first
+	recent *list.List
+}
+
+type guildIndex struct {
+	guildId   uint64
+	documents map[int]document
+	postings  map[string]map[int]struct{}
+}
+
+type document struct {
+	TicketId int              `json:"ticket_id"`
+	Messages []indexedMessage `json:"messages"`
+}
+
+type indexedMessage struct {
+	Id       uint64 `json:"id,string"`
+	AuthorId uint64 `json:"author_id,string"`
+	Content  string `json:"content"`
+}
+
+var Instance *Index
+
+func NewIndex(dir string, maxGuilds int, logger *zap.Logger) (*Index, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	// The guild being searched is always kept
+	if maxGuilds < 1 {
+		maxGuilds = 1
+	}
+
+	return &Index{
+		dir:       dir,
+		maxGuilds: maxGuilds,
+		logger:    logger,
+		guilds:    make(map[uint64]*list.Element),
+		recent:    list.New(),
+	}, nil
+}
+
+// Add indexes the messages of a transcript. Transcripts are immutable once archived, so a ticket that has already
+// been indexed is skipped.
+func (i *Index) Add(guildId uint64, ticketId int, transcript v2.Transcript) error {
+	path := i.documentPath(guildId, ticketId)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	doc := document{
+		TicketId: ticketId,
+		Messages: make([]indexedMessage, 0, len(transcript.Messages)),
+	}
+
+	for _, msg := range transcript.Messages {
+		if strings.TrimSpace(msg.Content) == "" {
+			continue
+		}
+
+		doc.Messages = append(doc.Messages, indexedMessage{
+			Id:       msg.Id,
+			AuthorId: msg.AuthorId,
+			Content:  msg.Content,
+		})
+	}
+
+	if err := writeDocument(i.guildDir(guildId), path, doc); err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	// If the guild has not been loaded yet, the document will be picked up from disk when it is
+	if element, ok := i.guilds[guildId]; ok {
+		element.Value.(*guildIndex).add(doc)
+	}
+
+	return nil
+}
+
+// AddInBackground indexes the transcript without blocking the caller, logging any failure. Handlers use this after
+// fetching a transcript, as the response should not wait on, or fail because of, the index.
+func (i *Index) AddInBackground(guildId uint64, ticketId int, transcript v2.Transcript) {
+	go func() {
+		if err := i.Add(guildId, ticketId, transcript); err != nil {
+			i.logger.Warn(
+				"Failed to index transcript",
+				zap.Uint64("guild_id", guildId),
+				zap.Int("ticket_id", ticketId),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// Search returns all tickets in the guild whose transcripts contain every term in the query, newest ticket first.
+func (i *Index) Search(guildId uint64, query string, snippetsPerTicket int) ([]Result, error) {
+	terms := uniqueTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	guild, err := i.loadGuild(guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	ticketIds := guild.match(terms)
+
+	results := make([]Result, 0, len(ticketIds))
+	for _, ticketId := range ticketIds {
+		results = append(results, Result{
+			TicketId: ticketId,
+			Snippets: buildSnippets(guild.documents[ticketId], terms, snippetsPerTicket),
+		})
+	}
+
+	return results, nil
+}
+
+// loadGuild returns the index of the guild, reading it from disk if it is not loaded, and marks it as the most recently
+// searched. The write lock is held throughout, as the recency list is updated on every call.
+func (i *Index) loadGuild(guildId uint64) (*guildIndex, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if element, ok := i.guilds[guildId]; ok {
+		i.recent.MoveToFront(element)
+		return element.Value.(*guildIndex), nil
+	}
+
+	guild := &guildIndex{
+		guildId:   guildId,
+		documents: make(map[int]document),
+		postings:  make(map[string]map[int]struct{}),
+	}
+
+	entries, err := os.ReadDir(i.guildDir(guildId))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		doc, err := readDocument(filepath.Join(i.guildDir(guildId), entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		guild.add(doc)
+	}
+
+	i.guilds[guildId] = i.recent.PushFront(guild)
+
+	// Searches already holding an evicted guild keep using it, it is only removed from the cache
+	for i.recent.Len() > i.maxGuilds {
+		evicted := i.recent.Remove(i.recent.Back()).(*guildIndex)
+		delete(i.guilds, evicted.guildId)
+	}
+
+	return guild, nil
+}
+
+func (i *Index) guildDir(guildId uint64) string {
+	return filepath.Join(i.dir, strconv.FormatUint(guildId, 10))
+}
+
+func (i *Index) documentPath(guildId uint64, ticketId int) string {
+	return filepath.Join(i.guildDir(guildId), fmt.Sprintf("%d.json", ticketId))
+}
+
+func (g *guildIndex) add(doc document) {
+	g.documents[doc.TicketId] = doc
+
+	for _, msg := range doc.Messages {
+		for _, token := range tokenize(msg.Content) {
+			tickets, ok := g.postings[token.term]
+			if !ok {
+				tickets = make(map[int]struct{})
+				g.postings[token.term] = tickets
+			}
+
+			tickets[doc.TicketId] = struct{}{}
+		}
+	}
+}
+
+func (g *guildIndex) match(terms []string) []int {
+	// Start from the rarest term to keep the intersection small
+	sort.Slice(terms, func(i, j int) bool {
+		return len(g.postings[terms[i]]) < len(g.postings[terms[j]])
+	})
+
+	var ticketIds []int
+	for ticketId := range g.postings[terms[0]] {
+		matchesAll := true
+		for _, term := range terms[1:] {
+			if _, ok := g.postings[term][ticketId]; !ok {
+				matchesAll = false
+				break
+			}
+		}
+
+		if matchesAll {
+			ticketIds = append(ticketIds, ticketId)
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(ticketIds)))
+	return ticketIds
+}
+
+func writeDocument(dir, path string, doc document) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a partially written document is never read. Each writer has its own
+	// file, as the same ticket may be indexed by several requests at once.
+	tmp, err := os.CreateTemp(dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name()) // Fails once renamed
+
+	if _, err := tmp.Write(encoded); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func readDocument(path string) (document, error) {
+	encoded, err := os.ReadFile(path)
+	if err != nil {
+		return document{}, err
+	}
+
+	var doc document
+	if err := json.Unmarshal(encoded, &doc); err != nil {
+		return document{}, err
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,131 @@
+package transcriptsearch
+
+import (
+	"sync"
+	"testing"
+
+	v2 "github.com/jadevelopmentgrp/Tickets-Archiver/pkg/model/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func newTranscript(contents ...string) v2.Transcript {
+	messages := make([]v2.Message, len(contents))
+	for i, content := range contents {
+		messages[i] = v2.Message{
+			Id:       uint64(i + 1),
+			AuthorId: 1,
+			Content:  content,
+		}
+	}
+
+	return v2.Transcript{Messages: messages}
+}
+
+func TestSearchMatchesAllTerms(t *testing.T) {
+	index, err := NewIndex(t.TempDir(), 10, zap.NewNop())
+	assert.NoError(t, err)
+
+	assert.NoError(t, index.Add(1, 10, newTranscript("Hello", "I get Error 500 when logging in")))
+	assert.NoError(t, index.Add(1, 11, newTranscript("error when saving")))
+	assert.NoError(t, index.Add(2, 12, newTranscript("error 500")))
+
+	results, err := index.Search(1, "error 500", 3)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, 10, results[0].TicketId)
+
+	snippet := results[0].Snippets[0]
+	assert.Equal(t, uint64(2), snippet.MessageId)
+	assert.Len(t, snippet.Highlights, 2)
+	assert.Equal(t, "Error", snippet.Text[snippet.Highlights[0].Start:snippet.Highlights[0].End])
+	assert.Equal(t, "500", snippet.Text[snippet.Highlights[1].Start:snippet.Highlights[1].End])
+}
+
+func TestSearchNewestFirst(t *testing.T) {
+	index, err := NewIndex(t.TempDir(), 10, zap.NewNop())
+	assert.NoError(t, err)
+
+	assert.NoError(t, index.Add(1, 3, newTranscript("refund please")))
+	assert.NoError(t, index.Add(1, 7, newTranscript("another refund")))
+
+	results, err := index.Search(1, "REFUND", 3)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, 7, results[0].TicketId)
+	assert.Equal(t, 3, results[1].TicketId)
+}
+
+func TestIndexPersisted(t *testing.T) {
+	dir := t.TempDir()
+
+	index, err := NewIndex(dir, 10, zap.NewNop())
+	assert.NoError(t, err)
+	assert.NoError(t, index.Add(1, 5, newTranscript("my payment failed")))
+
+	reloaded, err := NewIndex(dir, 10, zap.NewNop())
+	assert.NoError(t, err)
+
+	results, err := reloaded.Search(1, "payment", 3)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, 5, results[0].TicketId)
+}
+
+func TestLeastRecentGuildEvicted(t *testing.T) {
+	index, err := NewIndex(t.TempDir(), 2, zap.NewNop())
+	assert.NoError(t, err)
+
+	for guildId := uint64(1); guildId <= 3; guildId++ {
+		assert.NoError(t, index.Add(guildId, 1, newTranscript("refund")))
+
+		_, err := index.Search(guildId, "refund", 1)
+		assert.NoError(t, err)
+	}
+
+	assert.NotContains(t, index.guilds, uint64(1))
+	assert.Contains(t, index.guilds, uint64(2))
+	assert.Contains(t, index.guilds, uint64(3))
+
+	// Evicted guilds are read from disk again
+	results, err := index.Search(1, "refund", 1)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.NotContains(t, index.guilds, uint64(2))
+}
+
+func TestConcurrentAdd(t *testing.T) {
+	index, err := NewIndex(t.TempDir(), 10, zap.NewNop())
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, index.Add(1, 5, newTranscript("my payment failed")))
+		}()
+	}
+
+	wg.Wait()
+
+	results, err := index.Search(1, "payment", 3)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+}
+
+func TestSnippetTruncated(t *testing.T) {
+	content := ""
+	for i := 0; i < 50; i++ {
+		content += "filler "
+	}
+	content += "needle"
+	for i := 0; i < 50; i++ {
+		content += " filler"
+	}
+
+	snippet := cutSnippet(indexedMessage{Content: content}, []token{{term: "needle", start: 350, end: 356}})
+	assert.Less(t, len(snippet.Text), len(content))
+	assert.Len(t, snippet.Highlights, 1)
+	assert.Equal(t, "needle", snippet.Text[snippet.Highlights[0].Start:snippet.Highlights[0].End])
+}
@@ -0,0 +1,197 @@
+package transcriptsearch
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	minTermLength = 2
+	snippetRadius = 80
+)
+
+type (
+	Result struct {
+		TicketId int       `json:"ticket_id"`
+		Snippets []Snippet `json:"snippets"`
+	}
+
+	Snippet struct {
+		MessageId  uint64      `json:"message_id,string"`
+		AuthorId   uint64      `json:"author_id,string"`
+		Text       string      `json:"text"`
+		Highlights []Highlight `json:"highlights"`
+	}
+
+	// Highlight is a byte range within Snippet.Text that matched a search term
+	Highlight struct {
+		Start int `json:"start"`
+		End   int `json:"end"`
+	}
+)
+
+type token struct {
+	term       string
+	start, end int
+}
+
+// tokenize splits content into lowercase alphanumeric terms, recording the byte offsets of each term in the original
+// string.
+func tokenize(content string) []token {
+	var tokens []token
+
+	start := -1
+	for i, r := range content {
+		isWordChar := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWordChar && start == -1 {
+			start = i
+		} else if !isWordChar && start != -1 {
+			tokens = appendToken(tokens, content, start, i)
+			start = -1
+		}
+	}
+
+	if start != -1 {
+		tokens = appendToken(tokens, content, start, len(content))
+	}
+
+	return tokens
+}
+
+func appendToken(tokens []token, content string, start, end int) []token {
+	if utf8.RuneCountInString(content[start:end]) < minTermLength {
+		return tokens
+	}
+
+	return append(tokens, token{
+		term:  strings.ToLower(content[start:end]),
+		start: start,
+		end:   end,
+	})
+}
+
+func uniqueTerms(query string) []string {
+	seen := make(map[string]struct{})
+
+	var terms []string
+	for _, token := range tokenize(query) {
+		if _, ok := seen[token.term]; ok {
+			continue
+		}
+
+		seen[token.term] = struct{}{}
+		terms = append(terms, token.term)
+	}
+
+	return terms
+}
+
+// buildSnippets picks the messages containing the most query terms, and cuts a window of text around the first match
+// in each.
+func buildSnippets(doc document, terms []string, limit int) []Snippet {
+	termSet := make(map[string]struct{}, len(terms))
+	for _, term := range terms {
+		termSet[term] = struct{}{}
+	}
+
+	type candidate struct {
+		message  indexedMessage
+		matches  []token
+		distinct int
+	}
+
+	var candidates []candidate
+	for _, msg := range doc.Messages {
+		var matches []token
+		distinct := make(map[string]struct{})
+		for _, token := range tokenize(msg.Content) {
+			if _, ok := termSet[token.term]; ok {
+				matches = append(matches, token)
+				distinct[token.term] = struct{}{}
+			}
+		}
+
+		if len(matches) > 0 {
+			candidates = append(candidates, candidate{
+				message:  msg,
+				matches:  matches,
+				distinct: len(distinct),
+			})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distinct > candidates[j].distinct
+	})
+
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	snippets := make([]Snippet, len(candidates))
+	for i, candidate := range candidates {
+		snippets[i] = cutSnippet(candidate.message, candidate.matches)
+	}
+
+	return snippets
+}
+
+func cutSnippet(msg indexedMessage, matches []token) Snippet {
+	content := msg.Content
+
+	start := clampToRune(content, matches[0].start-snippetRadius)
+	end := clampToRune(content, matches[0].end+snippetRadius)
+
+	text := content[start:end]
+	if start > 0 {
+		text = "…" + text
+	}
+
+	if end < len(content) {
+		text += "…"
+	}
+
+	// Offsets shift by the length of the leading ellipsis, if one was added
+	offset := -start
+	if start > 0 {
+		offset += len("…")
+	}
+
+	var highlights []Highlight
+	for _, match := range matches {
+		if match.start < start || match.end > end {
+			continue
+		}
+
+		highlights = append(highlights, Highlight{
+			Start: match.start + offset,
+			End:   match.end + offset,
+		})
+	}
+
+	return Snippet{
+		MessageId:  msg.Id,
+		AuthorId:   msg.AuthorId,
+		Text:       text,
+		Highlights: highlights,
+	}
+}
+
+// clampToRune bounds i to the string, and moves it back to the start of the rune it falls within
+func clampToRune(s string, i int) int {
+	if i <= 0 {
+		return 0
+	}
+
+	if i >= len(s) {
+		return len(s)
+	}
+
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+
+	return i
+}
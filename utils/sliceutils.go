@@ -43,6 +43,20 @@ func ToSet[T comparable](slice []T) *collections.Set[T] {
 	return set
 }
 
+// Unique returns the distinct elements of the slice, in the order they first appear
+func Unique[T comparable](slice []T) []T {
+	seen := make(map[T]struct{}, len(slice))
+	result := make([]T, 0, len(slice))
+	for _, elem := range slice {
+		if _, ok := seen[elem]; !ok {
+			seen[elem] = struct{}{}
+			result = append(result, elem)
+		}
+	}
+
+	return result
+}
+
 func RoleToId(role guild.Role) uint64 {
 	return role.Id
 }
@@ -0,0 +1,29 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+)
+
+type EmbedTemplate struct {
+	Id        int          `json:"id"`
+	Name      string       `json:"name"`
+	Embed     *CustomEmbed `json:"embed"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+func NewEmbedTemplate(template dbclient.EmbedTemplate) (EmbedTemplate, error) {
+	var embed CustomEmbed
+	if err := json.Unmarshal(template.Data, &embed); err != nil {
+		return EmbedTemplate{}, err
+	}
+
+	return EmbedTemplate{
+		Id:        template.Id,
+		Name:      template.Name,
+		Embed:     &embed,
+		UpdatedAt: template.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,29 @@
+package types
+
+import (
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+)
+
+type TicketLabel struct {
+	Id     int    `json:"id"`
+	Name   string `json:"name"`
+	Colour Colour `json:"colour"`
+}
+
+func NewTicketLabel(label dbclient.TicketLabel) TicketLabel {
+	return TicketLabel{
+		Id:     label.Id,
+		Name:   label.Name,
+		Colour: Colour(label.Colour),
+	}
+}
+
+// NewTicketLabels wraps the labels, keyed by ID
+func NewTicketLabels(labels []dbclient.TicketLabel) map[int]TicketLabel {
+	wrapped := make(map[int]TicketLabel, len(labels))
+	for _, label := range labels {
+		wrapped[label.Id] = NewTicketLabel(label)
+	}
+
+	return wrapped
+}
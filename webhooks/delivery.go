@@ -0,0 +1,120 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	pollInterval    = 5 * time.Second
+	batchSize       = 25
+	claimLease      = 5 * time.Minute
+	maxAttempts     = 6
+	baseRetryDelay  = 30 * time.Second
+	maxRetryDelay   = time.Hour
+	maxErrorLength  = 255
+	deliveryLogTtl  = 30 * 24 * time.Hour
+	cleanupInterval = time.Hour
+)
+
+// RunDeliveryWorker sends pending webhook deliveries until ctx is cancelled. Deliveries are claimed with row locks, so
+// the worker can safely run on every replica.
+func RunDeliveryWorker(ctx context.Context, logger *zap.Logger) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	var lastCleanup time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if time.Since(lastCleanup) > cleanupInterval {
+			if err := dbclient.Dashboard.WebhookDeliveries.DeleteOlderThan(ctx, time.Now().Add(-deliveryLogTtl)); err != nil {
+				logger.Error("Failed to remove old webhook deliveries", zap.Error(err))
+			}
+
+			lastCleanup = time.Now()
+		}
+
+		deliveries, err := dbclient.Dashboard.WebhookDeliveries.ClaimDue(ctx, batchSize, claimLease)
+		if err != nil {
+			logger.Error("Failed to fetch due webhook deliveries", zap.Error(err))
+			continue
+		}
+
+		for _, delivery := range deliveries {
+			if err := attempt(ctx, delivery); err != nil {
+				logger.Error("Failed to store webhook delivery result", zap.Error(err), zap.Int64("delivery_id", delivery.Id))
+			}
+		}
+	}
+}
+
+// attempt sends a delivery and records the outcome. Only errors storing the outcome are returned.
+func attempt(ctx context.Context, delivery dbclient.ClaimedWebhookDelivery) error {
+	body, err := encodeBody(delivery.Payload)
+	if err != nil {
+		return dbclient.Dashboard.WebhookDeliveries.SetAttemptFailed(ctx, delivery.Id, nil, "Invalid payload", nil)
+	}
+
+	now := time.Now()
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		HeaderEvent:     delivery.EventType,
+		HeaderDelivery:  strconv.FormatInt(delivery.Id, 10),
+		HeaderTimestamp: strconv.FormatInt(now.Unix(), 10),
+		HeaderSignature: Sign(delivery.Secret, now, body),
+	}
+
+	res, statusCode, err := utils.SecureProxyClient.DoRequest(http.MethodPost, delivery.Url, headers, json.RawMessage(body))
+	if err == nil && statusCode >= 200 && statusCode <= 299 {
+		return dbclient.Dashboard.WebhookDeliveries.SetDelivered(ctx, delivery.Id, statusCode)
+	}
+
+	var errorMessage string
+	if err != nil {
+		errorMessage = err.Error()
+	} else {
+		errorMessage = fmt.Sprintf("Endpoint returned HTTP %d: %s", statusCode, res)
+	}
+
+	var responseStatus *int
+	if statusCode != 0 {
+		responseStatus = &statusCode
+	}
+
+	var nextAttemptAt *time.Time
+	if delivery.Attempts < maxAttempts {
+		nextAttemptAt = utils.Ptr(time.Now().Add(retryDelay(delivery.Attempts)))
+	}
+
+	return dbclient.Dashboard.WebhookDeliveries.SetAttemptFailed(ctx, delivery.Id, responseStatus, utils.StringMax(errorMessage, maxErrorLength), nextAttemptAt)
+}
+
+// encodeBody returns the request body to sign and send. The proxy client re-encodes the body with json.Marshal, which
+// compacts it and escapes HTML characters, so the payload is encoded in the same way first: the result is left unchanged
+// when it is encoded again, so the signed bytes are exactly those sent.
+func encodeBody(payload json.RawMessage) ([]byte, error) {
+	return json.Marshal(payload)
+}
+
+// retryDelay returns the delay before the next attempt, doubling after each failed attempt
+func retryDelay(attempts int) time.Duration {
+	delay := baseRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxRetryDelay)
+}
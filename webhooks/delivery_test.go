@@ -0,0 +1,30 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, retryDelay(1))
+	assert.Equal(t, time.Minute, retryDelay(2))
+	assert.Equal(t, 8*time.Minute, retryDelay(5))
+	assert.Equal(t, maxRetryDelay, retryDelay(20))
+}
+
+func TestEncodeBody(t *testing.T) {
+	body, err := encodeBody(json.RawMessage(`{"type": "ticket.closed", "data": {"reason": "<b>Done</b> & dusted"}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"ticket.closed","data":{"reason":"\u003cb\u003eDone\u003c/b\u003e \u0026 dusted"}}`, string(body))
+
+	// The proxy client encodes the body again before sending it
+	sent, err := json.Marshal(json.RawMessage(body))
+	assert.NoError(t, err)
+	assert.Equal(t, body, sent)
+
+	_, err = encodeBody(json.RawMessage(`{"type":`))
+	assert.Error(t, err)
+}
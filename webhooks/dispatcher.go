@@ -0,0 +1,58 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/redis"
+	"go.uber.org/zap"
+)
+
+// Payload is the JSON body sent to webhook endpoints
+type Payload struct {
+	Id        string                `json:"id"`
+	Type      redis.TicketEventType `json:"type"`
+	GuildId   uint64                `json:"guild_id,string"`
+	TicketId  int                   `json:"ticket_id"`
+	UserId    uint64                `json:"user_id,string"`
+	Timestamp time.Time             `json:"timestamp"`
+	Data      json.RawMessage       `json:"data,omitempty"`
+}
+
+// ListenEvents creates deliveries for ticket events published over Redis until ctx is cancelled. Every replica receives
+// each event, but deliveries are unique per event and webhook, so each event is only sent once.
+func ListenEvents(ctx context.Context, client *redis.RedisClient, logger *zap.Logger) {
+	ch := make(chan redis.TicketEvent)
+	go client.ListenTicketEvents(ctx, ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-ch:
+			if event.Id == "" || event.GuildId == 0 {
+				continue
+			}
+
+			payload, err := json.Marshal(Payload{
+				Id:        event.Id,
+				Type:      event.Type,
+				GuildId:   event.GuildId,
+				TicketId:  event.TicketId,
+				UserId:    event.UserId,
+				Timestamp: event.Timestamp,
+				Data:      event.Data,
+			})
+			if err != nil {
+				logger.Error("Failed to encode webhook payload", zap.Error(err), zap.String("event_id", event.Id))
+				continue
+			}
+
+			if err := dbclient.Dashboard.WebhookDeliveries.Enqueue(ctx, event.GuildId, event.Id, string(event.Type), payload); err != nil {
+				logger.Error("Failed to enqueue webhook deliveries", zap.Error(err), zap.String("event_id", event.Id), zap.Uint64("guild_id", event.GuildId))
+			}
+		}
+	}
+}
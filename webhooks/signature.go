@@ -0,0 +1,30 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderEvent     = "X-Tickets-Event"
+	HeaderDelivery  = "X-Tickets-Delivery"
+	HeaderTimestamp = "X-Tickets-Timestamp"
+	HeaderSignature = "X-Tickets-Signature"
+
+	signatureVersion = "v1"
+)
+
+// Sign returns the signature header value for a request body sent at the given time. Receivers should compute the
+// HMAC-SHA256 of "<timestamp>.<body>" with the webhook secret, compare it to the header in constant time, and reject
+// requests with timestamps too far in the past to prevent replays.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
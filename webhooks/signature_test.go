@@ -0,0 +1,18 @@
+package webhooks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+	body := []byte(`{"type":"ticket.opened"}`)
+
+	// echo -n '1700000000.{"type":"ticket.opened"}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "v1=b1f9c03859db1852a99402ca48f308712a83aec8537a7b8fd5852e29be7383df", Sign("secret", timestamp, body))
+	assert.NotEqual(t, Sign("secret", timestamp, body), Sign("other", timestamp, body))
+	assert.NotEqual(t, Sign("secret", timestamp, body), Sign("secret", timestamp.Add(time.Second), body))
+}
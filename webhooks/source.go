@@ -0,0 +1,146 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/redis"
+	"go.uber.org/zap"
+)
+
+const (
+	sourceInterval = 15 * time.Second
+	sourceLockKey  = "tickets:webhooks:eventsource"
+	sourceLockTtl  = 3 * sourceInterval
+
+	// recentLookback bounds how far back opened and closed tickets, and transcripts, are looked for. Changes are only
+	// missed if no replica has polled for this long.
+	recentLookback = 10 * time.Minute
+
+	// ratedLookback is how long after a ticket is closed that a rating is looked for
+	ratedLookback = 7 * 24 * time.Hour
+)
+
+type closedEventData struct {
+	Reason *string `json:"reason"`
+}
+
+type ratedEventData struct {
+	Rating int16 `json:"rating"`
+}
+
+type claimEventData struct {
+	ClaimedBy         *uint64 `json:"claimed_by,string"`
+	PreviousClaimedBy *uint64 `json:"previous_claimed_by,string"`
+}
+
+// RunEventSource publishes ticket.opened, ticket.claimed, ticket.unclaimed, ticket.closed, ticket.rated and
+// transcript.available events until ctx is cancelled. These changes are made by the worker, which does not publish
+// events itself, so they are found by polling its tables, in the guilds that have a webhook or live feed client to
+// receive them. One replica is elected to poll at a time, and each change is claimed in the database, so each event is
+// only published once.
+func RunEventSource(ctx context.Context, client *redis.RedisClient, logger *zap.Logger) {
+	ticker := time.NewTicker(sourceInterval)
+	defer ticker.Stop()
+
+	owner := uuid.NewString()
+
+	var lastCleanup time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		elected, err := client.HoldLock(ctx, sourceLockKey, owner, sourceLockTtl)
+		if err != nil {
+			logger.Error("Failed to acquire the webhook event source lock", zap.Error(err))
+			continue
+		}
+
+		if !elected {
+			continue
+		}
+
+		now := time.Now()
+		if now.Sub(lastCleanup) > cleanupInterval {
+			if err := dbclient.Dashboard.TicketEventLog.DeleteOlderThan(ctx, now.Add(-2*ratedLookback)); err != nil {
+				logger.Error("Failed to remove old ticket event claims", zap.Error(err))
+			}
+
+			if err := dbclient.Dashboard.TicketClaimState.DeleteClosed(ctx); err != nil {
+				logger.Error("Failed to remove the claim state of closed tickets", zap.Error(err))
+			}
+
+			lastCleanup = now
+		}
+
+		feedGuildIds, err := client.GetLiveFeedGuilds(ctx)
+		if err != nil {
+			logger.Error("Failed to fetch the guilds with live feed clients", zap.Error(err))
+			continue
+		}
+
+		activity, err := dbclient.Dashboard.TicketEventLog.ClaimNew(ctx, now.Add(-recentLookback), now.Add(-ratedLookback), feedGuildIds)
+		if err != nil {
+			logger.Error("Failed to fetch ticket activity", zap.Error(err))
+			continue
+		}
+
+		claims, err := dbclient.Dashboard.TicketClaimState.ClaimChanges(ctx, now.Add(-recentLookback), feedGuildIds)
+		if err != nil {
+			logger.Error("Failed to fetch ticket claim changes", zap.Error(err))
+		}
+
+		activity = append(activity, claims...)
+
+		for _, change := range activity {
+			event, err := newTicketEvent(change)
+			if err != nil {
+				logger.Error("Failed to encode ticket event", zap.Error(err), zap.Uint64("guild_id", change.GuildId), zap.Int("ticket_id", change.TicketId))
+				continue
+			}
+
+			if err := client.PublishTicketEvent(ctx, event); err != nil {
+				logger.Error("Failed to publish ticket event", zap.Error(err), zap.Uint64("guild_id", change.GuildId), zap.Int("ticket_id", change.TicketId))
+			}
+		}
+	}
+}
+
+func newTicketEvent(change dbclient.TicketActivity) (redis.TicketEvent, error) {
+	event := redis.TicketEvent{
+		Type:      redis.TicketEventType(change.Type),
+		GuildId:   change.GuildId,
+		TicketId:  change.TicketId,
+		UserId:    change.UserId,
+		Timestamp: change.OccurredAt,
+	}
+
+	var data any
+	switch event.Type {
+	case redis.TicketEventClosed:
+		data = closedEventData{Reason: change.Reason}
+	case redis.TicketEventRated:
+		if change.Rating != nil {
+			data = ratedEventData{Rating: *change.Rating}
+		}
+	case redis.TicketEventClaimed, redis.TicketEventUnclaimed:
+		data = claimEventData{ClaimedBy: change.ClaimedBy, PreviousClaimedBy: change.PreviousClaimedBy}
+	}
+
+	if data != nil {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return redis.TicketEvent{}, err
+		}
+
+		event.Data = encoded
+	}
+
+	return event, nil
+}
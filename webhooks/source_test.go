@@ -0,0 +1,62 @@
+package webhooks
+
+import (
+	"testing"
+	"time"
+
+	dbclient "github.com/jadevelopmentgrp/Tickets-Dashboard/database"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/redis"
+	"github.com/jadevelopmentgrp/Tickets-Dashboard/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTicketEvent(t *testing.T) {
+	occurredAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		change dbclient.TicketActivity
+		data   string
+	}{
+		{change: dbclient.TicketActivity{Type: "ticket.opened", UserId: 3}},
+		{change: dbclient.TicketActivity{Type: "ticket.closed", UserId: 4, Reason: utils.Ptr("Resolved")}, data: `{"reason":"Resolved"}`},
+		{change: dbclient.TicketActivity{Type: "ticket.rated", UserId: 3, Rating: utils.Ptr(int16(5))}, data: `{"rating":5}`},
+		{change: dbclient.TicketActivity{Type: "transcript.available"}},
+		{change: dbclient.TicketActivity{Type: "ticket.claimed", ClaimedBy: utils.Ptr(uint64(5))}, data: `{"claimed_by":"5","previous_claimed_by":null}`},
+		{change: dbclient.TicketActivity{Type: "ticket.unclaimed", PreviousClaimedBy: utils.Ptr(uint64(5))}, data: `{"claimed_by":null,"previous_claimed_by":"5"}`},
+	}
+
+	emitted := make(map[redis.TicketEventType]bool)
+	for _, test := range tests {
+		test.change.GuildId = 1
+		test.change.TicketId = 2
+		test.change.OccurredAt = occurredAt
+
+		event, err := newTicketEvent(test.change)
+		if !assert.NoError(t, err, test.change.Type) {
+			continue
+		}
+
+		assert.Equal(t, redis.TicketEventType(test.change.Type), event.Type)
+		assert.Equal(t, uint64(1), event.GuildId)
+		assert.Equal(t, 2, event.TicketId)
+		assert.Equal(t, test.change.UserId, event.UserId)
+		assert.Equal(t, occurredAt, event.Timestamp)
+
+		if test.data == "" {
+			assert.Empty(t, event.Data, test.change.Type)
+		} else {
+			assert.JSONEq(t, test.data, string(event.Data), test.change.Type)
+		}
+
+		emitted[event.Type] = true
+	}
+
+	// The remaining event type is published by the SLA evaluator
+	for _, eventType := range redis.TicketEventTypes {
+		switch eventType {
+		case redis.TicketEventSlaBreached:
+		default:
+			assert.True(t, emitted[eventType], "%s is not emitted", eventType)
+		}
+	}
+}